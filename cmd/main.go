@@ -2,17 +2,41 @@ package main
 
 import (
 	"context"
+	"errors"
 	_ "github.com/ilam072/shortener/docs"
+	apikeyrepo "github.com/ilam072/shortener/internal/apikey/repo/postgres"
+	apikeyrest "github.com/ilam072/shortener/internal/apikey/rest"
+	apikeyservice "github.com/ilam072/shortener/internal/apikey/service"
+	"github.com/ilam072/shortener/internal/auth"
+	"github.com/ilam072/shortener/internal/click/bots"
+	clickfeed "github.com/ilam072/shortener/internal/click/feed"
+	"github.com/ilam072/shortener/internal/click/geoip"
+	clickoutbox "github.com/ilam072/shortener/internal/click/outbox"
 	clickrepo "github.com/ilam072/shortener/internal/click/repo/postgres"
 	clickrest "github.com/ilam072/shortener/internal/click/rest"
+	clicksalt "github.com/ilam072/shortener/internal/click/salt"
 	clickservice "github.com/ilam072/shortener/internal/click/service"
 	"github.com/ilam072/shortener/internal/config"
+	domainrepo "github.com/ilam072/shortener/internal/customdomain/repo/postgres"
+	domainrest "github.com/ilam072/shortener/internal/customdomain/rest"
+	domainservice "github.com/ilam072/shortener/internal/customdomain/service"
+	"github.com/ilam072/shortener/internal/eventbus"
+	"github.com/ilam072/shortener/internal/eventbus/publisher"
 	"github.com/ilam072/shortener/internal/link/cache"
 	linkrepo "github.com/ilam072/shortener/internal/link/repo/postgres"
 	linkrest "github.com/ilam072/shortener/internal/link/rest"
 	linkservice "github.com/ilam072/shortener/internal/link/service"
 	"github.com/ilam072/shortener/internal/middleware"
+	userrepo "github.com/ilam072/shortener/internal/user/repo/postgres"
+	userrest "github.com/ilam072/shortener/internal/user/rest"
+	userservice "github.com/ilam072/shortener/internal/user/service"
 	"github.com/ilam072/shortener/internal/validator"
+	webhookrepo "github.com/ilam072/shortener/internal/webhook/repo/postgres"
+	webhookrest "github.com/ilam072/shortener/internal/webhook/rest"
+	webhookservice "github.com/ilam072/shortener/internal/webhook/service"
+	workspacerepo "github.com/ilam072/shortener/internal/workspace/repo/postgres"
+	workspacerest "github.com/ilam072/shortener/internal/workspace/rest"
+	workspaceservice "github.com/ilam072/shortener/internal/workspace/service"
 	"github.com/ilam072/shortener/pkg/db"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
@@ -22,15 +46,32 @@ import (
 	"github.com/wb-go/wbf/zlog"
 	"net/http"
 	"os/signal"
+	"slices"
+	"strings"
 	"syscall"
 	"time"
 )
 
+// reservedPaths are the top-level path segments of the service. While
+// redirects are served at the root, aliases and workspace slugs cannot take
+// them, including ones reserved for routes yet to come.
+var reservedPaths = []string{"api", "swagger", "health", "metrics", "static", "favicon.ico", "robots.txt"}
+
+// defaultRedirectPrefix is where redirects are served unless configured
+// otherwise, the one path under the reserved segments set aside for them.
+const defaultRedirectPrefix = "/api/s"
+
 // @title Shortener API
 // @version 1.0
 // @description REST API сервиса сокращения ссылок с аналитикой кликов
 // @BasePath /api
 // @schemes http
+// @securityDefinitions.apikey ApiKeyAuth
+// @in header
+// @name X-API-Key
+// @securityDefinitions.apikey BearerAuth
+// @in header
+// @name Authorization
 func main() {
 	// Initialize logger
 	zlog.Init()
@@ -61,6 +102,64 @@ func main() {
 	// Initialize cache
 	linkCache := cache.New(redisClient)
 
+	// Initialize click outbox
+	outboxStream := cfg.Click.OutboxStream
+	if outboxStream == "" {
+		outboxStream = "clicks:outbox"
+	}
+	clickOutbox := clickoutbox.New(redisClient, outboxStream)
+	visitorSalts := clicksalt.New(redisClient)
+
+	// Initialize geoip
+	var geo clickservice.GeoLocator
+	if cfg.Click.GeoIPDatabase != "" {
+		locator, err := geoip.Open(cfg.Click.GeoIPDatabase)
+		if err != nil {
+			zlog.Logger.Fatal().Err(err).Msg("failed to open geoip database")
+		}
+		defer locator.Close()
+		geo = locator
+	} else {
+		zlog.Logger.Info().Msg("GEOIP_DATABASE is not set, clicks are not located")
+	}
+
+	// Initialize bot classifier
+	var botClassifier *bots.Classifier
+	if cfg.Click.BotDenylist != "" {
+		botClassifier, err = bots.Load(cfg.Click.BotDenylist)
+	} else {
+		botClassifier, err = bots.New(nil)
+	}
+	if err != nil {
+		zlog.Logger.Fatal().Err(err).Msg("failed to initialize bot classifier")
+	}
+	ipMode := cfg.Click.IPMode
+	switch ipMode {
+	case "":
+		ipMode = clickservice.IPModeTruncate
+	case clickservice.IPModeFull, clickservice.IPModeTruncate, clickservice.IPModeHash, clickservice.IPModeDrop:
+	default:
+		zlog.Logger.Fatal().Str("mode", ipMode).Msg("unknown click ip mode")
+	}
+	clickEnricher := clickservice.NewEnricher(visitorSalts, geo, botClassifier, ipMode)
+
+	// Initialize live click feed
+	var clickFeed clickservice.ClickFeed
+	switch cfg.Click.StreamPubSub {
+	case "", "redis":
+		streamChannel := cfg.Click.StreamChannel
+		if streamChannel == "" {
+			streamChannel = "clicks:live"
+		}
+		redisFeed := clickfeed.NewRedis(redisClient, streamChannel)
+		go redisFeed.Run(ctx)
+		clickFeed = redisFeed
+	case "memory":
+		clickFeed = clickfeed.NewMemory()
+	default:
+		zlog.Logger.Fatal().Str("pubsub", cfg.Click.StreamPubSub).Msg("unknown click stream pubsub")
+	}
+
 	// Initialize retry strategy
 	strategy := retry.Strategy{
 		Attempts: cfg.Retry.Attempts,
@@ -68,39 +167,217 @@ func main() {
 		Backoff:  cfg.Retry.Backoff,
 	}
 
-	// Initialize link and click repositories
+	// Initialize repositories
 	clickRepo := clickrepo.New(DB)
 	linkRepo := linkrepo.New(DB)
+	apiKeyRepo := apikeyrepo.New(DB)
+	userRepo := userrepo.New(DB)
+	workspaceRepo := workspacerepo.New(DB)
+	domainRepo := domainrepo.New(DB)
+	webhookRepo := webhookrepo.New(DB)
 
-	// Initialize link and click services
-	link := linkservice.New(linkRepo, linkCache)
-	click := clickservice.New(clickRepo)
+	// Initialize services
+	aliasNamespace := cfg.Link.AliasNamespace
+	if aliasNamespace == "" {
+		aliasNamespace = linkservice.NamespaceGlobal
+	}
+	if aliasNamespace != linkservice.NamespaceGlobal && aliasNamespace != linkservice.NamespaceWorkspace {
+		zlog.Logger.Fatal().Str("namespace", aliasNamespace).Msg("unknown alias namespace")
+	}
+	redirectPrefix := defaultRedirectPrefix
+	if cfg.Link.RedirectPrefix != "" {
+		redirectPrefix = strings.TrimSuffix("/"+strings.Trim(cfg.Link.RedirectPrefix, "/"), "/")
+	}
+	// Under a reserved segment, aliases would collide with the routes
+	// there, such as /api/links.
+	segment, _, _ := strings.Cut(strings.TrimPrefix(redirectPrefix, "/"), "/")
+	if redirectPrefix != defaultRedirectPrefix && slices.Contains(reservedPaths, segment) {
+		zlog.Logger.Fatal().Str("prefix", redirectPrefix).Msg("redirect prefix is reserved")
+	}
+	var reserved []string
+	if redirectPrefix == "" {
+		reserved = reservedPaths
+	}
+	publicBaseURL := cfg.Link.PublicBaseURL
+	if publicBaseURL == "" {
+		publicBaseURL = "http://localhost" + cfg.Server.HTTPPort
+		zlog.Logger.Warn().Str("url", publicBaseURL).Msg("PUBLIC_BASE_URL is not set, short URLs use a local one")
+	}
+	baseURL := strings.TrimSuffix(publicBaseURL, "/") + redirectPrefix
+
+	webhookTimeout := cfg.Webhook.Timeout
+	if webhookTimeout <= 0 {
+		webhookTimeout = 5 * time.Second
+	}
+	webhookSender := webhookservice.NewSender(webhookTimeout)
+	dispatcher := webhookservice.NewDispatcher(webhookRepo, webhookSender, strategy, webhookDispatcherConfig(cfg.Webhook))
+	dispatcher.Start()
+	expiryInterval := cfg.Webhook.ExpiryInterval
+	if expiryInterval <= 0 {
+		expiryInterval = time.Minute
+	}
+	go webhookservice.NewExpiryWatcher(webhookRepo, dispatcher, expiryInterval).Run(ctx)
+
+	// Initialize event publisher
+	var eventPublisher eventbus.EventPublisher = publisher.Noop{}
+	var busPublisher *publisher.Publisher
+	var bus publisher.Bus
+	eventsConfig := eventPublisherConfig(cfg.Events)
+	switch cfg.Events.Publisher {
+	case "", "none":
+	case "redis":
+		eventStream := cfg.Events.RedisStream
+		if eventStream == "" {
+			eventStream = "shortener:events"
+		}
+		bus = publisher.NewRedisStream(redisClient, eventStream, cfg.Events.RedisMaxLen)
+	case "kafka":
+		var brokers []string
+		for _, broker := range strings.Split(cfg.Events.KafkaBrokers, ",") {
+			if broker = strings.TrimSpace(broker); broker != "" {
+				brokers = append(brokers, broker)
+			}
+		}
+		if len(brokers) == 0 {
+			zlog.Logger.Fatal().Msg("KAFKA_BROKERS is not set")
+		}
+		topic := cfg.Events.KafkaTopic
+		if topic == "" {
+			topic = "shortener.events"
+		}
+		bus = publisher.NewKafka(brokers, topic, eventsConfig.BatchSize)
+	default:
+		zlog.Logger.Fatal().Str("publisher", cfg.Events.Publisher).Msg("unknown event publisher")
+	}
+	if bus != nil {
+		busPublisher = publisher.New(bus, strategy, eventsConfig)
+		busPublisher.Start()
+		eventPublisher = busPublisher
+	}
 
-	// Initialize link and click handlers
-	linkHandler := linkrest.NewLinkHandler(link, click, v, strategy)
-	clickHandler := clickrest.NewClickHandler(click)
+	link := linkservice.New(linkRepo, linkCache, dispatcher, eventPublisher, cfg.Link.AliasQuarantine, aliasNamespace, baseURL, reserved)
+	click := clickservice.New(clickRepo, clickOutbox, clickEnricher, clickFeed, dispatcher, eventPublisher)
+	queueConfig := clickQueueConfig(cfg.Click)
+	clickQueue := clickservice.NewQueue(clickRepo, clickOutbox, clickEnricher, clickFeed, dispatcher, eventPublisher, queueConfig)
+	clickQueue.Start()
+	replayInterval := cfg.Click.ReplayInterval
+	if replayInterval <= 0 {
+		replayInterval = 10 * time.Second
+	}
+	go clickservice.NewReplayer(clickRepo, clickOutbox, queueConfig.BatchSize, replayInterval).Run(ctx)
+	compactInterval := cfg.Click.CompactInterval
+	if compactInterval <= 0 {
+		compactInterval = time.Minute
+	}
+	expiredPartitions := cfg.Click.ExpiredPartitions
+	switch expiredPartitions {
+	case "":
+		expiredPartitions = clickservice.ExpiredDrop
+	case clickservice.ExpiredDrop, clickservice.ExpiredDetach:
+	default:
+		zlog.Logger.Fatal().Str("expired", expiredPartitions).Msg("unknown expired click partitions mode")
+	}
+	go clickservice.NewCompactor(clickRepo, cfg.Click.RetentionDays, expiredPartitions, compactInterval).Run(ctx)
+	streamBuffer := cfg.Click.StreamBuffer
+	if streamBuffer <= 0 {
+		streamBuffer = 64
+	}
+	live := clickservice.NewLive(clickRepo, clickFeed, streamBuffer)
+	apiKey := apikeyservice.New(apiKeyRepo, cfg.Auth.BootstrapAPIKey)
+	user := userservice.New(userRepo, cfg.Auth.JWTSecret, cfg.Auth.JWTTTL)
+	workspace := workspaceservice.New(workspaceRepo, reserved)
+	customDomain := domainservice.New(domainRepo)
+	webhook := webhookservice.New(webhookRepo, webhookSender)
+
+	// Initialize handlers
+	linkHandler := linkrest.NewLinkHandler(link, clickQueue, v, strategy)
+	clickHandler := clickrest.NewClickHandler(click, live, clickStreamConfig(cfg.Click))
+	apiKeyHandler := apikeyrest.NewAPIKeyHandler(apiKey, v)
+	userHandler := userrest.NewUserHandler(user, v)
+	workspaceHandler := workspacerest.NewWorkspaceHandler(workspace, v)
+	domainHandler := domainrest.NewDomainHandler(customDomain, v)
+	webhookHandler := webhookrest.NewWebhookHandler(webhook, v)
 
 	// Initialize Gin engine
 	engine := ginext.New("")
 	engine.Use(ginext.Logger())
 	engine.Use(ginext.Recovery())
-	engine.Use(middleware.TimeoutMiddleware(2 * time.Second))
+	// Live streams are bounded by their idle timeout instead, exports by
+	// their write timeout and redeliveries by the webhook timeout.
+	engine.Use(middleware.TimeoutMiddleware(
+		2*time.Second,
+		"/api/analytics/:alias/stream",
+		"/api/analytics/:alias/export",
+		"/api/webhooks/:id/dead-letters/:letter_id/redeliver",
+	))
+
+	// Custom domains serve their short links at the root of the host.
+	var defaultHosts []string
+	for _, host := range strings.Split(cfg.Server.DefaultHosts, ",") {
+		if host = strings.TrimSpace(host); host != "" {
+			defaultHosts = append(defaultHosts, host)
+		}
+	}
+	engine.Use(middleware.HostRouting(defaultHosts, linkHandler.RedirectByHost))
 
 	engine.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
+	canCreate := middleware.AuthMiddleware(apiKey, user, auth.ScopeCreate)
+	canRead := middleware.AuthMiddleware(apiKey, user, auth.ScopeReadAnalytics)
+	isAdmin := middleware.AuthMiddleware(apiKey, user, auth.ScopeAdmin)
+	isOperator := middleware.AuthMiddleware(apiKey, user, auth.ScopeOperator)
+
+	// Everything is scoped to the caller's workspace. Link management is
+	// further scoped to the caller's own links, admins see all of them.
+	// Static routes take precedence over the redirect parameters, so
+	// redirects at the root do not shadow the API.
+	redirects := engine.Group(redirectPrefix)
+	if aliasNamespace == linkservice.NamespaceWorkspace {
+		redirects.GET("/:workspace/:alias", linkHandler.Redirect)
+	} else {
+		redirects.GET("/:alias", linkHandler.Redirect)
+	}
+
 	apiGroup := engine.Group("/api")
-	apiGroup.POST("/shorten", linkHandler.CreateLink)
-	apiGroup.GET("/s/:alias", linkHandler.Redirect)
-	apiGroup.GET("/analytics/:alias", clickHandler.GetAnalytics)
+	apiGroup.POST("/auth/login", userHandler.Login)
+	apiGroup.POST("/shorten", canCreate, linkHandler.CreateLink)
+	apiGroup.GET("/links", canRead, linkHandler.ListLinks)
+	apiGroup.GET("/links/:alias", canRead, linkHandler.GetLink)
+	apiGroup.PATCH("/links/:alias", canCreate, linkHandler.UpdateLink)
+	apiGroup.DELETE("/links/:alias", canCreate, linkHandler.DeleteLink)
+	apiGroup.POST("/links/:alias/disable", canCreate, linkHandler.DisableLink)
+	apiGroup.POST("/links/:alias/restore", canCreate, linkHandler.RestoreLink)
+	apiGroup.GET("/analytics/:alias", canRead, clickHandler.GetAnalytics)
+	apiGroup.GET("/analytics/:alias/stream", canRead, clickHandler.StreamClicks)
+	apiGroup.GET("/analytics/:alias/export", canRead, clickHandler.Export)
+	apiGroup.GET("/dashboard/top-links", canRead, clickHandler.GetTopLinks)
+	apiGroup.GET("/dashboard/clicks", canRead, clickHandler.GetWorkspaceClicks)
+	apiGroup.GET("/dashboard/links", canRead, clickHandler.GetCreatedLinks)
+	apiGroup.GET("/dashboard/domains", canRead, clickHandler.GetLinkDomains)
+	apiGroup.POST("/keys", isAdmin, apiKeyHandler.CreateKey)
+	apiGroup.GET("/keys", isAdmin, apiKeyHandler.ListKeys)
+	apiGroup.DELETE("/keys/:id", isAdmin, apiKeyHandler.RevokeKey)
+	apiGroup.POST("/users", isAdmin, userHandler.CreateUser)
+	apiGroup.POST("/workspaces", isOperator, workspaceHandler.CreateWorkspace)
+	apiGroup.GET("/workspaces", isOperator, workspaceHandler.ListWorkspaces)
+	apiGroup.POST("/domains", isAdmin, domainHandler.CreateDomain)
+	apiGroup.GET("/domains", isAdmin, domainHandler.ListDomains)
+	apiGroup.DELETE("/domains/:id", isAdmin, domainHandler.DeleteDomain)
+	apiGroup.POST("/webhooks", isAdmin, webhookHandler.CreateWebhook)
+	apiGroup.GET("/webhooks", isAdmin, webhookHandler.ListWebhooks)
+	apiGroup.DELETE("/webhooks/:id", isAdmin, webhookHandler.DeleteWebhook)
+	apiGroup.GET("/webhooks/:id/dead-letters", isAdmin, webhookHandler.ListDeadLetters)
+	apiGroup.POST("/webhooks/:id/dead-letters/:letter_id/redeliver", isAdmin, webhookHandler.Redeliver)
 
 	// Initialize and start http server
 	server := &http.Server{
 		Addr:    cfg.Server.HTTPPort,
 		Handler: engine,
 	}
+	server.RegisterOnShutdown(clickHandler.CloseStreams)
 
 	go func() {
-		if err := server.ListenAndServe(); err != nil {
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
 			zlog.Logger.Fatal().Err(err).Msg("failed to listen start http server")
 		}
 	}()
@@ -115,7 +392,111 @@ func main() {
 		zlog.Logger.Error().Err(err).Msg("server shutdown failed")
 	}
 
+	// Queued clicks are written before the database goes away.
+	drainCtx, drainCancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer drainCancel()
+
+	if err = clickQueue.Shutdown(drainCtx); err != nil {
+		zlog.Logger.Error().Err(err).Msg("failed to drain click queue")
+	}
+
+	// Events of the drained clicks are delivered and published last.
+	if err = dispatcher.Shutdown(drainCtx); err != nil {
+		zlog.Logger.Error().Err(err).Msg("failed to drain webhook events")
+	}
+	if busPublisher != nil {
+		if err = busPublisher.Shutdown(drainCtx); err != nil {
+			zlog.Logger.Error().Err(err).Msg("failed to drain published events")
+		}
+	}
+
 	if err := DB.Master.Close(); err != nil {
 		zlog.Logger.Error().Err(err).Msg("failed to close master database")
 	}
 }
+
+// clickStreamConfig fills in defaults for the unset live stream settings.
+func clickStreamConfig(cfg config.ClickConfig) clickrest.StreamConfig {
+	stream := clickrest.StreamConfig{
+		Heartbeat:    15 * time.Second,
+		IdleTimeout:  10 * time.Minute,
+		WriteTimeout: 10 * time.Second,
+	}
+	if cfg.StreamHeartbeat > 0 {
+		stream.Heartbeat = cfg.StreamHeartbeat
+	}
+	if cfg.StreamIdleTimeout > 0 {
+		stream.IdleTimeout = cfg.StreamIdleTimeout
+	}
+	if cfg.StreamWriteTimeout > 0 {
+		stream.WriteTimeout = cfg.StreamWriteTimeout
+	}
+	return stream
+}
+
+// clickQueueConfig fills in defaults for the unset click queue settings.
+func clickQueueConfig(cfg config.ClickConfig) clickservice.QueueConfig {
+	queue := clickservice.QueueConfig{
+		Size:          10000,
+		Workers:       4,
+		BatchSize:     500,
+		FlushInterval: time.Second,
+		Overflow:      clickservice.OverflowDrop,
+	}
+	if cfg.QueueSize > 0 {
+		queue.Size = cfg.QueueSize
+	}
+	if cfg.Workers > 0 {
+		queue.Workers = cfg.Workers
+	}
+	if cfg.BatchSize > 0 {
+		queue.BatchSize = cfg.BatchSize
+	}
+	if cfg.FlushInterval > 0 {
+		queue.FlushInterval = cfg.FlushInterval
+	}
+	switch cfg.QueueOverflow {
+	case "":
+	case clickservice.OverflowDrop, clickservice.OverflowBlock:
+		queue.Overflow = cfg.QueueOverflow
+	default:
+		zlog.Logger.Fatal().Str("overflow", cfg.QueueOverflow).Msg("unknown click queue overflow")
+	}
+	return queue
+}
+
+// webhookDispatcherConfig fills in defaults for the unset webhook delivery
+// settings.
+func webhookDispatcherConfig(cfg config.WebhookConfig) webhookservice.DispatcherConfig {
+	dispatcher := webhookservice.DispatcherConfig{
+		Size:          1000,
+		Workers:       4,
+		RetryInterval: 30 * time.Second,
+	}
+	if cfg.QueueSize > 0 {
+		dispatcher.Size = cfg.QueueSize
+	}
+	if cfg.Workers > 0 {
+		dispatcher.Workers = cfg.Workers
+	}
+	if cfg.RetryInterval > 0 {
+		dispatcher.RetryInterval = cfg.RetryInterval
+	}
+	return dispatcher
+}
+
+// eventPublisherConfig fills in defaults for the unset event publisher
+// settings.
+func eventPublisherConfig(cfg config.EventsConfig) publisher.Config {
+	events := publisher.Config{
+		Size:      10000,
+		BatchSize: 100,
+	}
+	if cfg.QueueSize > 0 {
+		events.Size = cfg.QueueSize
+	}
+	if cfg.BatchSize > 0 {
+		events.BatchSize = cfg.BatchSize
+	}
+	return events
+}
@@ -0,0 +1,146 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/ilam072/shortener/internal/apikey/repo"
+	"github.com/ilam072/shortener/internal/apikey/types/domain"
+	"github.com/ilam072/shortener/pkg/errutils"
+	"github.com/lib/pq"
+	"github.com/wb-go/wbf/dbpg"
+)
+
+type APIKeyRepo struct {
+	db *dbpg.DB
+}
+
+func New(db *dbpg.DB) *APIKeyRepo {
+	return &APIKeyRepo{db: db}
+}
+
+func (r *APIKeyRepo) CreateKey(ctx context.Context, key domain.APIKey) (domain.APIKey, error) {
+	const op = "repo.apikey.Create"
+
+	query := `
+		INSERT INTO api_keys(id, owner_id, workspace_id, name, prefix, key_hash, scopes)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING created_at;
+	`
+
+	if err := r.db.QueryRowContext(
+		ctx,
+		query,
+		key.ID,
+		key.OwnerID,
+		key.WorkspaceID,
+		key.Name,
+		key.Prefix,
+		key.Hash,
+		pq.Array(key.Scopes),
+	).Scan(&key.CreatedAt); err != nil {
+		return domain.APIKey{}, errutils.Wrap(op, err)
+	}
+
+	return key, nil
+}
+
+// TouchKey looks up an active key by hash and records that it was used.
+func (r *APIKeyRepo) TouchKey(ctx context.Context, hash string) (domain.APIKey, error) {
+	const op = "repo.apikey.Touch"
+
+	query := `
+		UPDATE api_keys k
+		SET last_used_at = now()
+		FROM workspaces w
+		WHERE k.key_hash = $1 AND k.revoked_at IS NULL AND w.id = k.workspace_id
+		RETURNING k.id, k.owner_id, k.workspace_id, w.slug, k.name, k.prefix, k.scopes, k.created_at, k.last_used_at;
+	`
+
+	var key domain.APIKey
+	if err := r.db.QueryRowContext(ctx, query, hash).Scan(
+		&key.ID,
+		&key.OwnerID,
+		&key.WorkspaceID,
+		&key.WorkspaceSlug,
+		&key.Name,
+		&key.Prefix,
+		pq.Array(&key.Scopes),
+		&key.CreatedAt,
+		&key.LastUsedAt,
+	); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.APIKey{}, errutils.Wrap(op, repo.ErrKeyNotFound)
+		}
+		return domain.APIKey{}, errutils.Wrap(op, err)
+	}
+
+	return key, nil
+}
+
+func (r *APIKeyRepo) ListKeys(ctx context.Context, workspaceID uuid.UUID) ([]domain.APIKey, error) {
+	const op = "repo.apikey.List"
+
+	query := `
+		SELECT id, owner_id, workspace_id, name, prefix, scopes, created_at, last_used_at, revoked_at
+		FROM api_keys
+		WHERE workspace_id = $1
+		ORDER BY created_at DESC;
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, workspaceID)
+	if err != nil {
+		return nil, errutils.Wrap(op, err)
+	}
+	defer rows.Close()
+
+	var keys []domain.APIKey
+	for rows.Next() {
+		var key domain.APIKey
+		if err := rows.Scan(
+			&key.ID,
+			&key.OwnerID,
+			&key.WorkspaceID,
+			&key.Name,
+			&key.Prefix,
+			pq.Array(&key.Scopes),
+			&key.CreatedAt,
+			&key.LastUsedAt,
+			&key.RevokedAt,
+		); err != nil {
+			return nil, errutils.Wrap(op, err)
+		}
+		keys = append(keys, key)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errutils.Wrap(op, err)
+	}
+
+	return keys, nil
+}
+
+func (r *APIKeyRepo) RevokeKey(ctx context.Context, workspaceID uuid.UUID, id uuid.UUID) error {
+	const op = "repo.apikey.Revoke"
+
+	query := `
+		UPDATE api_keys
+		SET revoked_at = COALESCE(revoked_at, now())
+		WHERE id = $1 AND workspace_id = $2;
+	`
+
+	res, err := r.db.ExecContext(ctx, query, id, workspaceID)
+	if err != nil {
+		return errutils.Wrap(op, err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return errutils.Wrap(op, err)
+	}
+	if affected == 0 {
+		return errutils.Wrap(op, repo.ErrKeyNotFound)
+	}
+
+	return nil
+}
@@ -0,0 +1,7 @@
+package repo
+
+import "errors"
+
+var (
+	ErrKeyNotFound = errors.New("api key not found")
+)
@@ -0,0 +1,127 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/ilam072/shortener/internal/apikey/service"
+	"github.com/ilam072/shortener/internal/apikey/types/dto"
+	"github.com/ilam072/shortener/internal/auth"
+	"github.com/ilam072/shortener/internal/response"
+	"github.com/wb-go/wbf/ginext"
+	"github.com/wb-go/wbf/zlog"
+	"net/http"
+)
+
+//go:generate mockgen -source=handler.go -destination=../mocks/rest_mocks.go -package=mocks
+type APIKey interface {
+	CreateKey(ctx context.Context, creator auth.Principal, key dto.CreateAPIKey) (dto.CreatedAPIKey, error)
+	ListKeys(ctx context.Context, workspaceID uuid.UUID) ([]dto.APIKey, error)
+	RevokeKey(ctx context.Context, workspaceID uuid.UUID, id string) error
+}
+
+type Validator interface {
+	Validate(i interface{}) error
+}
+
+type APIKeyHandler struct {
+	apiKey    APIKey
+	validator Validator
+}
+
+func NewAPIKeyHandler(apiKey APIKey, validator Validator) *APIKeyHandler {
+	return &APIKeyHandler{apiKey: apiKey, validator: validator}
+}
+
+// CreateKey godoc
+// @Summary Создать API-ключ
+// @Description Создаёт API-ключ с указанными правами от имени вызывающего пользователя. Ключ в открытом виде возвращается только в этом ответе
+// @Tags API keys
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Param input body dto.CreateAPIKey true "Имя и права ключа"
+// @Success 201 {object} response.Response{payload=dto.CreatedAPIKey} "Созданный ключ"
+// @Failure 400 {object} response.Response "invalid request body или validation error"
+// @Failure 401 {object} response.Response "invalid credentials"
+// @Failure 403 {object} response.Response "insufficient scope"
+// @Failure 500 {object} response.Response "internal server error"
+// @Router /keys [post]
+func (h *APIKeyHandler) CreateKey(c *ginext.Context) {
+	var key dto.CreateAPIKey
+	if err := json.NewDecoder(c.Request.Body).Decode(&key); err != nil {
+		response.Error("invalid request body").WriteJSON(c, http.StatusBadRequest)
+		return
+	}
+	if err := h.validator.Validate(key); err != nil {
+		response.Error(fmt.Sprintf("validation error: %s", err.Error())).WriteJSON(c, http.StatusBadRequest)
+		return
+	}
+
+	creator := auth.FromContext(c.Request.Context())
+	created, err := h.apiKey.CreateKey(c.Request.Context(), creator, key)
+	if err != nil {
+		zlog.Logger.Error().Err(err).Str("name", key.Name).Msg("failed to create api key")
+		response.Error("internal server error, try again later").WriteJSON(c, http.StatusInternalServerError)
+		return
+	}
+
+	response.Success(created).WriteJSON(c, http.StatusCreated)
+}
+
+// ListKeys godoc
+// @Summary Список API-ключей
+// @Description Возвращает все API-ключи рабочего пространства, включая отозванные, без самих ключей
+// @Tags API keys
+// @Produce json
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Success 200 {object} response.Response{payload=[]dto.APIKey} "API-ключи"
+// @Failure 401 {object} response.Response "invalid credentials"
+// @Failure 403 {object} response.Response "insufficient scope"
+// @Failure 500 {object} response.Response "internal server error"
+// @Router /keys [get]
+func (h *APIKeyHandler) ListKeys(c *ginext.Context) {
+	workspace := auth.FromContext(c.Request.Context()).Workspace
+	keys, err := h.apiKey.ListKeys(c.Request.Context(), workspace.ID)
+	if err != nil {
+		zlog.Logger.Error().Err(err).Msg("failed to list api keys")
+		response.Error("internal server error, try again later").WriteJSON(c, http.StatusInternalServerError)
+		return
+	}
+
+	response.Success(keys).WriteJSON(c, http.StatusOK)
+}
+
+// RevokeKey godoc
+// @Summary Отозвать API-ключ
+// @Description Отзывает API-ключ по id. Отозванный ключ больше не проходит аутентификацию
+// @Tags API keys
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Param id path string true "ID ключа"
+// @Success 204 "Key revoked"
+// @Failure 401 {object} response.Response "invalid credentials"
+// @Failure 403 {object} response.Response "insufficient scope"
+// @Failure 404 {object} response.Response "api key not found"
+// @Failure 500 {object} response.Response "internal server error"
+// @Router /keys/{id} [delete]
+func (h *APIKeyHandler) RevokeKey(c *ginext.Context) {
+	id := c.Param("id")
+
+	workspace := auth.FromContext(c.Request.Context()).Workspace
+	if err := h.apiKey.RevokeKey(c.Request.Context(), workspace.ID, id); err != nil {
+		if errors.Is(err, service.ErrKeyNotFound) {
+			response.Error("api key not found").WriteJSON(c, http.StatusNotFound)
+			return
+		}
+		zlog.Logger.Error().Err(err).Str("id", id).Msg("failed to revoke api key")
+		response.Error("internal server error, try again later").WriteJSON(c, http.StatusInternalServerError)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
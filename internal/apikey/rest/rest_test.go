@@ -0,0 +1,191 @@
+package rest_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"github.com/wb-go/wbf/ginext"
+	"go.uber.org/mock/gomock"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ilam072/shortener/internal/apikey/mocks"
+	"github.com/ilam072/shortener/internal/apikey/rest"
+	"github.com/ilam072/shortener/internal/apikey/service"
+	"github.com/ilam072/shortener/internal/apikey/types/dto"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+func newTestContext(method, path string, body []byte) (*ginext.Context, *httptest.ResponseRecorder) {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+
+	req := httptest.NewRequest(method, path, bytes.NewBuffer(body))
+	c.Request = req
+
+	return c, w
+}
+
+func TestAPIKeyHandler_CreateKey(t *testing.T) {
+	type fields struct {
+		setup func(apiKey *mocks.MockAPIKey, validator *mocks.MockValidator)
+	}
+	type want struct {
+		status int
+	}
+
+	tests := []struct {
+		name   string
+		body   interface{}
+		fields fields
+		want   want
+	}{
+		{
+			name: "invalid json",
+			body: "invalid",
+			want: want{status: http.StatusBadRequest},
+		},
+		{
+			name: "validation error",
+			body: dto.CreateAPIKey{Name: "ci", Scopes: []string{"root"}},
+			fields: fields{
+				setup: func(apiKey *mocks.MockAPIKey, validator *mocks.MockValidator) {
+					validator.EXPECT().
+						Validate(gomock.Any()).
+						Return(errors.New("validation failed"))
+				},
+			},
+			want: want{status: http.StatusBadRequest},
+		},
+		{
+			name: "internal error",
+			body: dto.CreateAPIKey{Name: "ci", Scopes: []string{"create"}},
+			fields: fields{
+				setup: func(apiKey *mocks.MockAPIKey, validator *mocks.MockValidator) {
+					validator.EXPECT().
+						Validate(gomock.Any()).
+						Return(nil)
+					apiKey.EXPECT().
+						CreateKey(gomock.Any(), gomock.Any(), gomock.Any()).
+						Return(dto.CreatedAPIKey{}, errors.New("db error"))
+				},
+			},
+			want: want{status: http.StatusInternalServerError},
+		},
+		{
+			name: "success",
+			body: dto.CreateAPIKey{Name: "ci", Scopes: []string{"create"}},
+			fields: fields{
+				setup: func(apiKey *mocks.MockAPIKey, validator *mocks.MockValidator) {
+					validator.EXPECT().
+						Validate(gomock.Any()).
+						Return(nil)
+					apiKey.EXPECT().
+						CreateKey(gomock.Any(), gomock.Any(), gomock.Any()).
+						Return(dto.CreatedAPIKey{Key: "shk_secret"}, nil)
+				},
+			},
+			want: want{status: http.StatusCreated},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockAPIKey := mocks.NewMockAPIKey(ctrl)
+			mockValidator := mocks.NewMockValidator(ctrl)
+
+			if tt.fields.setup != nil {
+				tt.fields.setup(mockAPIKey, mockValidator)
+			}
+
+			handler := rest.NewAPIKeyHandler(mockAPIKey, mockValidator)
+
+			var bodyBytes []byte
+			switch v := tt.body.(type) {
+			case string:
+				bodyBytes = []byte(v)
+			default:
+				bodyBytes, _ = json.Marshal(v)
+			}
+
+			c, w := newTestContext(http.MethodPost, "/keys", bodyBytes)
+
+			handler.CreateKey(c)
+
+			require.Equal(t, tt.want.status, w.Code)
+		})
+	}
+}
+
+func TestAPIKeyHandler_RevokeKey(t *testing.T) {
+	type fields struct {
+		setup func(apiKey *mocks.MockAPIKey)
+	}
+	type want struct {
+		status int
+	}
+
+	tests := []struct {
+		name   string
+		fields fields
+		want   want
+	}{
+		{
+			name: "key not found",
+			fields: fields{
+				setup: func(apiKey *mocks.MockAPIKey) {
+					apiKey.EXPECT().
+						RevokeKey(gomock.Any(), gomock.Any(), "key-id").
+						Return(service.ErrKeyNotFound)
+				},
+			},
+			want: want{status: http.StatusNotFound},
+		},
+		{
+			name: "success",
+			fields: fields{
+				setup: func(apiKey *mocks.MockAPIKey) {
+					apiKey.EXPECT().
+						RevokeKey(gomock.Any(), gomock.Any(), "key-id").
+						Return(nil)
+				},
+			},
+			want: want{status: http.StatusNoContent},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockAPIKey := mocks.NewMockAPIKey(ctrl)
+			mockValidator := mocks.NewMockValidator(ctrl)
+
+			if tt.fields.setup != nil {
+				tt.fields.setup(mockAPIKey)
+			}
+
+			handler := rest.NewAPIKeyHandler(mockAPIKey, mockValidator)
+
+			c, w := newTestContext(http.MethodDelete, "/keys/key-id", nil)
+			c.Params = gin.Params{{Key: "id", Value: "key-id"}}
+
+			handler.RevokeKey(c)
+			c.Writer.WriteHeaderNow()
+
+			require.Equal(t, tt.want.status, w.Code)
+		})
+	}
+}
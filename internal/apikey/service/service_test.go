@@ -0,0 +1,175 @@
+package service_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"go.uber.org/mock/gomock"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ilam072/shortener/internal/apikey/mocks"
+	apikeyrepo "github.com/ilam072/shortener/internal/apikey/repo"
+	"github.com/ilam072/shortener/internal/apikey/service"
+	"github.com/ilam072/shortener/internal/apikey/types/domain"
+	"github.com/ilam072/shortener/internal/apikey/types/dto"
+	"github.com/ilam072/shortener/internal/auth"
+)
+
+func TestAPIKey_CreateKey(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockAPIKeyRepo(ctrl)
+
+	var stored domain.APIKey
+	mockRepo.EXPECT().
+		CreateKey(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, key domain.APIKey) (domain.APIKey, error) {
+			stored = key
+			return key, nil
+		})
+
+	svc := service.New(mockRepo, "")
+
+	creator := auth.Principal{
+		UserID:    uuid.NullUUID{UUID: uuid.New(), Valid: true},
+		Workspace: auth.Workspace{ID: uuid.New(), Slug: "acme"},
+	}
+	created, err := svc.CreateKey(context.Background(), creator, dto.CreateAPIKey{
+		Name:   "ci",
+		Scopes: []string{auth.ScopeCreate},
+	})
+
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(created.Key, created.Prefix))
+
+	sum := sha256.Sum256([]byte(created.Key))
+	require.Equal(t, hex.EncodeToString(sum[:]), stored.Hash)
+	require.NotContains(t, stored.Hash, created.Key)
+	require.Equal(t, creator.UserID, stored.OwnerID)
+	require.Equal(t, creator.Workspace.ID, stored.WorkspaceID)
+}
+
+func TestAPIKey_Authenticate(t *testing.T) {
+	owner := uuid.NullUUID{UUID: uuid.New(), Valid: true}
+	workspace := auth.Workspace{ID: uuid.New(), Slug: "acme"}
+
+	type fields struct {
+		setup func(repo *mocks.MockAPIKeyRepo)
+	}
+	type want struct {
+		principal auth.Principal
+		err       error
+	}
+
+	tests := []struct {
+		name   string
+		key    string
+		fields fields
+		want   want
+	}{
+		{
+			name: "empty key",
+			key:  "",
+			want: want{err: service.ErrInvalidKey},
+		},
+		{
+			name: "bootstrap key",
+			key:  "bootstrap-secret",
+			want: want{principal: auth.Principal{Workspace: auth.DefaultWorkspace, Scopes: []string{auth.ScopeOperator}}},
+		},
+		{
+			name: "stored key",
+			key:  "shk_stored",
+			fields: fields{
+				setup: func(repo *mocks.MockAPIKeyRepo) {
+					repo.EXPECT().
+						TouchKey(gomock.Any(), gomock.Any()).
+						Return(domain.APIKey{
+							OwnerID:       owner,
+							WorkspaceID:   workspace.ID,
+							WorkspaceSlug: workspace.Slug,
+							Scopes:        []string{auth.ScopeReadAnalytics},
+						}, nil)
+				},
+			},
+			want: want{principal: auth.Principal{
+				UserID:    owner,
+				Workspace: workspace,
+				Scopes:    []string{auth.ScopeReadAnalytics},
+			}},
+		},
+		{
+			name: "unknown or revoked key",
+			key:  "shk_unknown",
+			fields: fields{
+				setup: func(repo *mocks.MockAPIKeyRepo) {
+					repo.EXPECT().
+						TouchKey(gomock.Any(), gomock.Any()).
+						Return(domain.APIKey{}, apikeyrepo.ErrKeyNotFound)
+				},
+			},
+			want: want{err: service.ErrInvalidKey},
+		},
+		{
+			name: "repo error",
+			key:  "shk_stored",
+			fields: fields{
+				setup: func(repo *mocks.MockAPIKeyRepo) {
+					repo.EXPECT().
+						TouchKey(gomock.Any(), gomock.Any()).
+						Return(domain.APIKey{}, errors.New("db error"))
+				},
+			},
+			want: want{err: errors.New("db error")},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockRepo := mocks.NewMockAPIKeyRepo(ctrl)
+
+			if tt.fields.setup != nil {
+				tt.fields.setup(mockRepo)
+			}
+
+			svc := service.New(mockRepo, "bootstrap-secret")
+
+			principal, err := svc.Authenticate(context.Background(), tt.key)
+
+			if tt.want.err != nil {
+				require.Error(t, err)
+				require.True(t, strings.Contains(err.Error(), tt.want.err.Error()))
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.want.principal, principal)
+		})
+	}
+}
+
+func TestAPIKey_RevokeKey(t *testing.T) {
+	workspaceID := uuid.New()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockAPIKeyRepo(ctrl)
+	mockRepo.EXPECT().
+		RevokeKey(gomock.Any(), workspaceID, gomock.Any()).
+		Return(apikeyrepo.ErrKeyNotFound)
+
+	svc := service.New(mockRepo, "")
+
+	require.ErrorIs(t, svc.RevokeKey(context.Background(), workspaceID, "not-a-uuid"), service.ErrKeyNotFound)
+	require.ErrorIs(t, svc.RevokeKey(context.Background(), workspaceID, "7f1c2a52-9c1e-4c57-9b43-4a3e8f1c9b01"), service.ErrKeyNotFound)
+}
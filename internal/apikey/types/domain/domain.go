@@ -0,0 +1,21 @@
+package domain
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+type APIKey struct {
+	ID          uuid.UUID
+	OwnerID     uuid.NullUUID
+	WorkspaceID uuid.UUID
+	// WorkspaceSlug is only read back by TouchKey, to build the principal.
+	WorkspaceSlug string
+	Name          string
+	Prefix        string
+	Hash          string
+	Scopes        []string
+	CreatedAt     time.Time
+	LastUsedAt    *time.Time
+	RevokedAt     *time.Time
+}
@@ -0,0 +1,26 @@
+package dto
+
+import "time"
+
+type CreateAPIKey struct {
+	Name   string   `json:"name" validate:"required,max=100"`
+	Scopes []string `json:"scopes" validate:"required,min=1,dive,oneof=create read-analytics admin"`
+}
+
+type APIKey struct {
+	ID          string     `json:"id"`
+	OwnerID     *string    `json:"owner_id,omitempty"`
+	WorkspaceID string     `json:"workspace_id"`
+	Name        string     `json:"name"`
+	Prefix      string     `json:"prefix"`
+	Scopes      []string   `json:"scopes"`
+	CreatedAt   time.Time  `json:"created_at"`
+	LastUsedAt  *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt   *time.Time `json:"revoked_at,omitempty"`
+}
+
+// CreatedAPIKey carries the plaintext key. It is returned only once, at creation.
+type CreatedAPIKey struct {
+	APIKey
+	Key string `json:"key"`
+}
@@ -0,0 +1,81 @@
+package auth
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"slices"
+)
+
+const (
+	ScopeCreate        = "create"
+	ScopeReadAnalytics = "read-analytics"
+	ScopeAdmin         = "admin"
+	// ScopeOperator manages the deployment itself: it creates workspaces
+	// and users in any of them. Only the bootstrap key is granted it.
+	ScopeOperator = "operator"
+)
+
+// Workspace is the tenant a principal acts in. Links, clicks, users and
+// API keys all belong to exactly one workspace.
+type Workspace struct {
+	ID   uuid.UUID
+	Slug string
+}
+
+// DefaultWorkspace is created by the migrations. Data that existed before
+// workspaces were introduced, and the bootstrap key, belong to it.
+var DefaultWorkspace = Workspace{
+	ID:   uuid.MustParse("00000000-0000-0000-0000-000000000001"),
+	Slug: "default",
+}
+
+// Principal is the authenticated caller of a management request: a user
+// signed in with a JWT or an API key, optionally owned by a user.
+type Principal struct {
+	UserID    uuid.NullUUID
+	Workspace Workspace
+	Scopes    []string
+}
+
+// HasScope reports whether the principal is granted scope. Operators are
+// granted every scope, admins every scope but operator.
+func (p Principal) HasScope(scope string) bool {
+	if slices.Contains(p.Scopes, ScopeOperator) {
+		return true
+	}
+	if scope != ScopeOperator && slices.Contains(p.Scopes, ScopeAdmin) {
+		return true
+	}
+	return slices.Contains(p.Scopes, scope)
+}
+
+func (p Principal) IsAdmin() bool {
+	return p.HasScope(ScopeAdmin)
+}
+
+// Access returns the set of links the principal may see.
+func (p Principal) Access() Access {
+	return Access{Workspace: p.Workspace, All: p.IsAdmin(), OwnerID: p.UserID}
+}
+
+// Access restricts queries to the links of one workspace and, unless All
+// is set, to those of a single owner in it. A null OwnerID stands for
+// links that have no owner.
+type Access struct {
+	Workspace Workspace
+	All       bool
+	OwnerID   uuid.NullUUID
+}
+
+type principalKey struct{}
+
+func WithPrincipal(ctx context.Context, p Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// FromContext returns the principal stored by WithPrincipal. Requests that
+// were not authenticated get an empty principal, which sees nothing.
+func FromContext(ctx context.Context) Principal {
+	p, _ := ctx.Value(principalKey{}).(Principal)
+	return p
+}
@@ -0,0 +1,32 @@
+package auth_test
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ilam072/shortener/internal/auth"
+)
+
+func TestPrincipal_HasScope(t *testing.T) {
+	require.True(t, auth.Principal{Scopes: []string{auth.ScopeAdmin}}.HasScope(auth.ScopeCreate))
+	require.True(t, auth.Principal{Scopes: []string{auth.ScopeCreate}}.HasScope(auth.ScopeCreate))
+	require.False(t, auth.Principal{Scopes: []string{auth.ScopeCreate}}.HasScope(auth.ScopeReadAnalytics))
+	require.False(t, auth.Principal{Scopes: []string{auth.ScopeAdmin}}.HasScope(auth.ScopeOperator))
+	require.True(t, auth.Principal{Scopes: []string{auth.ScopeOperator}}.HasScope(auth.ScopeAdmin))
+}
+
+func TestPrincipal_Access(t *testing.T) {
+	owner := uuid.NullUUID{UUID: uuid.New(), Valid: true}
+	ws := auth.Workspace{ID: uuid.New(), Slug: "acme"}
+
+	require.Equal(t,
+		auth.Access{Workspace: ws, All: true, OwnerID: owner},
+		auth.Principal{UserID: owner, Workspace: ws, Scopes: []string{auth.ScopeAdmin}}.Access(),
+	)
+	require.Equal(t,
+		auth.Access{Workspace: ws, OwnerID: owner},
+		auth.Principal{UserID: owner, Workspace: ws, Scopes: []string{auth.ScopeCreate}}.Access(),
+	)
+}
@@ -0,0 +1,76 @@
+package bots
+
+import (
+	"bufio"
+	"github.com/ilam072/shortener/pkg/errutils"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// defaultPatterns catch the fetchers the user_agent library does not flag:
+// link previews of social networks and messengers, uptime monitors and
+// HTTP libraries.
+var defaultPatterns = []string{
+	`facebookexternalhit`, `facebot`, `twitterbot`, `linkedinbot`, `slackbot`,
+	`telegrambot`, `whatsapp`, `discordbot`, `skypeuripreview`, `vkshare`,
+	`pinterest`, `redditbot`, `embedly`, `uptimerobot`, `pingdom`,
+	`statuscake`, `site24x7`, `headlesschrome`, `^curl/`, `^wget/`,
+	`python-requests`, `go-http-client`, `okhttp`, `^java/`, `bot\b`,
+	`crawler`, `spider`,
+}
+
+// Classifier tells bots by their user agent, matching it against
+// case-insensitive regular expressions.
+type Classifier struct {
+	patterns []*regexp.Regexp
+}
+
+// New returns a classifier for the default patterns and extra.
+func New(extra []string) (*Classifier, error) {
+	c := &Classifier{}
+	for _, pattern := range slices.Concat(defaultPatterns, extra) {
+		re, err := regexp.Compile("(?i)" + pattern)
+		if err != nil {
+			return nil, errutils.Wrap("invalid bot pattern "+pattern, err)
+		}
+		c.patterns = append(c.patterns, re)
+	}
+	return c, nil
+}
+
+// Load returns a classifier for the default patterns and the ones in the
+// denylist file at path, one per line. Blank lines and lines starting
+// with # are skipped.
+func Load(path string) (*Classifier, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, errutils.Wrap("failed to open bot denylist", err)
+	}
+	defer file.Close()
+
+	var patterns []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		patterns = append(patterns, line)
+	}
+	if err = scanner.Err(); err != nil {
+		return nil, errutils.Wrap("failed to read bot denylist", err)
+	}
+
+	return New(patterns)
+}
+
+func (c *Classifier) IsBot(userAgent string) bool {
+	for _, re := range c.patterns {
+		if re.MatchString(userAgent) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,63 @@
+package feed
+
+import (
+	"github.com/google/uuid"
+	"github.com/ilam072/shortener/internal/click/types/domain"
+	"sync"
+)
+
+// hub fans the clicks of links out to the subscribers of this replica.
+type hub struct {
+	mu   sync.RWMutex
+	next int
+	subs map[uuid.UUID]map[int]func(domain.LiveClick)
+}
+
+func newHub() *hub {
+	return &hub{subs: make(map[uuid.UUID]map[int]func(domain.LiveClick))}
+}
+
+// add subscribes deliver to the clicks of linkID. It tells whether it is
+// the first subscriber of the link.
+func (h *hub) add(linkID uuid.UUID, deliver func(domain.LiveClick)) (int, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	subs, ok := h.subs[linkID]
+	if !ok {
+		subs = make(map[int]func(domain.LiveClick))
+		h.subs[linkID] = subs
+	}
+	h.next++
+	subs[h.next] = deliver
+	return h.next, !ok
+}
+
+// remove unsubscribes id. It tells whether it was the last subscriber of
+// the link.
+func (h *hub) remove(linkID uuid.UUID, id int) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	subs, ok := h.subs[linkID]
+	if !ok {
+		return false
+	}
+	delete(subs, id)
+	if len(subs) > 0 {
+		return false
+	}
+	delete(h.subs, linkID)
+	return true
+}
+
+// deliver hands click to the subscribers of its link, which must not
+// block.
+func (h *hub) deliver(click domain.LiveClick) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for _, deliver := range h.subs[click.LinkID] {
+		deliver(click)
+	}
+}
@@ -0,0 +1,25 @@
+package feed
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/ilam072/shortener/internal/click/types/domain"
+)
+
+// Memory is a click feed within a single replica.
+type Memory struct {
+	hub *hub
+}
+
+func NewMemory() *Memory {
+	return &Memory{hub: newHub()}
+}
+
+func (m *Memory) Publish(click domain.LiveClick) {
+	m.hub.deliver(click)
+}
+
+func (m *Memory) Subscribe(_ context.Context, linkID uuid.UUID, deliver func(domain.LiveClick)) (func(), error) {
+	id, _ := m.hub.add(linkID, deliver)
+	return func() { m.hub.remove(linkID, id) }, nil
+}
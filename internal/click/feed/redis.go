@@ -0,0 +1,124 @@
+package feed
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+	"github.com/ilam072/shortener/internal/click/types/domain"
+	"github.com/ilam072/shortener/pkg/errutils"
+	wbfredis "github.com/wb-go/wbf/redis"
+	"github.com/wb-go/wbf/zlog"
+	"strings"
+	"sync"
+)
+
+// publishBuffer is the number of clicks waiting to be published. Clicks are
+// published off the redirect path and dropped while the buffer is full.
+const publishBuffer = 1024
+
+// Redis is a click feed across replicas over Redis pub/sub, with a channel
+// per link. A replica subscribes to the channel of a link while it has
+// subscribers of the link, over a single connection.
+type Redis struct {
+	client   *wbfredis.Client
+	prefix   string
+	hub      *hub
+	pending  chan domain.LiveClick
+	pubsubMu sync.Mutex
+	pubsub   *redis.PubSub
+}
+
+func NewRedis(client *wbfredis.Client, prefix string) *Redis {
+	return &Redis{
+		client:  client,
+		prefix:  prefix,
+		hub:     newHub(),
+		pending: make(chan domain.LiveClick, publishBuffer),
+		// The connection is only opened on the first subscription.
+		pubsub: client.Subscribe(context.Background()),
+	}
+}
+
+// Run publishes the clicks of this replica and delivers the ones of every
+// replica to its subscribers until ctx is done.
+func (r *Redis) Run(ctx context.Context) {
+	defer r.pubsub.Close()
+
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case click := <-r.pending:
+				r.publish(ctx, click)
+			}
+		}
+	}()
+
+	messages := r.pubsub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case message := <-messages:
+			var click domain.LiveClick
+			if err := json.Unmarshal([]byte(message.Payload), &click); err != nil {
+				zlog.Logger.Warn().Err(err).Str("channel", message.Channel).Msg("dropping malformed live click")
+				continue
+			}
+			r.hub.deliver(click)
+		}
+	}
+}
+
+// Publish queues click for publishing, or drops it while the queue is full.
+func (r *Redis) Publish(click domain.LiveClick) {
+	select {
+	case r.pending <- click:
+	default:
+	}
+}
+
+// Subscribe hands the clicks of linkID to deliver until the returned
+// function is called. The subscriptions of the replica change under a
+// single lock, so a link is never left unsubscribed with subscribers.
+func (r *Redis) Subscribe(ctx context.Context, linkID uuid.UUID, deliver func(domain.LiveClick)) (func(), error) {
+	r.pubsubMu.Lock()
+	defer r.pubsubMu.Unlock()
+
+	id, first := r.hub.add(linkID, deliver)
+	if first {
+		if err := r.pubsub.Subscribe(ctx, r.channel(linkID)); err != nil {
+			r.hub.remove(linkID, id)
+			return nil, errutils.Wrap("failed to subscribe to live clicks", err)
+		}
+	}
+
+	return func() {
+		r.pubsubMu.Lock()
+		defer r.pubsubMu.Unlock()
+
+		if !r.hub.remove(linkID, id) {
+			return
+		}
+		if err := r.pubsub.Unsubscribe(context.Background(), r.channel(linkID)); err != nil {
+			zlog.Logger.Warn().Err(err).Str("link_id", linkID.String()).Msg("failed to unsubscribe from live clicks")
+		}
+	}, nil
+}
+
+func (r *Redis) publish(ctx context.Context, click domain.LiveClick) {
+	payload, err := json.Marshal(click)
+	if err != nil {
+		zlog.Logger.Warn().Err(err).Msg("failed to encode live click")
+		return
+	}
+	if err = r.client.Publish(ctx, r.channel(click.LinkID), payload).Err(); err != nil {
+		zlog.Logger.Warn().Err(err).Msg("failed to publish live click")
+	}
+}
+
+func (r *Redis) channel(linkID uuid.UUID) string {
+	return strings.TrimSuffix(r.prefix, ":") + ":" + linkID.String()
+}
@@ -0,0 +1,60 @@
+package geoip
+
+import (
+	"github.com/ilam072/shortener/internal/click/types/domain"
+	"github.com/ilam072/shortener/pkg/errutils"
+	"github.com/oschwald/geoip2-golang"
+	"net"
+	"strings"
+)
+
+// Locator resolves ips against a local MaxMind database. City databases
+// give the country, region and city, country databases only the country.
+type Locator struct {
+	db   *geoip2.Reader
+	city bool
+}
+
+func Open(path string) (*Locator, error) {
+	db, err := geoip2.Open(path)
+	if err != nil {
+		return nil, errutils.Wrap("failed to open geoip database", err)
+	}
+	return &Locator{
+		db:   db,
+		city: strings.Contains(db.Metadata().DatabaseType, "City"),
+	}, nil
+}
+
+// Locate returns the location of ip, empty when it is not in the database.
+func (l *Locator) Locate(ip string) domain.Location {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return domain.Location{}
+	}
+
+	if !l.city {
+		record, err := l.db.Country(parsed)
+		if err != nil {
+			return domain.Location{}
+		}
+		return domain.Location{Country: record.Country.IsoCode}
+	}
+
+	record, err := l.db.City(parsed)
+	if err != nil {
+		return domain.Location{}
+	}
+	location := domain.Location{
+		Country: record.Country.IsoCode,
+		City:    record.City.Names["en"],
+	}
+	if len(record.Subdivisions) > 0 {
+		location.Region = record.Subdivisions[0].Names["en"]
+	}
+	return location
+}
+
+func (l *Locator) Close() error {
+	return l.db.Close()
+}
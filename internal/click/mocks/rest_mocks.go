@@ -13,6 +13,8 @@ import (
 	context "context"
 	reflect "reflect"
 
+	auth "github.com/ilam072/shortener/internal/auth"
+	service "github.com/ilam072/shortener/internal/click/service"
 	dto "github.com/ilam072/shortener/internal/click/types/dto"
 	gomock "go.uber.org/mock/gomock"
 )
@@ -41,17 +43,130 @@ func (m *MockClick) EXPECT() *MockClickMockRecorder {
 	return m.recorder
 }
 
+// Export mocks base method.
+func (m *MockClick) Export(ctx context.Context, access auth.Access, host, alias string, query dto.ExportQuery, w service.ExportWriter) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Export", ctx, access, host, alias, query, w)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Export indicates an expected call of Export.
+func (mr *MockClickMockRecorder) Export(ctx, access, host, alias, query, w any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Export", reflect.TypeOf((*MockClick)(nil).Export), ctx, access, host, alias, query, w)
+}
+
 // GetClicksSummary mocks base method.
-func (m *MockClick) GetClicksSummary(ctx context.Context, alias string) (dto.GetClicks, error) {
+func (m *MockClick) GetClicksSummary(ctx context.Context, access auth.Access, host, alias string, query dto.ClicksQuery) (dto.GetClicks, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetClicksSummary", ctx, alias)
+	ret := m.ctrl.Call(m, "GetClicksSummary", ctx, access, host, alias, query)
 	ret0, _ := ret[0].(dto.GetClicks)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetClicksSummary indicates an expected call of GetClicksSummary.
-func (mr *MockClickMockRecorder) GetClicksSummary(ctx, alias any) *gomock.Call {
+func (mr *MockClickMockRecorder) GetClicksSummary(ctx, access, host, alias, query any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetClicksSummary", reflect.TypeOf((*MockClick)(nil).GetClicksSummary), ctx, access, host, alias, query)
+}
+
+// GetCreatedLinks mocks base method.
+func (m *MockClick) GetCreatedLinks(ctx context.Context, access auth.Access, query dto.DashboardQuery) (dto.GetCreatedLinks, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCreatedLinks", ctx, access, query)
+	ret0, _ := ret[0].(dto.GetCreatedLinks)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCreatedLinks indicates an expected call of GetCreatedLinks.
+func (mr *MockClickMockRecorder) GetCreatedLinks(ctx, access, query any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCreatedLinks", reflect.TypeOf((*MockClick)(nil).GetCreatedLinks), ctx, access, query)
+}
+
+// GetLinkDomains mocks base method.
+func (m *MockClick) GetLinkDomains(ctx context.Context, access auth.Access, query dto.DashboardQuery) (dto.GetLinkDomains, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLinkDomains", ctx, access, query)
+	ret0, _ := ret[0].(dto.GetLinkDomains)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLinkDomains indicates an expected call of GetLinkDomains.
+func (mr *MockClickMockRecorder) GetLinkDomains(ctx, access, query any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLinkDomains", reflect.TypeOf((*MockClick)(nil).GetLinkDomains), ctx, access, query)
+}
+
+// GetTopLinks mocks base method.
+func (m *MockClick) GetTopLinks(ctx context.Context, access auth.Access, query dto.DashboardQuery) (dto.GetTopLinks, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTopLinks", ctx, access, query)
+	ret0, _ := ret[0].(dto.GetTopLinks)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTopLinks indicates an expected call of GetTopLinks.
+func (mr *MockClickMockRecorder) GetTopLinks(ctx, access, query any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTopLinks", reflect.TypeOf((*MockClick)(nil).GetTopLinks), ctx, access, query)
+}
+
+// GetWorkspaceClicks mocks base method.
+func (m *MockClick) GetWorkspaceClicks(ctx context.Context, access auth.Access, query dto.DashboardQuery) (dto.GetWorkspaceClicks, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWorkspaceClicks", ctx, access, query)
+	ret0, _ := ret[0].(dto.GetWorkspaceClicks)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWorkspaceClicks indicates an expected call of GetWorkspaceClicks.
+func (mr *MockClickMockRecorder) GetWorkspaceClicks(ctx, access, query any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWorkspaceClicks", reflect.TypeOf((*MockClick)(nil).GetWorkspaceClicks), ctx, access, query)
+}
+
+// MockLive is a mock of Live interface.
+type MockLive struct {
+	ctrl     *gomock.Controller
+	recorder *MockLiveMockRecorder
+	isgomock struct{}
+}
+
+// MockLiveMockRecorder is the mock recorder for MockLive.
+type MockLiveMockRecorder struct {
+	mock *MockLive
+}
+
+// NewMockLive creates a new mock instance.
+func NewMockLive(ctrl *gomock.Controller) *MockLive {
+	mock := &MockLive{ctrl: ctrl}
+	mock.recorder = &MockLiveMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockLive) EXPECT() *MockLiveMockRecorder {
+	return m.recorder
+}
+
+// StreamClicks mocks base method.
+func (m *MockLive) StreamClicks(ctx context.Context, access auth.Access, host, alias, includeBots string) (*service.ClickStream, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StreamClicks", ctx, access, host, alias, includeBots)
+	ret0, _ := ret[0].(*service.ClickStream)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// StreamClicks indicates an expected call of StreamClicks.
+func (mr *MockLiveMockRecorder) StreamClicks(ctx, access, host, alias, includeBots any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetClicksSummary", reflect.TypeOf((*MockClick)(nil).GetClicksSummary), ctx, alias)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StreamClicks", reflect.TypeOf((*MockLive)(nil).StreamClicks), ctx, access, host, alias, includeBots)
 }
@@ -12,8 +12,12 @@ package mocks
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
+	uuid "github.com/google/uuid"
+	auth "github.com/ilam072/shortener/internal/auth"
 	domain "github.com/ilam072/shortener/internal/click/types/domain"
+	dto "github.com/ilam072/shortener/internal/webhook/types/dto"
 	gomock "go.uber.org/mock/gomock"
 )
 
@@ -55,47 +59,511 @@ func (mr *MockClickRepoMockRecorder) CreateClick(ctx, click any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateClick", reflect.TypeOf((*MockClickRepo)(nil).CreateClick), ctx, click)
 }
 
-// GetClicksByDay mocks base method.
-func (m *MockClickRepo) GetClicksByDay(ctx context.Context, alias string) ([]domain.ClickRow, error) {
+// CreateClickPartition mocks base method.
+func (m *MockClickRepo) CreateClickPartition(ctx context.Context, from, to time.Time) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetClicksByDay", ctx, alias)
+	ret := m.ctrl.Call(m, "CreateClickPartition", ctx, from, to)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateClickPartition indicates an expected call of CreateClickPartition.
+func (mr *MockClickRepoMockRecorder) CreateClickPartition(ctx, from, to any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateClickPartition", reflect.TypeOf((*MockClickRepo)(nil).CreateClickPartition), ctx, from, to)
+}
+
+// CreateClicks mocks base method.
+func (m *MockClickRepo) CreateClicks(ctx context.Context, clicks []domain.Click) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateClicks", ctx, clicks)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateClicks indicates an expected call of CreateClicks.
+func (mr *MockClickRepoMockRecorder) CreateClicks(ctx, clicks any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateClicks", reflect.TypeOf((*MockClickRepo)(nil).CreateClicks), ctx, clicks)
+}
+
+// DetachClickPartition mocks base method.
+func (m *MockClickRepo) DetachClickPartition(ctx context.Context, partition domain.ClickPartition) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DetachClickPartition", ctx, partition)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DetachClickPartition indicates an expected call of DetachClickPartition.
+func (mr *MockClickRepoMockRecorder) DetachClickPartition(ctx, partition any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DetachClickPartition", reflect.TypeOf((*MockClickRepo)(nil).DetachClickPartition), ctx, partition)
+}
+
+// DropClickTable mocks base method.
+func (m *MockClickRepo) DropClickTable(ctx context.Context, name string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DropClickTable", ctx, name)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DropClickTable indicates an expected call of DropClickTable.
+func (mr *MockClickRepoMockRecorder) DropClickTable(ctx, name any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DropClickTable", reflect.TypeOf((*MockClickRepo)(nil).DropClickTable), ctx, name)
+}
+
+// ExportClicks mocks base method.
+func (m *MockClickRepo) ExportClicks(ctx context.Context, workspaceID, linkID uuid.UUID, series domain.Series, each func(domain.Click) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExportClicks", ctx, workspaceID, linkID, series, each)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ExportClicks indicates an expected call of ExportClicks.
+func (mr *MockClickRepoMockRecorder) ExportClicks(ctx, workspaceID, linkID, series, each any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExportClicks", reflect.TypeOf((*MockClickRepo)(nil).ExportClicks), ctx, workspaceID, linkID, series, each)
+}
+
+// GetClickBreakdown mocks base method.
+func (m *MockClickRepo) GetClickBreakdown(ctx context.Context, workspaceID, linkID uuid.UUID, dimension string, series domain.Series) ([]domain.ClickRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetClickBreakdown", ctx, workspaceID, linkID, dimension, series)
 	ret0, _ := ret[0].([]domain.ClickRow)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetClicksByDay indicates an expected call of GetClicksByDay.
-func (mr *MockClickRepoMockRecorder) GetClicksByDay(ctx, alias any) *gomock.Call {
+// GetClickBreakdown indicates an expected call of GetClickBreakdown.
+func (mr *MockClickRepoMockRecorder) GetClickBreakdown(ctx, workspaceID, linkID, dimension, series any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetClicksByDay", reflect.TypeOf((*MockClickRepo)(nil).GetClicksByDay), ctx, alias)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetClickBreakdown", reflect.TypeOf((*MockClickRepo)(nil).GetClickBreakdown), ctx, workspaceID, linkID, dimension, series)
 }
 
-// GetClicksByMonth mocks base method.
-func (m *MockClickRepo) GetClicksByMonth(ctx context.Context, alias string) ([]domain.ClickRow, error) {
+// GetClickPartitions mocks base method.
+func (m *MockClickRepo) GetClickPartitions(ctx context.Context) ([]domain.ClickPartition, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetClicksByMonth", ctx, alias)
-	ret0, _ := ret[0].([]domain.ClickRow)
+	ret := m.ctrl.Call(m, "GetClickPartitions", ctx)
+	ret0, _ := ret[0].([]domain.ClickPartition)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetClicksByMonth indicates an expected call of GetClicksByMonth.
-func (mr *MockClickRepoMockRecorder) GetClicksByMonth(ctx, alias any) *gomock.Call {
+// GetClickPartitions indicates an expected call of GetClickPartitions.
+func (mr *MockClickRepoMockRecorder) GetClickPartitions(ctx any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetClicksByMonth", reflect.TypeOf((*MockClickRepo)(nil).GetClicksByMonth), ctx, alias)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetClickPartitions", reflect.TypeOf((*MockClickRepo)(nil).GetClickPartitions), ctx)
 }
 
-// GetClicksByUserAgent mocks base method.
-func (m *MockClickRepo) GetClicksByUserAgent(ctx context.Context, alias string) ([]domain.ClickRow, error) {
+// GetClickSeries mocks base method.
+func (m *MockClickRepo) GetClickSeries(ctx context.Context, workspaceID, linkID uuid.UUID, series domain.Series) ([]domain.ClickBucket, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetClicksByUserAgent", ctx, alias)
-	ret0, _ := ret[0].([]domain.ClickRow)
+	ret := m.ctrl.Call(m, "GetClickSeries", ctx, workspaceID, linkID, series)
+	ret0, _ := ret[0].([]domain.ClickBucket)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetClickSeries indicates an expected call of GetClickSeries.
+func (mr *MockClickRepoMockRecorder) GetClickSeries(ctx, workspaceID, linkID, series any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetClickSeries", reflect.TypeOf((*MockClickRepo)(nil).GetClickSeries), ctx, workspaceID, linkID, series)
+}
+
+// GetLink mocks base method.
+func (m *MockClickRepo) GetLink(ctx context.Context, access auth.Access, host, alias string) (domain.Link, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLink", ctx, access, host, alias)
+	ret0, _ := ret[0].(domain.Link)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLink indicates an expected call of GetLink.
+func (mr *MockClickRepoMockRecorder) GetLink(ctx, access, host, alias any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLink", reflect.TypeOf((*MockClickRepo)(nil).GetLink), ctx, access, host, alias)
+}
+
+// GetLinkDomains mocks base method.
+func (m *MockClickRepo) GetLinkDomains(ctx context.Context, access auth.Access, limit int) ([]domain.LinkDomain, int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLinkDomains", ctx, access, limit)
+	ret0, _ := ret[0].([]domain.LinkDomain)
+	ret1, _ := ret[1].(int)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetLinkDomains indicates an expected call of GetLinkDomains.
+func (mr *MockClickRepoMockRecorder) GetLinkDomains(ctx, access, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLinkDomains", reflect.TypeOf((*MockClickRepo)(nil).GetLinkDomains), ctx, access, limit)
+}
+
+// GetLinkSeries mocks base method.
+func (m *MockClickRepo) GetLinkSeries(ctx context.Context, access auth.Access, series domain.Series) ([]domain.LinkBucket, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLinkSeries", ctx, access, series)
+	ret0, _ := ret[0].([]domain.LinkBucket)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLinkSeries indicates an expected call of GetLinkSeries.
+func (mr *MockClickRepoMockRecorder) GetLinkSeries(ctx, access, series any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLinkSeries", reflect.TypeOf((*MockClickRepo)(nil).GetLinkSeries), ctx, access, series)
+}
+
+// GetTopLinks mocks base method.
+func (m *MockClickRepo) GetTopLinks(ctx context.Context, access auth.Access, series domain.Series, limit int) ([]domain.TopLink, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTopLinks", ctx, access, series, limit)
+	ret0, _ := ret[0].([]domain.TopLink)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTopLinks indicates an expected call of GetTopLinks.
+func (mr *MockClickRepoMockRecorder) GetTopLinks(ctx, access, series, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTopLinks", reflect.TypeOf((*MockClickRepo)(nil).GetTopLinks), ctx, access, series, limit)
+}
+
+// GetWorkspaceSeries mocks base method.
+func (m *MockClickRepo) GetWorkspaceSeries(ctx context.Context, access auth.Access, series domain.Series) ([]domain.ClickBucket, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWorkspaceSeries", ctx, access, series)
+	ret0, _ := ret[0].([]domain.ClickBucket)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWorkspaceSeries indicates an expected call of GetWorkspaceSeries.
+func (mr *MockClickRepoMockRecorder) GetWorkspaceSeries(ctx, access, series any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWorkspaceSeries", reflect.TypeOf((*MockClickRepo)(nil).GetWorkspaceSeries), ctx, access, series)
+}
+
+// HasRawClicks mocks base method.
+func (m *MockClickRepo) HasRawClicks(ctx context.Context, partition string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HasRawClicks", ctx, partition)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// HasRawClicks indicates an expected call of HasRawClicks.
+func (mr *MockClickRepoMockRecorder) HasRawClicks(ctx, partition any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HasRawClicks", reflect.TypeOf((*MockClickRepo)(nil).HasRawClicks), ctx, partition)
+}
+
+// RollUpClicks mocks base method.
+func (m *MockClickRepo) RollUpClicks(ctx context.Context, since, until time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RollUpClicks", ctx, since, until)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RollUpClicks indicates an expected call of RollUpClicks.
+func (mr *MockClickRepoMockRecorder) RollUpClicks(ctx, since, until any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RollUpClicks", reflect.TypeOf((*MockClickRepo)(nil).RollUpClicks), ctx, since, until)
+}
+
+// MockClickOutbox is a mock of ClickOutbox interface.
+type MockClickOutbox struct {
+	ctrl     *gomock.Controller
+	recorder *MockClickOutboxMockRecorder
+	isgomock struct{}
+}
+
+// MockClickOutboxMockRecorder is the mock recorder for MockClickOutbox.
+type MockClickOutboxMockRecorder struct {
+	mock *MockClickOutbox
+}
+
+// NewMockClickOutbox creates a new mock instance.
+func NewMockClickOutbox(ctrl *gomock.Controller) *MockClickOutbox {
+	mock := &MockClickOutbox{ctrl: ctrl}
+	mock.recorder = &MockClickOutboxMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockClickOutbox) EXPECT() *MockClickOutboxMockRecorder {
+	return m.recorder
+}
+
+// Append mocks base method.
+func (m *MockClickOutbox) Append(ctx context.Context, clicks []domain.Click) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Append", ctx, clicks)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Append indicates an expected call of Append.
+func (mr *MockClickOutboxMockRecorder) Append(ctx, clicks any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Append", reflect.TypeOf((*MockClickOutbox)(nil).Append), ctx, clicks)
+}
+
+// DeadLetter mocks base method.
+func (m *MockClickOutbox) DeadLetter(ctx context.Context, click domain.BufferedClick, reason string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeadLetter", ctx, click, reason)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeadLetter indicates an expected call of DeadLetter.
+func (mr *MockClickOutboxMockRecorder) DeadLetter(ctx, click, reason any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeadLetter", reflect.TypeOf((*MockClickOutbox)(nil).DeadLetter), ctx, click, reason)
+}
+
+// Read mocks base method.
+func (m *MockClickOutbox) Read(ctx context.Context, count int) ([]domain.BufferedClick, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Read", ctx, count)
+	ret0, _ := ret[0].([]domain.BufferedClick)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Read indicates an expected call of Read.
+func (mr *MockClickOutboxMockRecorder) Read(ctx, count any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Read", reflect.TypeOf((*MockClickOutbox)(nil).Read), ctx, count)
+}
+
+// Remove mocks base method.
+func (m *MockClickOutbox) Remove(ctx context.Context, entryIDs ...string) error {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx}
+	for _, a := range entryIDs {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Remove", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Remove indicates an expected call of Remove.
+func (mr *MockClickOutboxMockRecorder) Remove(ctx any, entryIDs ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx}, entryIDs...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Remove", reflect.TypeOf((*MockClickOutbox)(nil).Remove), varargs...)
+}
+
+// MockVisitorSalt is a mock of VisitorSalt interface.
+type MockVisitorSalt struct {
+	ctrl     *gomock.Controller
+	recorder *MockVisitorSaltMockRecorder
+	isgomock struct{}
+}
+
+// MockVisitorSaltMockRecorder is the mock recorder for MockVisitorSalt.
+type MockVisitorSaltMockRecorder struct {
+	mock *MockVisitorSalt
+}
+
+// NewMockVisitorSalt creates a new mock instance.
+func NewMockVisitorSalt(ctrl *gomock.Controller) *MockVisitorSalt {
+	mock := &MockVisitorSalt{ctrl: ctrl}
+	mock.recorder = &MockVisitorSaltMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockVisitorSalt) EXPECT() *MockVisitorSaltMockRecorder {
+	return m.recorder
+}
+
+// Salt mocks base method.
+func (m *MockVisitorSalt) Salt(ctx context.Context, day string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Salt", ctx, day)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Salt indicates an expected call of Salt.
+func (mr *MockVisitorSaltMockRecorder) Salt(ctx, day any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Salt", reflect.TypeOf((*MockVisitorSalt)(nil).Salt), ctx, day)
+}
+
+// MockGeoLocator is a mock of GeoLocator interface.
+type MockGeoLocator struct {
+	ctrl     *gomock.Controller
+	recorder *MockGeoLocatorMockRecorder
+	isgomock struct{}
+}
+
+// MockGeoLocatorMockRecorder is the mock recorder for MockGeoLocator.
+type MockGeoLocatorMockRecorder struct {
+	mock *MockGeoLocator
+}
+
+// NewMockGeoLocator creates a new mock instance.
+func NewMockGeoLocator(ctrl *gomock.Controller) *MockGeoLocator {
+	mock := &MockGeoLocator{ctrl: ctrl}
+	mock.recorder = &MockGeoLocatorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockGeoLocator) EXPECT() *MockGeoLocatorMockRecorder {
+	return m.recorder
+}
+
+// Locate mocks base method.
+func (m *MockGeoLocator) Locate(ip string) domain.Location {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Locate", ip)
+	ret0, _ := ret[0].(domain.Location)
+	return ret0
+}
+
+// Locate indicates an expected call of Locate.
+func (mr *MockGeoLocatorMockRecorder) Locate(ip any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Locate", reflect.TypeOf((*MockGeoLocator)(nil).Locate), ip)
+}
+
+// MockBotClassifier is a mock of BotClassifier interface.
+type MockBotClassifier struct {
+	ctrl     *gomock.Controller
+	recorder *MockBotClassifierMockRecorder
+	isgomock struct{}
+}
+
+// MockBotClassifierMockRecorder is the mock recorder for MockBotClassifier.
+type MockBotClassifierMockRecorder struct {
+	mock *MockBotClassifier
+}
+
+// NewMockBotClassifier creates a new mock instance.
+func NewMockBotClassifier(ctrl *gomock.Controller) *MockBotClassifier {
+	mock := &MockBotClassifier{ctrl: ctrl}
+	mock.recorder = &MockBotClassifierMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockBotClassifier) EXPECT() *MockBotClassifierMockRecorder {
+	return m.recorder
+}
+
+// IsBot mocks base method.
+func (m *MockBotClassifier) IsBot(userAgent string) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsBot", userAgent)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// IsBot indicates an expected call of IsBot.
+func (mr *MockBotClassifierMockRecorder) IsBot(userAgent any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsBot", reflect.TypeOf((*MockBotClassifier)(nil).IsBot), userAgent)
+}
+
+// MockClickFeed is a mock of ClickFeed interface.
+type MockClickFeed struct {
+	ctrl     *gomock.Controller
+	recorder *MockClickFeedMockRecorder
+	isgomock struct{}
+}
+
+// MockClickFeedMockRecorder is the mock recorder for MockClickFeed.
+type MockClickFeedMockRecorder struct {
+	mock *MockClickFeed
+}
+
+// NewMockClickFeed creates a new mock instance.
+func NewMockClickFeed(ctrl *gomock.Controller) *MockClickFeed {
+	mock := &MockClickFeed{ctrl: ctrl}
+	mock.recorder = &MockClickFeedMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockClickFeed) EXPECT() *MockClickFeedMockRecorder {
+	return m.recorder
+}
+
+// Publish mocks base method.
+func (m *MockClickFeed) Publish(click domain.LiveClick) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Publish", click)
+}
+
+// Publish indicates an expected call of Publish.
+func (mr *MockClickFeedMockRecorder) Publish(click any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Publish", reflect.TypeOf((*MockClickFeed)(nil).Publish), click)
+}
+
+// Subscribe mocks base method.
+func (m *MockClickFeed) Subscribe(ctx context.Context, linkID uuid.UUID, deliver func(domain.LiveClick)) (func(), error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Subscribe", ctx, linkID, deliver)
+	ret0, _ := ret[0].(func())
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetClicksByUserAgent indicates an expected call of GetClicksByUserAgent.
-func (mr *MockClickRepoMockRecorder) GetClicksByUserAgent(ctx, alias any) *gomock.Call {
+// Subscribe indicates an expected call of Subscribe.
+func (mr *MockClickFeedMockRecorder) Subscribe(ctx, linkID, deliver any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Subscribe", reflect.TypeOf((*MockClickFeed)(nil).Subscribe), ctx, linkID, deliver)
+}
+
+// MockClickEvents is a mock of ClickEvents interface.
+type MockClickEvents struct {
+	ctrl     *gomock.Controller
+	recorder *MockClickEventsMockRecorder
+	isgomock struct{}
+}
+
+// MockClickEventsMockRecorder is the mock recorder for MockClickEvents.
+type MockClickEventsMockRecorder struct {
+	mock *MockClickEvents
+}
+
+// NewMockClickEvents creates a new mock instance.
+func NewMockClickEvents(ctrl *gomock.Controller) *MockClickEvents {
+	mock := &MockClickEvents{ctrl: ctrl}
+	mock.recorder = &MockClickEventsMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockClickEvents) EXPECT() *MockClickEventsMockRecorder {
+	return m.recorder
+}
+
+// Emit mocks base method.
+func (m *MockClickEvents) Emit(event dto.Event) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Emit", event)
+}
+
+// Emit indicates an expected call of Emit.
+func (mr *MockClickEventsMockRecorder) Emit(event any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetClicksByUserAgent", reflect.TypeOf((*MockClickRepo)(nil).GetClicksByUserAgent), ctx, alias)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Emit", reflect.TypeOf((*MockClickEvents)(nil).Emit), event)
 }
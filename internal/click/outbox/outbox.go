@@ -0,0 +1,104 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/go-redis/redis/v8"
+	"github.com/ilam072/shortener/internal/click/types/domain"
+	"github.com/ilam072/shortener/pkg/errutils"
+	wbfredis "github.com/wb-go/wbf/redis"
+	"github.com/wb-go/wbf/zlog"
+)
+
+// ClickOutbox buffers clicks that could not be stored in a Redis stream,
+// one entry per click. Clicks that can never be stored are moved to a
+// dead letter stream, named after the stream with a ":dead" suffix.
+type ClickOutbox struct {
+	client *wbfredis.Client
+	stream string
+	dead   string
+}
+
+func New(client *wbfredis.Client, stream string) *ClickOutbox {
+	return &ClickOutbox{client: client, stream: stream, dead: stream + ":dead"}
+}
+
+const (
+	clickField = "click"
+	errorField = "error"
+)
+
+func (o *ClickOutbox) Append(ctx context.Context, clicks []domain.Click) error {
+	pipe := o.client.Pipeline()
+	for _, click := range clicks {
+		value, err := json.Marshal(click)
+		if err != nil {
+			return errutils.Wrap("failed to encode click", err)
+		}
+		pipe.XAdd(ctx, &redis.XAddArgs{
+			Stream: o.stream,
+			Values: map[string]interface{}{clickField: value},
+		})
+	}
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		return errutils.Wrap("failed to append clicks to outbox", err)
+	}
+	return nil
+}
+
+// Read returns up to count of the oldest buffered clicks. Entries stay in
+// the outbox until they are removed, except for malformed ones, which could
+// never be replayed and are dropped.
+func (o *ClickOutbox) Read(ctx context.Context, count int) ([]domain.BufferedClick, error) {
+	messages, err := o.client.XRangeN(ctx, o.stream, "-", "+", int64(count)).Result()
+	if err != nil {
+		return nil, errutils.Wrap("failed to read clicks from outbox", err)
+	}
+
+	clicks := make([]domain.BufferedClick, 0, len(messages))
+	for _, message := range messages {
+		value, _ := message.Values[clickField].(string)
+
+		var click domain.Click
+		if err = json.Unmarshal([]byte(value), &click); err != nil {
+			zlog.Logger.Warn().Err(err).Str("entry", message.ID).Msg("dropping malformed click from outbox")
+			if err = o.Remove(ctx, message.ID); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		clicks = append(clicks, domain.BufferedClick{EntryID: message.ID, Click: click})
+	}
+	return clicks, nil
+}
+
+// DeadLetter moves a buffered click to the dead letter stream along with
+// the reason it could not be stored, where it is kept for inspection.
+func (o *ClickOutbox) DeadLetter(ctx context.Context, click domain.BufferedClick, reason string) error {
+	value, err := json.Marshal(click.Click)
+	if err != nil {
+		return errutils.Wrap("failed to encode click", err)
+	}
+
+	pipe := o.client.TxPipeline()
+	pipe.XAdd(ctx, &redis.XAddArgs{
+		Stream: o.dead,
+		Values: map[string]interface{}{clickField: value, errorField: reason},
+	})
+	pipe.XDel(ctx, o.stream, click.EntryID)
+	if _, err = pipe.Exec(ctx); err != nil {
+		return errutils.Wrap("failed to move click to dead letters", err)
+	}
+	return nil
+}
+
+func (o *ClickOutbox) Remove(ctx context.Context, entryIDs ...string) error {
+	if len(entryIDs) == 0 {
+		return nil
+	}
+	if err := o.client.XDel(ctx, o.stream, entryIDs...).Err(); err != nil {
+		return errutils.Wrap("failed to remove clicks from outbox", err)
+	}
+	return nil
+}
@@ -2,9 +2,21 @@ package postgres
 
 import (
 	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/ilam072/shortener/internal/auth"
+	"github.com/ilam072/shortener/internal/click/repo"
 	"github.com/ilam072/shortener/internal/click/types/domain"
 	"github.com/ilam072/shortener/pkg/errutils"
+	"github.com/lib/pq"
 	"github.com/wb-go/wbf/dbpg"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 )
 
 type ClickRepo struct {
@@ -19,19 +31,35 @@ func (r *ClickRepo) CreateClick(ctx context.Context, click domain.Click) error {
 	const op = "repo.click.Create"
 
 	query := `
-		INSERT INTO clicks(id, alias, user_agent, client_name, device_type, ip)
-		VALUES ($1, $2, $3, $4, $5, $6);
+		INSERT INTO clicks(
+			id, link_id, workspace_id, alias, user_agent, client_name, client_version,
+			device_type, os_name, os_version, referrer_host, ip, country_code, region, city,
+			is_bot, visitor_id, clicked_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18);
 	`
 
 	if _, err := r.db.ExecContext(
 		ctx,
 		query,
 		click.ID,
+		click.LinkID,
+		click.WorkspaceID,
 		click.Alias,
 		click.UserAgent,
 		click.Client,
+		nullString(click.ClientVersion),
 		click.Device,
-		click.IP,
+		nullString(click.OS),
+		nullString(click.OSVersion),
+		nullString(click.Referrer),
+		nullString(click.IP),
+		nullString(click.Location.Country),
+		nullString(click.Location.Region),
+		nullString(click.Location.City),
+		click.IsBot,
+		nullString(click.VisitorID),
+		click.ClickedAt,
 	); err != nil {
 		return errutils.Wrap(op, err)
 	}
@@ -39,18 +67,318 @@ func (r *ClickRepo) CreateClick(ctx context.Context, click domain.Click) error {
 	return nil
 }
 
-func (r *ClickRepo) GetClicksByDay(ctx context.Context, alias string) ([]domain.ClickRow, error) {
-	const op = "repo.click.GetByDay"
+// clickTypes are the types of the clicks columns in insert order. Rows
+// selected from VALUES need them spelled out.
+var clickTypes = []string{
+	"uuid", "uuid", "uuid", "text", "text", "text", "text",
+	"text", "text", "text", "text", "text", "text", "text", "text",
+	"boolean", "text", "timestamp",
+}
+
+// maxParams is the most parameters Postgres takes in a statement.
+const maxParams = 65535
+
+// CreateClicks inserts clicks with multi-row INSERTs, as many clicks at a
+// time as a statement has parameters for. Clicks that are already stored
+// are skipped, so a batch can safely be written again, and so are clicks
+// of links deleted in the meantime. It returns repo.ErrInvalidClick when
+// Postgres rejects the data of a click.
+func (r *ClickRepo) CreateClicks(ctx context.Context, clicks []domain.Click) error {
+	const op = "repo.click.CreateClicks"
+
+	limit := maxParams / len(clickTypes)
+	for len(clicks) > 0 {
+		n := min(len(clicks), limit)
+		if err := r.insertClicks(ctx, clicks[:n]); err != nil {
+			if isDataError(err) {
+				err = fmt.Errorf("%w: %w", repo.ErrInvalidClick, err)
+			}
+			return errutils.Wrap(op, err)
+		}
+		clicks = clicks[n:]
+	}
+
+	return nil
+}
+
+func (r *ClickRepo) insertClicks(ctx context.Context, clicks []domain.Click) error {
+	columns := len(clickTypes)
+	values := make([]string, 0, len(clicks))
+	args := make([]interface{}, 0, len(clicks)*columns)
+	for i, click := range clicks {
+		placeholders := make([]string, columns)
+		for j := range placeholders {
+			placeholders[j] = "$" + strconv.Itoa(i*columns+j+1) + "::" + clickTypes[j]
+		}
+		values = append(values, "("+strings.Join(placeholders, ", ")+")")
+		args = append(args,
+			click.ID,
+			click.LinkID,
+			click.WorkspaceID,
+			click.Alias,
+			click.UserAgent,
+			click.Client,
+			nullString(click.ClientVersion),
+			click.Device,
+			nullString(click.OS),
+			nullString(click.OSVersion),
+			nullString(click.Referrer),
+			nullString(click.IP),
+			nullString(click.Location.Country),
+			nullString(click.Location.Region),
+			nullString(click.Location.City),
+			click.IsBot,
+			nullString(click.VisitorID),
+			click.ClickedAt,
+		)
+	}
 
 	query := `
-		SELECT DATE(clicked_at)::text AS aggregation, COUNT(*) AS clicks
+		INSERT INTO clicks(
+			id, link_id, workspace_id, alias, user_agent, client_name, client_version,
+			device_type, os_name, os_version, referrer_host, ip, country_code, region, city,
+			is_bot, visitor_id, clicked_at
+		)
+		SELECT v.*
+		FROM (VALUES ` + strings.Join(values, ", ") + `)
+			AS v(
+				id, link_id, workspace_id, alias, user_agent, client_name, client_version,
+				device_type, os_name, os_version, referrer_host, ip, country_code, region, city,
+				is_bot, visitor_id, clicked_at
+			)
+		WHERE EXISTS (SELECT 1 FROM links l WHERE l.id = v.link_id)
+		ON CONFLICT (id, clicked_at) DO NOTHING;
+	`
+
+	_, err := r.db.ExecContext(ctx, query, args...)
+	return err
+}
+
+// GetClickSeries counts the clicks of a link per bucket of series. Every
+// bucket of the range is returned, the ones without clicks with zero.
+// Clicks are stored in UTC and bucketed in the timezone of the series.
+// Rolled up clicks count towards the bucket their UTC hour falls in, only
+// the clicks not rolled up yet are counted from the raw rows.
+func (r *ClickRepo) GetClickSeries(ctx context.Context, workspaceID uuid.UUID, linkID uuid.UUID, series domain.Series) ([]domain.ClickBucket, error) {
+	const op = "repo.click.GetClickSeries"
+
+	// Buckets longer than an hour count the visitors of a UTC day once, in
+	// the hour of their first visit, which the raw clicks of a visitor
+	// already rolled up that day have been counted in.
+	query := `
+		WITH buckets AS (
+			SELECT generate_series(
+				date_trunc($3, $4::timestamptz AT TIME ZONE $6),
+				date_trunc($3, ($5::timestamptz - interval '1 microsecond') AT TIME ZONE $6),
+				('1 ' || $3)::interval
+			) AS bucket
+		), counts AS (
+			SELECT date_trunc($3, c.clicked_at AT TIME ZONE 'UTC' AT TIME ZONE $6) AS bucket,
+				COUNT(*) AS clicks,
+				COUNT(DISTINCT c.visitor_id) FILTER (WHERE $3 = 'hour' OR NOT EXISTS (
+					SELECT 1 FROM clicks p
+					WHERE p.link_id = c.link_id AND p.visitor_id = c.visitor_id AND p.rolled_up
+						AND p.clicked_at >= date_trunc('day', c.clicked_at)
+						AND p.clicked_at < date_trunc('day', c.clicked_at) + interval '1 day'
+				)) AS unique_clicks
+			FROM clicks c
+			WHERE c.workspace_id = $1 AND c.link_id = $2 AND NOT c.rolled_up
+				AND c.clicked_at >= $4::timestamptz AT TIME ZONE 'UTC'
+				AND c.clicked_at < $5::timestamptz AT TIME ZONE 'UTC'
+				AND ` + trafficCondition(series.Traffic) + `
+			GROUP BY 1
+			UNION ALL
+			SELECT date_trunc($3, bucket AT TIME ZONE 'UTC' AT TIME ZONE $6) AS bucket,
+				SUM(clicks) AS clicks,
+				SUM(CASE WHEN $3 = 'hour' THEN unique_clicks ELSE first_visits END) AS unique_clicks
+			FROM click_rollups
+			WHERE workspace_id = $1 AND link_id = $2 AND dimension = ''
+				AND bucket >= $4::timestamptz AT TIME ZONE 'UTC'
+				AND bucket < $5::timestamptz AT TIME ZONE 'UTC'
+				AND ` + trafficCondition(series.Traffic) + `
+			GROUP BY 1
+		)
+		SELECT b.bucket, COALESCE(SUM(c.clicks), 0), COALESCE(SUM(c.unique_clicks), 0)
+		FROM buckets b
+		LEFT JOIN counts c ON c.bucket = b.bucket
+		GROUP BY b.bucket
+		ORDER BY b.bucket;
+	`
+
+	rows, err := r.db.QueryContext(
+		ctx,
+		query,
+		workspaceID,
+		linkID,
+		series.Granularity,
+		series.From,
+		series.To,
+		series.Timezone,
+	)
+	if err != nil {
+		return nil, errutils.Wrap(op, err)
+	}
+	defer rows.Close()
+
+	var buckets []domain.ClickBucket
+	for rows.Next() {
+		var bucket domain.ClickBucket
+		if err := rows.Scan(&bucket.Start, &bucket.Clicks, &bucket.UniqueClicks); err != nil {
+			return nil, errutils.Wrap(op, err)
+		}
+		buckets = append(buckets, bucket)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errutils.Wrap(op, err)
+	}
+
+	return buckets, nil
+}
+
+// exportBatch is the number of clicks fetched from an export cursor at
+// once.
+const exportBatch = 1000
+
+// ExportClicks hands the raw clicks of a link in the range of series to
+// each, oldest first, and stops at the first error of each. Clicks are
+// read through a server-side cursor a batch at a time, so that only a
+// batch is held in memory however many clicks there are.
+func (r *ClickRepo) ExportClicks(ctx context.Context, workspaceID uuid.UUID, linkID uuid.UUID, series domain.Series, each func(domain.Click) error) error {
+	const op = "repo.click.ExportClicks"
+
+	// Cursors live in a transaction, which only reads.
+	tx, err := r.db.Master.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return errutils.Wrap(op, err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	declare := `
+		DECLARE export_clicks NO SCROLL CURSOR FOR
+		SELECT id, link_id, workspace_id, COALESCE(alias, ''), COALESCE(user_agent, ''), COALESCE(client_name, ''),
+			COALESCE(client_version, ''), COALESCE(device_type, ''), COALESCE(os_name, ''),
+			COALESCE(os_version, ''), COALESCE(referrer_host, ''), COALESCE(country_code, ''),
+			COALESCE(region, ''), COALESCE(city, ''), is_bot, COALESCE(visitor_id, ''), clicked_at
 		FROM clicks
-		WHERE alias = $1
-		GROUP BY DATE(clicked_at)
-		ORDER BY aggregation;
+		WHERE workspace_id = $1 AND link_id = $2
+			AND clicked_at >= $3::timestamptz AT TIME ZONE 'UTC'
+			AND clicked_at < $4::timestamptz AT TIME ZONE 'UTC'
+			AND ` + trafficCondition(series.Traffic) + `
+		ORDER BY clicked_at, id;
+	`
+	if _, err = tx.ExecContext(ctx, declare, workspaceID, linkID, series.From, series.To); err != nil {
+		return errutils.Wrap(op, err)
+	}
+
+	fetch := `FETCH ` + strconv.Itoa(exportBatch) + ` FROM export_clicks;`
+	for {
+		fetched, err := r.fetchClicks(ctx, tx, fetch, each)
+		if err != nil {
+			return errutils.Wrap(op, err)
+		}
+		if fetched < exportBatch {
+			return nil
+		}
+	}
+}
+
+// fetchClicks fetches a batch of clicks from a cursor and hands them to
+// each.
+func (r *ClickRepo) fetchClicks(ctx context.Context, tx *sql.Tx, fetch string, each func(domain.Click) error) (int, error) {
+	rows, err := tx.QueryContext(ctx, fetch)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	fetched := 0
+	for rows.Next() {
+		var click domain.Click
+		if err := rows.Scan(
+			&click.ID,
+			&click.LinkID,
+			&click.WorkspaceID,
+			&click.Alias,
+			&click.UserAgent,
+			&click.Client,
+			&click.ClientVersion,
+			&click.Device,
+			&click.OS,
+			&click.OSVersion,
+			&click.Referrer,
+			&click.Location.Country,
+			&click.Location.Region,
+			&click.Location.City,
+			&click.IsBot,
+			&click.VisitorID,
+			&click.ClickedAt,
+		); err != nil {
+			return fetched, err
+		}
+		fetched++
+		if err := each(click); err != nil {
+			return fetched, err
+		}
+	}
+
+	return fetched, rows.Err()
+}
+
+// breakdownColumns are the columns clicks are grouped by per dimension.
+var breakdownColumns = map[string]string{
+	domain.DimensionUserAgent: "client_name",
+	domain.DimensionDevice:    "device_type",
+	domain.DimensionOS:        "os_name",
+	domain.DimensionReferrer:  "referrer_host",
+	domain.DimensionCountry:   "country_code",
+}
+
+// GetClickBreakdown counts the clicks of a link in the range of series by
+// dimension, most clicked first. Clicks without the attribute are counted
+// under an empty one. Visitors count once per UTC day and value.
+func (r *ClickRepo) GetClickBreakdown(ctx context.Context, workspaceID uuid.UUID, linkID uuid.UUID, dimension string, series domain.Series) ([]domain.ClickRow, error) {
+	const op = "repo.click.GetClickBreakdown"
+
+	column, ok := breakdownColumns[dimension]
+	if !ok {
+		return nil, errutils.Wrap(op, fmt.Errorf("unknown dimension %q", dimension))
+	}
+
+	query := `
+		WITH counts AS (
+			SELECT COALESCE(c.` + column + `, '') AS aggregation,
+				COUNT(*) AS clicks,
+				COUNT(DISTINCT c.visitor_id) FILTER (WHERE NOT EXISTS (
+					SELECT 1 FROM clicks p
+					WHERE p.link_id = c.link_id AND p.visitor_id = c.visitor_id AND p.rolled_up
+						AND COALESCE(p.` + column + `, '') = COALESCE(c.` + column + `, '')
+						AND p.clicked_at >= date_trunc('day', c.clicked_at)
+						AND p.clicked_at < date_trunc('day', c.clicked_at) + interval '1 day'
+				)) AS unique_clicks
+			FROM clicks c
+			WHERE c.workspace_id = $1 AND c.link_id = $2 AND NOT c.rolled_up
+				AND c.clicked_at >= $3::timestamptz AT TIME ZONE 'UTC'
+				AND c.clicked_at < $4::timestamptz AT TIME ZONE 'UTC'
+				AND ` + trafficCondition(series.Traffic) + `
+			GROUP BY 1
+			UNION ALL
+			SELECT value AS aggregation,
+				SUM(clicks) AS clicks,
+				SUM(first_visits) AS unique_clicks
+			FROM click_rollups
+			WHERE workspace_id = $1 AND link_id = $2 AND dimension = $5
+				AND bucket >= $3::timestamptz AT TIME ZONE 'UTC'
+				AND bucket < $4::timestamptz AT TIME ZONE 'UTC'
+				AND ` + trafficCondition(series.Traffic) + `
+			GROUP BY 1
+		)
+		SELECT aggregation, SUM(clicks) AS clicks, SUM(unique_clicks) AS unique_clicks
+		FROM counts
+		GROUP BY aggregation
+		ORDER BY clicks DESC, aggregation;
 	`
 
-	rows, err := r.db.QueryContext(ctx, query, alias)
+	rows, err := r.db.QueryContext(ctx, query, workspaceID, linkID, series.From, series.To, dimension)
 	if err != nil {
 		return nil, errutils.Wrap(op, err)
 	}
@@ -59,69 +387,502 @@ func (r *ClickRepo) GetClicksByDay(ctx context.Context, alias string) ([]domain.
 	var clicks []domain.ClickRow
 	for rows.Next() {
 		var row domain.ClickRow
-		if err := rows.Scan(&row.Aggregation, &row.Clicks); err != nil {
+		if err := rows.Scan(&row.Aggregation, &row.Clicks, &row.UniqueClicks); err != nil {
 			return nil, errutils.Wrap(op, err)
 		}
 		clicks = append(clicks, row)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, errutils.Wrap(op, err)
+	}
 
 	return clicks, nil
 }
 
-func (r *ClickRepo) GetClicksByMonth(ctx context.Context, alias string) ([]domain.ClickRow, error) {
-	const op = "repo.click.GetByMonth"
+// GetLink reads the link behind alias if it is visible through access.
+// host is the custom domain of the link, empty for the default hosts.
+// Links outside of access are reported as ErrAliasNotFound.
+func (r *ClickRepo) GetLink(ctx context.Context, access auth.Access, host string, alias string) (domain.Link, error) {
+	const op = "repo.click.GetLink"
 
 	query := `
-		SELECT TO_CHAR(DATE_TRUNC('month', clicked_at), 'YYYY-MM') AS aggregation, COUNT(*) AS clicks
-		FROM clicks
-		WHERE alias = $1
-		GROUP BY TO_CHAR(DATE_TRUNC('month', clicked_at), 'YYYY-MM')
-		ORDER BY aggregation;
+		SELECT id, max_clicks, click_count
+		FROM links
+		WHERE alias = $1 AND workspace_id = $2 AND ($3 OR owner_id IS NOT DISTINCT FROM $4)
+		  AND (CASE WHEN $5 = '' THEN domain_id IS NULL
+		       ELSE domain_id = (SELECT id FROM domains WHERE host = $5) END)
+		  AND released_at IS NULL
+		LIMIT 1;
+	`
+
+	var link domain.Link
+	if err := r.db.QueryRowContext(ctx, query, alias, access.Workspace.ID, access.All, access.OwnerID, host).Scan(
+		&link.ID,
+		&link.MaxClicks,
+		&link.Used,
+	); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.Link{}, errutils.Wrap(op, repo.ErrAliasNotFound)
+		}
+		return domain.Link{}, errutils.Wrap(op, err)
+	}
+
+	return link, nil
+}
+
+// GetTopLinks ranks the links visible through access by their clicks in
+// the range of series, at most limit of them. Links without clicks are
+// left out.
+func (r *ClickRepo) GetTopLinks(ctx context.Context, access auth.Access, series domain.Series, limit int) ([]domain.TopLink, error) {
+	const op = "repo.click.GetTopLinks"
+
+	query := `
+		WITH counts AS (
+			SELECT c.link_id,
+				COUNT(*) AS clicks,
+				COUNT(DISTINCT c.visitor_id) FILTER (WHERE NOT EXISTS (
+					SELECT 1 FROM clicks p
+					WHERE p.link_id = c.link_id AND p.visitor_id = c.visitor_id AND p.rolled_up
+						AND p.clicked_at >= date_trunc('day', c.clicked_at)
+						AND p.clicked_at < date_trunc('day', c.clicked_at) + interval '1 day'
+				)) AS unique_clicks
+			FROM clicks c
+			WHERE c.workspace_id = $1 AND NOT c.rolled_up
+				AND c.clicked_at >= $4::timestamptz AT TIME ZONE 'UTC'
+				AND c.clicked_at < $5::timestamptz AT TIME ZONE 'UTC'
+				AND ` + trafficCondition(series.Traffic) + `
+			GROUP BY c.link_id
+			UNION ALL
+			SELECT link_id,
+				SUM(clicks) AS clicks,
+				SUM(first_visits) AS unique_clicks
+			FROM click_rollups
+			WHERE workspace_id = $1 AND dimension = ''
+				AND bucket >= $4::timestamptz AT TIME ZONE 'UTC'
+				AND bucket < $5::timestamptz AT TIME ZONE 'UTC'
+				AND ` + trafficCondition(series.Traffic) + `
+			GROUP BY link_id
+		)
+		SELECT l.alias, COALESCE(d.host, ''), l.url, SUM(c.clicks) AS clicks, SUM(c.unique_clicks)
+		FROM counts c
+		JOIN links l ON l.id = c.link_id
+		LEFT JOIN domains d ON d.id = l.domain_id
+		WHERE l.workspace_id = $1 AND ($2 OR l.owner_id IS NOT DISTINCT FROM $3)
+		GROUP BY l.id, l.alias, d.host, l.url
+		ORDER BY clicks DESC, l.alias
+		LIMIT $6;
 	`
 
-	rows, err := r.db.QueryContext(ctx, query, alias)
+	rows, err := r.db.QueryContext(ctx, query, access.Workspace.ID, access.All, access.OwnerID, series.From, series.To, limit)
 	if err != nil {
 		return nil, errutils.Wrap(op, err)
 	}
 	defer rows.Close()
 
-	var clicks []domain.ClickRow
+	var links []domain.TopLink
 	for rows.Next() {
-		var row domain.ClickRow
-		if err := rows.Scan(&row.Aggregation, &row.Clicks); err != nil {
+		var link domain.TopLink
+		if err := rows.Scan(&link.Alias, &link.Domain, &link.URL, &link.Clicks, &link.UniqueClicks); err != nil {
 			return nil, errutils.Wrap(op, err)
 		}
-		clicks = append(clicks, row)
+		links = append(links, link)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errutils.Wrap(op, err)
 	}
 
-	return clicks, nil
+	return links, nil
 }
 
-func (r *ClickRepo) GetClicksByUserAgent(ctx context.Context, alias string) ([]domain.ClickRow, error) {
-	const op = "repo.click.GetByUserAgent"
+// GetWorkspaceSeries counts the clicks of all the links visible through
+// access per bucket of series, like GetClickSeries does for one link.
+// Visitors are counted per link, so a visitor of two links counts twice.
+func (r *ClickRepo) GetWorkspaceSeries(ctx context.Context, access auth.Access, series domain.Series) ([]domain.ClickBucket, error) {
+	const op = "repo.click.GetWorkspaceSeries"
 
 	query := `
-		SELECT client_name AS aggregation, COUNT(*) AS clicks
-		FROM clicks
-		WHERE alias = $1
-		GROUP BY client_name
-		ORDER BY clicks DESC;
+		WITH buckets AS (
+			SELECT generate_series(
+				date_trunc($4, $5::timestamptz AT TIME ZONE $7),
+				date_trunc($4, ($6::timestamptz - interval '1 microsecond') AT TIME ZONE $7),
+				('1 ' || $4)::interval
+			) AS bucket
+		), scoped AS (
+			SELECT id FROM links
+			WHERE workspace_id = $1 AND ($2 OR owner_id IS NOT DISTINCT FROM $3)
+		), counts AS (
+			SELECT date_trunc($4, c.clicked_at AT TIME ZONE 'UTC' AT TIME ZONE $7) AS bucket,
+				COUNT(*) AS clicks,
+				COUNT(DISTINCT c.visitor_id) FILTER (WHERE $4 = 'hour' OR NOT EXISTS (
+					SELECT 1 FROM clicks p
+					WHERE p.link_id = c.link_id AND p.visitor_id = c.visitor_id AND p.rolled_up
+						AND p.clicked_at >= date_trunc('day', c.clicked_at)
+						AND p.clicked_at < date_trunc('day', c.clicked_at) + interval '1 day'
+				)) AS unique_clicks
+			FROM clicks c
+			JOIN scoped s ON s.id = c.link_id
+			WHERE c.workspace_id = $1 AND NOT c.rolled_up
+				AND c.clicked_at >= $5::timestamptz AT TIME ZONE 'UTC'
+				AND c.clicked_at < $6::timestamptz AT TIME ZONE 'UTC'
+				AND ` + trafficCondition(series.Traffic) + `
+			GROUP BY 1, c.link_id
+			UNION ALL
+			SELECT date_trunc($4, r.bucket AT TIME ZONE 'UTC' AT TIME ZONE $7) AS bucket,
+				SUM(r.clicks) AS clicks,
+				SUM(CASE WHEN $4 = 'hour' THEN r.unique_clicks ELSE r.first_visits END) AS unique_clicks
+			FROM click_rollups r
+			JOIN scoped s ON s.id = r.link_id
+			WHERE r.workspace_id = $1 AND r.dimension = ''
+				AND r.bucket >= $5::timestamptz AT TIME ZONE 'UTC'
+				AND r.bucket < $6::timestamptz AT TIME ZONE 'UTC'
+				AND ` + trafficCondition(series.Traffic) + `
+			GROUP BY 1
+		)
+		SELECT b.bucket, COALESCE(SUM(c.clicks), 0), COALESCE(SUM(c.unique_clicks), 0)
+		FROM buckets b
+		LEFT JOIN counts c ON c.bucket = b.bucket
+		GROUP BY b.bucket
+		ORDER BY b.bucket;
 	`
 
-	rows, err := r.db.QueryContext(ctx, query, alias)
+	rows, err := r.db.QueryContext(
+		ctx,
+		query,
+		access.Workspace.ID,
+		access.All,
+		access.OwnerID,
+		series.Granularity,
+		series.From,
+		series.To,
+		series.Timezone,
+	)
 	if err != nil {
 		return nil, errutils.Wrap(op, err)
 	}
 	defer rows.Close()
 
-	var clicks []domain.ClickRow
+	var buckets []domain.ClickBucket
 	for rows.Next() {
-		var row domain.ClickRow
-		if err := rows.Scan(&row.Aggregation, &row.Clicks); err != nil {
+		var bucket domain.ClickBucket
+		if err := rows.Scan(&bucket.Start, &bucket.Clicks, &bucket.UniqueClicks); err != nil {
 			return nil, errutils.Wrap(op, err)
 		}
-		clicks = append(clicks, row)
+		buckets = append(buckets, bucket)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errutils.Wrap(op, err)
 	}
 
-	return clicks, nil
+	return buckets, nil
+}
+
+// GetLinkSeries counts the links visible through access created per
+// bucket of series, deleted ones included. Every bucket of the range is
+// returned, the ones without links with zero.
+func (r *ClickRepo) GetLinkSeries(ctx context.Context, access auth.Access, series domain.Series) ([]domain.LinkBucket, error) {
+	const op = "repo.click.GetLinkSeries"
+
+	query := `
+		WITH buckets AS (
+			SELECT generate_series(
+				date_trunc($4, $5::timestamptz AT TIME ZONE $7),
+				date_trunc($4, ($6::timestamptz - interval '1 microsecond') AT TIME ZONE $7),
+				('1 ' || $4)::interval
+			) AS bucket
+		), counts AS (
+			SELECT date_trunc($4, created_at AT TIME ZONE 'UTC' AT TIME ZONE $7) AS bucket,
+				COUNT(*) AS links
+			FROM links
+			WHERE workspace_id = $1 AND ($2 OR owner_id IS NOT DISTINCT FROM $3)
+				AND created_at >= $5::timestamptz AT TIME ZONE 'UTC'
+				AND created_at < $6::timestamptz AT TIME ZONE 'UTC'
+			GROUP BY 1
+		)
+		SELECT b.bucket, COALESCE(c.links, 0)
+		FROM buckets b
+		LEFT JOIN counts c ON c.bucket = b.bucket
+		ORDER BY b.bucket;
+	`
+
+	rows, err := r.db.QueryContext(
+		ctx,
+		query,
+		access.Workspace.ID,
+		access.All,
+		access.OwnerID,
+		series.Granularity,
+		series.From,
+		series.To,
+		series.Timezone,
+	)
+	if err != nil {
+		return nil, errutils.Wrap(op, err)
+	}
+	defer rows.Close()
+
+	var buckets []domain.LinkBucket
+	for rows.Next() {
+		var bucket domain.LinkBucket
+		if err := rows.Scan(&bucket.Start, &bucket.Links); err != nil {
+			return nil, errutils.Wrap(op, err)
+		}
+		buckets = append(buckets, bucket)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errutils.Wrap(op, err)
+	}
+
+	return buckets, nil
+}
+
+// GetLinkDomains counts the links visible through access by the host of
+// their url, most linked first, at most limit of them, along with the
+// number of links. Deleted links are left out.
+func (r *ClickRepo) GetLinkDomains(ctx context.Context, access auth.Access, limit int) ([]domain.LinkDomain, int, error) {
+	const op = "repo.click.GetLinkDomains"
+
+	// The host is what follows the scheme and the userinfo, up to the
+	// port or the path. IPv6 hosts keep their brackets.
+	query := `
+		SELECT domain, COUNT(*) AS links, SUM(COUNT(*)) OVER () AS total
+		FROM (
+			SELECT COALESCE(lower(substring(url FROM '^[A-Za-z][A-Za-z0-9+.-]*://(?:[^/?#@]*@)?(\[[^]]*\]|[^/?#:]*)')), '') AS domain
+			FROM links
+			WHERE workspace_id = $1 AND ($2 OR owner_id IS NOT DISTINCT FROM $3) AND status <> 'deleted'
+		) l
+		GROUP BY domain
+		ORDER BY links DESC, domain
+		LIMIT $4;
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, access.Workspace.ID, access.All, access.OwnerID, limit)
+	if err != nil {
+		return nil, 0, errutils.Wrap(op, err)
+	}
+	defer rows.Close()
+
+	var (
+		domains []domain.LinkDomain
+		total   int
+	)
+	for rows.Next() {
+		var linkDomain domain.LinkDomain
+		if err := rows.Scan(&linkDomain.Domain, &linkDomain.Links, &total); err != nil {
+			return nil, 0, errutils.Wrap(op, err)
+		}
+		domains = append(domains, linkDomain)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, errutils.Wrap(op, err)
+	}
+
+	return domains, total, nil
+}
+
+// RollUpClicks rolls the raw clicks of [since, until) not rolled up yet
+// into the hourly rollups. The rollups of every UTC day with such clicks
+// are recomputed from its raw clicks, which are marked rolled up in the
+// same statement, so that a click is counted either raw or rolled up.
+// since is the start of a UTC day, until the start of an hour.
+func (r *ClickRepo) RollUpClicks(ctx context.Context, since time.Time, until time.Time) (int64, error) {
+	const op = "repo.click.RollUpClicks"
+
+	selects := []string{rollupSelect("", "''")}
+	for _, dimension := range slices.Sorted(maps.Keys(breakdownColumns)) {
+		selects = append(selects, rollupSelect(dimension, "COALESCE("+breakdownColumns[dimension]+", '')"))
+	}
+
+	// Concurrent compactions would recompute the same days from different
+	// snapshots, only one runs at a time.
+	query := `
+		WITH lock AS (
+			SELECT pg_try_advisory_xact_lock(hashtext('click_rollups')) AS locked
+		), dirty AS (
+			UPDATE clicks SET rolled_up = true
+			WHERE NOT rolled_up AND clicked_at >= $1 AND clicked_at < $2
+				AND (SELECT locked FROM lock)
+			RETURNING link_id, date_trunc('day', clicked_at) AS day
+		), days AS (
+			SELECT DISTINCT link_id, day FROM dirty
+		), day_clicks AS (
+			SELECT c.*, date_trunc('hour', c.clicked_at) AS bucket
+			FROM clicks c
+			JOIN days d ON d.link_id = c.link_id
+			WHERE c.clicked_at >= d.day AND c.clicked_at < d.day + interval '1 day'
+				AND c.clicked_at < $2
+		), rolled AS (` + strings.Join(selects, `
+			UNION ALL`) + `
+		)
+		INSERT INTO click_rollups(link_id, workspace_id, bucket, is_bot, dimension, value, clicks, unique_clicks, first_visits)
+		SELECT link_id, workspace_id, bucket, is_bot, dimension, value, clicks, unique_clicks, first_visits
+		FROM rolled
+		ON CONFLICT (link_id, dimension, bucket, is_bot, value) DO UPDATE
+		SET clicks = EXCLUDED.clicks,
+			unique_clicks = EXCLUDED.unique_clicks,
+			first_visits = EXCLUDED.first_visits;
+	`
+
+	res, err := r.db.ExecContext(ctx, query, since.UTC(), until.UTC())
+	if err != nil {
+		return 0, errutils.Wrap(op, err)
+	}
+
+	rolled, err := res.RowsAffected()
+	if err != nil {
+		return 0, errutils.Wrap(op, err)
+	}
+	return rolled, nil
+}
+
+// rollupSelect aggregates the clicks of the days to roll up by hour and
+// value. A visitor is a first visit in the hour it is first seen with the
+// value on its UTC day.
+func rollupSelect(dimension string, value string) string {
+	return `
+			SELECT workspace_id, link_id, bucket, is_bot, '` + dimension + `' AS dimension, value,
+				COUNT(*) AS clicks,
+				COUNT(DISTINCT visitor_id) AS unique_clicks,
+				COUNT(DISTINCT visitor_id) FILTER (WHERE first) AS first_visits
+			FROM (
+				SELECT workspace_id, link_id, bucket, is_bot, visitor_id, value,
+					row_number() OVER (
+						PARTITION BY link_id, visitor_id, value, date_trunc('day', clicked_at)
+						ORDER BY clicked_at
+					) = 1 AS first
+				FROM (SELECT *, ` + value + ` AS value FROM day_clicks) v
+			) f
+			GROUP BY workspace_id, link_id, bucket, is_bot, value`
+}
+
+// GetClickPartitions returns the partitions of clicks, by end of range.
+func (r *ClickRepo) GetClickPartitions(ctx context.Context) ([]domain.ClickPartition, error) {
+	const op = "repo.click.GetClickPartitions"
+
+	query := `
+		SELECT c.relname,
+			substring(pg_get_expr(c.relpartbound, c.oid) FROM 'TO \(''([^'']+)''\)')::timestamp AS upper,
+			i.inhdetachpending
+		FROM pg_inherits i
+		JOIN pg_class c ON c.oid = i.inhrelid
+		WHERE i.inhparent = 'clicks'::regclass
+		ORDER BY upper;
+	`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, errutils.Wrap(op, err)
+	}
+	defer rows.Close()
+
+	var partitions []domain.ClickPartition
+	for rows.Next() {
+		var (
+			partition domain.ClickPartition
+			to        sql.NullTime
+		)
+		if err := rows.Scan(&partition.Name, &to, &partition.DetachPending); err != nil {
+			return nil, errutils.Wrap(op, err)
+		}
+		// A default partition has no range.
+		if !to.Valid {
+			continue
+		}
+		partition.To = to.Time
+		partitions = append(partitions, partition)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errutils.Wrap(op, err)
+	}
+
+	return partitions, nil
+}
+
+// HasRawClicks reports whether a partition of clicks holds clicks not
+// rolled up yet.
+func (r *ClickRepo) HasRawClicks(ctx context.Context, partition string) (bool, error) {
+	const op = "repo.click.HasRawClicks"
+
+	query := fmt.Sprintf(`SELECT EXISTS (SELECT 1 FROM %s WHERE NOT rolled_up);`, pq.QuoteIdentifier(partition))
+
+	var raw bool
+	if err := r.db.QueryRowContext(ctx, query).Scan(&raw); err != nil {
+		return false, errutils.Wrap(op, err)
+	}
+	return raw, nil
+}
+
+// CreateClickPartition creates the partition of clicks for [from, to),
+// named after the month of from.
+func (r *ClickRepo) CreateClickPartition(ctx context.Context, from time.Time, to time.Time) error {
+	const op = "repo.click.CreateClickPartition"
+
+	query := fmt.Sprintf(
+		`CREATE TABLE IF NOT EXISTS %s PARTITION OF clicks FOR VALUES FROM (%s) TO (%s);`,
+		pq.QuoteIdentifier("clicks_"+from.UTC().Format("2006_01")),
+		pq.QuoteLiteral(from.UTC().Format(time.DateTime)),
+		pq.QuoteLiteral(to.UTC().Format(time.DateTime)),
+	)
+
+	if _, err := r.db.ExecContext(ctx, query); err != nil {
+		return errutils.Wrap(op, err)
+	}
+	return nil
+}
+
+// DetachClickPartition detaches a partition from clicks without blocking
+// the queries on clicks, or finishes a detach that was interrupted.
+func (r *ClickRepo) DetachClickPartition(ctx context.Context, partition domain.ClickPartition) error {
+	const op = "repo.click.DetachClickPartition"
+
+	mode := "CONCURRENTLY"
+	if partition.DetachPending {
+		mode = "FINALIZE"
+	}
+	query := fmt.Sprintf(`ALTER TABLE clicks DETACH PARTITION %s %s;`, pq.QuoteIdentifier(partition.Name), mode)
+
+	if _, err := r.db.ExecContext(ctx, query); err != nil {
+		return errutils.Wrap(op, err)
+	}
+	return nil
+}
+
+// DropClickTable drops a detached partition of clicks.
+func (r *ClickRepo) DropClickTable(ctx context.Context, name string) error {
+	const op = "repo.click.DropClickTable"
+
+	query := fmt.Sprintf(`DROP TABLE IF EXISTS %s;`, pq.QuoteIdentifier(name))
+
+	if _, err := r.db.ExecContext(ctx, query); err != nil {
+		return errutils.Wrap(op, err)
+	}
+	return nil
+}
+
+// trafficCondition is the condition selecting the clicks of traffic.
+func trafficCondition(traffic string) string {
+	switch traffic {
+	case domain.TrafficBots:
+		return "is_bot"
+	case domain.TrafficAll:
+		return "true"
+	default:
+		return "NOT is_bot"
+	}
+}
+
+// isDataError reports whether Postgres rejected the data written, as
+// opposed to failing to write it: a data exception or an integrity
+// constraint violation.
+func isDataError(err error) bool {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return false
+	}
+	class := pqErr.Code.Class()
+	return class == "22" || class == "23"
+}
+
+// nullString stores empty strings as NULL.
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
 }
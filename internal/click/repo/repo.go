@@ -1 +1,10 @@
 package repo
+
+import "errors"
+
+var (
+	ErrAliasNotFound = errors.New("alias not found")
+	// ErrInvalidClick is returned when Postgres rejects the data of a
+	// click, which writing it again would not change.
+	ErrInvalidClick = errors.New("invalid click")
+)
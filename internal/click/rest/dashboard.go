@@ -0,0 +1,150 @@
+package rest
+
+import (
+	"errors"
+	"github.com/ilam072/shortener/internal/auth"
+	"github.com/ilam072/shortener/internal/click/types/dto"
+	"github.com/ilam072/shortener/internal/response"
+	"github.com/wb-go/wbf/ginext"
+	"github.com/wb-go/wbf/zlog"
+	"net/http"
+)
+
+// GetTopLinks godoc
+// @Summary Самые популярные ссылки
+// @Description Возвращает ссылки рабочего пространства с наибольшим числом кликов за период. Администраторы видят все ссылки пространства, остальные — только свои. Клики ботов по умолчанию не учитываются
+// @Tags Dashboard
+// @Produce json
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Param from query string false "Начало периода, RFC 3339 или дата (по умолчанию 30 дней до конца)"
+// @Param to query string false "Конец периода, RFC 3339 или дата включительно (по умолчанию сейчас)"
+// @Param tz query string false "Часовой пояс IANA (по умолчанию UTC)"
+// @Param include_bots query bool false "Учитывать клики ботов (по умолчанию false)"
+// @Param limit query int false "Число ссылок, от 1 до 100 (по умолчанию 10)"
+// @Success 200 {object} dto.GetTopLinks "Ссылки по убыванию числа кликов"
+// @Failure 400 {object} response.Response "invalid query"
+// @Failure 401 {object} response.Response "invalid credentials"
+// @Failure 403 {object} response.Response "insufficient scope"
+// @Failure 500 {object} response.Response "internal server error"
+// @Router /dashboard/top-links [get]
+func (h *ClickHandler) GetTopLinks(c *ginext.Context) {
+	access := auth.FromContext(c.Request.Context()).Access()
+
+	links, err := h.click.GetTopLinks(c.Request.Context(), access, dashboardQuery(c))
+	if err != nil {
+		writeDashboardError(c, err, "failed to get top links")
+		return
+	}
+
+	response.Raw(c, http.StatusOK, links)
+}
+
+// GetWorkspaceClicks godoc
+// @Summary Клики по всем ссылкам
+// @Description Возвращает временной ряд кликов по всем ссылкам рабочего пространства за период с нулями в пустых интервалах. Уникальные клики считаются отдельно для каждой ссылки. Клики ботов по умолчанию не учитываются
+// @Tags Dashboard
+// @Produce json
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Param from query string false "Начало периода, RFC 3339 или дата (по умолчанию 30 дней до конца)"
+// @Param to query string false "Конец периода, RFC 3339 или дата включительно (по умолчанию сейчас)"
+// @Param tz query string false "Часовой пояс IANA (по умолчанию UTC)"
+// @Param granularity query string false "Интервал ряда" Enums(hour, day, week, month)
+// @Param include_bots query bool false "Учитывать клики ботов (по умолчанию false)"
+// @Success 200 {object} dto.GetWorkspaceClicks "Клики по всем ссылкам"
+// @Failure 400 {object} response.Response "invalid query"
+// @Failure 401 {object} response.Response "invalid credentials"
+// @Failure 403 {object} response.Response "insufficient scope"
+// @Failure 500 {object} response.Response "internal server error"
+// @Router /dashboard/clicks [get]
+func (h *ClickHandler) GetWorkspaceClicks(c *ginext.Context) {
+	access := auth.FromContext(c.Request.Context()).Access()
+
+	clicks, err := h.click.GetWorkspaceClicks(c.Request.Context(), access, dashboardQuery(c))
+	if err != nil {
+		writeDashboardError(c, err, "failed to get workspace clicks")
+		return
+	}
+
+	response.Raw(c, http.StatusOK, clicks)
+}
+
+// GetCreatedLinks godoc
+// @Summary Новые ссылки
+// @Description Возвращает временной ряд числа ссылок, созданных в рабочем пространстве за период, включая удалённые
+// @Tags Dashboard
+// @Produce json
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Param from query string false "Начало периода, RFC 3339 или дата (по умолчанию 30 дней до конца)"
+// @Param to query string false "Конец периода, RFC 3339 или дата включительно (по умолчанию сейчас)"
+// @Param tz query string false "Часовой пояс IANA (по умолчанию UTC)"
+// @Param granularity query string false "Интервал ряда" Enums(hour, day, week, month)
+// @Success 200 {object} dto.GetCreatedLinks "Созданные ссылки"
+// @Failure 400 {object} response.Response "invalid query"
+// @Failure 401 {object} response.Response "invalid credentials"
+// @Failure 403 {object} response.Response "insufficient scope"
+// @Failure 500 {object} response.Response "internal server error"
+// @Router /dashboard/links [get]
+func (h *ClickHandler) GetCreatedLinks(c *ginext.Context) {
+	access := auth.FromContext(c.Request.Context()).Access()
+
+	links, err := h.click.GetCreatedLinks(c.Request.Context(), access, dashboardQuery(c))
+	if err != nil {
+		writeDashboardError(c, err, "failed to get created links")
+		return
+	}
+
+	response.Raw(c, http.StatusOK, links)
+}
+
+// GetLinkDomains godoc
+// @Summary Ссылки по доменам назначения
+// @Description Возвращает число ссылок рабочего пространства по домену, на который они ведут, начиная с самых частых. Удалённые ссылки не учитываются
+// @Tags Dashboard
+// @Produce json
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Param limit query int false "Число доменов, от 1 до 100 (по умолчанию 10)"
+// @Success 200 {object} dto.GetLinkDomains "Ссылки по доменам"
+// @Failure 400 {object} response.Response "invalid query"
+// @Failure 401 {object} response.Response "invalid credentials"
+// @Failure 403 {object} response.Response "insufficient scope"
+// @Failure 500 {object} response.Response "internal server error"
+// @Router /dashboard/domains [get]
+func (h *ClickHandler) GetLinkDomains(c *ginext.Context) {
+	access := auth.FromContext(c.Request.Context()).Access()
+
+	domains, err := h.click.GetLinkDomains(c.Request.Context(), access, dashboardQuery(c))
+	if err != nil {
+		writeDashboardError(c, err, "failed to get link domains")
+		return
+	}
+
+	response.Raw(c, http.StatusOK, domains)
+}
+
+func dashboardQuery(c *ginext.Context) dto.DashboardQuery {
+	return dto.DashboardQuery{
+		From:        c.Query("from"),
+		To:          c.Query("to"),
+		Timezone:    c.Query("tz"),
+		Granularity: c.Query("granularity"),
+		IncludeBots: c.Query("include_bots"),
+		Limit:       c.Query("limit"),
+	}
+}
+
+// writeDashboardError answers a dashboard request that failed with err,
+// logging msg for the errors that are not the caller's.
+func writeDashboardError(c *ginext.Context, err error, msg string) {
+	for _, queryErr := range queryErrors {
+		if errors.Is(err, queryErr) {
+			response.Error(queryErr.Error()).WriteJSON(c, http.StatusBadRequest)
+			return
+		}
+	}
+	zlog.Logger.Error().Err(err).Msg(msg)
+	response.Error("internal server error, try again later").WriteJSON(c, http.StatusInternalServerError)
+}
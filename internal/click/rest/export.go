@@ -0,0 +1,225 @@
+package rest
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/ilam072/shortener/internal/click/service"
+	"github.com/wb-go/wbf/ginext"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Export formats.
+const (
+	FormatCSV    = "csv"
+	FormatNDJSON = "ndjson"
+)
+
+// exportFlushRows is how many rows are buffered before they are sent.
+const exportFlushRows = 500
+
+var errInvalidExportFormat = errors.New("format must be one of csv, ndjson")
+
+// exportResponse sends an export as an attachment. Nothing is written until
+// the header row, so a failed export can still answer with an error.
+type exportResponse struct {
+	c           *ginext.Context
+	rc          *http.ResponseController
+	timeout     time.Duration
+	contentType string
+	filename    string
+	started     bool
+	rows        int
+}
+
+func (r *exportResponse) start() error {
+	r.c.Header("Content-Type", r.contentType)
+	r.c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": r.filename}))
+	r.c.Header("Cache-Control", "no-store")
+	r.c.Status(http.StatusOK)
+	r.started = true
+	return r.deadline()
+}
+
+// clearExportHeaders drops the headers of an export that failed before
+// any of it was sent, for the error to replace it.
+func clearExportHeaders(c *ginext.Context) {
+	header := c.Writer.Header()
+	header.Del("Content-Type")
+	header.Del("Content-Disposition")
+	header.Del("Cache-Control")
+}
+
+// row counts a buffered row, reporting when the rows are due to be sent.
+func (r *exportResponse) row() bool {
+	r.rows++
+	return r.rows%exportFlushRows == 0
+}
+
+// deadline bounds the next write, so a client that does not read does not
+// hold the export open.
+func (r *exportResponse) deadline() error {
+	if err := r.rc.SetWriteDeadline(time.Now().Add(r.timeout)); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		return err
+	}
+	return nil
+}
+
+// exportWriter is a service.ExportWriter that is flushed once the export
+// is done.
+type exportWriter interface {
+	service.ExportWriter
+	Flush() error
+}
+
+func newExportWriter(c *ginext.Context, format string, name string, timeout time.Duration) exportWriter {
+	resp := exportResponse{c: c, rc: http.NewResponseController(c.Writer), timeout: timeout}
+	if format == FormatNDJSON {
+		resp.contentType = "application/x-ndjson"
+		resp.filename = name + ".ndjson"
+		return &ndjsonWriter{exportResponse: resp, w: bufio.NewWriter(c.Writer)}
+	}
+	resp.contentType = "text/csv; charset=utf-8"
+	resp.filename = name + ".csv"
+	return &csvWriter{exportResponse: resp, w: csv.NewWriter(c.Writer)}
+}
+
+// csvWriter writes an export as CSV with a header row.
+type csvWriter struct {
+	exportResponse
+	w      *csv.Writer
+	record []string
+}
+
+func (w *csvWriter) Header(columns []string) error {
+	if err := w.start(); err != nil {
+		return err
+	}
+	w.record = make([]string, len(columns))
+	return w.w.Write(columns)
+}
+
+func (w *csvWriter) Row(values []any) error {
+	for i, value := range values {
+		w.record[i] = formatValue(value)
+		if _, ok := value.(string); ok {
+			w.record[i] = escapeFormula(w.record[i])
+		}
+	}
+	if err := w.w.Write(w.record); err != nil {
+		return err
+	}
+	if w.row() {
+		return w.Flush()
+	}
+	return nil
+}
+
+func (w *csvWriter) Flush() error {
+	if !w.started {
+		return nil
+	}
+	if err := w.deadline(); err != nil {
+		return err
+	}
+	w.w.Flush()
+	if err := w.w.Error(); err != nil {
+		return err
+	}
+	return w.rc.Flush()
+}
+
+// ndjsonWriter writes an export as one JSON object per row, keyed by the
+// columns in their order.
+type ndjsonWriter struct {
+	exportResponse
+	w       *bufio.Writer
+	columns [][]byte
+}
+
+func (w *ndjsonWriter) Header(columns []string) error {
+	if err := w.start(); err != nil {
+		return err
+	}
+	w.columns = make([][]byte, len(columns))
+	for i, column := range columns {
+		key, err := json.Marshal(column)
+		if err != nil {
+			return err
+		}
+		w.columns[i] = key
+	}
+	return nil
+}
+
+func (w *ndjsonWriter) Row(values []any) error {
+	w.w.WriteByte('{')
+	for i, value := range values {
+		if i > 0 {
+			w.w.WriteByte(',')
+		}
+		payload, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+		w.w.Write(w.columns[i])
+		w.w.WriteByte(':')
+		w.w.Write(payload)
+	}
+	if _, err := w.w.WriteString("}\n"); err != nil {
+		return err
+	}
+	if w.row() {
+		return w.Flush()
+	}
+	return nil
+}
+
+func (w *ndjsonWriter) Flush() error {
+	if !w.started {
+		return nil
+	}
+	if err := w.deadline(); err != nil {
+		return err
+	}
+	if err := w.w.Flush(); err != nil {
+		return err
+	}
+	return w.rc.Flush()
+}
+
+// formulaPrefixes are the first characters that make spreadsheets read a
+// cell as a formula.
+const formulaPrefixes = "=+-@\t\r"
+
+// escapeFormula keeps a text field from being read as a formula by
+// spreadsheets, since user agents, referrers and locations come from
+// clients.
+func escapeFormula(field string) string {
+	if field != "" && strings.ContainsRune(formulaPrefixes, rune(field[0])) {
+		return "'" + field
+	}
+	return field
+}
+
+// formatValue formats a value of an export row as a CSV field.
+func formatValue(value any) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case bool:
+		return strconv.FormatBool(v)
+	case int:
+		return strconv.Itoa(v)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -2,35 +2,99 @@ package rest
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/ilam072/shortener/internal/auth"
+	"github.com/ilam072/shortener/internal/click/service"
+	"github.com/ilam072/shortener/internal/click/types/domain"
 	"github.com/ilam072/shortener/internal/click/types/dto"
 	_ "github.com/ilam072/shortener/internal/click/types/dto"
 	"github.com/ilam072/shortener/internal/response"
 	"github.com/wb-go/wbf/ginext"
 	"github.com/wb-go/wbf/zlog"
 	"net/http"
+	"sync"
+	"time"
 )
 
 //go:generate mockgen -source=handler.go -destination=../mocks/rest_mocks.go -package=mocks
 type Click interface {
-	GetClicksSummary(ctx context.Context, alias string) (dto.GetClicks, error)
+	GetClicksSummary(ctx context.Context, access auth.Access, host string, alias string, query dto.ClicksQuery) (dto.GetClicks, error)
+	Export(ctx context.Context, access auth.Access, host string, alias string, query dto.ExportQuery, w service.ExportWriter) error
+	GetTopLinks(ctx context.Context, access auth.Access, query dto.DashboardQuery) (dto.GetTopLinks, error)
+	GetWorkspaceClicks(ctx context.Context, access auth.Access, query dto.DashboardQuery) (dto.GetWorkspaceClicks, error)
+	GetCreatedLinks(ctx context.Context, access auth.Access, query dto.DashboardQuery) (dto.GetCreatedLinks, error)
+	GetLinkDomains(ctx context.Context, access auth.Access, query dto.DashboardQuery) (dto.GetLinkDomains, error)
+}
+
+type Live interface {
+	StreamClicks(ctx context.Context, access auth.Access, host string, alias string, includeBots string) (*service.ClickStream, error)
+}
+
+// StreamConfig bounds live click streams.
+type StreamConfig struct {
+	// Heartbeat is how often a comment is sent on a stream without clicks,
+	// so that proxies do not close it.
+	Heartbeat time.Duration
+	// IdleTimeout ends streams without clicks for that long.
+	IdleTimeout time.Duration
+	// WriteTimeout ends streams and exports whose client does not take a
+	// write in that long.
+	WriteTimeout time.Duration
+}
+
+// queryErrors are the errors of malformed analytics queries.
+var queryErrors = []error{
+	service.ErrInvalidTimezone,
+	service.ErrInvalidGranularity,
+	service.ErrInvalidRange,
+	service.ErrRangeTooLarge,
+	service.ErrInvalidDimension,
+	service.ErrGeoUnavailable,
+	service.ErrInvalidIncludeBots,
+	service.ErrInvalidLimit,
 }
 
 type ClickHandler struct {
-	click Click
+	click  Click
+	live   Live
+	stream StreamConfig
+
+	closeOnce sync.Once
+	closed    chan struct{}
+}
+
+func NewClickHandler(click Click, live Live, stream StreamConfig) *ClickHandler {
+	return &ClickHandler{click: click, live: live, stream: stream, closed: make(chan struct{})}
 }
 
-func NewClickHandler(click Click) *ClickHandler {
-	return &ClickHandler{click: click}
+// CloseStreams ends the open live streams, which would otherwise hold up
+// the shutdown of the server.
+func (h *ClickHandler) CloseStreams() {
+	h.closeOnce.Do(func() { close(h.closed) })
 }
 
 // GetAnalytics godoc
 // @Summary Получить аналитику по ссылке
-// @Description Возвращает статистику кликов по alias: по дням, месяцам и user-agent
+// @Description Возвращает статистику кликов по alias за период: временной ряд с нулями в пустых интервалах и разбивки по браузеру, устройству, ОС, источнику перехода и стране. Клики ботов по умолчанию не учитываются и сводятся отдельно
 // @Tags Analytics
 // @Produce json
+// @Security ApiKeyAuth
+// @Security BearerAuth
 // @Param alias path string true "Alias ссылки"
+// @Param domain query string false "Собственный домен ссылки"
+// @Param from query string false "Начало периода, RFC 3339 или дата (по умолчанию 30 дней до конца)"
+// @Param to query string false "Конец периода, RFC 3339 или дата включительно (по умолчанию сейчас)"
+// @Param tz query string false "Часовой пояс IANA (по умолчанию UTC)"
+// @Param granularity query string false "Интервал ряда" Enums(hour, day, week, month)
+// @Param include_bots query bool false "Учитывать клики ботов (по умолчанию false)"
+// @Param dimensions query string false "Разбивки через запятую: user_agent, device, os, referrer, country (по умолчанию все доступные)"
 // @Success 200 {object} dto.GetClicks "Статистика кликов"
-// @Failure 400 {object} response.Response "alias must not be empty"
+// @Failure 400 {object} response.Response "invalid query"
+// @Failure 401 {object} response.Response "invalid credentials"
+// @Failure 403 {object} response.Response "insufficient scope"
+// @Failure 404 {object} response.Response "alias not found"
 // @Failure 500 {object} response.Response "internal server error"
 // @Router /analytics/{alias} [get]
 func (h *ClickHandler) GetAnalytics(c *ginext.Context) {
@@ -40,8 +104,28 @@ func (h *ClickHandler) GetAnalytics(c *ginext.Context) {
 		return
 	}
 
-	summary, err := h.click.GetClicksSummary(c.Request.Context(), alias)
+	access := auth.FromContext(c.Request.Context()).Access()
+	query := dto.ClicksQuery{
+		From:        c.Query("from"),
+		To:          c.Query("to"),
+		Timezone:    c.Query("tz"),
+		Granularity: c.Query("granularity"),
+		Dimensions:  c.Query("dimensions"),
+		IncludeBots: c.Query("include_bots"),
+	}
+
+	summary, err := h.click.GetClicksSummary(c.Request.Context(), access, c.Query("domain"), alias, query)
 	if err != nil {
+		if errors.Is(err, service.ErrAliasNotFound) {
+			response.Error("alias not found").WriteJSON(c, http.StatusNotFound)
+			return
+		}
+		for _, queryErr := range queryErrors {
+			if errors.Is(err, queryErr) {
+				response.Error(queryErr.Error()).WriteJSON(c, http.StatusBadRequest)
+				return
+			}
+		}
 		zlog.Logger.Error().Err(err).Str("alias", alias).Msg("failed to get click summary")
 		response.Error("internal server error, try again later").WriteJSON(c, http.StatusInternalServerError)
 		return
@@ -49,3 +133,212 @@ func (h *ClickHandler) GetAnalytics(c *ginext.Context) {
 
 	response.Raw(c, http.StatusOK, summary)
 }
+
+// Export godoc
+// @Summary Выгрузить клики по ссылке
+// @Description Выгружает клики по alias за период файлом CSV или NDJSON. Таблица clicks содержит сами клики без IP, series — временной ряд, а user_agent, device, os, referrer и country — разбивки по ним. Файл передаётся по мере чтения, клики читаются курсором. Клики ботов по умолчанию не выгружаются
+// @Tags Analytics
+// @Produce text/csv
+// @Produce application/x-ndjson
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Param alias path string true "Alias ссылки"
+// @Param domain query string false "Собственный домен ссылки"
+// @Param format query string false "Формат файла (по умолчанию csv)" Enums(csv, ndjson)
+// @Param table query string false "Выгружаемая таблица (по умолчанию clicks)" Enums(clicks, series, user_agent, device, os, referrer, country)
+// @Param from query string false "Начало периода, RFC 3339 или дата (по умолчанию 30 дней до конца)"
+// @Param to query string false "Конец периода, RFC 3339 или дата включительно (по умолчанию сейчас)"
+// @Param tz query string false "Часовой пояс IANA для времени в файле (по умолчанию UTC)"
+// @Param granularity query string false "Интервал ряда series" Enums(hour, day, week, month)
+// @Param include_bots query bool false "Выгружать клики ботов (по умолчанию false)"
+// @Success 200 {file} file "Файл выгрузки"
+// @Failure 400 {object} response.Response "invalid query"
+// @Failure 401 {object} response.Response "invalid credentials"
+// @Failure 403 {object} response.Response "insufficient scope"
+// @Failure 404 {object} response.Response "alias not found"
+// @Failure 500 {object} response.Response "internal server error"
+// @Router /analytics/{alias}/export [get]
+func (h *ClickHandler) Export(c *ginext.Context) {
+	alias := c.Param("alias")
+	if alias == "" {
+		response.Error("alias must not be empty.").WriteJSON(c, http.StatusBadRequest)
+		return
+	}
+
+	format := c.DefaultQuery("format", FormatCSV)
+	if format != FormatCSV && format != FormatNDJSON {
+		response.Error(errInvalidExportFormat.Error()).WriteJSON(c, http.StatusBadRequest)
+		return
+	}
+
+	access := auth.FromContext(c.Request.Context()).Access()
+	query := dto.ExportQuery{
+		Table:       c.DefaultQuery("table", service.ExportClicks),
+		From:        c.Query("from"),
+		To:          c.Query("to"),
+		Timezone:    c.Query("tz"),
+		Granularity: c.Query("granularity"),
+		IncludeBots: c.Query("include_bots"),
+	}
+
+	w := newExportWriter(c, format, alias+"-"+query.Table, h.stream.WriteTimeout)
+	err := h.click.Export(c.Request.Context(), access, c.Query("domain"), alias, query, w)
+	if err == nil {
+		err = w.Flush()
+	}
+	if err != nil {
+		// Once rows are sent, the status is too, so the export just ends
+		// short. Until then the rows are only buffered and are dropped.
+		if c.Writer.Written() {
+			zlog.Logger.Error().Err(err).Str("alias", alias).Msg("failed to export clicks")
+			return
+		}
+		clearExportHeaders(c)
+		if errors.Is(err, service.ErrAliasNotFound) {
+			response.Error("alias not found").WriteJSON(c, http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, service.ErrInvalidExportTable) {
+			response.Error(service.ErrInvalidExportTable.Error()).WriteJSON(c, http.StatusBadRequest)
+			return
+		}
+		for _, queryErr := range queryErrors {
+			if errors.Is(err, queryErr) {
+				response.Error(queryErr.Error()).WriteJSON(c, http.StatusBadRequest)
+				return
+			}
+		}
+		zlog.Logger.Error().Err(err).Str("alias", alias).Msg("failed to export clicks")
+		response.Error("internal server error, try again later").WriteJSON(c, http.StatusInternalServerError)
+		return
+	}
+}
+
+// StreamClicks godoc
+// @Summary Поток кликов в реальном времени
+// @Description Отправляет клики по alias по мере их появления как Server-Sent Events. Событие click содержит время, устройство, браузер и страну, если она известна. Событие dropped сообщает число кликов, пропущенных медленным клиентом. Поток закрывается событием timeout, если кликов не было дольше таймаута простоя. Клики ботов по умолчанию не отправляются
+// @Tags Analytics
+// @Produce text/event-stream
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Param alias path string true "Alias ссылки"
+// @Param domain query string false "Собственный домен ссылки"
+// @Param include_bots query bool false "Отправлять клики ботов (по умолчанию false)"
+// @Success 200 {object} dto.LiveClick "Поток событий click"
+// @Failure 400 {object} response.Response "invalid query"
+// @Failure 401 {object} response.Response "invalid credentials"
+// @Failure 403 {object} response.Response "insufficient scope"
+// @Failure 404 {object} response.Response "alias not found"
+// @Failure 500 {object} response.Response "internal server error"
+// @Router /analytics/{alias}/stream [get]
+func (h *ClickHandler) StreamClicks(c *ginext.Context) {
+	alias := c.Param("alias")
+	if alias == "" {
+		response.Error("alias must not be empty.").WriteJSON(c, http.StatusBadRequest)
+		return
+	}
+
+	ctx := c.Request.Context()
+	access := auth.FromContext(ctx).Access()
+
+	stream, err := h.live.StreamClicks(ctx, access, c.Query("domain"), alias, c.Query("include_bots"))
+	if err != nil {
+		if errors.Is(err, service.ErrAliasNotFound) {
+			response.Error("alias not found").WriteJSON(c, http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, service.ErrInvalidIncludeBots) {
+			response.Error(service.ErrInvalidIncludeBots.Error()).WriteJSON(c, http.StatusBadRequest)
+			return
+		}
+		zlog.Logger.Error().Err(err).Str("alias", alias).Msg("failed to stream clicks")
+		response.Error("internal server error, try again later").WriteJSON(c, http.StatusInternalServerError)
+		return
+	}
+	defer stream.Close()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	w := newEventWriter(c, h.stream.WriteTimeout)
+	if err = w.comment("stream"); err != nil {
+		return
+	}
+
+	heartbeat := time.NewTicker(h.stream.Heartbeat)
+	defer heartbeat.Stop()
+	idle := time.NewTimer(h.stream.IdleTimeout)
+	defer idle.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-h.closed:
+			return
+		case <-idle.C:
+			_ = w.event("timeout", struct{}{})
+			return
+		case <-heartbeat.C:
+			err = w.comment("ping")
+		case click := <-stream.Clicks():
+			if dropped := stream.Dropped(); dropped > 0 {
+				if err = w.event("dropped", dto.DroppedClicks{Dropped: dropped}); err != nil {
+					return
+				}
+			}
+			err = w.event("click", mapToLiveClick(click))
+			idle.Reset(h.stream.IdleTimeout)
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// eventWriter writes Server-Sent Events, each flushed within a deadline.
+type eventWriter struct {
+	w       http.ResponseWriter
+	rc      *http.ResponseController
+	timeout time.Duration
+}
+
+func newEventWriter(c *ginext.Context, timeout time.Duration) *eventWriter {
+	return &eventWriter{w: c.Writer, rc: http.NewResponseController(c.Writer), timeout: timeout}
+}
+
+func (e *eventWriter) event(name string, data any) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	return e.write("event: %s\ndata: %s\n\n", name, payload)
+}
+
+func (e *eventWriter) comment(text string) error {
+	return e.write(": %s\n\n", text)
+}
+
+func (e *eventWriter) write(format string, args ...any) error {
+	// A client that does not read holds the write, the deadline ends it.
+	if err := e.rc.SetWriteDeadline(time.Now().Add(e.timeout)); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		return err
+	}
+	if _, err := fmt.Fprintf(e.w, format, args...); err != nil {
+		return err
+	}
+	return e.rc.Flush()
+}
+
+func mapToLiveClick(click domain.LiveClick) dto.LiveClick {
+	return dto.LiveClick{
+		Time:    click.ClickedAt.UTC().Format(time.RFC3339Nano),
+		Device:  click.Device,
+		Client:  click.Client,
+		Country: click.Country,
+		Bot:     click.IsBot,
+	}
+}
@@ -1,19 +1,29 @@
 package rest_test
 
 import (
+	"bufio"
+	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"github.com/google/uuid"
 	"github.com/wb-go/wbf/ginext"
 	"go.uber.org/mock/gomock"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/require"
 
+	"github.com/ilam072/shortener/internal/auth"
+	"github.com/ilam072/shortener/internal/click/feed"
 	"github.com/ilam072/shortener/internal/click/mocks"
 	"github.com/ilam072/shortener/internal/click/rest"
+	"github.com/ilam072/shortener/internal/click/service"
+	"github.com/ilam072/shortener/internal/click/types/domain"
 	"github.com/ilam072/shortener/internal/click/types/dto"
 )
 
@@ -21,6 +31,13 @@ func init() {
 	gin.SetMode(gin.TestMode)
 }
 
+// testStream bounds live streams in tests.
+var testStream = rest.StreamConfig{
+	Heartbeat:    time.Hour,
+	IdleTimeout:  time.Hour,
+	WriteTimeout: time.Second,
+}
+
 func newTestContext(method, path string) (*ginext.Context, *httptest.ResponseRecorder) {
 	w := httptest.NewRecorder()
 	c, _ := gin.CreateTestContext(w)
@@ -43,6 +60,7 @@ func TestClickHandler_GetAnalytics(t *testing.T) {
 	tests := []struct {
 		name   string
 		alias  string
+		query  string
 		fields fields
 		want   want
 	}{
@@ -53,13 +71,61 @@ func TestClickHandler_GetAnalytics(t *testing.T) {
 				status: http.StatusBadRequest,
 			},
 		},
+		{
+			name:  "alias not found",
+			alias: "abc",
+			fields: fields{
+				setup: func(click *mocks.MockClick) {
+					click.EXPECT().
+						GetClicksSummary(gomock.Any(), gomock.Any(), "", "abc", dto.ClicksQuery{}).
+						Return(dto.GetClicks{}, service.ErrAliasNotFound)
+				},
+			},
+			want: want{
+				status: http.StatusNotFound,
+			},
+		},
+		{
+			name:  "query parameters",
+			alias: "abc",
+			query: "?from=2025-01-01&to=2025-01-31&tz=Europe/Berlin&granularity=week",
+			fields: fields{
+				setup: func(click *mocks.MockClick) {
+					click.EXPECT().
+						GetClicksSummary(gomock.Any(), gomock.Any(), "", "abc", dto.ClicksQuery{
+							From:        "2025-01-01",
+							To:          "2025-01-31",
+							Timezone:    "Europe/Berlin",
+							Granularity: "week",
+						}).
+						Return(dto.GetClicks{Alias: "abc"}, nil)
+				},
+			},
+			want: want{
+				status: http.StatusOK,
+			},
+		},
+		{
+			name:  "invalid query",
+			alias: "abc",
+			fields: fields{
+				setup: func(click *mocks.MockClick) {
+					click.EXPECT().
+						GetClicksSummary(gomock.Any(), gomock.Any(), "", "abc", dto.ClicksQuery{}).
+						Return(dto.GetClicks{}, fmt.Errorf("service: %w", service.ErrInvalidGranularity))
+				},
+			},
+			want: want{
+				status: http.StatusBadRequest,
+			},
+		},
 		{
 			name:  "service error",
 			alias: "abc",
 			fields: fields{
 				setup: func(click *mocks.MockClick) {
 					click.EXPECT().
-						GetClicksSummary(gomock.Any(), "abc").
+						GetClicksSummary(gomock.Any(), gomock.Any(), "", "abc", dto.ClicksQuery{}).
 						Return(dto.GetClicks{}, errors.New("db error"))
 				},
 			},
@@ -73,11 +139,11 @@ func TestClickHandler_GetAnalytics(t *testing.T) {
 			fields: fields{
 				setup: func(click *mocks.MockClick) {
 					click.EXPECT().
-						GetClicksSummary(gomock.Any(), "abc").
+						GetClicksSummary(gomock.Any(), gomock.Any(), "", "abc", dto.ClicksQuery{}).
 						Return(dto.GetClicks{
 							Alias: "abc",
-							ByDay: []dto.ClicksByDay{
-								{Date: "2025-01-01", Clicks: 10},
+							Series: []dto.ClicksAt{
+								{Time: "2025-01-01T00:00:00Z", Clicks: 10},
 							},
 						}, nil)
 				},
@@ -89,8 +155,8 @@ func TestClickHandler_GetAnalytics(t *testing.T) {
 					err := json.Unmarshal(body, &res)
 					require.NoError(t, err)
 					require.Equal(t, "abc", res.Alias)
-					require.Len(t, res.ByDay, 1)
-					require.Equal(t, 10, res.ByDay[0].Clicks)
+					require.Len(t, res.Series, 1)
+					require.Equal(t, 10, res.Series[0].Clicks)
 				},
 			},
 		},
@@ -107,9 +173,9 @@ func TestClickHandler_GetAnalytics(t *testing.T) {
 				tt.fields.setup(mockClick)
 			}
 
-			handler := rest.NewClickHandler(mockClick)
+			handler := rest.NewClickHandler(mockClick, mocks.NewMockLive(ctrl), testStream)
 
-			c, w := newTestContext(http.MethodGet, "/analytics/"+tt.alias)
+			c, w := newTestContext(http.MethodGet, "/analytics/"+tt.alias+tt.query)
 			c.Params = gin.Params{{Key: "alias", Value: tt.alias}}
 
 			handler.GetAnalytics(c)
@@ -122,3 +188,419 @@ func TestClickHandler_GetAnalytics(t *testing.T) {
 		})
 	}
 }
+
+func TestClickHandler_StreamClicks_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		alias  string
+		query  string
+		setup  func(live *mocks.MockLive)
+		status int
+	}{
+		{
+			name:   "empty alias",
+			alias:  "",
+			status: http.StatusBadRequest,
+		},
+		{
+			name:  "alias not found",
+			alias: "abc",
+			setup: func(live *mocks.MockLive) {
+				live.EXPECT().
+					StreamClicks(gomock.Any(), gomock.Any(), "", "abc", "").
+					Return(nil, service.ErrAliasNotFound)
+			},
+			status: http.StatusNotFound,
+		},
+		{
+			name:  "invalid include_bots",
+			alias: "abc",
+			query: "?include_bots=maybe",
+			setup: func(live *mocks.MockLive) {
+				live.EXPECT().
+					StreamClicks(gomock.Any(), gomock.Any(), "", "abc", "maybe").
+					Return(nil, service.ErrInvalidIncludeBots)
+			},
+			status: http.StatusBadRequest,
+		},
+		{
+			name:  "internal error",
+			alias: "abc",
+			setup: func(live *mocks.MockLive) {
+				live.EXPECT().
+					StreamClicks(gomock.Any(), gomock.Any(), "", "abc", "").
+					Return(nil, errors.New("redis error"))
+			},
+			status: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockLive := mocks.NewMockLive(ctrl)
+			if tt.setup != nil {
+				tt.setup(mockLive)
+			}
+
+			handler := rest.NewClickHandler(mocks.NewMockClick(ctrl), mockLive, testStream)
+
+			c, w := newTestContext(http.MethodGet, "/analytics/"+tt.alias+"/stream"+tt.query)
+			c.Params = gin.Params{{Key: "alias", Value: tt.alias}}
+
+			handler.StreamClicks(c)
+
+			require.Equal(t, tt.status, w.Code)
+		})
+	}
+}
+
+// openStream serves the live stream of alias "abc" of a link with handler
+// and returns its event lines.
+func openStream(t *testing.T, handler *rest.ClickHandler, query string) *bufio.Scanner {
+	engine := gin.New()
+	engine.GET("/analytics/:alias/stream", handler.StreamClicks)
+	server := httptest.NewServer(engine)
+	t.Cleanup(server.Close)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/analytics/abc/stream"+query, nil)
+	require.NoError(t, err)
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = resp.Body.Close() })
+
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	lines := bufio.NewScanner(resp.Body)
+	require.True(t, lines.Scan())
+	require.Equal(t, ": stream", lines.Text())
+	return lines
+}
+
+// nextEvent reads the next event of lines, skipping comments.
+func nextEvent(t *testing.T, lines *bufio.Scanner) (string, string) {
+	var name, data string
+	for lines.Scan() {
+		line := lines.Text()
+		switch {
+		case line == "" && name != "":
+			return name, data
+		case strings.HasPrefix(line, "event: "):
+			name = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			data = strings.TrimPrefix(line, "data: ")
+		}
+	}
+	t.Fatalf("stream ended: %v", lines.Err())
+	return "", ""
+}
+
+func TestClickHandler_StreamClicks(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	linkID := uuid.New()
+	mockRepo := mocks.NewMockClickRepo(ctrl)
+	mockRepo.EXPECT().
+		GetLink(gomock.Any(), gomock.Any(), "", "abc").
+		Return(domain.Link{ID: linkID}, nil)
+
+	clickFeed := feed.NewMemory()
+	handler := rest.NewClickHandler(mocks.NewMockClick(ctrl), service.NewLive(mockRepo, clickFeed, 4), testStream)
+	lines := openStream(t, handler, "")
+
+	clickedAt := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	clickFeed.Publish(domain.LiveClick{LinkID: linkID, ClickedAt: clickedAt, Device: "bot", Client: "Googlebot", IsBot: true})
+	clickFeed.Publish(domain.LiveClick{LinkID: uuid.New(), ClickedAt: clickedAt, Device: "desktop", Client: "Chrome"})
+	clickFeed.Publish(domain.LiveClick{LinkID: linkID, ClickedAt: clickedAt, Device: "mobile", Client: "Safari", Country: "DE"})
+
+	name, data := nextEvent(t, lines)
+	require.Equal(t, "click", name)
+
+	var click dto.LiveClick
+	require.NoError(t, json.Unmarshal([]byte(data), &click))
+	require.Equal(t, dto.LiveClick{Time: "2025-03-01T12:00:00Z", Device: "mobile", Client: "Safari", Country: "DE"}, click)
+}
+
+func TestClickHandler_StreamClicks_IdleTimeout(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockClickRepo(ctrl)
+	mockRepo.EXPECT().
+		GetLink(gomock.Any(), gomock.Any(), "", "abc").
+		Return(domain.Link{ID: uuid.New()}, nil)
+
+	stream := testStream
+	stream.IdleTimeout = 50 * time.Millisecond
+	handler := rest.NewClickHandler(mocks.NewMockClick(ctrl), service.NewLive(mockRepo, feed.NewMemory(), 4), stream)
+	lines := openStream(t, handler, "")
+
+	name, _ := nextEvent(t, lines)
+	require.Equal(t, "timeout", name)
+	require.False(t, lines.Scan())
+}
+
+func TestClickHandler_Export(t *testing.T) {
+	// writeTable exports a header and two rows.
+	writeTable := func(_ context.Context, _ auth.Access, _, _ string, _ dto.ExportQuery, w service.ExportWriter) error {
+		if err := w.Header([]string{"device", "clicks", "bot"}); err != nil {
+			return err
+		}
+		if err := w.Row([]any{"mobile", 5, false}); err != nil {
+			return err
+		}
+		return w.Row([]any{`say "hi", bot`, int64(2), true})
+	}
+
+	tests := []struct {
+		name        string
+		alias       string
+		query       string
+		setup       func(click *mocks.MockClick)
+		status      int
+		contentType string
+		filename    string
+		body        string
+	}{
+		{
+			name:  "csv by default",
+			alias: "abc",
+			query: "?table=device&from=2025-01-01",
+			setup: func(click *mocks.MockClick) {
+				click.EXPECT().
+					Export(gomock.Any(), gomock.Any(), "", "abc", dto.ExportQuery{Table: "device", From: "2025-01-01"}, gomock.Any()).
+					DoAndReturn(writeTable)
+			},
+			status:      http.StatusOK,
+			contentType: "text/csv; charset=utf-8",
+			filename:    "abc-device.csv",
+			body:        "device,clicks,bot\nmobile,5,false\n\"say \"\"hi\"\", bot\",2,true\n",
+		},
+		{
+			name:  "csv escapes formulas",
+			alias: "abc",
+			query: "?table=user_agent",
+			setup: func(click *mocks.MockClick) {
+				click.EXPECT().
+					Export(gomock.Any(), gomock.Any(), "", "abc", dto.ExportQuery{Table: "user_agent"}, gomock.Any()).
+					DoAndReturn(func(_ context.Context, _ auth.Access, _, _ string, _ dto.ExportQuery, w service.ExportWriter) error {
+						if err := w.Header([]string{"user_agent", "referrer", "city", "clicks"}); err != nil {
+							return err
+						}
+						if err := w.Row([]any{`=HYPERLINK("http://evil")`, "+1", "@SUM(A1)", 3}); err != nil {
+							return err
+						}
+						return w.Row([]any{"-2", "\tx", "Berlin", 4})
+					})
+			},
+			status:      http.StatusOK,
+			contentType: "text/csv; charset=utf-8",
+			filename:    "abc-user_agent.csv",
+			body:        "user_agent,referrer,city,clicks\n\"'=HYPERLINK(\"\"http://evil\"\")\",'+1,'@SUM(A1),3\n'-2,'\tx,Berlin,4\n",
+		},
+		{
+			name:  "ndjson",
+			alias: "abc",
+			query: "?format=ndjson",
+			setup: func(click *mocks.MockClick) {
+				click.EXPECT().
+					Export(gomock.Any(), gomock.Any(), "", "abc", dto.ExportQuery{Table: service.ExportClicks}, gomock.Any()).
+					DoAndReturn(writeTable)
+			},
+			status:      http.StatusOK,
+			contentType: "application/x-ndjson",
+			filename:    "abc-clicks.ndjson",
+			body:        "{\"device\":\"mobile\",\"clicks\":5,\"bot\":false}\n{\"device\":\"say \\\"hi\\\", bot\",\"clicks\":2,\"bot\":true}\n",
+		},
+		{
+			name:   "empty alias",
+			alias:  "",
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "invalid format",
+			alias:  "abc",
+			query:  "?format=xml",
+			status: http.StatusBadRequest,
+		},
+		{
+			name:  "invalid table",
+			alias: "abc",
+			query: "?table=ip",
+			setup: func(click *mocks.MockClick) {
+				click.EXPECT().
+					Export(gomock.Any(), gomock.Any(), "", "abc", gomock.Any(), gomock.Any()).
+					Return(service.ErrInvalidExportTable)
+			},
+			status: http.StatusBadRequest,
+		},
+		{
+			name:  "invalid range",
+			alias: "abc",
+			query: "?from=yesterday",
+			setup: func(click *mocks.MockClick) {
+				click.EXPECT().
+					Export(gomock.Any(), gomock.Any(), "", "abc", gomock.Any(), gomock.Any()).
+					Return(service.ErrInvalidRange)
+			},
+			status: http.StatusBadRequest,
+		},
+		{
+			name:  "alias not found",
+			alias: "abc",
+			setup: func(click *mocks.MockClick) {
+				click.EXPECT().
+					Export(gomock.Any(), gomock.Any(), "", "abc", gomock.Any(), gomock.Any()).
+					Return(service.ErrAliasNotFound)
+			},
+			status: http.StatusNotFound,
+		},
+		{
+			name:  "error before any row is sent",
+			alias: "abc",
+			setup: func(click *mocks.MockClick) {
+				click.EXPECT().
+					Export(gomock.Any(), gomock.Any(), "", "abc", gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, _ auth.Access, _, _ string, _ dto.ExportQuery, w service.ExportWriter) error {
+						require.NoError(t, w.Header([]string{"id"}))
+						return errors.New("db error")
+					})
+			},
+			status:      http.StatusInternalServerError,
+			contentType: "application/json; charset=utf-8",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockClick := mocks.NewMockClick(ctrl)
+			if tt.setup != nil {
+				tt.setup(mockClick)
+			}
+
+			handler := rest.NewClickHandler(mockClick, mocks.NewMockLive(ctrl), testStream)
+
+			c, w := newTestContext(http.MethodGet, "/analytics/"+tt.alias+"/export"+tt.query)
+			c.Params = gin.Params{{Key: "alias", Value: tt.alias}}
+
+			handler.Export(c)
+
+			require.Equal(t, tt.status, w.Code)
+			if tt.contentType != "" {
+				require.Equal(t, tt.contentType, w.Header().Get("Content-Type"))
+			}
+			if tt.filename != "" {
+				require.Equal(t, `attachment; filename=`+tt.filename, w.Header().Get("Content-Disposition"))
+			} else {
+				require.Empty(t, w.Header().Get("Content-Disposition"))
+			}
+			if tt.body != "" {
+				require.Equal(t, tt.body, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestClickHandler_Dashboard(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    string
+		handler func(h *rest.ClickHandler) func(*ginext.Context)
+		setup   func(click *mocks.MockClick)
+		status  int
+	}{
+		{
+			name:    "top links",
+			path:    "/dashboard/top-links?limit=5&include_bots=true",
+			handler: func(h *rest.ClickHandler) func(*ginext.Context) { return h.GetTopLinks },
+			setup: func(click *mocks.MockClick) {
+				click.EXPECT().
+					GetTopLinks(gomock.Any(), gomock.Any(), dto.DashboardQuery{IncludeBots: "true", Limit: "5"}).
+					Return(dto.GetTopLinks{Links: []dto.TopLink{{Alias: "abc", Clicks: 3}}}, nil)
+			},
+			status: http.StatusOK,
+		},
+		{
+			name:    "top links, invalid limit",
+			path:    "/dashboard/top-links?limit=0",
+			handler: func(h *rest.ClickHandler) func(*ginext.Context) { return h.GetTopLinks },
+			setup: func(click *mocks.MockClick) {
+				click.EXPECT().
+					GetTopLinks(gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(dto.GetTopLinks{}, service.ErrInvalidLimit)
+			},
+			status: http.StatusBadRequest,
+		},
+		{
+			name:    "workspace clicks",
+			path:    "/dashboard/clicks?granularity=hour&tz=UTC",
+			handler: func(h *rest.ClickHandler) func(*ginext.Context) { return h.GetWorkspaceClicks },
+			setup: func(click *mocks.MockClick) {
+				click.EXPECT().
+					GetWorkspaceClicks(gomock.Any(), gomock.Any(), dto.DashboardQuery{Granularity: "hour", Timezone: "UTC"}).
+					Return(dto.GetWorkspaceClicks{Clicks: 3}, nil)
+			},
+			status: http.StatusOK,
+		},
+		{
+			name:    "workspace clicks, internal error",
+			path:    "/dashboard/clicks",
+			handler: func(h *rest.ClickHandler) func(*ginext.Context) { return h.GetWorkspaceClicks },
+			setup: func(click *mocks.MockClick) {
+				click.EXPECT().
+					GetWorkspaceClicks(gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(dto.GetWorkspaceClicks{}, errors.New("db error"))
+			},
+			status: http.StatusInternalServerError,
+		},
+		{
+			name:    "created links, invalid timezone",
+			path:    "/dashboard/links?tz=Mars/Olympus",
+			handler: func(h *rest.ClickHandler) func(*ginext.Context) { return h.GetCreatedLinks },
+			setup: func(click *mocks.MockClick) {
+				click.EXPECT().
+					GetCreatedLinks(gomock.Any(), gomock.Any(), dto.DashboardQuery{Timezone: "Mars/Olympus"}).
+					Return(dto.GetCreatedLinks{}, service.ErrInvalidTimezone)
+			},
+			status: http.StatusBadRequest,
+		},
+		{
+			name:    "link domains",
+			path:    "/dashboard/domains",
+			handler: func(h *rest.ClickHandler) func(*ginext.Context) { return h.GetLinkDomains },
+			setup: func(click *mocks.MockClick) {
+				click.EXPECT().
+					GetLinkDomains(gomock.Any(), gomock.Any(), dto.DashboardQuery{}).
+					Return(dto.GetLinkDomains{Links: 1, Domains: []dto.LinksByDomain{{Domain: "example.com", Links: 1}}}, nil)
+			},
+			status: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockClick := mocks.NewMockClick(ctrl)
+			tt.setup(mockClick)
+
+			handler := rest.NewClickHandler(mockClick, mocks.NewMockLive(ctrl), testStream)
+
+			c, w := newTestContext(http.MethodGet, tt.path)
+			tt.handler(handler)(c)
+
+			require.Equal(t, tt.status, w.Code)
+		})
+	}
+}
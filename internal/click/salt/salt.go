@@ -0,0 +1,76 @@
+package salt
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"github.com/ilam072/shortener/pkg/errutils"
+	"github.com/wb-go/wbf/redis"
+	"sync"
+	"time"
+)
+
+// ttl keeps a salt past its day, for clicks hashed around midnight, but
+// not much longer: once it is gone, visitor ids cannot be linked to ips.
+const ttl = 48 * time.Hour
+
+// retryAfter spaces out attempts while Redis is unavailable, so clicks do
+// not queue up behind it.
+const retryAfter = 10 * time.Second
+
+var errUnavailable = errors.New("salt is unavailable")
+
+// Salts hands out a random salt per day, shared by all instances through
+// Redis. The salt of the current day is kept in memory.
+type Salts struct {
+	client *redis.Client
+
+	mu       sync.Mutex
+	day      string
+	salt     string
+	failedAt time.Time
+}
+
+func New(client *redis.Client) *Salts {
+	return &Salts{client: client}
+}
+
+// Salt returns the salt of day, creating it if no instance has yet.
+func (s *Salts) Salt(ctx context.Context, day string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.day == day {
+		return s.salt, nil
+	}
+	if time.Since(s.failedAt) < retryAfter {
+		return "", errUnavailable
+	}
+
+	salt, err := s.load(ctx, day)
+	if err != nil {
+		s.failedAt = time.Now()
+		return "", err
+	}
+
+	s.day, s.salt = day, salt
+	return salt, nil
+}
+
+func (s *Salts) load(ctx context.Context, day string) (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", errutils.Wrap("failed to generate salt", err)
+	}
+
+	key := "clicks:salt:" + day
+	if err := s.client.SetNX(ctx, key, hex.EncodeToString(b), ttl).Err(); err != nil {
+		return "", errutils.Wrap("failed to store salt", err)
+	}
+	salt, err := s.client.Get(ctx, key)
+	if err != nil {
+		return "", errutils.Wrap("failed to get salt", err)
+	}
+	return salt, nil
+}
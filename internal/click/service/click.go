@@ -2,102 +2,297 @@ package service
 
 import (
 	"context"
+	"errors"
 	"github.com/google/uuid"
+	"github.com/ilam072/shortener/internal/auth"
+	"github.com/ilam072/shortener/internal/click/repo"
 	"github.com/ilam072/shortener/internal/click/types/domain"
 	"github.com/ilam072/shortener/internal/click/types/dto"
+	"github.com/ilam072/shortener/internal/eventbus"
+	webhookdomain "github.com/ilam072/shortener/internal/webhook/types/domain"
+	webhookdto "github.com/ilam072/shortener/internal/webhook/types/dto"
 	"github.com/ilam072/shortener/pkg/errutils"
+	"strings"
+	"time"
 )
 
 //go:generate mockgen -source=click.go -destination=../mocks/service_mocks.go -package=mocks
 type ClickRepo interface {
 	CreateClick(ctx context.Context, click domain.Click) error
-	GetClicksByDay(ctx context.Context, alias string) ([]domain.ClickRow, error)
-	GetClicksByMonth(ctx context.Context, alias string) ([]domain.ClickRow, error)
-	GetClicksByUserAgent(ctx context.Context, alias string) ([]domain.ClickRow, error)
+	CreateClicks(ctx context.Context, clicks []domain.Click) error
+	GetClickSeries(ctx context.Context, workspaceID uuid.UUID, linkID uuid.UUID, series domain.Series) ([]domain.ClickBucket, error)
+	GetClickBreakdown(ctx context.Context, workspaceID uuid.UUID, linkID uuid.UUID, dimension string, series domain.Series) ([]domain.ClickRow, error)
+	GetLink(ctx context.Context, access auth.Access, host string, alias string) (domain.Link, error)
+	ExportClicks(ctx context.Context, workspaceID uuid.UUID, linkID uuid.UUID, series domain.Series, each func(domain.Click) error) error
+	GetTopLinks(ctx context.Context, access auth.Access, series domain.Series, limit int) ([]domain.TopLink, error)
+	GetWorkspaceSeries(ctx context.Context, access auth.Access, series domain.Series) ([]domain.ClickBucket, error)
+	GetLinkSeries(ctx context.Context, access auth.Access, series domain.Series) ([]domain.LinkBucket, error)
+	GetLinkDomains(ctx context.Context, access auth.Access, limit int) ([]domain.LinkDomain, int, error)
+	RollUpClicks(ctx context.Context, since time.Time, until time.Time) (int64, error)
+	GetClickPartitions(ctx context.Context) ([]domain.ClickPartition, error)
+	HasRawClicks(ctx context.Context, partition string) (bool, error)
+	CreateClickPartition(ctx context.Context, from time.Time, to time.Time) error
+	DetachClickPartition(ctx context.Context, partition domain.ClickPartition) error
+	DropClickTable(ctx context.Context, name string) error
 }
 
+// ClickOutbox durably buffers clicks the repo could not store, until the
+// replayer writes them.
+type ClickOutbox interface {
+	Append(ctx context.Context, clicks []domain.Click) error
+	Read(ctx context.Context, count int) ([]domain.BufferedClick, error)
+	Remove(ctx context.Context, entryIDs ...string) error
+	DeadLetter(ctx context.Context, click domain.BufferedClick, reason string) error
+}
+
+// VisitorSalt provides the salt visitors are hashed with on a day, given
+// as YYYY-MM-DD in UTC.
+type VisitorSalt interface {
+	Salt(ctx context.Context, day string) (string, error)
+}
+
+// GeoLocator resolves the location of an ip.
+type GeoLocator interface {
+	Locate(ip string) domain.Location
+}
+
+// BotClassifier tells bots by their user agent.
+type BotClassifier interface {
+	IsBot(userAgent string) bool
+}
+
+// ClickFeed fans the clicks of links out to their live streams, across
+// replicas. Publish must not block the redirect, and deliver must not block
+// the feed.
+type ClickFeed interface {
+	Publish(click domain.LiveClick)
+	Subscribe(ctx context.Context, linkID uuid.UUID, deliver func(domain.LiveClick)) (func(), error)
+}
+
+// ClickEvents announces clicks to the webhooks of their workspace. Emit
+// must not block the redirect.
+type ClickEvents interface {
+	Emit(event webhookdto.Event)
+}
+
+var ErrAliasNotFound = errors.New("alias not found")
+
 type Click struct {
-	repo ClickRepo
+	repo      ClickRepo
+	outbox    ClickOutbox
+	enricher  *Enricher
+	feed      ClickFeed
+	events    ClickEvents
+	publisher eventbus.EventPublisher
 }
 
-func New(repo ClickRepo) *Click {
-	return &Click{repo: repo}
+func New(
+	repo ClickRepo,
+	outbox ClickOutbox,
+	enricher *Enricher,
+	feed ClickFeed,
+	events ClickEvents,
+	publisher eventbus.EventPublisher,
+) *Click {
+	return &Click{repo: repo, outbox: outbox, enricher: enricher, feed: feed, events: events, publisher: publisher}
 }
 
+// SaveClick stores a click, or buffers it in the outbox when the repo
+// fails. It only fails when both do.
 func (c *Click) SaveClick(ctx context.Context, click dto.Click) error {
 	const op = "service.click.Save"
 
-	domainClick := domain.Click{
-		ID:        uuid.New(),
-		Alias:     click.Alias,
-		UserAgent: click.UserAgent,
-		Client:    click.Client,
-		Device:    click.Device,
-		IP:        click.IP,
-	}
-
+	domainClick := c.enricher.newClick(ctx, click)
 	if err := c.repo.CreateClick(ctx, domainClick); err != nil {
-		return errutils.Wrap(op, err)
+		if outboxErr := c.outbox.Append(ctx, []domain.Click{domainClick}); outboxErr != nil {
+			return errutils.Wrap(op, errors.Join(err, outboxErr))
+		}
 	}
+	c.feed.Publish(liveClick(domainClick))
+	c.events.Emit(clickEvent(domainClick))
+	c.publisher.Publish(publishedClick(domainClick))
 
 	return nil
 }
 
-func (c *Click) GetClicksSummary(ctx context.Context, alias string) (dto.GetClicks, error) {
+// GetClicksSummary aggregates the clicks of a link visible through access
+// over the range of query, with the breakdowns it asks for. The link is
+// resolved first, so clicks of links outside of access are never scanned.
+func (c *Click) GetClicksSummary(ctx context.Context, access auth.Access, host string, alias string, query dto.ClicksQuery) (dto.GetClicks, error) {
 	const op = "service.click.GetClicksSummary"
 
-	byDay, err := c.repo.GetClicksByDay(ctx, alias)
+	series, loc, err := parseSeries(query, time.Now())
+	if err != nil {
+		return dto.GetClicks{}, errutils.Wrap(op, err)
+	}
+	dimensions, err := parseDimensions(query.Dimensions, c.enricher.geo != nil)
 	if err != nil {
 		return dto.GetClicks{}, errutils.Wrap(op, err)
 	}
 
-	byMonth, err := c.repo.GetClicksByMonth(ctx, alias)
+	link, err := c.repo.GetLink(ctx, access, strings.ToLower(host), alias)
 	if err != nil {
+		if errors.Is(err, repo.ErrAliasNotFound) {
+			return dto.GetClicks{}, errutils.Wrap(op, ErrAliasNotFound)
+		}
 		return dto.GetClicks{}, errutils.Wrap(op, err)
 	}
 
-	byUserAgent, err := c.repo.GetClicksByUserAgent(ctx, alias)
+	buckets, err := c.repo.GetClickSeries(ctx, access.Workspace.ID, link.ID, series)
 	if err != nil {
 		return dto.GetClicks{}, errutils.Wrap(op, err)
 	}
 
-	return dto.GetClicks{
+	summary := dto.GetClicks{
 		Alias:       alias,
-		ByDay:       mapToClicksByDay(byDay),
-		ByMonth:     mapToClicksByMonth(byMonth),
-		ByUserAgent: mapToClicksByUserAgent(byUserAgent),
-	}, nil
+		From:        series.From.In(loc).Format(time.RFC3339),
+		To:          series.To.In(loc).Format(time.RFC3339),
+		Timezone:    series.Timezone,
+		Granularity: series.Granularity,
+		IncludeBots: series.Traffic == domain.TrafficAll,
+		Series:      mapToClicksAt(buckets, loc),
+		Limit:       mapToClickLimit(link),
+	}
+
+	for _, dimension := range dimensions {
+		rows, err := c.repo.GetClickBreakdown(ctx, access.Workspace.ID, link.ID, dimension, series)
+		if err != nil {
+			return dto.GetClicks{}, errutils.Wrap(op, err)
+		}
+
+		switch dimension {
+		case domain.DimensionUserAgent:
+			summary.ByUserAgent = mapToClicksByUserAgent(rows)
+		case domain.DimensionDevice:
+			summary.ByDevice = mapToClicksByDevice(rows)
+		case domain.DimensionOS:
+			summary.ByOS = mapToClicksByOS(rows)
+		case domain.DimensionReferrer:
+			summary.ByReferrer = mapToClicksByReferrer(rows)
+		case domain.DimensionCountry:
+			summary.ByCountry = mapToClicksByCountry(rows)
+		}
+	}
+
+	// Visitors are hashed with their user agent, so the unique clicks of
+	// distinct user agents add up.
+	botSeries := series
+	botSeries.Traffic = domain.TrafficBots
+	botRows, err := c.repo.GetClickBreakdown(ctx, access.Workspace.ID, link.ID, domain.DimensionUserAgent, botSeries)
+	if err != nil {
+		return dto.GetClicks{}, errutils.Wrap(op, err)
+	}
+	summary.Bots.ByUserAgent = mapToClicksByUserAgent(botRows)
+	for _, row := range botRows {
+		summary.Bots.Clicks += row.Clicks
+		summary.Bots.UniqueClicks += row.UniqueClicks
+	}
+
+	return summary, nil
 }
 
-func mapToClicksByDay(rows []domain.ClickRow) []dto.ClicksByDay {
-	result := make([]dto.ClicksByDay, 0, len(rows))
+func mapToClicksByUserAgent(rows []domain.ClickRow) []dto.ClicksByUserAgent {
+	result := make([]dto.ClicksByUserAgent, 0, len(rows))
 	for _, row := range rows {
-		result = append(result, dto.ClicksByDay{
-			Date:   row.Aggregation,
-			Clicks: row.Clicks,
+		result = append(result, dto.ClicksByUserAgent{
+			UserAgent:    row.Aggregation,
+			Clicks:       row.Clicks,
+			UniqueClicks: row.UniqueClicks,
 		})
 	}
 	return result
 }
 
-func mapToClicksByMonth(rows []domain.ClickRow) []dto.ClicksByMonth {
-	result := make([]dto.ClicksByMonth, 0, len(rows))
+func mapToClicksByDevice(rows []domain.ClickRow) []dto.ClicksByDevice {
+	result := make([]dto.ClicksByDevice, 0, len(rows))
 	for _, row := range rows {
-		result = append(result, dto.ClicksByMonth{
-			Month:  row.Aggregation,
-			Clicks: row.Clicks,
+		result = append(result, dto.ClicksByDevice{
+			Device:       row.Aggregation,
+			Clicks:       row.Clicks,
+			UniqueClicks: row.UniqueClicks,
 		})
 	}
 	return result
 }
 
-func mapToClicksByUserAgent(rows []domain.ClickRow) []dto.ClicksByUserAgent {
-	result := make([]dto.ClicksByUserAgent, 0, len(rows))
+func mapToClicksByOS(rows []domain.ClickRow) []dto.ClicksByOS {
+	result := make([]dto.ClicksByOS, 0, len(rows))
 	for _, row := range rows {
-		result = append(result, dto.ClicksByUserAgent{
-			UserAgent: row.Aggregation,
-			Clicks:    row.Clicks,
+		result = append(result, dto.ClicksByOS{
+			OS:           row.Aggregation,
+			Clicks:       row.Clicks,
+			UniqueClicks: row.UniqueClicks,
+		})
+	}
+	return result
+}
+
+func mapToClicksByReferrer(rows []domain.ClickRow) []dto.ClicksByReferrer {
+	result := make([]dto.ClicksByReferrer, 0, len(rows))
+	for _, row := range rows {
+		result = append(result, dto.ClicksByReferrer{
+			Referrer:     row.Aggregation,
+			Clicks:       row.Clicks,
+			UniqueClicks: row.UniqueClicks,
 		})
 	}
 	return result
 }
+
+func mapToClicksByCountry(rows []domain.ClickRow) []dto.ClicksByCountry {
+	result := make([]dto.ClicksByCountry, 0, len(rows))
+	for _, row := range rows {
+		result = append(result, dto.ClicksByCountry{
+			Country:      row.Aggregation,
+			Clicks:       row.Clicks,
+			UniqueClicks: row.UniqueClicks,
+		})
+	}
+	return result
+}
+
+func mapToClickLimit(link domain.Link) *dto.ClickLimit {
+	if link.MaxClicks == nil {
+		return nil
+	}
+	return &dto.ClickLimit{
+		MaxClicks: *link.MaxClicks,
+		Used:      link.Used,
+		Exhausted: link.Used >= *link.MaxClicks,
+	}
+}
+
+func clickEvent(click domain.Click) webhookdto.Event {
+	return webhookdto.Event{
+		Type:        webhookdomain.EventLinkClicked,
+		WorkspaceID: click.WorkspaceID,
+		Link:        webhookdto.EventLink{ID: click.LinkID, Alias: click.Alias},
+		Click: &webhookdto.EventClick{
+			ClickedAt: click.ClickedAt,
+			Device:    click.Device,
+			Client:    click.Client,
+			OS:        click.OS,
+			Referrer:  click.Referrer,
+			Country:   click.Location.Country,
+			Bot:       click.IsBot,
+		},
+		CreatedAt: click.ClickedAt,
+	}
+}
+
+func publishedClick(click domain.Click) eventbus.Event {
+	return eventbus.NewLinkClicked(
+		click.WorkspaceID,
+		eventbus.Link{ID: click.LinkID, Alias: click.Alias},
+		eventbus.Click{
+			Device:    click.Device,
+			Client:    click.Client,
+			OS:        click.OS,
+			Referrer:  click.Referrer,
+			Country:   click.Location.Country,
+			Bot:       click.IsBot,
+			VisitorID: click.VisitorID,
+		},
+		click.ClickedAt,
+	)
+}
@@ -0,0 +1,170 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/ilam072/shortener/internal/click/types/domain"
+	"github.com/ilam072/shortener/pkg/errutils"
+	"github.com/wb-go/wbf/zlog"
+	"time"
+)
+
+const (
+	// ExpiredDrop drops the partitions of clicks past the retention.
+	ExpiredDrop = "drop"
+	// ExpiredDetach detaches them, leaving their tables to be archived.
+	ExpiredDetach = "detach"
+)
+
+// partitionsAhead is the number of months partitions are created ahead of
+// the current one, so that clicks never lack a partition.
+const partitionsAhead = 2
+
+// Compactor rolls up the raw clicks of closed hours, so that analytics
+// only scan the raw clicks of the current hour, and manages the monthly
+// partitions of the raw clicks. Rolled up clicks keep counting by hour,
+// per dimension, without the rows, and so without their ip or user agent.
+type Compactor struct {
+	repo          ClickRepo
+	retentionDays int
+	expired       string
+	interval      time.Duration
+}
+
+// NewCompactor returns a compactor. Raw clicks are kept forever when
+// retentionDays is 0, expired is ExpiredDrop or ExpiredDetach otherwise.
+func NewCompactor(repo ClickRepo, retentionDays int, expired string, interval time.Duration) *Compactor {
+	return &Compactor{repo: repo, retentionDays: retentionDays, expired: expired, interval: interval}
+}
+
+// Run compacts and manages the partitions right away and then every
+// interval until ctx is done.
+func (c *Compactor) Run(ctx context.Context) {
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	for {
+		now := time.Now()
+		if err := c.Compact(ctx, now); err != nil {
+			zlog.Logger.Warn().Err(err).Msg("failed to compact clicks")
+		}
+		if err := c.Partition(ctx, now); err != nil {
+			zlog.Logger.Warn().Err(err).Msg("failed to manage click partitions")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Compact rolls up the clicks of the hours closed at now. Clicks of days
+// past the retention are left raw until their partition expires, since
+// their days could no longer be recomputed once it is gone.
+func (c *Compactor) Compact(ctx context.Context, now time.Time) error {
+	const op = "service.click.Compactor.Compact"
+
+	rolled, err := c.repo.RollUpClicks(ctx, c.cutoff(now), now.UTC().Truncate(time.Hour))
+	if err != nil {
+		return errutils.Wrap(op, err)
+	}
+	if rolled > 0 {
+		zlog.Logger.Debug().Int64("rollups", rolled).Msg("rolled up clicks")
+	}
+
+	return nil
+}
+
+// Partition creates the partitions of the months up to partitionsAhead
+// after now and expires the ones that ended more than retentionDays
+// before now. Raw clicks are thus kept for the retention and up to a
+// month longer. Clicks of an expired partition that were never rolled up,
+// such as the history of the legacy partition or clicks replayed late,
+// are rolled up before it goes, and it is kept while any are left.
+func (c *Compactor) Partition(ctx context.Context, now time.Time) error {
+	const op = "service.click.Compactor.Partition"
+
+	partitions, err := c.repo.GetClickPartitions(ctx)
+	if err != nil {
+		return errutils.Wrap(op, err)
+	}
+
+	// Partitions are only added after the last one, months before it are
+	// covered by the range of an older partition.
+	var end time.Time
+	for _, partition := range partitions {
+		if partition.To.After(end) {
+			end = partition.To
+		}
+	}
+	month := time.Date(now.UTC().Year(), now.UTC().Month(), 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i <= partitionsAhead; i, month = i+1, month.AddDate(0, 1, 0) {
+		if month.Before(end) {
+			continue
+		}
+		if err = c.repo.CreateClickPartition(ctx, month, month.AddDate(0, 1, 0)); err != nil {
+			return errutils.Wrap(op, err)
+		}
+		zlog.Logger.Info().Str("month", month.Format("2006-01")).Msg("created click partition")
+	}
+
+	if c.retentionDays == 0 {
+		return nil
+	}
+	var errs []error
+	cutoff := c.cutoff(now)
+	for _, partition := range partitions {
+		if partition.To.After(cutoff) {
+			continue
+		}
+		if err = c.expire(ctx, partition); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if err = errors.Join(errs...); err != nil {
+		return errutils.Wrap(op, err)
+	}
+
+	return nil
+}
+
+func (c *Compactor) expire(ctx context.Context, partition domain.ClickPartition) error {
+	// A partition left detaching was checked before the detach started.
+	if !partition.DetachPending {
+		if _, err := c.repo.RollUpClicks(ctx, time.Time{}, partition.To); err != nil {
+			return err
+		}
+		raw, err := c.repo.HasRawClicks(ctx, partition.Name)
+		if err != nil {
+			return err
+		}
+		if raw {
+			zlog.Logger.Warn().Str("partition", partition.Name).Msg("expired click partition still has clicks to roll up, keeping it")
+			return nil
+		}
+	}
+
+	if err := c.repo.DetachClickPartition(ctx, partition); err != nil {
+		return err
+	}
+	if c.expired == ExpiredDetach {
+		zlog.Logger.Info().Str("partition", partition.Name).Msg("detached expired click partition")
+		return nil
+	}
+	if err := c.repo.DropClickTable(ctx, partition.Name); err != nil {
+		return err
+	}
+	zlog.Logger.Info().Str("partition", partition.Name).Msg("dropped expired click partition")
+	return nil
+}
+
+// cutoff is the start of the oldest UTC day kept raw, the zero time when
+// raw clicks are kept forever.
+func (c *Compactor) cutoff(now time.Time) time.Time {
+	if c.retentionDays == 0 {
+		return time.Time{}
+	}
+	return now.UTC().Truncate(24*time.Hour).AddDate(0, 0, -c.retentionDays)
+}
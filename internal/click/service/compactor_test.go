@@ -0,0 +1,279 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"go.uber.org/mock/gomock"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ilam072/shortener/internal/click/mocks"
+	"github.com/ilam072/shortener/internal/click/service"
+	"github.com/ilam072/shortener/internal/click/types/domain"
+)
+
+func month(m time.Month) time.Time {
+	return time.Date(2024, m, 1, 0, 0, 0, 0, time.UTC)
+}
+
+func TestCompactor_Compact(t *testing.T) {
+	now := time.Date(2024, 3, 31, 15, 20, 0, 0, time.UTC)
+	hour := time.Date(2024, 3, 31, 15, 0, 0, 0, time.UTC)
+
+	type fields struct {
+		retentionDays int
+		setup         func(repo *mocks.MockClickRepo)
+	}
+	type want struct {
+		err bool
+	}
+
+	tests := []struct {
+		name   string
+		fields fields
+		want   want
+	}{
+		{
+			name: "rolls up every closed hour without retention",
+			fields: fields{
+				setup: func(repo *mocks.MockClickRepo) {
+					repo.EXPECT().RollUpClicks(gomock.Any(), time.Time{}, hour).Return(int64(12), nil)
+				},
+			},
+		},
+		{
+			name: "rolls up closed hours within retention",
+			fields: fields{
+				retentionDays: 30,
+				setup: func(repo *mocks.MockClickRepo) {
+					repo.EXPECT().RollUpClicks(gomock.Any(), month(time.March), hour).Return(int64(12), nil)
+				},
+			},
+		},
+		{
+			name: "repo error",
+			fields: fields{
+				setup: func(repo *mocks.MockClickRepo) {
+					repo.EXPECT().RollUpClicks(gomock.Any(), time.Time{}, hour).Return(int64(0), errors.New("db error"))
+				},
+			},
+			want: want{err: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockRepo := mocks.NewMockClickRepo(ctrl)
+			tt.fields.setup(mockRepo)
+
+			err := service.NewCompactor(mockRepo, tt.fields.retentionDays, service.ExpiredDrop, time.Minute).Compact(context.Background(), now)
+			if tt.want.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestCompactor_Partition(t *testing.T) {
+	now := time.Date(2024, 5, 10, 15, 20, 0, 0, time.UTC)
+	legacy := domain.ClickPartition{Name: "clicks_legacy", To: month(time.March)}
+	march := domain.ClickPartition{Name: "clicks_2024_03", To: month(time.April)}
+	april := domain.ClickPartition{Name: "clicks_2024_04", To: month(time.May)}
+	may := domain.ClickPartition{Name: "clicks_2024_05", To: month(time.June)}
+
+	type fields struct {
+		retentionDays int
+		expired       string
+		setup         func(repo *mocks.MockClickRepo)
+	}
+	type want struct {
+		err bool
+	}
+
+	tests := []struct {
+		name   string
+		fields fields
+		want   want
+	}{
+		{
+			name: "creates the months after the last partition",
+			fields: fields{
+				setup: func(repo *mocks.MockClickRepo) {
+					repo.EXPECT().GetClickPartitions(gomock.Any()).Return([]domain.ClickPartition{legacy, march, april, may}, nil)
+					gomock.InOrder(
+						repo.EXPECT().CreateClickPartition(gomock.Any(), month(time.June), month(time.July)).Return(nil),
+						repo.EXPECT().CreateClickPartition(gomock.Any(), month(time.July), month(time.August)).Return(nil),
+					)
+				},
+			},
+		},
+		{
+			name: "skips months covered by the legacy partition",
+			fields: fields{
+				setup: func(repo *mocks.MockClickRepo) {
+					repo.EXPECT().GetClickPartitions(gomock.Any()).Return([]domain.ClickPartition{
+						{Name: "clicks_legacy", To: month(time.June)},
+						{Name: "clicks_2024_06", To: month(time.July)},
+						{Name: "clicks_2024_07", To: month(time.August)},
+					}, nil)
+				},
+			},
+		},
+		{
+			name: "drops partitions past the retention",
+			fields: fields{
+				retentionDays: 30,
+				expired:       service.ExpiredDrop,
+				setup: func(repo *mocks.MockClickRepo) {
+					repo.EXPECT().GetClickPartitions(gomock.Any()).Return([]domain.ClickPartition{legacy, march, april, may}, nil)
+					repo.EXPECT().CreateClickPartition(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(2)
+					gomock.InOrder(
+						repo.EXPECT().RollUpClicks(gomock.Any(), time.Time{}, legacy.To).Return(int64(0), nil),
+						repo.EXPECT().HasRawClicks(gomock.Any(), legacy.Name).Return(false, nil),
+						repo.EXPECT().DetachClickPartition(gomock.Any(), legacy).Return(nil),
+						repo.EXPECT().DropClickTable(gomock.Any(), legacy.Name).Return(nil),
+						repo.EXPECT().RollUpClicks(gomock.Any(), time.Time{}, march.To).Return(int64(0), nil),
+						repo.EXPECT().HasRawClicks(gomock.Any(), march.Name).Return(false, nil),
+						repo.EXPECT().DetachClickPartition(gomock.Any(), march).Return(nil),
+						repo.EXPECT().DropClickTable(gomock.Any(), march.Name).Return(nil),
+					)
+				},
+			},
+		},
+		{
+			name: "detaches partitions past the retention",
+			fields: fields{
+				retentionDays: 60,
+				expired:       service.ExpiredDetach,
+				setup: func(repo *mocks.MockClickRepo) {
+					repo.EXPECT().GetClickPartitions(gomock.Any()).Return([]domain.ClickPartition{legacy, march, april, may}, nil)
+					repo.EXPECT().CreateClickPartition(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(2)
+					gomock.InOrder(
+						repo.EXPECT().RollUpClicks(gomock.Any(), time.Time{}, legacy.To).Return(int64(0), nil),
+						repo.EXPECT().HasRawClicks(gomock.Any(), legacy.Name).Return(false, nil),
+						repo.EXPECT().DetachClickPartition(gomock.Any(), legacy).Return(nil),
+					)
+				},
+			},
+		},
+		{
+			name: "rolls up expired partitions before dropping them",
+			fields: fields{
+				retentionDays: 60,
+				expired:       service.ExpiredDrop,
+				setup: func(repo *mocks.MockClickRepo) {
+					repo.EXPECT().GetClickPartitions(gomock.Any()).Return([]domain.ClickPartition{legacy, march, april, may}, nil)
+					repo.EXPECT().CreateClickPartition(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(2)
+					gomock.InOrder(
+						repo.EXPECT().RollUpClicks(gomock.Any(), time.Time{}, legacy.To).Return(int64(24), nil),
+						repo.EXPECT().HasRawClicks(gomock.Any(), legacy.Name).Return(false, nil),
+						repo.EXPECT().DetachClickPartition(gomock.Any(), legacy).Return(nil),
+						repo.EXPECT().DropClickTable(gomock.Any(), legacy.Name).Return(nil),
+					)
+				},
+			},
+		},
+		{
+			name: "keeps expired partitions with clicks left to roll up",
+			fields: fields{
+				retentionDays: 60,
+				expired:       service.ExpiredDrop,
+				setup: func(repo *mocks.MockClickRepo) {
+					repo.EXPECT().GetClickPartitions(gomock.Any()).Return([]domain.ClickPartition{legacy, march, april, may}, nil)
+					repo.EXPECT().CreateClickPartition(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(2)
+					gomock.InOrder(
+						repo.EXPECT().RollUpClicks(gomock.Any(), time.Time{}, legacy.To).Return(int64(0), nil),
+						repo.EXPECT().HasRawClicks(gomock.Any(), legacy.Name).Return(true, nil),
+					)
+				},
+			},
+		},
+		{
+			name: "finishes an interrupted detach",
+			fields: fields{
+				retentionDays: 60,
+				expired:       service.ExpiredDetach,
+				setup: func(repo *mocks.MockClickRepo) {
+					pending := domain.ClickPartition{Name: legacy.Name, To: legacy.To, DetachPending: true}
+					repo.EXPECT().GetClickPartitions(gomock.Any()).Return([]domain.ClickPartition{pending, march, april, may}, nil)
+					repo.EXPECT().CreateClickPartition(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(2)
+					repo.EXPECT().DetachClickPartition(gomock.Any(), pending).Return(nil)
+				},
+			},
+		},
+		{
+			name: "roll up error",
+			fields: fields{
+				retentionDays: 60,
+				expired:       service.ExpiredDrop,
+				setup: func(repo *mocks.MockClickRepo) {
+					repo.EXPECT().GetClickPartitions(gomock.Any()).Return([]domain.ClickPartition{legacy, march, april, may}, nil)
+					repo.EXPECT().CreateClickPartition(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(2)
+					repo.EXPECT().RollUpClicks(gomock.Any(), time.Time{}, legacy.To).Return(int64(0), errors.New("db error"))
+				},
+			},
+			want: want{err: true},
+		},
+		{
+			name: "keeps expiring after a failure",
+			fields: fields{
+				retentionDays: 30,
+				expired:       service.ExpiredDrop,
+				setup: func(repo *mocks.MockClickRepo) {
+					repo.EXPECT().GetClickPartitions(gomock.Any()).Return([]domain.ClickPartition{legacy, march, april, may}, nil)
+					repo.EXPECT().CreateClickPartition(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(2)
+					repo.EXPECT().RollUpClicks(gomock.Any(), time.Time{}, gomock.Any()).Return(int64(0), nil).Times(2)
+					repo.EXPECT().HasRawClicks(gomock.Any(), gomock.Any()).Return(false, nil).Times(2)
+					repo.EXPECT().DetachClickPartition(gomock.Any(), legacy).Return(errors.New("db error"))
+					repo.EXPECT().DetachClickPartition(gomock.Any(), march).Return(nil)
+					repo.EXPECT().DropClickTable(gomock.Any(), march.Name).Return(nil)
+				},
+			},
+			want: want{err: true},
+		},
+		{
+			name: "create error",
+			fields: fields{
+				setup: func(repo *mocks.MockClickRepo) {
+					repo.EXPECT().GetClickPartitions(gomock.Any()).Return(nil, nil)
+					repo.EXPECT().CreateClickPartition(gomock.Any(), month(time.May), month(time.June)).Return(errors.New("db error"))
+				},
+			},
+			want: want{err: true},
+		},
+		{
+			name: "list error",
+			fields: fields{
+				setup: func(repo *mocks.MockClickRepo) {
+					repo.EXPECT().GetClickPartitions(gomock.Any()).Return(nil, errors.New("db error"))
+				},
+			},
+			want: want{err: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockRepo := mocks.NewMockClickRepo(ctrl)
+			tt.fields.setup(mockRepo)
+
+			compactor := service.NewCompactor(mockRepo, tt.fields.retentionDays, tt.fields.expired, time.Minute)
+			err := compactor.Partition(context.Background(), now)
+			if tt.want.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,186 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/ilam072/shortener/internal/auth"
+	"github.com/ilam072/shortener/internal/click/types/domain"
+	"github.com/ilam072/shortener/internal/click/types/dto"
+	"github.com/ilam072/shortener/pkg/errutils"
+	"strconv"
+	"time"
+)
+
+const (
+	// defaultLimit is the number of rows of rankings without limit.
+	defaultLimit = 10
+	// maxLimit bounds the number of rows of rankings.
+	maxLimit = 100
+)
+
+var ErrInvalidLimit = errors.New("limit must be between 1 and 100")
+
+// GetTopLinks ranks the links visible through access by their clicks over
+// the range of query.
+func (c *Click) GetTopLinks(ctx context.Context, access auth.Access, query dto.DashboardQuery) (dto.GetTopLinks, error) {
+	const op = "service.click.GetTopLinks"
+
+	series, loc, err := parseSeries(clicksQuery(query), time.Now())
+	if err != nil {
+		return dto.GetTopLinks{}, errutils.Wrap(op, err)
+	}
+	limit, err := parseLimit(query.Limit)
+	if err != nil {
+		return dto.GetTopLinks{}, errutils.Wrap(op, err)
+	}
+
+	links, err := c.repo.GetTopLinks(ctx, access, series, limit)
+	if err != nil {
+		return dto.GetTopLinks{}, errutils.Wrap(op, err)
+	}
+
+	result := dto.GetTopLinks{
+		From:        series.From.In(loc).Format(time.RFC3339),
+		To:          series.To.In(loc).Format(time.RFC3339),
+		Timezone:    series.Timezone,
+		IncludeBots: series.Traffic == domain.TrafficAll,
+		Links:       make([]dto.TopLink, 0, len(links)),
+	}
+	for _, link := range links {
+		result.Links = append(result.Links, dto.TopLink{
+			Alias:        link.Alias,
+			Domain:       link.Domain,
+			URL:          link.URL,
+			Clicks:       link.Clicks,
+			UniqueClicks: link.UniqueClicks,
+		})
+	}
+
+	return result, nil
+}
+
+// GetWorkspaceClicks counts the clicks of all the links visible through
+// access over the range of query.
+func (c *Click) GetWorkspaceClicks(ctx context.Context, access auth.Access, query dto.DashboardQuery) (dto.GetWorkspaceClicks, error) {
+	const op = "service.click.GetWorkspaceClicks"
+
+	series, loc, err := parseSeries(clicksQuery(query), time.Now())
+	if err != nil {
+		return dto.GetWorkspaceClicks{}, errutils.Wrap(op, err)
+	}
+
+	buckets, err := c.repo.GetWorkspaceSeries(ctx, access, series)
+	if err != nil {
+		return dto.GetWorkspaceClicks{}, errutils.Wrap(op, err)
+	}
+
+	result := dto.GetWorkspaceClicks{
+		From:        series.From.In(loc).Format(time.RFC3339),
+		To:          series.To.In(loc).Format(time.RFC3339),
+		Timezone:    series.Timezone,
+		Granularity: series.Granularity,
+		IncludeBots: series.Traffic == domain.TrafficAll,
+		Series:      mapToClicksAt(buckets, loc),
+	}
+	for _, bucket := range buckets {
+		result.Clicks += bucket.Clicks
+		result.UniqueClicks += bucket.UniqueClicks
+	}
+
+	return result, nil
+}
+
+// GetCreatedLinks counts the links visible through access created over the
+// range of query.
+func (c *Click) GetCreatedLinks(ctx context.Context, access auth.Access, query dto.DashboardQuery) (dto.GetCreatedLinks, error) {
+	const op = "service.click.GetCreatedLinks"
+
+	series, loc, err := parseSeries(clicksQuery(query), time.Now())
+	if err != nil {
+		return dto.GetCreatedLinks{}, errutils.Wrap(op, err)
+	}
+
+	buckets, err := c.repo.GetLinkSeries(ctx, access, series)
+	if err != nil {
+		return dto.GetCreatedLinks{}, errutils.Wrap(op, err)
+	}
+
+	result := dto.GetCreatedLinks{
+		From:        series.From.In(loc).Format(time.RFC3339),
+		To:          series.To.In(loc).Format(time.RFC3339),
+		Timezone:    series.Timezone,
+		Granularity: series.Granularity,
+		Series:      mapToLinksAt(buckets, loc),
+	}
+	for _, bucket := range buckets {
+		result.Links += bucket.Links
+	}
+
+	return result, nil
+}
+
+// GetLinkDomains counts the links visible through access by the host they
+// redirect to.
+func (c *Click) GetLinkDomains(ctx context.Context, access auth.Access, query dto.DashboardQuery) (dto.GetLinkDomains, error) {
+	const op = "service.click.GetLinkDomains"
+
+	limit, err := parseLimit(query.Limit)
+	if err != nil {
+		return dto.GetLinkDomains{}, errutils.Wrap(op, err)
+	}
+
+	domains, total, err := c.repo.GetLinkDomains(ctx, access, limit)
+	if err != nil {
+		return dto.GetLinkDomains{}, errutils.Wrap(op, err)
+	}
+
+	result := dto.GetLinkDomains{
+		Links:   total,
+		Domains: make([]dto.LinksByDomain, 0, len(domains)),
+	}
+	for _, linkDomain := range domains {
+		result.Domains = append(result.Domains, dto.LinksByDomain{
+			Domain: linkDomain.Domain,
+			Links:  linkDomain.Links,
+		})
+	}
+
+	return result, nil
+}
+
+// clicksQuery is the part of query that selects a series.
+func clicksQuery(query dto.DashboardQuery) dto.ClicksQuery {
+	return dto.ClicksQuery{
+		From:        query.From,
+		To:          query.To,
+		Timezone:    query.Timezone,
+		Granularity: query.Granularity,
+		IncludeBots: query.IncludeBots,
+	}
+}
+
+// parseLimit parses the number of rows of a ranking, defaultLimit when
+// empty.
+func parseLimit(value string) (int, error) {
+	if value == "" {
+		return defaultLimit, nil
+	}
+	limit, err := strconv.Atoi(value)
+	if err != nil || limit < 1 || limit > maxLimit {
+		return 0, ErrInvalidLimit
+	}
+	return limit, nil
+}
+
+func mapToLinksAt(buckets []domain.LinkBucket, loc *time.Location) []dto.LinksAt {
+	result := make([]dto.LinksAt, 0, len(buckets))
+	for _, bucket := range buckets {
+		start := bucket.Start
+		start = time.Date(start.Year(), start.Month(), start.Day(), start.Hour(), start.Minute(), start.Second(), 0, loc)
+		result = append(result, dto.LinksAt{
+			Time:  start.Format(time.RFC3339),
+			Links: bucket.Links,
+		})
+	}
+	return result
+}
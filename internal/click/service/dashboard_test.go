@@ -0,0 +1,178 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"go.uber.org/mock/gomock"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ilam072/shortener/internal/auth"
+	"github.com/ilam072/shortener/internal/click/mocks"
+	"github.com/ilam072/shortener/internal/click/service"
+	"github.com/ilam072/shortener/internal/click/types/domain"
+	"github.com/ilam072/shortener/internal/click/types/dto"
+	"github.com/ilam072/shortener/internal/eventbus/publisher"
+)
+
+func TestClickService_GetTopLinks(t *testing.T) {
+	access := auth.Access{Workspace: auth.Workspace{ID: uuid.New(), Slug: "acme"}, OwnerID: uuid.NullUUID{UUID: uuid.New(), Valid: true}}
+
+	tests := []struct {
+		name  string
+		query dto.DashboardQuery
+		setup func(repo *mocks.MockClickRepo)
+		links []dto.TopLink
+		err   error
+	}{
+		{
+			name:  "default limit",
+			query: dto.DashboardQuery{From: "2025-01-01", To: "2025-01-31", IncludeBots: "true"},
+			setup: func(repo *mocks.MockClickRepo) {
+				repo.EXPECT().
+					GetTopLinks(gomock.Any(), access, gomock.Any(), 10).
+					DoAndReturn(func(_ context.Context, _ auth.Access, series domain.Series, _ int) ([]domain.TopLink, error) {
+						require.Equal(t, domain.TrafficAll, series.Traffic)
+						require.Equal(t, "2025-02-01T00:00:00Z", series.To.UTC().Format(time.RFC3339))
+						return []domain.TopLink{
+							{Alias: "abc", URL: "https://example.com", Clicks: 10, UniqueClicks: 4},
+							{Alias: "def", Domain: "go.acme.com", URL: "https://acme.com", Clicks: 3, UniqueClicks: 3},
+						}, nil
+					})
+			},
+			links: []dto.TopLink{
+				{Alias: "abc", URL: "https://example.com", Clicks: 10, UniqueClicks: 4},
+				{Alias: "def", Domain: "go.acme.com", URL: "https://acme.com", Clicks: 3, UniqueClicks: 3},
+			},
+		},
+		{
+			name:  "limit",
+			query: dto.DashboardQuery{Limit: "100"},
+			setup: func(repo *mocks.MockClickRepo) {
+				repo.EXPECT().
+					GetTopLinks(gomock.Any(), access, gomock.Any(), 100).
+					Return(nil, nil)
+			},
+			links: []dto.TopLink{},
+		},
+		{
+			name:  "limit too large",
+			query: dto.DashboardQuery{Limit: "101"},
+			err:   service.ErrInvalidLimit,
+		},
+		{
+			name:  "invalid range",
+			query: dto.DashboardQuery{From: "2025-02-01", To: "2025-01-01"},
+			err:   service.ErrInvalidRange,
+		},
+		{
+			name: "repo error",
+			setup: func(repo *mocks.MockClickRepo) {
+				repo.EXPECT().
+					GetTopLinks(gomock.Any(), access, gomock.Any(), 10).
+					Return(nil, errors.New("db error"))
+			},
+			err: errors.New("db error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockRepo := mocks.NewMockClickRepo(ctrl)
+			if tt.setup != nil {
+				tt.setup(mockRepo)
+			}
+
+			click := service.New(mockRepo, mocks.NewMockClickOutbox(ctrl), newEnricher(ctrl), newFeed(ctrl), newEvents(ctrl), publisher.Noop{})
+			res, err := click.GetTopLinks(context.Background(), access, tt.query)
+			if tt.err != nil {
+				require.ErrorContains(t, err, tt.err.Error())
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.links, res.Links)
+		})
+	}
+}
+
+func TestClickService_GetWorkspaceClicks(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	access := auth.Access{Workspace: auth.Workspace{ID: uuid.New(), Slug: "acme"}, All: true}
+	mockRepo := mocks.NewMockClickRepo(ctrl)
+	mockRepo.EXPECT().
+		GetWorkspaceSeries(gomock.Any(), access, gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ auth.Access, series domain.Series) ([]domain.ClickBucket, error) {
+			require.Equal(t, service.GranularityDay, series.Granularity)
+			require.Equal(t, domain.TrafficHumans, series.Traffic)
+			return []domain.ClickBucket{
+				{Start: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), Clicks: 10, UniqueClicks: 4},
+				{Start: time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC), Clicks: 5, UniqueClicks: 5},
+			}, nil
+		})
+
+	click := service.New(mockRepo, mocks.NewMockClickOutbox(ctrl), newEnricher(ctrl), newFeed(ctrl), newEvents(ctrl), publisher.Noop{})
+	res, err := click.GetWorkspaceClicks(context.Background(), access, dto.DashboardQuery{From: "2025-01-01", To: "2025-01-02", Timezone: "Europe/Moscow"})
+	require.NoError(t, err)
+
+	require.Equal(t, 15, res.Clicks)
+	require.Equal(t, 9, res.UniqueClicks)
+	require.Equal(t, []dto.ClicksAt{
+		{Time: "2025-01-01T00:00:00+03:00", Clicks: 10, UniqueClicks: 4},
+		{Time: "2025-01-02T00:00:00+03:00", Clicks: 5, UniqueClicks: 5},
+	}, res.Series)
+}
+
+func TestClickService_GetCreatedLinks(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	access := auth.Access{Workspace: auth.Workspace{ID: uuid.New(), Slug: "acme"}, All: true}
+	mockRepo := mocks.NewMockClickRepo(ctrl)
+	mockRepo.EXPECT().
+		GetLinkSeries(gomock.Any(), access, gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ auth.Access, series domain.Series) ([]domain.LinkBucket, error) {
+			require.Equal(t, service.GranularityWeek, series.Granularity)
+			return []domain.LinkBucket{
+				{Start: time.Date(2024, 12, 30, 0, 0, 0, 0, time.UTC), Links: 2},
+				{Start: time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC), Links: 0},
+			}, nil
+		})
+
+	click := service.New(mockRepo, mocks.NewMockClickOutbox(ctrl), newEnricher(ctrl), newFeed(ctrl), newEvents(ctrl), publisher.Noop{})
+	res, err := click.GetCreatedLinks(context.Background(), access, dto.DashboardQuery{From: "2025-01-01", To: "2025-01-07", Granularity: "week"})
+	require.NoError(t, err)
+
+	require.Equal(t, 2, res.Links)
+	require.Equal(t, []dto.LinksAt{
+		{Time: "2024-12-30T00:00:00Z", Links: 2},
+		{Time: "2025-01-06T00:00:00Z", Links: 0},
+	}, res.Series)
+}
+
+func TestClickService_GetLinkDomains(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	access := auth.Access{Workspace: auth.Workspace{ID: uuid.New(), Slug: "acme"}, All: true}
+	mockRepo := mocks.NewMockClickRepo(ctrl)
+	mockRepo.EXPECT().
+		GetLinkDomains(gomock.Any(), access, 2).
+		Return([]domain.LinkDomain{{Domain: "example.com", Links: 5}, {Domain: "acme.com", Links: 2}}, 8, nil)
+
+	click := service.New(mockRepo, mocks.NewMockClickOutbox(ctrl), newEnricher(ctrl), newFeed(ctrl), newEvents(ctrl), publisher.Noop{})
+	res, err := click.GetLinkDomains(context.Background(), access, dto.DashboardQuery{Limit: "2"})
+	require.NoError(t, err)
+
+	require.Equal(t, dto.GetLinkDomains{
+		Links:   8,
+		Domains: []dto.LinksByDomain{{Domain: "example.com", Links: 5}, {Domain: "acme.com", Links: 2}},
+	}, res)
+}
@@ -0,0 +1,131 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"github.com/google/uuid"
+	"github.com/ilam072/shortener/internal/click/types/domain"
+	"github.com/ilam072/shortener/internal/click/types/dto"
+	"github.com/wb-go/wbf/zlog"
+	"net/netip"
+	"time"
+)
+
+// IP modes, how much of the ip of a click is stored.
+const (
+	// IPModeFull stores the ip as is.
+	IPModeFull = "full"
+	// IPModeTruncate zeroes the last octet of IPv4 and the last 80 bits of
+	// IPv6 addresses.
+	IPModeTruncate = "truncate"
+	// IPModeHash stores a hash of the ip, salted with the salt of the day.
+	IPModeHash = "hash"
+	// IPModeDrop stores no ip.
+	IPModeDrop = "drop"
+)
+
+// Enricher completes clicks with what is known about them at the moment
+// they happen, which for queued clicks is well before they are stored.
+type Enricher struct {
+	salts  VisitorSalt
+	geo    GeoLocator
+	bots   BotClassifier
+	ipMode string
+}
+
+// NewEnricher returns an enricher. geo is nil when no geoip database is
+// configured, clicks are then stored without a location. ipMode is one of
+// the IP modes.
+func NewEnricher(salts VisitorSalt, geo GeoLocator, bots BotClassifier, ipMode string) *Enricher {
+	return &Enricher{salts: salts, geo: geo, bots: bots, ipMode: ipMode}
+}
+
+// newClick stamps a click with its id, time, visitor and location, and
+// tells whether it comes from a bot. The ip is anonymized last, once the
+// visitor and the location are derived from the full one.
+func (e *Enricher) newClick(ctx context.Context, click dto.Click) domain.Click {
+	clickedAt := time.Now().UTC()
+
+	// Without the salt the click is still stored, it only does not count
+	// towards unique clicks.
+	var visitorID string
+	salt, err := e.salts.Salt(ctx, clickedAt.Format(time.DateOnly))
+	if err != nil {
+		zlog.Logger.Warn().Err(err).Msg("failed to get visitor salt")
+	} else {
+		visitorID = hashVisitor(salt, click.IP, click.UserAgent)
+	}
+
+	var location domain.Location
+	if e.geo != nil {
+		location = e.geo.Locate(click.IP)
+	}
+
+	return domain.Click{
+		ID:            uuid.New(),
+		LinkID:        click.LinkID,
+		WorkspaceID:   click.WorkspaceID,
+		Alias:         click.Alias,
+		UserAgent:     click.UserAgent,
+		Client:        click.Client,
+		ClientVersion: click.ClientVersion,
+		Device:        click.Device,
+		OS:            click.OS,
+		OSVersion:     click.OSVersion,
+		Referrer:      click.Referrer,
+		IP:            e.anonymizeIP(click.IP, salt, err == nil),
+		Location:      location,
+		IsBot:         click.Device == "bot" || e.bots.IsBot(click.UserAgent),
+		VisitorID:     visitorID,
+		ClickedAt:     clickedAt,
+	}
+}
+
+// anonymizeIP returns what is stored of ip in the ip mode. Without the salt
+// of the day a hashed ip is dropped.
+func (e *Enricher) anonymizeIP(ip string, salt string, salted bool) string {
+	switch e.ipMode {
+	case IPModeFull:
+		return ip
+	case IPModeHash:
+		if !salted || ip == "" {
+			return ""
+		}
+		h := sha256.Sum256([]byte(salt + "\x00" + ip))
+		return hex.EncodeToString(h[:])
+	case IPModeDrop:
+		return ""
+	default:
+		return truncateIP(ip)
+	}
+}
+
+// truncateIP zeroes the host part of ip, or drops an ip it cannot parse.
+func truncateIP(ip string) string {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return ""
+	}
+	addr = addr.Unmap()
+
+	bits := 48
+	if addr.Is4() {
+		bits = 24
+	}
+	prefix, err := addr.Prefix(bits)
+	if err != nil {
+		return ""
+	}
+	return prefix.Addr().String()
+}
+
+func hashVisitor(salt string, ip string, userAgent string) string {
+	h := sha256.New()
+	h.Write([]byte(salt))
+	h.Write([]byte{0})
+	h.Write([]byte(ip))
+	h.Write([]byte{0})
+	h.Write([]byte(userAgent))
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,151 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/ilam072/shortener/internal/auth"
+	"github.com/ilam072/shortener/internal/click/repo"
+	"github.com/ilam072/shortener/internal/click/types/domain"
+	"github.com/ilam072/shortener/internal/click/types/dto"
+	"github.com/ilam072/shortener/pkg/errutils"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Export tables besides the breakdowns, which are named after their
+// dimension.
+const (
+	// ExportClicks is the raw clicks.
+	ExportClicks = "clicks"
+	// ExportSeries is the series of click counts.
+	ExportSeries = "series"
+)
+
+var ErrInvalidExportTable = errors.New("table must be one of clicks, series, user_agent, device, os, referrer, country")
+
+// ExportWriter writes an export table, its header first and then its rows,
+// which hold strings, ints and bools.
+type ExportWriter interface {
+	Header(columns []string) error
+	Row(values []any) error
+}
+
+// clickColumns are the columns of the raw clicks export.
+var clickColumns = []string{
+	"id", "clicked_at", "device", "client", "client_version", "os", "os_version",
+	"referrer", "country", "region", "city", "bot", "visitor_id", "user_agent",
+}
+
+// Export writes a table of the clicks of a link visible through access to
+// w. The query is checked and the link resolved before the header is
+// written, so that nothing is written for a request that fails on them.
+// Raw clicks are streamed from the repo, never held all at once.
+func (c *Click) Export(ctx context.Context, access auth.Access, host string, alias string, query dto.ExportQuery, w ExportWriter) error {
+	const op = "service.click.Export"
+
+	series, loc, err := parseSeries(dto.ClicksQuery{
+		From:        query.From,
+		To:          query.To,
+		Timezone:    query.Timezone,
+		Granularity: query.Granularity,
+		IncludeBots: query.IncludeBots,
+	}, time.Now())
+	if err != nil {
+		return errutils.Wrap(op, err)
+	}
+
+	table := query.Table
+	if table == "" {
+		table = ExportClicks
+	}
+	if table != ExportClicks && table != ExportSeries {
+		if !slices.Contains(dimensions, table) {
+			return errutils.Wrap(op, ErrInvalidExportTable)
+		}
+		if table == domain.DimensionCountry && c.enricher.geo == nil {
+			return errutils.Wrap(op, ErrGeoUnavailable)
+		}
+	}
+
+	link, err := c.repo.GetLink(ctx, access, strings.ToLower(host), alias)
+	if err != nil {
+		if errors.Is(err, repo.ErrAliasNotFound) {
+			return errutils.Wrap(op, ErrAliasNotFound)
+		}
+		return errutils.Wrap(op, err)
+	}
+
+	switch table {
+	case ExportClicks:
+		err = c.exportClicks(ctx, access, link, series, loc, w)
+	case ExportSeries:
+		err = c.exportSeries(ctx, access, link, series, loc, w)
+	default:
+		err = c.exportBreakdown(ctx, access, link, table, series, w)
+	}
+	if err != nil {
+		return errutils.Wrap(op, err)
+	}
+
+	return nil
+}
+
+func (c *Click) exportClicks(ctx context.Context, access auth.Access, link domain.Link, series domain.Series, loc *time.Location, w ExportWriter) error {
+	if err := w.Header(clickColumns); err != nil {
+		return err
+	}
+
+	return c.repo.ExportClicks(ctx, access.Workspace.ID, link.ID, series, func(click domain.Click) error {
+		return w.Row([]any{
+			click.ID.String(),
+			click.ClickedAt.In(loc).Format(time.RFC3339),
+			click.Device,
+			click.Client,
+			click.ClientVersion,
+			click.OS,
+			click.OSVersion,
+			click.Referrer,
+			click.Location.Country,
+			click.Location.Region,
+			click.Location.City,
+			click.IsBot,
+			click.VisitorID,
+			click.UserAgent,
+		})
+	})
+}
+
+func (c *Click) exportSeries(ctx context.Context, access auth.Access, link domain.Link, series domain.Series, loc *time.Location, w ExportWriter) error {
+	buckets, err := c.repo.GetClickSeries(ctx, access.Workspace.ID, link.ID, series)
+	if err != nil {
+		return err
+	}
+
+	if err = w.Header([]string{"time", "clicks", "unique_clicks"}); err != nil {
+		return err
+	}
+	for _, bucket := range mapToClicksAt(buckets, loc) {
+		if err = w.Row([]any{bucket.Time, bucket.Clicks, bucket.UniqueClicks}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *Click) exportBreakdown(ctx context.Context, access auth.Access, link domain.Link, dimension string, series domain.Series, w ExportWriter) error {
+	rows, err := c.repo.GetClickBreakdown(ctx, access.Workspace.ID, link.ID, dimension, series)
+	if err != nil {
+		return err
+	}
+
+	if err = w.Header([]string{dimension, "clicks", "unique_clicks"}); err != nil {
+		return err
+	}
+	for _, row := range rows {
+		if err = w.Row([]any{row.Aggregation, row.Clicks, row.UniqueClicks}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
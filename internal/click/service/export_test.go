@@ -0,0 +1,180 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"go.uber.org/mock/gomock"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ilam072/shortener/internal/auth"
+	"github.com/ilam072/shortener/internal/click/mocks"
+	clickrepo "github.com/ilam072/shortener/internal/click/repo"
+	"github.com/ilam072/shortener/internal/click/service"
+	"github.com/ilam072/shortener/internal/click/types/domain"
+	"github.com/ilam072/shortener/internal/click/types/dto"
+	"github.com/ilam072/shortener/internal/eventbus/publisher"
+)
+
+// tableWriter records the table of an export.
+type tableWriter struct {
+	header []string
+	rows   [][]any
+}
+
+func (w *tableWriter) Header(columns []string) error {
+	w.header = columns
+	return nil
+}
+
+func (w *tableWriter) Row(values []any) error {
+	w.rows = append(w.rows, values)
+	return nil
+}
+
+func TestClickService_Export(t *testing.T) {
+	workspaceID := uuid.New()
+	linkID := uuid.New()
+	clickID := uuid.New()
+	access := auth.Access{Workspace: auth.Workspace{ID: workspaceID, Slug: "acme"}, All: true}
+	query := dto.ExportQuery{From: "2025-01-01", To: "2025-01-02", Timezone: "Europe/Moscow"}
+
+	tests := []struct {
+		name   string
+		table  string
+		setup  func(repo *mocks.MockClickRepo)
+		header []string
+		rows   [][]any
+		err    error
+	}{
+		{
+			name: "clicks by default",
+			setup: func(repo *mocks.MockClickRepo) {
+				repo.EXPECT().
+					GetLink(gomock.Any(), access, "", "abc").
+					Return(domain.Link{ID: linkID}, nil)
+				repo.EXPECT().
+					ExportClicks(gomock.Any(), workspaceID, linkID, gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, _, _ uuid.UUID, series domain.Series, each func(domain.Click) error) error {
+						require.Equal(t, "2024-12-31T21:00:00Z", series.From.UTC().Format(time.RFC3339))
+						require.Equal(t, domain.TrafficHumans, series.Traffic)
+						return each(domain.Click{
+							ID:            clickID,
+							LinkID:        linkID,
+							UserAgent:     "Mozilla/5.0",
+							Device:        "mobile",
+							Client:        "Safari",
+							ClientVersion: "17.0",
+							OS:            "iOS",
+							OSVersion:     "17.1",
+							Referrer:      "example.com",
+							IP:            "203.0.113.0",
+							VisitorID:     "visitor",
+							Location:      domain.Location{Country: "DE", Region: "Berlin", City: "Berlin"},
+							ClickedAt:     time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC),
+						})
+					})
+			},
+			header: []string{
+				"id", "clicked_at", "device", "client", "client_version", "os", "os_version",
+				"referrer", "country", "region", "city", "bot", "visitor_id", "user_agent",
+			},
+			rows: [][]any{{
+				clickID.String(), "2025-01-01T12:00:00+03:00", "mobile", "Safari", "17.0", "iOS", "17.1",
+				"example.com", "DE", "Berlin", "Berlin", false, "visitor", "Mozilla/5.0",
+			}},
+		},
+		{
+			name:  "series",
+			table: service.ExportSeries,
+			setup: func(repo *mocks.MockClickRepo) {
+				repo.EXPECT().
+					GetLink(gomock.Any(), access, "", "abc").
+					Return(domain.Link{ID: linkID}, nil)
+				repo.EXPECT().
+					GetClickSeries(gomock.Any(), workspaceID, linkID, gomock.Any()).
+					Return([]domain.ClickBucket{{Start: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), Clicks: 10, UniqueClicks: 4}}, nil)
+			},
+			header: []string{"time", "clicks", "unique_clicks"},
+			rows:   [][]any{{"2025-01-01T00:00:00+03:00", 10, 4}},
+		},
+		{
+			name:  "breakdown",
+			table: domain.DimensionDevice,
+			setup: func(repo *mocks.MockClickRepo) {
+				repo.EXPECT().
+					GetLink(gomock.Any(), access, "", "abc").
+					Return(domain.Link{ID: linkID}, nil)
+				repo.EXPECT().
+					GetClickBreakdown(gomock.Any(), workspaceID, linkID, domain.DimensionDevice, gomock.Any()).
+					Return([]domain.ClickRow{{Aggregation: "mobile", Clicks: 5, UniqueClicks: 2}}, nil)
+			},
+			header: []string{"device", "clicks", "unique_clicks"},
+			rows:   [][]any{{"mobile", 5, 2}},
+		},
+		{
+			name:  "unknown table",
+			table: "device,os",
+			err:   service.ErrInvalidExportTable,
+		},
+		{
+			name:  "country without geoip",
+			table: domain.DimensionCountry,
+			err:   service.ErrGeoUnavailable,
+		},
+		{
+			name: "alias not found",
+			setup: func(repo *mocks.MockClickRepo) {
+				repo.EXPECT().
+					GetLink(gomock.Any(), access, "", "abc").
+					Return(domain.Link{}, clickrepo.ErrAliasNotFound)
+			},
+			err: service.ErrAliasNotFound,
+		},
+		{
+			name: "repo error",
+			setup: func(repo *mocks.MockClickRepo) {
+				repo.EXPECT().
+					GetLink(gomock.Any(), access, "", "abc").
+					Return(domain.Link{ID: linkID}, nil)
+				repo.EXPECT().
+					ExportClicks(gomock.Any(), workspaceID, linkID, gomock.Any(), gomock.Any()).
+					Return(errors.New("db error"))
+			},
+			header: []string{
+				"id", "clicked_at", "device", "client", "client_version", "os", "os_version",
+				"referrer", "country", "region", "city", "bot", "visitor_id", "user_agent",
+			},
+			err: errors.New("db error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockRepo := mocks.NewMockClickRepo(ctrl)
+			if tt.setup != nil {
+				tt.setup(mockRepo)
+			}
+
+			click := service.New(mockRepo, mocks.NewMockClickOutbox(ctrl), newEnricher(ctrl), newFeed(ctrl), newEvents(ctrl), publisher.Noop{})
+			query := query
+			query.Table = tt.table
+
+			w := &tableWriter{}
+			err := click.Export(context.Background(), access, "", "abc", query, w)
+			if tt.err != nil {
+				require.ErrorContains(t, err, tt.err.Error())
+			} else {
+				require.NoError(t, err)
+			}
+			require.Equal(t, tt.header, w.header)
+			require.Equal(t, tt.rows, w.rows)
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/ilam072/shortener/internal/auth"
+	"github.com/ilam072/shortener/internal/click/repo"
+	"github.com/ilam072/shortener/internal/click/types/domain"
+	"github.com/ilam072/shortener/pkg/errutils"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Live streams the clicks of links as they happen.
+type Live struct {
+	repo   ClickRepo
+	feed   ClickFeed
+	buffer int
+}
+
+// NewLive returns a live service whose streams buffer up to buffer clicks.
+func NewLive(repo ClickRepo, feed ClickFeed, buffer int) *Live {
+	return &Live{repo: repo, feed: feed, buffer: buffer}
+}
+
+// ClickStream is a live stream of the clicks of a link. The clicks a slow
+// reader has no room for in the buffer are dropped and counted, so that
+// it never holds up the feed.
+type ClickStream struct {
+	clicks      chan domain.LiveClick
+	dropped     atomic.Int64
+	unsubscribe func()
+	once        sync.Once
+}
+
+// Clicks returns the clicks of the stream.
+func (s *ClickStream) Clicks() <-chan domain.LiveClick {
+	return s.clicks
+}
+
+// Dropped returns the number of clicks dropped since the last call.
+func (s *ClickStream) Dropped() int64 {
+	return s.dropped.Swap(0)
+}
+
+// Close ends the stream.
+func (s *ClickStream) Close() {
+	s.once.Do(s.unsubscribe)
+}
+
+// StreamClicks opens a live stream of the clicks of a link visible through
+// access, with the clicks of bots only when includeBots says so.
+func (l *Live) StreamClicks(ctx context.Context, access auth.Access, host string, alias string, includeBots string) (*ClickStream, error) {
+	const op = "service.click.Live.StreamClicks"
+
+	bots, err := parseIncludeBots(includeBots)
+	if err != nil {
+		return nil, errutils.Wrap(op, err)
+	}
+
+	link, err := l.repo.GetLink(ctx, access, strings.ToLower(host), alias)
+	if err != nil {
+		if errors.Is(err, repo.ErrAliasNotFound) {
+			return nil, errutils.Wrap(op, ErrAliasNotFound)
+		}
+		return nil, errutils.Wrap(op, err)
+	}
+
+	stream := &ClickStream{clicks: make(chan domain.LiveClick, l.buffer)}
+	stream.unsubscribe, err = l.feed.Subscribe(ctx, link.ID, func(click domain.LiveClick) {
+		if click.IsBot && !bots {
+			return
+		}
+		select {
+		case stream.clicks <- click:
+		default:
+			stream.dropped.Add(1)
+		}
+	})
+	if err != nil {
+		return nil, errutils.Wrap(op, err)
+	}
+
+	return stream, nil
+}
+
+func liveClick(click domain.Click) domain.LiveClick {
+	return domain.LiveClick{
+		LinkID:    click.LinkID,
+		ClickedAt: click.ClickedAt,
+		Device:    click.Device,
+		Client:    click.Client,
+		Country:   click.Location.Country,
+		IsBot:     click.IsBot,
+	}
+}
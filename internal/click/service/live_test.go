@@ -0,0 +1,135 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"go.uber.org/mock/gomock"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ilam072/shortener/internal/auth"
+	"github.com/ilam072/shortener/internal/click/mocks"
+	clickrepo "github.com/ilam072/shortener/internal/click/repo"
+	"github.com/ilam072/shortener/internal/click/service"
+	"github.com/ilam072/shortener/internal/click/types/domain"
+)
+
+func TestLive_StreamClicks(t *testing.T) {
+	linkID := uuid.New()
+	human := domain.LiveClick{LinkID: linkID, ClickedAt: time.Now(), Device: "desktop", Client: "Firefox"}
+	bot := domain.LiveClick{LinkID: linkID, ClickedAt: time.Now(), Device: "bot", Client: "Googlebot", IsBot: true}
+
+	type args struct {
+		includeBots string
+	}
+	type want struct {
+		err     error
+		clicks  []domain.LiveClick
+		dropped int64
+	}
+
+	tests := []struct {
+		name  string
+		args  args
+		setup func(repo *mocks.MockClickRepo, feed *mocks.MockClickFeed)
+		want  want
+	}{
+		{
+			name: "leaves bots out by default",
+			setup: func(repo *mocks.MockClickRepo, feed *mocks.MockClickFeed) {
+				repo.EXPECT().GetLink(gomock.Any(), gomock.Any(), "", "abc").Return(domain.Link{ID: linkID}, nil)
+				feed.EXPECT().
+					Subscribe(gomock.Any(), linkID, gomock.Any()).
+					DoAndReturn(func(_ context.Context, _ uuid.UUID, deliver func(domain.LiveClick)) (func(), error) {
+						deliver(bot)
+						deliver(human)
+						return func() {}, nil
+					})
+			},
+			want: want{clicks: []domain.LiveClick{human}},
+		},
+		{
+			name: "includes bots on request",
+			args: args{includeBots: "true"},
+			setup: func(repo *mocks.MockClickRepo, feed *mocks.MockClickFeed) {
+				repo.EXPECT().GetLink(gomock.Any(), gomock.Any(), "", "abc").Return(domain.Link{ID: linkID}, nil)
+				feed.EXPECT().
+					Subscribe(gomock.Any(), linkID, gomock.Any()).
+					DoAndReturn(func(_ context.Context, _ uuid.UUID, deliver func(domain.LiveClick)) (func(), error) {
+						deliver(bot)
+						deliver(human)
+						return func() {}, nil
+					})
+			},
+			want: want{clicks: []domain.LiveClick{bot, human}},
+		},
+		{
+			name: "drops clicks past the buffer",
+			setup: func(repo *mocks.MockClickRepo, feed *mocks.MockClickFeed) {
+				repo.EXPECT().GetLink(gomock.Any(), gomock.Any(), "", "abc").Return(domain.Link{ID: linkID}, nil)
+				feed.EXPECT().
+					Subscribe(gomock.Any(), linkID, gomock.Any()).
+					DoAndReturn(func(_ context.Context, _ uuid.UUID, deliver func(domain.LiveClick)) (func(), error) {
+						for i := 0; i < 5; i++ {
+							deliver(human)
+						}
+						return func() {}, nil
+					})
+			},
+			want: want{clicks: []domain.LiveClick{human, human}, dropped: 3},
+		},
+		{
+			name:  "invalid include_bots",
+			args:  args{includeBots: "maybe"},
+			setup: func(repo *mocks.MockClickRepo, feed *mocks.MockClickFeed) {},
+			want:  want{err: service.ErrInvalidIncludeBots},
+		},
+		{
+			name: "alias not found",
+			setup: func(repo *mocks.MockClickRepo, feed *mocks.MockClickFeed) {
+				repo.EXPECT().GetLink(gomock.Any(), gomock.Any(), "", "abc").Return(domain.Link{}, clickrepo.ErrAliasNotFound)
+			},
+			want: want{err: service.ErrAliasNotFound},
+		},
+		{
+			name: "feed error",
+			setup: func(repo *mocks.MockClickRepo, feed *mocks.MockClickFeed) {
+				repo.EXPECT().GetLink(gomock.Any(), gomock.Any(), "", "abc").Return(domain.Link{ID: linkID}, nil)
+				feed.EXPECT().Subscribe(gomock.Any(), linkID, gomock.Any()).Return(nil, errors.New("redis error"))
+			},
+			want: want{err: errors.New("redis error")},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockRepo := mocks.NewMockClickRepo(ctrl)
+			mockFeed := mocks.NewMockClickFeed(ctrl)
+			tt.setup(mockRepo, mockFeed)
+
+			live := service.NewLive(mockRepo, mockFeed, 2)
+			stream, err := live.StreamClicks(context.Background(), auth.Access{All: true}, "", "abc", tt.args.includeBots)
+			if tt.want.err != nil {
+				require.ErrorContains(t, err, tt.want.err.Error())
+				require.Nil(t, stream)
+				return
+			}
+			require.NoError(t, err)
+			defer stream.Close()
+
+			var clicks []domain.LiveClick
+			for len(stream.Clicks()) > 0 {
+				clicks = append(clicks, <-stream.Clicks())
+			}
+			require.Equal(t, tt.want.clicks, clicks)
+			require.Equal(t, tt.want.dropped, stream.Dropped())
+			require.Zero(t, stream.Dropped())
+		})
+	}
+}
@@ -0,0 +1,187 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/ilam072/shortener/internal/click/types/domain"
+	"github.com/ilam072/shortener/internal/click/types/dto"
+	"github.com/ilam072/shortener/internal/eventbus"
+	"github.com/ilam072/shortener/pkg/errutils"
+	"github.com/wb-go/wbf/zlog"
+	"sync"
+	"time"
+)
+
+const (
+	// OverflowDrop rejects clicks while the queue is full.
+	OverflowDrop = "drop"
+	// OverflowBlock makes the redirect wait for room in the queue.
+	OverflowBlock = "block"
+)
+
+var (
+	ErrQueueFull   = errors.New("click queue is full")
+	ErrQueueClosed = errors.New("click queue is closed")
+)
+
+type QueueConfig struct {
+	// Size is the number of clicks buffered in memory.
+	Size int
+	// Workers is the number of goroutines writing clicks to the repo.
+	Workers int
+	// BatchSize is the largest number of clicks written at once.
+	BatchSize int
+	// FlushInterval is how long a worker holds an incomplete batch.
+	FlushInterval time.Duration
+	// Overflow is OverflowDrop or OverflowBlock.
+	Overflow string
+}
+
+// Queue takes clicks off the redirect path. Clicks are buffered and written
+// to the repo in batches by a pool of workers.
+type Queue struct {
+	repo      ClickRepo
+	outbox    ClickOutbox
+	enricher  *Enricher
+	feed      ClickFeed
+	events    ClickEvents
+	publisher eventbus.EventPublisher
+	cfg       QueueConfig
+	clicks    chan domain.Click
+
+	mu     sync.RWMutex
+	closed bool
+	wg     sync.WaitGroup
+}
+
+func NewQueue(
+	repo ClickRepo,
+	outbox ClickOutbox,
+	enricher *Enricher,
+	feed ClickFeed,
+	events ClickEvents,
+	publisher eventbus.EventPublisher,
+	cfg QueueConfig,
+) *Queue {
+	return &Queue{
+		repo:      repo,
+		outbox:    outbox,
+		enricher:  enricher,
+		feed:      feed,
+		events:    events,
+		publisher: publisher,
+		cfg:       cfg,
+		clicks:    make(chan domain.Click, cfg.Size),
+	}
+}
+
+// Start launches the workers.
+func (q *Queue) Start() {
+	for i := 0; i < q.cfg.Workers; i++ {
+		q.wg.Add(1)
+		go q.work()
+	}
+}
+
+// SaveClick enqueues a click, publishes it to the live streams and the
+// event bus and announces it to webhooks. When the
+// queue is full, the click is rejected with ErrQueueFull or waits for room
+// until ctx is done, depending on the overflow behavior.
+func (q *Queue) SaveClick(ctx context.Context, click dto.Click) error {
+	const op = "service.click.Queue.SaveClick"
+
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	if q.closed {
+		return errutils.Wrap(op, ErrQueueClosed)
+	}
+
+	domainClick := q.enricher.newClick(ctx, click)
+
+	if q.cfg.Overflow == OverflowBlock {
+		select {
+		case q.clicks <- domainClick:
+		case <-ctx.Done():
+			return errutils.Wrap(op, ctx.Err())
+		}
+	} else {
+		select {
+		case q.clicks <- domainClick:
+		default:
+			return errutils.Wrap(op, ErrQueueFull)
+		}
+	}
+	q.feed.Publish(liveClick(domainClick))
+	q.events.Emit(clickEvent(domainClick))
+	q.publisher.Publish(publishedClick(domainClick))
+
+	return nil
+}
+
+// Shutdown stops accepting clicks and waits until the workers have written
+// the ones already queued, or until ctx is done.
+func (q *Queue) Shutdown(ctx context.Context) error {
+	const op = "service.click.Queue.Shutdown"
+
+	q.mu.Lock()
+	if !q.closed {
+		q.closed = true
+		close(q.clicks)
+	}
+	q.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		q.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return errutils.Wrap(op, ctx.Err())
+	}
+}
+
+func (q *Queue) work() {
+	defer q.wg.Done()
+
+	ticker := time.NewTicker(q.cfg.FlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]domain.Click, 0, q.cfg.BatchSize)
+	for {
+		select {
+		case click, ok := <-q.clicks:
+			if !ok {
+				q.flush(batch)
+				return
+			}
+			batch = append(batch, click)
+			if len(batch) >= q.cfg.BatchSize {
+				q.flush(batch)
+				batch = batch[:0]
+			}
+		case <-ticker.C:
+			q.flush(batch)
+			batch = batch[:0]
+		}
+	}
+}
+
+func (q *Queue) flush(batch []domain.Click) {
+	if len(batch) == 0 {
+		return
+	}
+
+	// A batch the repo fails on is kept in the outbox for the replayer.
+	ctx := context.Background()
+	if err := q.repo.CreateClicks(ctx, batch); err != nil {
+		zlog.Logger.Warn().Err(err).Int("clicks", len(batch)).Msg("failed to save clicks, buffering them")
+		if err = q.outbox.Append(ctx, batch); err != nil {
+			zlog.Logger.Error().Err(err).Int("clicks", len(batch)).Msg("failed to buffer clicks")
+		}
+	}
+}
@@ -0,0 +1,209 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"go.uber.org/mock/gomock"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ilam072/shortener/internal/click/mocks"
+	"github.com/ilam072/shortener/internal/click/service"
+	"github.com/ilam072/shortener/internal/click/types/domain"
+	"github.com/ilam072/shortener/internal/click/types/dto"
+	"github.com/ilam072/shortener/internal/eventbus/publisher"
+)
+
+func TestQueue_FlushesInBatches(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockClickRepo(ctrl)
+	mockRepo.EXPECT().
+		CreateClicks(gomock.Any(), gomock.Len(2)).
+		DoAndReturn(func(_ context.Context, clicks []domain.Click) error {
+			for _, click := range clicks {
+				require.NotEqual(t, uuid.Nil, click.ID)
+				require.False(t, click.ClickedAt.IsZero())
+			}
+			return nil
+		}).
+		Times(2)
+
+	queue := service.NewQueue(mockRepo, mocks.NewMockClickOutbox(ctrl), newEnricher(ctrl), newFeed(ctrl), newEvents(ctrl), publisher.Noop{}, service.QueueConfig{
+		Size:          10,
+		Workers:       1,
+		BatchSize:     2,
+		FlushInterval: time.Hour,
+		Overflow:      service.OverflowDrop,
+	})
+	queue.Start()
+
+	for i := 0; i < 4; i++ {
+		require.NoError(t, queue.SaveClick(context.Background(), dto.Click{Alias: "abc"}))
+	}
+
+	require.NoError(t, queue.Shutdown(context.Background()))
+}
+
+func TestQueue_BuffersFailedBatch(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockClickRepo(ctrl)
+	mockOutbox := mocks.NewMockClickOutbox(ctrl)
+	mockRepo.EXPECT().
+		CreateClicks(gomock.Any(), gomock.Len(2)).
+		Return(errors.New("db error"))
+	mockOutbox.EXPECT().
+		Append(gomock.Any(), gomock.Len(2)).
+		Return(nil)
+
+	queue := service.NewQueue(mockRepo, mockOutbox, newEnricher(ctrl), newFeed(ctrl), newEvents(ctrl), publisher.Noop{}, service.QueueConfig{
+		Size:          10,
+		Workers:       1,
+		BatchSize:     2,
+		FlushInterval: time.Hour,
+		Overflow:      service.OverflowDrop,
+	})
+	queue.Start()
+
+	for i := 0; i < 2; i++ {
+		require.NoError(t, queue.SaveClick(context.Background(), dto.Click{Alias: "abc"}))
+	}
+
+	require.NoError(t, queue.Shutdown(context.Background()))
+}
+
+func TestQueue_FlushesOnInterval(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	flushed := make(chan struct{})
+	mockRepo := mocks.NewMockClickRepo(ctrl)
+	mockRepo.EXPECT().
+		CreateClicks(gomock.Any(), gomock.Len(1)).
+		DoAndReturn(func(context.Context, []domain.Click) error {
+			close(flushed)
+			return nil
+		})
+
+	queue := service.NewQueue(mockRepo, mocks.NewMockClickOutbox(ctrl), newEnricher(ctrl), newFeed(ctrl), newEvents(ctrl), publisher.Noop{}, service.QueueConfig{
+		Size:          10,
+		Workers:       1,
+		BatchSize:     100,
+		FlushInterval: 10 * time.Millisecond,
+		Overflow:      service.OverflowDrop,
+	})
+	queue.Start()
+
+	require.NoError(t, queue.SaveClick(context.Background(), dto.Click{Alias: "abc"}))
+
+	select {
+	case <-flushed:
+	case <-time.After(time.Second):
+		t.Fatal("click was not flushed")
+	}
+
+	require.NoError(t, queue.Shutdown(context.Background()))
+}
+
+func TestQueue_Overflow(t *testing.T) {
+	tests := []struct {
+		name     string
+		overflow string
+		wantErr  error
+	}{
+		{
+			name:     "drop",
+			overflow: service.OverflowDrop,
+			wantErr:  service.ErrQueueFull,
+		},
+		{
+			name:     "block",
+			overflow: service.OverflowBlock,
+			wantErr:  context.DeadlineExceeded,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockRepo := mocks.NewMockClickRepo(ctrl)
+			mockRepo.EXPECT().
+				CreateClicks(gomock.Any(), gomock.Len(1)).
+				Return(nil)
+
+			// Workers are not started, so the queue fills up.
+			queue := service.NewQueue(mockRepo, mocks.NewMockClickOutbox(ctrl), newEnricher(ctrl), newFeed(ctrl), newEvents(ctrl), publisher.Noop{}, service.QueueConfig{
+				Size:          1,
+				Workers:       1,
+				BatchSize:     10,
+				FlushInterval: time.Hour,
+				Overflow:      tt.overflow,
+			})
+
+			require.NoError(t, queue.SaveClick(context.Background(), dto.Click{Alias: "abc"}))
+
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+			defer cancel()
+
+			require.ErrorIs(t, queue.SaveClick(ctx, dto.Click{Alias: "abc"}), tt.wantErr)
+
+			// The queued click is still written on shutdown.
+			queue.Start()
+			require.NoError(t, queue.Shutdown(context.Background()))
+		})
+	}
+}
+
+func TestQueue_SaveClick_Closed(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	queue := service.NewQueue(mocks.NewMockClickRepo(ctrl), mocks.NewMockClickOutbox(ctrl), newEnricher(ctrl), newFeed(ctrl), newEvents(ctrl), publisher.Noop{}, service.QueueConfig{
+		Size:          1,
+		Workers:       1,
+		BatchSize:     1,
+		FlushInterval: time.Hour,
+		Overflow:      service.OverflowDrop,
+	})
+	queue.Start()
+	require.NoError(t, queue.Shutdown(context.Background()))
+
+	require.ErrorIs(t, queue.SaveClick(context.Background(), dto.Click{Alias: "abc"}), service.ErrQueueClosed)
+}
+
+func TestQueue_PublishesAcceptedClicks(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	linkID := uuid.New()
+	mockFeed := mocks.NewMockClickFeed(ctrl)
+	mockFeed.EXPECT().
+		Publish(gomock.Any()).
+		Do(func(click domain.LiveClick) {
+			require.Equal(t, linkID, click.LinkID)
+			require.Equal(t, "mobile", click.Device)
+			require.Equal(t, "Safari", click.Client)
+			require.False(t, click.ClickedAt.IsZero())
+		})
+
+	// The queue is never started, so the second click finds it full.
+	queue := service.NewQueue(mocks.NewMockClickRepo(ctrl), mocks.NewMockClickOutbox(ctrl), newEnricher(ctrl), mockFeed, newEvents(ctrl), publisher.Noop{}, service.QueueConfig{
+		Size:          1,
+		Workers:       1,
+		BatchSize:     1,
+		FlushInterval: time.Hour,
+		Overflow:      service.OverflowDrop,
+	})
+
+	click := dto.Click{LinkID: linkID, Alias: "abc", Device: "mobile", Client: "Safari"}
+	require.NoError(t, queue.SaveClick(context.Background(), click))
+	require.ErrorIs(t, queue.SaveClick(context.Background(), click), service.ErrQueueFull)
+}
@@ -0,0 +1,104 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/ilam072/shortener/internal/click/repo"
+	"github.com/ilam072/shortener/internal/click/types/domain"
+	"github.com/ilam072/shortener/pkg/errutils"
+	"github.com/wb-go/wbf/zlog"
+	"time"
+)
+
+// Replayer moves clicks buffered in the outbox into the repo. Clicks keep
+// the id they got when they happened and the repo skips stored ones, so a
+// batch written twice, by a retry or by another instance, is stored once.
+// A batch that fails is written again click by click, and clicks the repo
+// rejects are moved to the dead letters, so that they do not hold up the
+// clicks behind them.
+type Replayer struct {
+	repo      ClickRepo
+	outbox    ClickOutbox
+	batchSize int
+	interval  time.Duration
+}
+
+func NewReplayer(repo ClickRepo, outbox ClickOutbox, batchSize int, interval time.Duration) *Replayer {
+	return &Replayer{
+		repo:      repo,
+		outbox:    outbox,
+		batchSize: batchSize,
+		interval:  interval,
+	}
+}
+
+// Run replays the outbox every interval until ctx is done.
+func (r *Replayer) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := r.Replay(ctx); err != nil {
+				zlog.Logger.Warn().Err(err).Msg("failed to replay buffered clicks")
+			}
+		}
+	}
+}
+
+// Replay writes buffered clicks batch by batch until the outbox is empty.
+// A batch is removed from the outbox only once it is stored.
+func (r *Replayer) Replay(ctx context.Context) error {
+	const op = "service.click.Replayer.Replay"
+
+	for {
+		buffered, err := r.outbox.Read(ctx, r.batchSize)
+		if err != nil {
+			return errutils.Wrap(op, err)
+		}
+		if len(buffered) == 0 {
+			return nil
+		}
+
+		clicks := make([]domain.Click, 0, len(buffered))
+		entryIDs := make([]string, 0, len(buffered))
+		for _, b := range buffered {
+			clicks = append(clicks, b.Click)
+			entryIDs = append(entryIDs, b.EntryID)
+		}
+
+		if err = r.repo.CreateClicks(ctx, clicks); err != nil {
+			if err = r.replayEach(ctx, buffered); err != nil {
+				return errutils.Wrap(op, err)
+			}
+		} else if err = r.outbox.Remove(ctx, entryIDs...); err != nil {
+			return errutils.Wrap(op, err)
+		}
+
+		if len(buffered) < r.batchSize {
+			return nil
+		}
+	}
+}
+
+// replayEach writes buffered clicks one at a time, stopping at the first
+// one that fails for a reason other than the click itself.
+func (r *Replayer) replayEach(ctx context.Context, buffered []domain.BufferedClick) error {
+	for _, b := range buffered {
+		err := r.repo.CreateClicks(ctx, []domain.Click{b.Click})
+		switch {
+		case err == nil:
+			err = r.outbox.Remove(ctx, b.EntryID)
+		case errors.Is(err, repo.ErrInvalidClick):
+			zlog.Logger.Error().Err(err).Str("entry", b.EntryID).Str("click", b.Click.ID.String()).Msg("moving rejected click to dead letters")
+			err = r.outbox.DeadLetter(ctx, b, err.Error())
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,176 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"go.uber.org/mock/gomock"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ilam072/shortener/internal/click/mocks"
+	clickrepo "github.com/ilam072/shortener/internal/click/repo"
+	"github.com/ilam072/shortener/internal/click/service"
+	"github.com/ilam072/shortener/internal/click/types/domain"
+)
+
+func bufferedClicks(n int) []domain.BufferedClick {
+	clicks := make([]domain.BufferedClick, 0, n)
+	for i := 0; i < n; i++ {
+		clicks = append(clicks, domain.BufferedClick{
+			EntryID: uuid.NewString(),
+			Click:   domain.Click{ID: uuid.New(), Alias: "abc"},
+		})
+	}
+	return clicks
+}
+
+func TestReplayer_Replay(t *testing.T) {
+	type fields struct {
+		setup func(repo *mocks.MockClickRepo, outbox *mocks.MockClickOutbox)
+	}
+	type want struct {
+		err bool
+	}
+
+	tests := []struct {
+		name   string
+		fields fields
+		want   want
+	}{
+		{
+			name: "empty outbox",
+			fields: fields{
+				setup: func(repo *mocks.MockClickRepo, outbox *mocks.MockClickOutbox) {
+					outbox.EXPECT().
+						Read(gomock.Any(), 2).
+						Return(nil, nil)
+				},
+			},
+			want: want{},
+		},
+		{
+			name: "replays until drained",
+			fields: fields{
+				setup: func(repo *mocks.MockClickRepo, outbox *mocks.MockClickOutbox) {
+					first, second := bufferedClicks(2), bufferedClicks(1)
+					gomock.InOrder(
+						outbox.EXPECT().Read(gomock.Any(), 2).Return(first, nil),
+						repo.EXPECT().
+							CreateClicks(gomock.Any(), []domain.Click{first[0].Click, first[1].Click}).
+							Return(nil),
+						outbox.EXPECT().Remove(gomock.Any(), first[0].EntryID, first[1].EntryID).Return(nil),
+						outbox.EXPECT().Read(gomock.Any(), 2).Return(second, nil),
+						repo.EXPECT().
+							CreateClicks(gomock.Any(), []domain.Click{second[0].Click}).
+							Return(nil),
+						outbox.EXPECT().Remove(gomock.Any(), second[0].EntryID).Return(nil),
+					)
+				},
+			},
+			want: want{},
+		},
+		{
+			name: "repo error keeps clicks buffered",
+			fields: fields{
+				setup: func(repo *mocks.MockClickRepo, outbox *mocks.MockClickOutbox) {
+					buffered := bufferedClicks(2)
+					gomock.InOrder(
+						outbox.EXPECT().Read(gomock.Any(), 2).Return(buffered, nil),
+						repo.EXPECT().
+							CreateClicks(gomock.Any(), gomock.Len(2)).
+							Return(errors.New("db error")),
+						repo.EXPECT().
+							CreateClicks(gomock.Any(), []domain.Click{buffered[0].Click}).
+							Return(errors.New("db error")),
+					)
+				},
+			},
+			want: want{err: true},
+		},
+		{
+			name: "dead letters rejected clicks",
+			fields: fields{
+				setup: func(repo *mocks.MockClickRepo, outbox *mocks.MockClickOutbox) {
+					first, second := bufferedClicks(2), bufferedClicks(1)
+					rejected := fmt.Errorf("%w: pq: invalid input syntax", clickrepo.ErrInvalidClick)
+					gomock.InOrder(
+						outbox.EXPECT().Read(gomock.Any(), 2).Return(first, nil),
+						repo.EXPECT().
+							CreateClicks(gomock.Any(), gomock.Len(2)).
+							Return(rejected),
+						repo.EXPECT().
+							CreateClicks(gomock.Any(), []domain.Click{first[0].Click}).
+							Return(rejected),
+						outbox.EXPECT().DeadLetter(gomock.Any(), first[0], rejected.Error()).Return(nil),
+						repo.EXPECT().
+							CreateClicks(gomock.Any(), []domain.Click{first[1].Click}).
+							Return(nil),
+						outbox.EXPECT().Remove(gomock.Any(), first[1].EntryID).Return(nil),
+						outbox.EXPECT().Read(gomock.Any(), 2).Return(second, nil),
+						repo.EXPECT().
+							CreateClicks(gomock.Any(), []domain.Click{second[0].Click}).
+							Return(nil),
+						outbox.EXPECT().Remove(gomock.Any(), second[0].EntryID).Return(nil),
+					)
+				},
+			},
+			want: want{},
+		},
+		{
+			name: "dead letter error",
+			fields: fields{
+				setup: func(repo *mocks.MockClickRepo, outbox *mocks.MockClickOutbox) {
+					buffered := bufferedClicks(1)
+					gomock.InOrder(
+						outbox.EXPECT().Read(gomock.Any(), 2).Return(buffered, nil),
+						repo.EXPECT().
+							CreateClicks(gomock.Any(), gomock.Len(1)).
+							Return(clickrepo.ErrInvalidClick).
+							Times(2),
+						outbox.EXPECT().DeadLetter(gomock.Any(), buffered[0], gomock.Any()).Return(errors.New("redis error")),
+					)
+				},
+			},
+			want: want{err: true},
+		},
+		{
+			name: "outbox error",
+			fields: fields{
+				setup: func(repo *mocks.MockClickRepo, outbox *mocks.MockClickOutbox) {
+					outbox.EXPECT().
+						Read(gomock.Any(), 2).
+						Return(nil, errors.New("redis error"))
+				},
+			},
+			want: want{err: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockRepo := mocks.NewMockClickRepo(ctrl)
+			mockOutbox := mocks.NewMockClickOutbox(ctrl)
+
+			if tt.fields.setup != nil {
+				tt.fields.setup(mockRepo, mockOutbox)
+			}
+
+			replayer := service.NewReplayer(mockRepo, mockOutbox, 2, time.Hour)
+
+			err := replayer.Replay(context.Background())
+
+			if tt.want.err {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
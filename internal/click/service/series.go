@@ -0,0 +1,185 @@
+package service
+
+import (
+	"errors"
+	"github.com/ilam072/shortener/internal/click/types/domain"
+	"github.com/ilam072/shortener/internal/click/types/dto"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	// Timezones are validated against the embedded database, so they do
+	// not depend on the zoneinfo of the host.
+	_ "time/tzdata"
+)
+
+// Series granularities, the units of date_trunc they are bucketed by.
+const (
+	GranularityHour  = "hour"
+	GranularityDay   = "day"
+	GranularityWeek  = "week"
+	GranularityMonth = "month"
+)
+
+var (
+	ErrInvalidTimezone    = errors.New("unknown timezone")
+	ErrInvalidGranularity = errors.New("granularity must be one of hour, day, week, month")
+	ErrInvalidRange       = errors.New("invalid time range")
+	ErrRangeTooLarge      = errors.New("time range has too many buckets for the granularity")
+	ErrInvalidDimension   = errors.New("dimensions must be a list of user_agent, device, os, referrer, country")
+	ErrGeoUnavailable     = errors.New("country breakdown requires a geoip database")
+	ErrInvalidIncludeBots = errors.New("include_bots must be true or false")
+)
+
+// dimensions are the breakdowns returned by default, in response order.
+var dimensions = []string{
+	domain.DimensionUserAgent,
+	domain.DimensionDevice,
+	domain.DimensionOS,
+	domain.DimensionReferrer,
+	domain.DimensionCountry,
+}
+
+const (
+	// defaultRange is the range of a series without from.
+	defaultRange = 30 * 24 * time.Hour
+	// maxBuckets bounds the size of a series.
+	maxBuckets = 1000
+)
+
+// bucketSizes are the shortest lengths of the buckets of each granularity.
+var bucketSizes = map[string]time.Duration{
+	GranularityHour:  time.Hour,
+	GranularityDay:   24 * time.Hour,
+	GranularityWeek:  7 * 24 * time.Hour,
+	GranularityMonth: 28 * 24 * time.Hour,
+}
+
+// parseSeries resolves query into a series ending at now by default.
+func parseSeries(query dto.ClicksQuery, now time.Time) (domain.Series, *time.Location, error) {
+	timezone := query.Timezone
+	if timezone == "" {
+		timezone = "UTC"
+	}
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return domain.Series{}, nil, ErrInvalidTimezone
+	}
+
+	granularity := query.Granularity
+	if granularity == "" {
+		granularity = GranularityDay
+	}
+	bucketSize, ok := bucketSizes[granularity]
+	if !ok {
+		return domain.Series{}, nil, ErrInvalidGranularity
+	}
+
+	includeBots, err := parseIncludeBots(query.IncludeBots)
+	if err != nil {
+		return domain.Series{}, nil, err
+	}
+	traffic := domain.TrafficHumans
+	if includeBots {
+		traffic = domain.TrafficAll
+	}
+
+	to := now
+	if query.To != "" {
+		if to, err = parseTime(query.To, loc, true); err != nil {
+			return domain.Series{}, nil, err
+		}
+	}
+	from := to.Add(-defaultRange)
+	if query.From != "" {
+		if from, err = parseTime(query.From, loc, false); err != nil {
+			return domain.Series{}, nil, err
+		}
+	}
+
+	if !from.Before(to) {
+		return domain.Series{}, nil, ErrInvalidRange
+	}
+	if to.Sub(from)/bucketSize >= maxBuckets {
+		return domain.Series{}, nil, ErrRangeTooLarge
+	}
+
+	return domain.Series{
+		From:        from,
+		To:          to,
+		Timezone:    loc.String(),
+		Granularity: granularity,
+		Traffic:     traffic,
+	}, loc, nil
+}
+
+// parseIncludeBots parses whether bots are included, false when empty.
+func parseIncludeBots(value string) (bool, error) {
+	if value == "" {
+		return false, nil
+	}
+	includeBots, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, ErrInvalidIncludeBots
+	}
+	return includeBots, nil
+}
+
+// parseDimensions resolves a comma-separated list of dimensions, all of
+// them when empty. Without geo, clicks have no country to break down by.
+func parseDimensions(list string, geo bool) ([]string, error) {
+	if strings.TrimSpace(list) == "" {
+		if !geo {
+			return slices.DeleteFunc(slices.Clone(dimensions), func(dimension string) bool {
+				return dimension == domain.DimensionCountry
+			}), nil
+		}
+		return dimensions, nil
+	}
+
+	var requested []string
+	for _, dimension := range strings.Split(list, ",") {
+		dimension = strings.TrimSpace(dimension)
+		if !slices.Contains(dimensions, dimension) {
+			return nil, ErrInvalidDimension
+		}
+		if dimension == domain.DimensionCountry && !geo {
+			return nil, ErrGeoUnavailable
+		}
+		if !slices.Contains(requested, dimension) {
+			requested = append(requested, dimension)
+		}
+	}
+	return requested, nil
+}
+
+// parseTime parses an RFC 3339 time or a date in loc. A date ends the
+// range after its last moment when end is set.
+func parseTime(value string, loc *time.Location, end bool) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+
+	t, err := time.ParseInLocation(time.DateOnly, value, loc)
+	if err != nil {
+		return time.Time{}, ErrInvalidRange
+	}
+	if end {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t, nil
+}
+
+func mapToClicksAt(buckets []domain.ClickBucket, loc *time.Location) []dto.ClicksAt {
+	result := make([]dto.ClicksAt, 0, len(buckets))
+	for _, bucket := range buckets {
+		start := bucket.Start
+		start = time.Date(start.Year(), start.Month(), start.Day(), start.Hour(), start.Minute(), start.Second(), 0, loc)
+		result = append(result, dto.ClicksAt{
+			Time:         start.Format(time.RFC3339),
+			Clicks:       bucket.Clicks,
+			UniqueClicks: bucket.UniqueClicks,
+		})
+	}
+	return result
+}
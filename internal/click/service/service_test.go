@@ -3,20 +3,299 @@ package service_test
 import (
 	"context"
 	"errors"
+	"github.com/google/uuid"
 	"go.uber.org/mock/gomock"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
+	"github.com/ilam072/shortener/internal/auth"
 	"github.com/ilam072/shortener/internal/click/mocks"
+	clickrepo "github.com/ilam072/shortener/internal/click/repo"
 	"github.com/ilam072/shortener/internal/click/service"
 	"github.com/ilam072/shortener/internal/click/types/domain"
 	"github.com/ilam072/shortener/internal/click/types/dto"
+	"github.com/ilam072/shortener/internal/eventbus"
+	eventbusmocks "github.com/ilam072/shortener/internal/eventbus/mocks"
+	"github.com/ilam072/shortener/internal/eventbus/publisher"
+	webhookdomain "github.com/ilam072/shortener/internal/webhook/types/domain"
+	webhookdto "github.com/ilam072/shortener/internal/webhook/types/dto"
 )
 
+// newSalts returns a salt source that always has a salt.
+func newSalts(ctrl *gomock.Controller) *mocks.MockVisitorSalt {
+	salts := mocks.NewMockVisitorSalt(ctrl)
+	salts.EXPECT().
+		Salt(gomock.Any(), gomock.Any()).
+		Return("salt", nil).
+		AnyTimes()
+	return salts
+}
+
+// newEnricher returns an enricher without geo.
+func newEnricher(ctrl *gomock.Controller) *service.Enricher {
+	return service.NewEnricher(newSalts(ctrl), nil, newBots(ctrl, false), service.IPModeTruncate)
+}
+
+// newFeed returns a click feed without subscribers.
+func newFeed(ctrl *gomock.Controller) *mocks.MockClickFeed {
+	feed := mocks.NewMockClickFeed(ctrl)
+	feed.EXPECT().
+		Publish(gomock.Any()).
+		AnyTimes()
+	return feed
+}
+
+// newEvents returns click events for tests that do not check them.
+func newEvents(ctrl *gomock.Controller) *mocks.MockClickEvents {
+	events := mocks.NewMockClickEvents(ctrl)
+	events.EXPECT().
+		Emit(gomock.Any()).
+		AnyTimes()
+	return events
+}
+
+// newBots returns a bot classifier telling every user agent the same.
+func newBots(ctrl *gomock.Controller, isBot bool) *mocks.MockBotClassifier {
+	bots := mocks.NewMockBotClassifier(ctrl)
+	bots.EXPECT().
+		IsBot(gomock.Any()).
+		Return(isBot).
+		AnyTimes()
+	return bots
+}
+
+func TestClickService_SaveClick_Location(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	location := domain.Location{Country: "DE", Region: "Berlin", City: "Berlin"}
+	mockGeo := mocks.NewMockGeoLocator(ctrl)
+	mockGeo.EXPECT().
+		Locate("203.0.113.7").
+		Return(location)
+
+	mockRepo := mocks.NewMockClickRepo(ctrl)
+	mockRepo.EXPECT().
+		CreateClick(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, click domain.Click) error {
+			require.Equal(t, location, click.Location)
+			return nil
+		})
+
+	svc := service.New(mockRepo, mocks.NewMockClickOutbox(ctrl), service.NewEnricher(newSalts(ctrl), mockGeo, newBots(ctrl, false), service.IPModeTruncate), newFeed(ctrl), newEvents(ctrl), publisher.Noop{})
+
+	require.NoError(t, svc.SaveClick(context.Background(), dto.Click{Alias: "abc", IP: "203.0.113.7"}))
+}
+
+func TestClickService_SaveClick_Events(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockGeo := mocks.NewMockGeoLocator(ctrl)
+	mockGeo.EXPECT().
+		Locate(gomock.Any()).
+		Return(domain.Location{Country: "DE"})
+
+	mockRepo := mocks.NewMockClickRepo(ctrl)
+	mockRepo.EXPECT().
+		CreateClick(gomock.Any(), gomock.Any()).
+		Return(nil)
+
+	linkID, workspaceID := uuid.New(), uuid.New()
+	var event webhookdto.Event
+	mockEvents := mocks.NewMockClickEvents(ctrl)
+	mockEvents.EXPECT().
+		Emit(gomock.Any()).
+		Do(func(e webhookdto.Event) { event = e })
+	var published eventbus.Event
+	mockPublisher := eventbusmocks.NewMockEventPublisher(ctrl)
+	mockPublisher.EXPECT().
+		Publish(gomock.Any()).
+		Do(func(e eventbus.Event) { published = e })
+
+	enricher := service.NewEnricher(newSalts(ctrl), mockGeo, newBots(ctrl, false), service.IPModeTruncate)
+	svc := service.New(mockRepo, mocks.NewMockClickOutbox(ctrl), enricher, newFeed(ctrl), mockEvents, mockPublisher)
+
+	require.NoError(t, svc.SaveClick(context.Background(), dto.Click{
+		LinkID:      linkID,
+		WorkspaceID: workspaceID,
+		Alias:       "abc",
+		Device:      "mobile",
+		Client:      "Chrome",
+		Referrer:    "news.example",
+		IP:          "203.0.113.7",
+	}))
+
+	require.Equal(t, webhookdomain.EventLinkClicked, event.Type)
+	require.Equal(t, workspaceID, event.WorkspaceID)
+	require.Equal(t, webhookdto.EventLink{ID: linkID, Alias: "abc"}, event.Link)
+	require.NotNil(t, event.Click)
+	require.Equal(t, "mobile", event.Click.Device)
+	require.Equal(t, "Chrome", event.Click.Client)
+	require.Equal(t, "news.example", event.Click.Referrer)
+	require.Equal(t, "DE", event.Click.Country)
+	require.False(t, event.Click.Bot)
+
+	require.Equal(t, eventbus.TypeLinkClicked, published.Type)
+	require.Equal(t, workspaceID, published.WorkspaceID)
+	require.Equal(t, "abc", published.Key())
+	require.Equal(t, event.Click.ClickedAt, published.OccurredAt)
+	require.NotNil(t, published.Click)
+	require.Equal(t, "DE", published.Click.Country)
+	require.NotEmpty(t, published.Click.VisitorID)
+}
+
+func TestClickService_SaveClick_Bots(t *testing.T) {
+	tests := []struct {
+		name       string
+		device     string
+		classified bool
+		want       bool
+	}{
+		{name: "human", device: "desktop", want: false},
+		{name: "flagged by user agent library", device: "bot", want: true},
+		{name: "matched by classifier", device: "desktop", classified: true, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockRepo := mocks.NewMockClickRepo(ctrl)
+			mockRepo.EXPECT().
+				CreateClick(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ context.Context, click domain.Click) error {
+					require.Equal(t, tt.want, click.IsBot)
+					return nil
+				})
+
+			enricher := service.NewEnricher(newSalts(ctrl), nil, newBots(ctrl, tt.classified), service.IPModeTruncate)
+			svc := service.New(mockRepo, mocks.NewMockClickOutbox(ctrl), enricher, newFeed(ctrl), newEvents(ctrl), publisher.Noop{})
+
+			require.NoError(t, svc.SaveClick(context.Background(), dto.Click{Alias: "abc", Device: tt.device}))
+		})
+	}
+}
+
+func TestClickService_SaveClick_IPMode(t *testing.T) {
+	tests := []struct {
+		name   string
+		ipMode string
+		ip     string
+		want   string
+	}{
+		{name: "full", ipMode: service.IPModeFull, ip: "203.0.113.7", want: "203.0.113.7"},
+		{name: "truncate ipv4", ipMode: service.IPModeTruncate, ip: "203.0.113.7", want: "203.0.113.0"},
+		{name: "truncate ipv6", ipMode: service.IPModeTruncate, ip: "2001:db8:1234:5678:9abc:def0:1234:5678", want: "2001:db8:1234::"},
+		{name: "truncate mapped ipv4", ipMode: service.IPModeTruncate, ip: "::ffff:203.0.113.7", want: "203.0.113.0"},
+		{name: "truncate invalid", ipMode: service.IPModeTruncate, ip: "unknown", want: ""},
+		{name: "drop", ipMode: service.IPModeDrop, ip: "203.0.113.7", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockRepo := mocks.NewMockClickRepo(ctrl)
+			mockRepo.EXPECT().
+				CreateClick(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ context.Context, click domain.Click) error {
+					require.Equal(t, tt.want, click.IP)
+					return nil
+				})
+
+			enricher := service.NewEnricher(newSalts(ctrl), nil, newBots(ctrl, false), tt.ipMode)
+			svc := service.New(mockRepo, mocks.NewMockClickOutbox(ctrl), enricher, newFeed(ctrl), newEvents(ctrl), publisher.Noop{})
+
+			require.NoError(t, svc.SaveClick(context.Background(), dto.Click{Alias: "abc", IP: tt.ip}))
+		})
+	}
+}
+
+func TestClickService_SaveClick_IPModeHash(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var ips []string
+	mockRepo := mocks.NewMockClickRepo(ctrl)
+	mockRepo.EXPECT().
+		CreateClick(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, click domain.Click) error {
+			ips = append(ips, click.IP)
+			return nil
+		}).
+		Times(3)
+
+	svc := service.New(mockRepo, mocks.NewMockClickOutbox(ctrl), service.NewEnricher(newSalts(ctrl), nil, newBots(ctrl, false), service.IPModeHash), newFeed(ctrl), newEvents(ctrl), publisher.Noop{})
+
+	for _, ip := range []string{"203.0.113.7", "203.0.113.7", "203.0.113.8"} {
+		require.NoError(t, svc.SaveClick(context.Background(), dto.Click{Alias: "abc", IP: ip}))
+	}
+
+	require.Len(t, ips[0], 64)
+	require.Equal(t, ips[0], ips[1])
+	require.NotEqual(t, ips[0], ips[2])
+}
+
+func TestClickService_SaveClick_VisitorID(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var visitors []string
+	mockRepo := mocks.NewMockClickRepo(ctrl)
+	mockRepo.EXPECT().
+		CreateClick(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, click domain.Click) error {
+			visitors = append(visitors, click.VisitorID)
+			return nil
+		}).
+		Times(3)
+
+	svc := service.New(mockRepo, mocks.NewMockClickOutbox(ctrl), newEnricher(ctrl), newFeed(ctrl), newEvents(ctrl), publisher.Noop{})
+
+	for _, click := range []dto.Click{
+		{Alias: "abc", IP: "127.0.0.1", UserAgent: "ua"},
+		{Alias: "abc", IP: "127.0.0.1", UserAgent: "ua"},
+		{Alias: "abc", IP: "127.0.0.2", UserAgent: "ua"},
+	} {
+		require.NoError(t, svc.SaveClick(context.Background(), click))
+	}
+
+	require.NotEmpty(t, visitors[0])
+	require.NotContains(t, visitors[0], "127.0.0.1")
+	require.Equal(t, visitors[0], visitors[1])
+	require.NotEqual(t, visitors[0], visitors[2])
+}
+
+func TestClickService_SaveClick_WithoutSalt(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockSalts := mocks.NewMockVisitorSalt(ctrl)
+	mockSalts.EXPECT().
+		Salt(gomock.Any(), gomock.Any()).
+		Return("", errors.New("redis error"))
+
+	mockRepo := mocks.NewMockClickRepo(ctrl)
+	mockRepo.EXPECT().
+		CreateClick(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, click domain.Click) error {
+			require.Empty(t, click.VisitorID)
+			return nil
+		})
+
+	svc := service.New(mockRepo, mocks.NewMockClickOutbox(ctrl), service.NewEnricher(mockSalts, nil, newBots(ctrl, false), service.IPModeTruncate), newFeed(ctrl), newEvents(ctrl), publisher.Noop{})
+
+	require.NoError(t, svc.SaveClick(context.Background(), dto.Click{Alias: "abc", IP: "127.0.0.1"}))
+}
+
 func TestClickService_SaveClick(t *testing.T) {
 	type fields struct {
-		setup func(repo *mocks.MockClickRepo)
+		setup func(repo *mocks.MockClickRepo, outbox *mocks.MockClickOutbox)
 	}
 	type args struct {
 		click dto.Click
@@ -34,7 +313,7 @@ func TestClickService_SaveClick(t *testing.T) {
 		{
 			name: "success",
 			fields: fields{
-				setup: func(repo *mocks.MockClickRepo) {
+				setup: func(repo *mocks.MockClickRepo, outbox *mocks.MockClickOutbox) {
 					repo.EXPECT().
 						CreateClick(gomock.Any(), gomock.AssignableToTypeOf(domain.Click{})).
 						Return(nil)
@@ -49,15 +328,44 @@ func TestClickService_SaveClick(t *testing.T) {
 					IP:        "127.0.0.1",
 				},
 			},
-			want: want{err: false},
+			want: want{},
 		},
 		{
-			name: "repo error",
+			name: "repo error, buffered",
 			fields: fields{
-				setup: func(repo *mocks.MockClickRepo) {
+				setup: func(repo *mocks.MockClickRepo, outbox *mocks.MockClickOutbox) {
+					var stored domain.Click
+					repo.EXPECT().
+						CreateClick(gomock.Any(), gomock.Any()).
+						DoAndReturn(func(_ context.Context, click domain.Click) error {
+							stored = click
+							return errors.New("db error")
+						})
+					outbox.EXPECT().
+						Append(gomock.Any(), gomock.Any()).
+						DoAndReturn(func(_ context.Context, clicks []domain.Click) error {
+							require.Equal(t, []domain.Click{stored}, clicks)
+							return nil
+						})
+				},
+			},
+			args: args{
+				click: dto.Click{
+					Alias: "abc",
+				},
+			},
+			want: want{},
+		},
+		{
+			name: "repo and outbox error",
+			fields: fields{
+				setup: func(repo *mocks.MockClickRepo, outbox *mocks.MockClickOutbox) {
 					repo.EXPECT().
 						CreateClick(gomock.Any(), gomock.Any()).
 						Return(errors.New("db error"))
+					outbox.EXPECT().
+						Append(gomock.Any(), gomock.Any()).
+						Return(errors.New("redis error"))
 				},
 			},
 			args: args{
@@ -75,12 +383,13 @@ func TestClickService_SaveClick(t *testing.T) {
 			defer ctrl.Finish()
 
 			mockRepo := mocks.NewMockClickRepo(ctrl)
+			mockOutbox := mocks.NewMockClickOutbox(ctrl)
 
 			if tt.fields.setup != nil {
-				tt.fields.setup(mockRepo)
+				tt.fields.setup(mockRepo, mockOutbox)
 			}
 
-			svc := service.New(mockRepo)
+			svc := service.New(mockRepo, mockOutbox, newEnricher(ctrl), newFeed(ctrl), newEvents(ctrl), publisher.Noop{})
 
 			err := svc.SaveClick(context.Background(), tt.args.click)
 
@@ -94,92 +403,247 @@ func TestClickService_SaveClick(t *testing.T) {
 }
 
 func TestClickService_GetClicksSummary(t *testing.T) {
+	workspaceID := uuid.New()
+	linkID := uuid.New()
+	access := auth.Access{Workspace: auth.Workspace{ID: workspaceID, Slug: "acme"}, All: true}
+	query := dto.ClicksQuery{From: "2025-01-01", To: "2025-01-02", Timezone: "Europe/Moscow", Granularity: "day"}
+
 	type fields struct {
 		setup func(repo *mocks.MockClickRepo)
 	}
 	type want struct {
-		err bool
+		series []dto.ClicksAt
+		limit  *dto.ClickLimit
+		check  func(t *testing.T, res dto.GetClicks)
+		err    error
 	}
 
 	tests := []struct {
 		name   string
 		alias  string
+		query  dto.ClicksQuery
 		fields fields
 		want   want
 	}{
 		{
 			name:  "success",
 			alias: "abc",
+			query: query,
 			fields: fields{
 				setup: func(repo *mocks.MockClickRepo) {
 					repo.EXPECT().
-						GetClicksByDay(gomock.Any(), "abc").
-						Return([]domain.ClickRow{
-							{Aggregation: "2025-01-01", Clicks: 10},
-						}, nil)
+						GetClickSeries(gomock.Any(), workspaceID, linkID, gomock.Any()).
+						DoAndReturn(func(_ context.Context, _, _ uuid.UUID, series domain.Series) ([]domain.ClickBucket, error) {
+							require.Equal(t, "2024-12-31T21:00:00Z", series.From.UTC().Format(time.RFC3339))
+							require.Equal(t, "2025-01-02T21:00:00Z", series.To.UTC().Format(time.RFC3339))
+							require.Equal(t, "Europe/Moscow", series.Timezone)
+							require.Equal(t, service.GranularityDay, series.Granularity)
+							return []domain.ClickBucket{
+								{Start: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), Clicks: 10, UniqueClicks: 4},
+								{Start: time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC), Clicks: 0, UniqueClicks: 0},
+							}, nil
+						})
 
 					repo.EXPECT().
-						GetClicksByMonth(gomock.Any(), "abc").
-						Return([]domain.ClickRow{
-							{Aggregation: "2025-01", Clicks: 100},
-						}, nil)
+						GetClickBreakdown(gomock.Any(), workspaceID, linkID, gomock.Any(), gomock.Any()).
+						DoAndReturn(func(_ context.Context, _, _ uuid.UUID, dimension string, series domain.Series) ([]domain.ClickRow, error) {
+							if series.Traffic == domain.TrafficBots {
+								return []domain.ClickRow{
+									{Aggregation: "Googlebot", Clicks: 7, UniqueClicks: 3},
+									{Aggregation: "Twitterbot", Clicks: 2, UniqueClicks: 1},
+								}, nil
+							}
+							require.Equal(t, domain.TrafficHumans, series.Traffic)
+							return []domain.ClickRow{{Aggregation: dimension, Clicks: 50, UniqueClicks: 20}}, nil
+						}).
+						Times(5)
 
 					repo.EXPECT().
-						GetClicksByUserAgent(gomock.Any(), "abc").
-						Return([]domain.ClickRow{
-							{Aggregation: "chrome", Clicks: 50},
-						}, nil)
+						GetLink(gomock.Any(), access, "", "abc").
+						Return(domain.Link{ID: linkID}, nil)
+				},
+			},
+			want: want{
+				series: []dto.ClicksAt{
+					{Time: "2025-01-01T00:00:00+03:00", Clicks: 10, UniqueClicks: 4},
+					{Time: "2025-01-02T00:00:00+03:00", Clicks: 0, UniqueClicks: 0},
+				},
+				check: func(t *testing.T, res dto.GetClicks) {
+					require.Equal(t, []dto.ClicksByUserAgent{{UserAgent: "user_agent", Clicks: 50, UniqueClicks: 20}}, res.ByUserAgent)
+					require.Equal(t, []dto.ClicksByDevice{{Device: "device", Clicks: 50, UniqueClicks: 20}}, res.ByDevice)
+					require.Equal(t, []dto.ClicksByOS{{OS: "os", Clicks: 50, UniqueClicks: 20}}, res.ByOS)
+					require.Equal(t, []dto.ClicksByReferrer{{Referrer: "referrer", Clicks: 50, UniqueClicks: 20}}, res.ByReferrer)
+					require.False(t, res.IncludeBots)
+					require.Equal(t, 9, res.Bots.Clicks)
+					require.Equal(t, 4, res.Bots.UniqueClicks)
+					require.Len(t, res.Bots.ByUserAgent, 2)
 				},
 			},
-			want: want{err: false},
 		},
 		{
-			name:  "error on get by day",
+			name:  "exhausted click limit, device only",
 			alias: "abc",
+			query: dto.ClicksQuery{From: query.From, To: query.To, Dimensions: "device"},
 			fields: fields{
 				setup: func(repo *mocks.MockClickRepo) {
+					maxClicks := 1
+					repo.EXPECT().GetClickSeries(gomock.Any(), workspaceID, linkID, gomock.Any()).Return(nil, nil)
 					repo.EXPECT().
-						GetClicksByDay(gomock.Any(), "abc").
-						Return(nil, errors.New("db error"))
+						GetClickBreakdown(gomock.Any(), workspaceID, linkID, domain.DimensionDevice, gomock.Any()).
+						Return(nil, nil)
+					repo.EXPECT().
+						GetClickBreakdown(gomock.Any(), workspaceID, linkID, domain.DimensionUserAgent, gomock.Any()).
+						Return(nil, nil)
+					repo.EXPECT().
+						GetLink(gomock.Any(), access, "", "abc").
+						Return(domain.Link{ID: linkID, MaxClicks: &maxClicks, Used: 1}, nil)
 				},
 			},
-			want: want{err: true},
+			want: want{
+				series: []dto.ClicksAt{},
+				limit:  &dto.ClickLimit{MaxClicks: 1, Used: 1, Exhausted: true},
+				check: func(t *testing.T, res dto.GetClicks) {
+					require.NotNil(t, res.ByDevice)
+					require.Nil(t, res.ByUserAgent)
+					require.Nil(t, res.ByOS)
+					require.Nil(t, res.ByReferrer)
+				},
+			},
+		},
+		{
+			name:  "unknown timezone",
+			alias: "abc",
+			query: dto.ClicksQuery{Timezone: "Mars/Olympus"},
+			want:  want{err: service.ErrInvalidTimezone},
+		},
+		{
+			name:  "unknown granularity",
+			alias: "abc",
+			query: dto.ClicksQuery{Granularity: "minute"},
+			want:  want{err: service.ErrInvalidGranularity},
+		},
+		{
+			name:  "malformed date",
+			alias: "abc",
+			query: dto.ClicksQuery{From: "01.01.2025"},
+			want:  want{err: service.ErrInvalidRange},
+		},
+		{
+			name:  "from after to",
+			alias: "abc",
+			query: dto.ClicksQuery{From: "2025-02-01", To: "2025-01-01"},
+			want:  want{err: service.ErrInvalidRange},
+		},
+		{
+			name:  "range too large",
+			alias: "abc",
+			query: dto.ClicksQuery{From: "2023-01-01", To: "2025-01-01", Granularity: "hour"},
+			want:  want{err: service.ErrRangeTooLarge},
+		},
+		{
+			name:  "unknown dimension",
+			alias: "abc",
+			query: dto.ClicksQuery{Dimensions: "device,planet"},
+			want:  want{err: service.ErrInvalidDimension},
+		},
+		{
+			name:  "country without geoip",
+			alias: "abc",
+			query: dto.ClicksQuery{Dimensions: "country"},
+			want:  want{err: service.ErrGeoUnavailable},
 		},
 		{
-			name:  "error on get by month",
+			name:  "malformed include_bots",
 			alias: "abc",
+			query: dto.ClicksQuery{IncludeBots: "maybe"},
+			want:  want{err: service.ErrInvalidIncludeBots},
+		},
+		{
+			name:  "including bots",
+			alias: "abc",
+			query: dto.ClicksQuery{From: query.From, To: query.To, Dimensions: "os", IncludeBots: "true"},
 			fields: fields{
 				setup: func(repo *mocks.MockClickRepo) {
 					repo.EXPECT().
-						GetClicksByDay(gomock.Any(), "abc").
-						Return(nil, nil)
+						GetLink(gomock.Any(), access, "", "abc").
+						Return(domain.Link{ID: linkID}, nil)
+					repo.EXPECT().
+						GetClickSeries(gomock.Any(), workspaceID, linkID, gomock.Any()).
+						DoAndReturn(func(_ context.Context, _, _ uuid.UUID, series domain.Series) ([]domain.ClickBucket, error) {
+							require.Equal(t, domain.TrafficAll, series.Traffic)
+							return nil, nil
+						})
+					repo.EXPECT().
+						GetClickBreakdown(gomock.Any(), workspaceID, linkID, domain.DimensionOS, gomock.Any()).
+						DoAndReturn(func(_ context.Context, _, _ uuid.UUID, _ string, series domain.Series) ([]domain.ClickRow, error) {
+							require.Equal(t, domain.TrafficAll, series.Traffic)
+							return nil, nil
+						})
+					repo.EXPECT().
+						GetClickBreakdown(gomock.Any(), workspaceID, linkID, domain.DimensionUserAgent, gomock.Any()).
+						DoAndReturn(func(_ context.Context, _, _ uuid.UUID, _ string, series domain.Series) ([]domain.ClickRow, error) {
+							require.Equal(t, domain.TrafficBots, series.Traffic)
+							return nil, nil
+						})
+				},
+			},
+			want: want{
+				series: []dto.ClicksAt{},
+				check: func(t *testing.T, res dto.GetClicks) {
+					require.True(t, res.IncludeBots)
+				},
+			},
+		},
+		{
+			name:  "alias not visible",
+			alias: "abc",
+			query: query,
+			fields: fields{
+				setup: func(repo *mocks.MockClickRepo) {
+					repo.EXPECT().
+						GetLink(gomock.Any(), access, "", "abc").
+						Return(domain.Link{}, clickrepo.ErrAliasNotFound)
+				},
+			},
+			want: want{err: service.ErrAliasNotFound},
+		},
+		{
+			name:  "error on get series",
+			alias: "abc",
+			query: query,
+			fields: fields{
+				setup: func(repo *mocks.MockClickRepo) {
+					repo.EXPECT().
+						GetLink(gomock.Any(), access, "", "abc").
+						Return(domain.Link{ID: linkID}, nil)
 
 					repo.EXPECT().
-						GetClicksByMonth(gomock.Any(), "abc").
+						GetClickSeries(gomock.Any(), workspaceID, linkID, gomock.Any()).
 						Return(nil, errors.New("db error"))
 				},
 			},
-			want: want{err: true},
+			want: want{err: errors.New("db error")},
 		},
 		{
-			name:  "error on get by user agent",
+			name:  "error on get breakdown",
 			alias: "abc",
+			query: query,
 			fields: fields{
 				setup: func(repo *mocks.MockClickRepo) {
 					repo.EXPECT().
-						GetClicksByDay(gomock.Any(), "abc").
-						Return(nil, nil)
+						GetLink(gomock.Any(), access, "", "abc").
+						Return(domain.Link{ID: linkID}, nil)
 
 					repo.EXPECT().
-						GetClicksByMonth(gomock.Any(), "abc").
+						GetClickSeries(gomock.Any(), workspaceID, linkID, gomock.Any()).
 						Return(nil, nil)
 
 					repo.EXPECT().
-						GetClicksByUserAgent(gomock.Any(), "abc").
+						GetClickBreakdown(gomock.Any(), workspaceID, linkID, domain.DimensionUserAgent, gomock.Any()).
 						Return(nil, errors.New("db error"))
 				},
 			},
-			want: want{err: true},
+			want: want{err: errors.New("db error")},
 		},
 	}
 
@@ -194,18 +658,55 @@ func TestClickService_GetClicksSummary(t *testing.T) {
 				tt.fields.setup(mockRepo)
 			}
 
-			svc := service.New(mockRepo)
+			svc := service.New(mockRepo, mocks.NewMockClickOutbox(ctrl), newEnricher(ctrl), newFeed(ctrl), newEvents(ctrl), publisher.Noop{})
 
-			res, err := svc.GetClicksSummary(context.Background(), tt.alias)
+			res, err := svc.GetClicksSummary(context.Background(), access, "", tt.alias, tt.query)
 
-			if tt.want.err {
-				require.Error(t, err)
+			if tt.want.err != nil {
+				require.ErrorContains(t, err, tt.want.err.Error())
 				require.Empty(t, res)
 				return
 			}
 
 			require.NoError(t, err)
 			require.Equal(t, tt.alias, res.Alias)
+			require.Equal(t, tt.want.series, res.Series)
+			require.Equal(t, tt.want.limit, res.Limit)
+			if tt.want.check != nil {
+				tt.want.check(t, res)
+			}
 		})
 	}
 }
+
+func TestClickService_GetClicksSummary_ByCountry(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	workspaceID := uuid.New()
+	linkID := uuid.New()
+	access := auth.Access{Workspace: auth.Workspace{ID: workspaceID, Slug: "acme"}, All: true}
+
+	mockRepo := mocks.NewMockClickRepo(ctrl)
+	mockRepo.EXPECT().
+		GetLink(gomock.Any(), access, "", "abc").
+		Return(domain.Link{ID: linkID}, nil)
+	mockRepo.EXPECT().
+		GetClickSeries(gomock.Any(), workspaceID, linkID, gomock.Any()).
+		Return(nil, nil)
+	mockRepo.EXPECT().
+		GetClickBreakdown(gomock.Any(), workspaceID, linkID, domain.DimensionCountry, gomock.Any()).
+		Return([]domain.ClickRow{{Aggregation: "DE", Clicks: 3, UniqueClicks: 2}}, nil)
+	mockRepo.EXPECT().
+		GetClickBreakdown(gomock.Any(), workspaceID, linkID, gomock.Any(), gomock.Any()).
+		Return(nil, nil).
+		Times(5)
+
+	enricher := service.NewEnricher(newSalts(ctrl), mocks.NewMockGeoLocator(ctrl), newBots(ctrl, false), service.IPModeTruncate)
+	svc := service.New(mockRepo, mocks.NewMockClickOutbox(ctrl), enricher, newFeed(ctrl), newEvents(ctrl), publisher.Noop{})
+
+	res, err := svc.GetClicksSummary(context.Background(), access, "", "abc", dto.ClicksQuery{})
+
+	require.NoError(t, err)
+	require.Equal(t, []dto.ClicksByCountry{{Country: "DE", Clicks: 3, UniqueClicks: 2}}, res.ByCountry)
+}
@@ -2,18 +2,131 @@ package domain
 
 import (
 	"github.com/google/uuid"
+	"time"
 )
 
 type Click struct {
+	ID            uuid.UUID
+	LinkID        uuid.UUID
+	WorkspaceID   uuid.UUID
+	Alias         string
+	UserAgent     string
+	Client        string
+	ClientVersion string
+	Device        string
+	OS            string
+	OSVersion     string
+	// Referrer is the host of the Referer, empty for direct visits.
+	Referrer string
+	IP       string
+	Location Location
+	IsBot    bool
+	// VisitorID is the salted hash of IP and UserAgent, empty when the
+	// salt of the day was not available.
+	VisitorID string
+	ClickedAt time.Time
+}
+
+// BufferedClick is a click waiting in the outbox under EntryID.
+type BufferedClick struct {
+	EntryID string
+	Click   Click
+}
+
+// Link is the part of a link the analytics need: its id to select the
+// clicks by, and its click limit.
+type Link struct {
 	ID        uuid.UUID
-	Alias     string
-	UserAgent string
-	Client    string
+	MaxClicks *int
+	Used      int
+}
+
+// Breakdown dimensions, the click attributes clicks can be counted by.
+const (
+	DimensionUserAgent = "user_agent"
+	DimensionDevice    = "device"
+	DimensionOS        = "os"
+	DimensionReferrer  = "referrer"
+	DimensionCountry   = "country"
+)
+
+// Location is where an ip is, as far as the geoip database knows. Country
+// is an ISO 3166-1 alpha-2 code.
+type Location struct {
+	Country string
+	Region  string
+	City    string
+}
+
+// Traffic selects clicks by whether they come from bots.
+const (
+	TrafficHumans = "humans"
+	TrafficBots   = "bots"
+	TrafficAll    = "all"
+)
+
+// Series selects the clicks of Traffic in [From, To) counted per
+// Granularity bucket, where buckets start at Granularity boundaries in
+// Timezone.
+type Series struct {
+	From        time.Time
+	To          time.Time
+	Timezone    string
+	Granularity string
+	Traffic     string
+}
+
+// ClickBucket is a bucket of a series. Start is the wall-clock time the
+// bucket starts at in the timezone of the series.
+type ClickBucket struct {
+	Start        time.Time
+	Clicks       int
+	UniqueClicks int
+}
+
+// LiveClick is what live streams show of a click as it happens.
+type LiveClick struct {
+	LinkID    uuid.UUID
+	ClickedAt time.Time
 	Device    string
-	IP        string
+	Client    string
+	Country   string
+	IsBot     bool
+}
+
+// ClickPartition is a partition of the clicks, holding the ones before To.
+// DetachPending is set while it is being detached.
+type ClickPartition struct {
+	Name          string
+	To            time.Time
+	DetachPending bool
 }
 
 type ClickRow struct {
-	Aggregation string
-	Clicks      int
+	Aggregation  string
+	Clicks       int
+	UniqueClicks int
+}
+
+// TopLink is a link of a workspace with its clicks in a range.
+type TopLink struct {
+	Alias string
+	// Domain is the custom domain of the link, empty for the default
+	// hosts.
+	Domain       string
+	URL          string
+	Clicks       int
+	UniqueClicks int
+}
+
+// LinkBucket is the number of links created in a bucket of a series.
+type LinkBucket struct {
+	Start time.Time
+	Links int
+}
+
+// LinkDomain is the number of links to a destination host.
+type LinkDomain struct {
+	Domain string
+	Links  int
 }
@@ -1,31 +1,197 @@
 package dto
 
+import "github.com/google/uuid"
+
+// ClicksQuery narrows the analytics of a link. From and To are RFC 3339
+// times or dates in Timezone, To being inclusive for dates. Dimensions is
+// a comma-separated list of the breakdowns to return, all of them when
+// empty. Bot clicks are left out unless IncludeBots is true.
+type ClicksQuery struct {
+	From        string
+	To          string
+	Timezone    string
+	Granularity string
+	Dimensions  string
+	IncludeBots string
+}
+
+// ExportQuery selects the table of an export and the clicks it covers.
+type ExportQuery struct {
+	Table       string
+	From        string
+	To          string
+	Timezone    string
+	Granularity string
+	IncludeBots string
+}
+
 type Click struct {
-	Alias     string `json:"alias"`
-	UserAgent string `json:"user_agent"`
-	Client    string `json:"client"`
-	Device    string `json:"device"`
-	IP        string `json:"ip"`
+	LinkID        uuid.UUID `json:"link_id"`
+	WorkspaceID   uuid.UUID `json:"workspace_id"`
+	Alias         string    `json:"alias"`
+	UserAgent     string    `json:"user_agent"`
+	Client        string    `json:"client"`
+	ClientVersion string    `json:"client_version"`
+	Device        string    `json:"device"`
+	OS            string    `json:"os"`
+	OSVersion     string    `json:"os_version"`
+	Referrer      string    `json:"referrer"`
+	IP            string    `json:"ip"`
 }
 
 type GetClicks struct {
 	Alias       string              `json:"alias"`
-	ByDay       []ClicksByDay       `json:"by_day"`
-	ByMonth     []ClicksByMonth     `json:"by_month"`
+	From        string              `json:"from"`
+	To          string              `json:"to"`
+	Timezone    string              `json:"tz"`
+	Granularity string              `json:"granularity"`
+	IncludeBots bool                `json:"include_bots"`
+	Series      []ClicksAt          `json:"series"`
 	ByUserAgent []ClicksByUserAgent `json:"by_user_agent"`
+	ByDevice    []ClicksByDevice    `json:"by_device"`
+	ByOS        []ClicksByOS        `json:"by_os"`
+	ByReferrer  []ClicksByReferrer  `json:"by_referrer"`
+	ByCountry   []ClicksByCountry   `json:"by_country"`
+	Bots        BotTraffic          `json:"bots"`
+	Limit       *ClickLimit         `json:"limit,omitempty"`
 }
 
-type ClicksByDay struct {
-	Date   string `json:"date"`
-	Clicks int    `json:"clicks"`
+type ClickLimit struct {
+	MaxClicks int  `json:"max_clicks"`
+	Used      int  `json:"used"`
+	Exhausted bool `json:"exhausted"`
 }
 
-type ClicksByMonth struct {
-	Month  string `json:"month"`
-	Clicks int    `json:"clicks"`
+// ClicksAt is the number of clicks in the bucket starting at Time.
+// Visitors get a new identity every day, so UniqueClicks of buckets longer
+// than a day count a visitor once per day.
+type ClicksAt struct {
+	Time         string `json:"time"`
+	Clicks       int    `json:"clicks"`
+	UniqueClicks int    `json:"unique_clicks"`
 }
 
 type ClicksByUserAgent struct {
-	UserAgent string `json:"user_agent"`
-	Clicks    int    `json:"clicks"`
+	UserAgent    string `json:"user_agent"`
+	Clicks       int    `json:"clicks"`
+	UniqueClicks int    `json:"unique_clicks"`
+}
+
+type ClicksByDevice struct {
+	Device       string `json:"device"`
+	Clicks       int    `json:"clicks"`
+	UniqueClicks int    `json:"unique_clicks"`
+}
+
+type ClicksByOS struct {
+	OS           string `json:"os"`
+	Clicks       int    `json:"clicks"`
+	UniqueClicks int    `json:"unique_clicks"`
+}
+
+// ClicksByReferrer counts clicks by the host of the referring page, empty
+// for direct visits.
+type ClicksByReferrer struct {
+	Referrer     string `json:"referrer"`
+	Clicks       int    `json:"clicks"`
+	UniqueClicks int    `json:"unique_clicks"`
+}
+
+// ClicksByCountry counts clicks by ISO 3166-1 alpha-2 country code, empty
+// for unknown locations.
+type ClicksByCountry struct {
+	Country      string `json:"country"`
+	Clicks       int    `json:"clicks"`
+	UniqueClicks int    `json:"unique_clicks"`
+}
+
+// BotTraffic sums up the clicks of bots in the range, whether or not they
+// are included in the other figures.
+type BotTraffic struct {
+	Clicks       int                 `json:"clicks"`
+	UniqueClicks int                 `json:"unique_clicks"`
+	ByUserAgent  []ClicksByUserAgent `json:"by_user_agent"`
+}
+
+// LiveClick is a click event of a live stream.
+type LiveClick struct {
+	Time    string `json:"time"`
+	Device  string `json:"device"`
+	Client  string `json:"client"`
+	Country string `json:"country,omitempty"`
+	Bot     bool   `json:"bot"`
+}
+
+// DroppedClicks tells a live stream missed clicks it did not read in time.
+type DroppedClicks struct {
+	Dropped int64 `json:"dropped"`
+}
+
+// DashboardQuery narrows the analytics of a workspace like ClicksQuery
+// does those of a link. Limit caps the number of rows of rankings.
+type DashboardQuery struct {
+	From        string
+	To          string
+	Timezone    string
+	Granularity string
+	IncludeBots string
+	Limit       string
+}
+
+// GetTopLinks ranks the links of a workspace by their clicks in the range.
+type GetTopLinks struct {
+	From        string    `json:"from"`
+	To          string    `json:"to"`
+	Timezone    string    `json:"tz"`
+	IncludeBots bool      `json:"include_bots"`
+	Links       []TopLink `json:"links"`
+}
+
+type TopLink struct {
+	Alias        string `json:"alias"`
+	Domain       string `json:"domain,omitempty"`
+	URL          string `json:"url"`
+	Clicks       int    `json:"clicks"`
+	UniqueClicks int    `json:"unique_clicks"`
+}
+
+// GetWorkspaceClicks is the series of the clicks of all the links of a
+// workspace. A visitor of several links counts once per link.
+type GetWorkspaceClicks struct {
+	From         string     `json:"from"`
+	To           string     `json:"to"`
+	Timezone     string     `json:"tz"`
+	Granularity  string     `json:"granularity"`
+	IncludeBots  bool       `json:"include_bots"`
+	Clicks       int        `json:"clicks"`
+	UniqueClicks int        `json:"unique_clicks"`
+	Series       []ClicksAt `json:"series"`
+}
+
+// GetCreatedLinks is the series of the links created in a workspace.
+type GetCreatedLinks struct {
+	From        string    `json:"from"`
+	To          string    `json:"to"`
+	Timezone    string    `json:"tz"`
+	Granularity string    `json:"granularity"`
+	Links       int       `json:"links"`
+	Series      []LinksAt `json:"series"`
+}
+
+// LinksAt is the number of links created in the bucket starting at Time.
+type LinksAt struct {
+	Time  string `json:"time"`
+	Links int    `json:"links"`
+}
+
+// GetLinkDomains counts the links of a workspace by the host they redirect
+// to, for the most linked hosts. Links counts all of them.
+type GetLinkDomains struct {
+	Links   int             `json:"links"`
+	Domains []LinksByDomain `json:"domains"`
+}
+
+type LinksByDomain struct {
+	Domain string `json:"domain"`
+	Links  int    `json:"links"`
 }
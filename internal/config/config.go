@@ -8,10 +8,15 @@ import (
 )
 
 type Config struct {
-	DB     DBConfig     `mapstructure:",squash"`
-	Server ServerConfig `mapstructure:",squash"`
-	Redis  RedisConfig  `mapstructure:",squash"`
-	Retry  RetryConfig  `mapstructure:",squash"`
+	DB      DBConfig      `mapstructure:",squash"`
+	Server  ServerConfig  `mapstructure:",squash"`
+	Redis   RedisConfig   `mapstructure:",squash"`
+	Retry   RetryConfig   `mapstructure:",squash"`
+	Link    LinkConfig    `mapstructure:",squash"`
+	Auth    AuthConfig    `mapstructure:",squash"`
+	Click   ClickConfig   `mapstructure:",squash"`
+	Webhook WebhookConfig `mapstructure:",squash"`
+	Events  EventsConfig  `mapstructure:",squash"`
 }
 
 type DBConfig struct {
@@ -28,6 +33,10 @@ type DBConfig struct {
 
 type ServerConfig struct {
 	HTTPPort string `mapstructure:"HTTP_PORT"`
+	// DefaultHosts is a comma-separated list of the hosts serving the API.
+	// Requests to other hosts are redirects on custom domains. When empty,
+	// custom domains are not routed.
+	DefaultHosts string `mapstructure:"DEFAULT_HOSTS"`
 }
 
 type RedisConfig struct {
@@ -43,6 +52,105 @@ type RetryConfig struct {
 	Backoff  float64       `mapstructure:"RETRY_BACKOFF"`
 }
 
+type LinkConfig struct {
+	// AliasQuarantine is how long the alias of a deleted link stays
+	// reserved, during which the link can be restored. Once its alias is
+	// reused, the deleted link and its clicks are kept, but it can no
+	// longer be looked up by alias.
+	AliasQuarantine time.Duration `mapstructure:"ALIAS_QUARANTINE"`
+	// AliasNamespace is "global" (the default) or "workspace". Links keep
+	// the namespace they were created in, so switching it does not move
+	// existing aliases.
+	AliasNamespace string `mapstructure:"ALIAS_NAMESPACE"`
+	// RedirectPrefix is the path redirects are served under, "/api/s" by
+	// default. "/" serves them at the root, next to /api and /swagger. Other
+	// prefixes cannot start with one of the service's own paths.
+	RedirectPrefix string `mapstructure:"REDIRECT_PREFIX"`
+	// PublicBaseURL is the scheme and host short URLs are built from.
+	PublicBaseURL string `mapstructure:"PUBLIC_BASE_URL"`
+}
+
+type AuthConfig struct {
+	BootstrapAPIKey string        `mapstructure:"BOOTSTRAP_API_KEY"`
+	JWTSecret       string        `mapstructure:"JWT_SECRET"`
+	JWTTTL          time.Duration `mapstructure:"JWT_TTL"`
+}
+
+type ClickConfig struct {
+	QueueSize     int           `mapstructure:"CLICK_QUEUE_SIZE"`
+	Workers       int           `mapstructure:"CLICK_WORKERS"`
+	BatchSize     int           `mapstructure:"CLICK_BATCH_SIZE"`
+	FlushInterval time.Duration `mapstructure:"CLICK_FLUSH_INTERVAL"`
+	// QueueOverflow is "drop" (the default) to lose clicks while the queue
+	// is full, or "block" to hold redirects until there is room.
+	QueueOverflow string `mapstructure:"CLICK_QUEUE_OVERFLOW"`
+	// OutboxStream is the Redis stream clicks are buffered in while they
+	// cannot be stored. Clicks Postgres rejects are moved to the stream of
+	// the same name with a ":dead" suffix.
+	OutboxStream   string        `mapstructure:"CLICK_OUTBOX_STREAM"`
+	ReplayInterval time.Duration `mapstructure:"CLICK_REPLAY_INTERVAL"`
+	// GeoIPDatabase is the path of a GeoLite2 or GeoIP2 database. Clicks
+	// are not located when it is empty.
+	GeoIPDatabase string `mapstructure:"GEOIP_DATABASE"`
+	// BotDenylist is the path of a file of user agent patterns, one
+	// regular expression per line, telling bots on top of the built-in ones.
+	BotDenylist string `mapstructure:"BOT_DENYLIST"`
+	// IPMode is how much of the ip of clicks is stored: "truncate" (the
+	// default), "full", "hash" or "drop".
+	IPMode string `mapstructure:"CLICK_IP_MODE"`
+	// RetentionDays is how long raw clicks are kept once rolled up. They
+	// are expired by monthly partition, and kept forever when it is 0.
+	RetentionDays int `mapstructure:"CLICK_RETENTION_DAYS"`
+	// ExpiredPartitions is "drop" (the default) to drop the partitions of
+	// expired clicks, or "detach" to keep them as standalone tables.
+	ExpiredPartitions string `mapstructure:"CLICK_EXPIRED_PARTITIONS"`
+	// CompactInterval is how often the clicks of closed hours are rolled up.
+	CompactInterval time.Duration `mapstructure:"CLICK_COMPACT_INTERVAL"`
+	// StreamPubSub is "redis" (the default) to stream the clicks of every
+	// replica live, or "memory" for the clicks of this one only.
+	StreamPubSub  string `mapstructure:"CLICK_STREAM_PUBSUB"`
+	StreamChannel string `mapstructure:"CLICK_STREAM_CHANNEL"`
+	// StreamBuffer is the number of clicks a live stream holds for a slow
+	// client before it drops them.
+	StreamBuffer      int           `mapstructure:"CLICK_STREAM_BUFFER"`
+	StreamHeartbeat   time.Duration `mapstructure:"CLICK_STREAM_HEARTBEAT"`
+	StreamIdleTimeout time.Duration `mapstructure:"CLICK_STREAM_IDLE_TIMEOUT"`
+	// StreamWriteTimeout bounds the writes of exports as well.
+	StreamWriteTimeout time.Duration `mapstructure:"CLICK_STREAM_WRITE_TIMEOUT"`
+}
+
+type WebhookConfig struct {
+	// QueueSize is the number of events waiting for delivery beyond which
+	// new ones are kept as dead letters to be retried, up to as many again,
+	// and dropped past that.
+	QueueSize int           `mapstructure:"WEBHOOK_QUEUE_SIZE"`
+	Workers   int           `mapstructure:"WEBHOOK_WORKERS"`
+	Timeout   time.Duration `mapstructure:"WEBHOOK_TIMEOUT"`
+	// RetryInterval is how often failed deliveries are looked for to retry
+	// them, on the schedule of the RETRY_* settings.
+	RetryInterval time.Duration `mapstructure:"WEBHOOK_RETRY_INTERVAL"`
+	// ExpiryInterval is how often links that expired are looked for, to
+	// announce them.
+	ExpiryInterval time.Duration `mapstructure:"WEBHOOK_EXPIRY_INTERVAL"`
+}
+
+type EventsConfig struct {
+	// Publisher is "none" (the default) to publish no events, "redis" to
+	// publish them to a Redis stream or "kafka" to a Kafka topic.
+	Publisher string `mapstructure:"EVENTS_PUBLISHER"`
+	// QueueSize is the number of events waiting to be published beyond
+	// which new ones are dropped.
+	QueueSize int `mapstructure:"EVENTS_QUEUE_SIZE"`
+	BatchSize int `mapstructure:"EVENTS_BATCH_SIZE"`
+	// RedisStream is trimmed to about RedisMaxLen entries, never when it
+	// is 0.
+	RedisStream string `mapstructure:"EVENTS_REDIS_STREAM"`
+	RedisMaxLen int64  `mapstructure:"EVENTS_REDIS_MAXLEN"`
+	// KafkaBrokers is a comma-separated list of host:port addresses.
+	KafkaBrokers string `mapstructure:"KAFKA_BROKERS"`
+	KafkaTopic   string `mapstructure:"KAFKA_TOPIC"`
+}
+
 func MustLoad() *Config {
 	c := config.New()
 	if err := c.Load(".env", ".env", ""); err != nil {
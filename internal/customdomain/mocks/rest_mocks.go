@@ -0,0 +1,125 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: handler.go
+//
+// Generated by this command:
+//
+//	mockgen -source=handler.go -destination=../mocks/rest_mocks.go -package=mocks
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	uuid "github.com/google/uuid"
+	dto "github.com/ilam072/shortener/internal/customdomain/types/dto"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockDomain is a mock of Domain interface.
+type MockDomain struct {
+	ctrl     *gomock.Controller
+	recorder *MockDomainMockRecorder
+	isgomock struct{}
+}
+
+// MockDomainMockRecorder is the mock recorder for MockDomain.
+type MockDomainMockRecorder struct {
+	mock *MockDomain
+}
+
+// NewMockDomain creates a new mock instance.
+func NewMockDomain(ctrl *gomock.Controller) *MockDomain {
+	mock := &MockDomain{ctrl: ctrl}
+	mock.recorder = &MockDomainMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDomain) EXPECT() *MockDomainMockRecorder {
+	return m.recorder
+}
+
+// CreateDomain mocks base method.
+func (m *MockDomain) CreateDomain(ctx context.Context, workspaceID uuid.UUID, d dto.CreateDomain) (dto.Domain, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateDomain", ctx, workspaceID, d)
+	ret0, _ := ret[0].(dto.Domain)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateDomain indicates an expected call of CreateDomain.
+func (mr *MockDomainMockRecorder) CreateDomain(ctx, workspaceID, d any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateDomain", reflect.TypeOf((*MockDomain)(nil).CreateDomain), ctx, workspaceID, d)
+}
+
+// DeleteDomain mocks base method.
+func (m *MockDomain) DeleteDomain(ctx context.Context, workspaceID uuid.UUID, id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteDomain", ctx, workspaceID, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteDomain indicates an expected call of DeleteDomain.
+func (mr *MockDomainMockRecorder) DeleteDomain(ctx, workspaceID, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteDomain", reflect.TypeOf((*MockDomain)(nil).DeleteDomain), ctx, workspaceID, id)
+}
+
+// ListDomains mocks base method.
+func (m *MockDomain) ListDomains(ctx context.Context, workspaceID uuid.UUID) ([]dto.Domain, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListDomains", ctx, workspaceID)
+	ret0, _ := ret[0].([]dto.Domain)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListDomains indicates an expected call of ListDomains.
+func (mr *MockDomainMockRecorder) ListDomains(ctx, workspaceID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListDomains", reflect.TypeOf((*MockDomain)(nil).ListDomains), ctx, workspaceID)
+}
+
+// MockValidator is a mock of Validator interface.
+type MockValidator struct {
+	ctrl     *gomock.Controller
+	recorder *MockValidatorMockRecorder
+	isgomock struct{}
+}
+
+// MockValidatorMockRecorder is the mock recorder for MockValidator.
+type MockValidatorMockRecorder struct {
+	mock *MockValidator
+}
+
+// NewMockValidator creates a new mock instance.
+func NewMockValidator(ctrl *gomock.Controller) *MockValidator {
+	mock := &MockValidator{ctrl: ctrl}
+	mock.recorder = &MockValidatorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockValidator) EXPECT() *MockValidatorMockRecorder {
+	return m.recorder
+}
+
+// Validate mocks base method.
+func (m *MockValidator) Validate(i any) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Validate", i)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Validate indicates an expected call of Validate.
+func (mr *MockValidatorMockRecorder) Validate(i any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Validate", reflect.TypeOf((*MockValidator)(nil).Validate), i)
+}
@@ -0,0 +1,87 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: domain.go
+//
+// Generated by this command:
+//
+//	mockgen -source=domain.go -destination=../mocks/service_mocks.go -package=mocks
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	uuid "github.com/google/uuid"
+	domain "github.com/ilam072/shortener/internal/customdomain/types/domain"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockDomainRepo is a mock of DomainRepo interface.
+type MockDomainRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockDomainRepoMockRecorder
+	isgomock struct{}
+}
+
+// MockDomainRepoMockRecorder is the mock recorder for MockDomainRepo.
+type MockDomainRepoMockRecorder struct {
+	mock *MockDomainRepo
+}
+
+// NewMockDomainRepo creates a new mock instance.
+func NewMockDomainRepo(ctrl *gomock.Controller) *MockDomainRepo {
+	mock := &MockDomainRepo{ctrl: ctrl}
+	mock.recorder = &MockDomainRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDomainRepo) EXPECT() *MockDomainRepoMockRecorder {
+	return m.recorder
+}
+
+// CreateDomain mocks base method.
+func (m *MockDomainRepo) CreateDomain(ctx context.Context, d domain.Domain) (domain.Domain, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateDomain", ctx, d)
+	ret0, _ := ret[0].(domain.Domain)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateDomain indicates an expected call of CreateDomain.
+func (mr *MockDomainRepoMockRecorder) CreateDomain(ctx, d any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateDomain", reflect.TypeOf((*MockDomainRepo)(nil).CreateDomain), ctx, d)
+}
+
+// DeleteDomain mocks base method.
+func (m *MockDomainRepo) DeleteDomain(ctx context.Context, workspaceID, id uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteDomain", ctx, workspaceID, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteDomain indicates an expected call of DeleteDomain.
+func (mr *MockDomainRepoMockRecorder) DeleteDomain(ctx, workspaceID, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteDomain", reflect.TypeOf((*MockDomainRepo)(nil).DeleteDomain), ctx, workspaceID, id)
+}
+
+// ListDomains mocks base method.
+func (m *MockDomainRepo) ListDomains(ctx context.Context, workspaceID uuid.UUID) ([]domain.Domain, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListDomains", ctx, workspaceID)
+	ret0, _ := ret[0].([]domain.Domain)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListDomains indicates an expected call of ListDomains.
+func (mr *MockDomainRepoMockRecorder) ListDomains(ctx, workspaceID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListDomains", reflect.TypeOf((*MockDomainRepo)(nil).ListDomains), ctx, workspaceID)
+}
@@ -0,0 +1,117 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/ilam072/shortener/internal/customdomain/repo"
+	"github.com/ilam072/shortener/internal/customdomain/types/domain"
+	"github.com/ilam072/shortener/pkg/errutils"
+	"github.com/lib/pq"
+	"github.com/wb-go/wbf/dbpg"
+)
+
+type DomainRepo struct {
+	db *dbpg.DB
+}
+
+func New(db *dbpg.DB) *DomainRepo {
+	return &DomainRepo{db: db}
+}
+
+func (r *DomainRepo) CreateDomain(ctx context.Context, d domain.Domain) (domain.Domain, error) {
+	const op = "repo.domain.Create"
+
+	query := `
+		INSERT INTO domains(id, workspace_id, host, fallback_url)
+		VALUES ($1, $2, $3, $4)
+		RETURNING created_at;
+	`
+
+	if err := r.db.QueryRowContext(
+		ctx,
+		query,
+		d.ID,
+		d.WorkspaceID,
+		d.Host,
+		d.FallbackURL,
+	).Scan(&d.CreatedAt); err != nil {
+		if isViolation(err, "23505") {
+			return domain.Domain{}, errutils.Wrap(op, repo.ErrHostAlreadyExists)
+		}
+		return domain.Domain{}, errutils.Wrap(op, err)
+	}
+
+	return d, nil
+}
+
+func (r *DomainRepo) ListDomains(ctx context.Context, workspaceID uuid.UUID) ([]domain.Domain, error) {
+	const op = "repo.domain.List"
+
+	query := `
+		SELECT id, workspace_id, host, fallback_url, created_at
+		FROM domains
+		WHERE workspace_id = $1
+		ORDER BY created_at;
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, workspaceID)
+	if err != nil {
+		return nil, errutils.Wrap(op, err)
+	}
+	defer rows.Close()
+
+	var domains []domain.Domain
+	for rows.Next() {
+		var d domain.Domain
+		if err := rows.Scan(
+			&d.ID,
+			&d.WorkspaceID,
+			&d.Host,
+			&d.FallbackURL,
+			&d.CreatedAt,
+		); err != nil {
+			return nil, errutils.Wrap(op, err)
+		}
+		domains = append(domains, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errutils.Wrap(op, err)
+	}
+
+	return domains, nil
+}
+
+// DeleteDomain removes a domain of the workspace. Domains that still have
+// links are kept and reported with ErrDomainInUse.
+func (r *DomainRepo) DeleteDomain(ctx context.Context, workspaceID uuid.UUID, id uuid.UUID) error {
+	const op = "repo.domain.Delete"
+
+	query := `
+		DELETE FROM domains
+		WHERE id = $1 AND workspace_id = $2;
+	`
+
+	res, err := r.db.ExecContext(ctx, query, id, workspaceID)
+	if err != nil {
+		if isViolation(err, "23503") {
+			return errutils.Wrap(op, repo.ErrDomainInUse)
+		}
+		return errutils.Wrap(op, err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return errutils.Wrap(op, err)
+	}
+	if affected == 0 {
+		return errutils.Wrap(op, repo.ErrDomainNotFound)
+	}
+
+	return nil
+}
+
+func isViolation(err error, code pq.ErrorCode) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == code
+}
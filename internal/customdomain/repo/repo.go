@@ -0,0 +1,9 @@
+package repo
+
+import "errors"
+
+var (
+	ErrHostAlreadyExists = errors.New("host already exists")
+	ErrDomainNotFound    = errors.New("domain not found")
+	ErrDomainInUse       = errors.New("domain has links")
+)
@@ -0,0 +1,137 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/ilam072/shortener/internal/auth"
+	"github.com/ilam072/shortener/internal/customdomain/service"
+	"github.com/ilam072/shortener/internal/customdomain/types/dto"
+	"github.com/ilam072/shortener/internal/response"
+	"github.com/wb-go/wbf/ginext"
+	"github.com/wb-go/wbf/zlog"
+	"net/http"
+)
+
+//go:generate mockgen -source=handler.go -destination=../mocks/rest_mocks.go -package=mocks
+type Domain interface {
+	CreateDomain(ctx context.Context, workspaceID uuid.UUID, d dto.CreateDomain) (dto.Domain, error)
+	ListDomains(ctx context.Context, workspaceID uuid.UUID) ([]dto.Domain, error)
+	DeleteDomain(ctx context.Context, workspaceID uuid.UUID, id string) error
+}
+
+type Validator interface {
+	Validate(i interface{}) error
+}
+
+type DomainHandler struct {
+	domain    Domain
+	validator Validator
+}
+
+func NewDomainHandler(domain Domain, validator Validator) *DomainHandler {
+	return &DomainHandler{domain: domain, validator: validator}
+}
+
+// CreateDomain godoc
+// @Summary Добавить домен
+// @Description Регистрирует собственный домен рабочего пространства. Короткие ссылки домена открываются по адресу https://{host}/{alias}, а для неизвестных alias выполняется редирект на fallback_url
+// @Tags Domains
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Param input body dto.CreateDomain true "Хост и fallback URL домена"
+// @Success 201 {object} response.Response{payload=dto.Domain} "Добавленный домен"
+// @Failure 400 {object} response.Response "invalid request body или validation error"
+// @Failure 401 {object} response.Response "invalid credentials"
+// @Failure 403 {object} response.Response "insufficient scope"
+// @Failure 409 {object} response.Response "host already exists"
+// @Failure 500 {object} response.Response "internal server error"
+// @Router /domains [post]
+func (h *DomainHandler) CreateDomain(c *ginext.Context) {
+	var d dto.CreateDomain
+	if err := json.NewDecoder(c.Request.Body).Decode(&d); err != nil {
+		response.Error("invalid request body").WriteJSON(c, http.StatusBadRequest)
+		return
+	}
+	if err := h.validator.Validate(d); err != nil {
+		response.Error(fmt.Sprintf("validation error: %s", err.Error())).WriteJSON(c, http.StatusBadRequest)
+		return
+	}
+
+	workspace := auth.FromContext(c.Request.Context()).Workspace
+	created, err := h.domain.CreateDomain(c.Request.Context(), workspace.ID, d)
+	if err != nil {
+		if errors.Is(err, service.ErrHostAlreadyExists) {
+			response.Error("domain with such host already exists").WriteJSON(c, http.StatusConflict)
+			return
+		}
+		zlog.Logger.Error().Err(err).Str("host", d.Host).Msg("failed to create domain")
+		response.Error("internal server error, try again later").WriteJSON(c, http.StatusInternalServerError)
+		return
+	}
+
+	response.Success(created).WriteJSON(c, http.StatusCreated)
+}
+
+// ListDomains godoc
+// @Summary Список доменов
+// @Description Возвращает домены рабочего пространства
+// @Tags Domains
+// @Produce json
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Success 200 {object} response.Response{payload=[]dto.Domain} "Домены"
+// @Failure 401 {object} response.Response "invalid credentials"
+// @Failure 403 {object} response.Response "insufficient scope"
+// @Failure 500 {object} response.Response "internal server error"
+// @Router /domains [get]
+func (h *DomainHandler) ListDomains(c *ginext.Context) {
+	workspace := auth.FromContext(c.Request.Context()).Workspace
+	domains, err := h.domain.ListDomains(c.Request.Context(), workspace.ID)
+	if err != nil {
+		zlog.Logger.Error().Err(err).Msg("failed to list domains")
+		response.Error("internal server error, try again later").WriteJSON(c, http.StatusInternalServerError)
+		return
+	}
+
+	response.Success(domains).WriteJSON(c, http.StatusOK)
+}
+
+// DeleteDomain godoc
+// @Summary Удалить домен
+// @Description Удаляет домен по id. Домен, на котором есть ссылки, удалить нельзя
+// @Tags Domains
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Param id path string true "ID домена"
+// @Success 204 "Domain deleted"
+// @Failure 401 {object} response.Response "invalid credentials"
+// @Failure 403 {object} response.Response "insufficient scope"
+// @Failure 404 {object} response.Response "domain not found"
+// @Failure 409 {object} response.Response "domain has links"
+// @Failure 500 {object} response.Response "internal server error"
+// @Router /domains/{id} [delete]
+func (h *DomainHandler) DeleteDomain(c *ginext.Context) {
+	id := c.Param("id")
+
+	workspace := auth.FromContext(c.Request.Context()).Workspace
+	if err := h.domain.DeleteDomain(c.Request.Context(), workspace.ID, id); err != nil {
+		switch {
+		case errors.Is(err, service.ErrDomainNotFound):
+			response.Error("domain not found").WriteJSON(c, http.StatusNotFound)
+			return
+		case errors.Is(err, service.ErrDomainInUse):
+			response.Error("domain has links").WriteJSON(c, http.StatusConflict)
+			return
+		}
+		zlog.Logger.Error().Err(err).Str("id", id).Msg("failed to delete domain")
+		response.Error("internal server error, try again later").WriteJSON(c, http.StatusInternalServerError)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,165 @@
+package rest_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"github.com/wb-go/wbf/ginext"
+	"go.uber.org/mock/gomock"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ilam072/shortener/internal/customdomain/mocks"
+	"github.com/ilam072/shortener/internal/customdomain/rest"
+	"github.com/ilam072/shortener/internal/customdomain/service"
+	"github.com/ilam072/shortener/internal/customdomain/types/dto"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+func newTestContext(method, path string, body []byte) (*ginext.Context, *httptest.ResponseRecorder) {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+
+	req := httptest.NewRequest(method, path, bytes.NewBuffer(body))
+	c.Request = req
+
+	return c, w
+}
+
+func TestDomainHandler_CreateDomain(t *testing.T) {
+	type fields struct {
+		setup func(domain *mocks.MockDomain, validator *mocks.MockValidator)
+	}
+	type want struct {
+		status int
+	}
+
+	tests := []struct {
+		name   string
+		body   interface{}
+		fields fields
+		want   want
+	}{
+		{
+			name: "invalid json",
+			body: "invalid",
+			want: want{status: http.StatusBadRequest},
+		},
+		{
+			name: "validation error",
+			body: dto.CreateDomain{Host: "not a host"},
+			fields: fields{
+				setup: func(domain *mocks.MockDomain, validator *mocks.MockValidator) {
+					validator.EXPECT().
+						Validate(gomock.Any()).
+						Return(errors.New("validation failed"))
+				},
+			},
+			want: want{status: http.StatusBadRequest},
+		},
+		{
+			name: "host already exists",
+			body: dto.CreateDomain{Host: "go.acme.io"},
+			fields: fields{
+				setup: func(domain *mocks.MockDomain, validator *mocks.MockValidator) {
+					validator.EXPECT().
+						Validate(gomock.Any()).
+						Return(nil)
+					domain.EXPECT().
+						CreateDomain(gomock.Any(), gomock.Any(), gomock.Any()).
+						Return(dto.Domain{}, service.ErrHostAlreadyExists)
+				},
+			},
+			want: want{status: http.StatusConflict},
+		},
+		{
+			name: "success",
+			body: dto.CreateDomain{Host: "go.acme.io", FallbackURL: "https://acme.io"},
+			fields: fields{
+				setup: func(domain *mocks.MockDomain, validator *mocks.MockValidator) {
+					validator.EXPECT().
+						Validate(gomock.Any()).
+						Return(nil)
+					domain.EXPECT().
+						CreateDomain(gomock.Any(), gomock.Any(), dto.CreateDomain{Host: "go.acme.io", FallbackURL: "https://acme.io"}).
+						Return(dto.Domain{Host: "go.acme.io"}, nil)
+				},
+			},
+			want: want{status: http.StatusCreated},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockDomain := mocks.NewMockDomain(ctrl)
+			mockValidator := mocks.NewMockValidator(ctrl)
+
+			if tt.fields.setup != nil {
+				tt.fields.setup(mockDomain, mockValidator)
+			}
+
+			handler := rest.NewDomainHandler(mockDomain, mockValidator)
+
+			var bodyBytes []byte
+			switch v := tt.body.(type) {
+			case string:
+				bodyBytes = []byte(v)
+			default:
+				bodyBytes, _ = json.Marshal(v)
+			}
+
+			c, w := newTestContext(http.MethodPost, "/domains", bodyBytes)
+
+			handler.CreateDomain(c)
+
+			require.Equal(t, tt.want.status, w.Code)
+		})
+	}
+}
+
+func TestDomainHandler_DeleteDomain(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		status int
+	}{
+		{name: "not found", err: service.ErrDomainNotFound, status: http.StatusNotFound},
+		{name: "has links", err: service.ErrDomainInUse, status: http.StatusConflict},
+		{name: "success", status: http.StatusNoContent},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockDomain := mocks.NewMockDomain(ctrl)
+			mockValidator := mocks.NewMockValidator(ctrl)
+
+			mockDomain.EXPECT().
+				DeleteDomain(gomock.Any(), gomock.Any(), "domain-id").
+				Return(tt.err)
+
+			handler := rest.NewDomainHandler(mockDomain, mockValidator)
+
+			c, w := newTestContext(http.MethodDelete, "/domains/domain-id", nil)
+			c.Params = gin.Params{{Key: "id", Value: "domain-id"}}
+
+			handler.DeleteDomain(c)
+			c.Writer.WriteHeaderNow()
+
+			require.Equal(t, tt.status, w.Code)
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/ilam072/shortener/internal/customdomain/repo"
+	"github.com/ilam072/shortener/internal/customdomain/types/domain"
+	"github.com/ilam072/shortener/internal/customdomain/types/dto"
+	"github.com/ilam072/shortener/pkg/errutils"
+	"strings"
+)
+
+//go:generate mockgen -source=domain.go -destination=../mocks/service_mocks.go -package=mocks
+type DomainRepo interface {
+	CreateDomain(ctx context.Context, d domain.Domain) (domain.Domain, error)
+	ListDomains(ctx context.Context, workspaceID uuid.UUID) ([]domain.Domain, error)
+	DeleteDomain(ctx context.Context, workspaceID uuid.UUID, id uuid.UUID) error
+}
+
+type Domain struct {
+	repo DomainRepo
+}
+
+func New(repo DomainRepo) *Domain {
+	return &Domain{repo: repo}
+}
+
+var (
+	ErrHostAlreadyExists = errors.New("host already exists")
+	ErrDomainNotFound    = errors.New("domain not found")
+	ErrDomainInUse       = errors.New("domain has links")
+)
+
+// CreateDomain registers a custom host for the workspace. Hosts are
+// compared case-insensitively, so they are stored lowercased.
+func (s *Domain) CreateDomain(ctx context.Context, workspaceID uuid.UUID, d dto.CreateDomain) (dto.Domain, error) {
+	const op = "service.domain.Create"
+
+	var fallbackURL *string
+	if d.FallbackURL != "" {
+		fallbackURL = &d.FallbackURL
+	}
+
+	created, err := s.repo.CreateDomain(ctx, domain.Domain{
+		ID:          uuid.New(),
+		WorkspaceID: workspaceID,
+		Host:        strings.ToLower(d.Host),
+		FallbackURL: fallbackURL,
+	})
+	if err != nil {
+		if errors.Is(err, repo.ErrHostAlreadyExists) {
+			return dto.Domain{}, errutils.Wrap(op, ErrHostAlreadyExists)
+		}
+		return dto.Domain{}, errutils.Wrap(op, err)
+	}
+
+	return toDTO(created), nil
+}
+
+func (s *Domain) ListDomains(ctx context.Context, workspaceID uuid.UUID) ([]dto.Domain, error) {
+	const op = "service.domain.List"
+
+	domains, err := s.repo.ListDomains(ctx, workspaceID)
+	if err != nil {
+		return nil, errutils.Wrap(op, err)
+	}
+
+	result := make([]dto.Domain, 0, len(domains))
+	for _, d := range domains {
+		result = append(result, toDTO(d))
+	}
+
+	return result, nil
+}
+
+func (s *Domain) DeleteDomain(ctx context.Context, workspaceID uuid.UUID, id string) error {
+	const op = "service.domain.Delete"
+
+	domainID, err := uuid.Parse(id)
+	if err != nil {
+		return ErrDomainNotFound
+	}
+
+	if err = s.repo.DeleteDomain(ctx, workspaceID, domainID); err != nil {
+		switch {
+		case errors.Is(err, repo.ErrDomainNotFound):
+			return errutils.Wrap(op, ErrDomainNotFound)
+		case errors.Is(err, repo.ErrDomainInUse):
+			return errutils.Wrap(op, ErrDomainInUse)
+		}
+		return errutils.Wrap(op, err)
+	}
+
+	return nil
+}
+
+func toDTO(d domain.Domain) dto.Domain {
+	return dto.Domain{
+		ID:          d.ID.String(),
+		Host:        d.Host,
+		FallbackURL: d.FallbackURL,
+		CreatedAt:   d.CreatedAt,
+	}
+}
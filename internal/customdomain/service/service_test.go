@@ -0,0 +1,161 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"go.uber.org/mock/gomock"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ilam072/shortener/internal/customdomain/mocks"
+	domainrepo "github.com/ilam072/shortener/internal/customdomain/repo"
+	"github.com/ilam072/shortener/internal/customdomain/service"
+	"github.com/ilam072/shortener/internal/customdomain/types/domain"
+	"github.com/ilam072/shortener/internal/customdomain/types/dto"
+)
+
+func TestDomain_CreateDomain(t *testing.T) {
+	workspaceID := uuid.New()
+
+	type fields struct {
+		setup func(repo *mocks.MockDomainRepo)
+	}
+	type want struct {
+		err error
+	}
+
+	tests := []struct {
+		name   string
+		input  dto.CreateDomain
+		fields fields
+		want   want
+	}{
+		{
+			name:  "success lowercases host",
+			input: dto.CreateDomain{Host: "Go.Acme.io", FallbackURL: "https://acme.io"},
+			fields: fields{
+				setup: func(repo *mocks.MockDomainRepo) {
+					repo.EXPECT().
+						CreateDomain(gomock.Any(), gomock.Cond(func(d domain.Domain) bool {
+							return d.WorkspaceID == workspaceID && d.Host == "go.acme.io" &&
+								d.FallbackURL != nil && *d.FallbackURL == "https://acme.io"
+						})).
+						DoAndReturn(func(_ context.Context, d domain.Domain) (domain.Domain, error) {
+							return d, nil
+						})
+				},
+			},
+			want: want{},
+		},
+		{
+			name:  "without fallback",
+			input: dto.CreateDomain{Host: "go.acme.io"},
+			fields: fields{
+				setup: func(repo *mocks.MockDomainRepo) {
+					repo.EXPECT().
+						CreateDomain(gomock.Any(), gomock.Cond(func(d domain.Domain) bool {
+							return d.FallbackURL == nil
+						})).
+						DoAndReturn(func(_ context.Context, d domain.Domain) (domain.Domain, error) {
+							return d, nil
+						})
+				},
+			},
+			want: want{},
+		},
+		{
+			name:  "host already exists",
+			input: dto.CreateDomain{Host: "go.acme.io"},
+			fields: fields{
+				setup: func(repo *mocks.MockDomainRepo) {
+					repo.EXPECT().
+						CreateDomain(gomock.Any(), gomock.Any()).
+						Return(domain.Domain{}, domainrepo.ErrHostAlreadyExists)
+				},
+			},
+			want: want{err: service.ErrHostAlreadyExists},
+		},
+		{
+			name:  "repo error",
+			input: dto.CreateDomain{Host: "go.acme.io"},
+			fields: fields{
+				setup: func(repo *mocks.MockDomainRepo) {
+					repo.EXPECT().
+						CreateDomain(gomock.Any(), gomock.Any()).
+						Return(domain.Domain{}, errors.New("db error"))
+				},
+			},
+			want: want{err: errors.New("db error")},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockRepo := mocks.NewMockDomainRepo(ctrl)
+
+			if tt.fields.setup != nil {
+				tt.fields.setup(mockRepo)
+			}
+
+			svc := service.New(mockRepo)
+
+			got, err := svc.CreateDomain(context.Background(), workspaceID, tt.input)
+
+			if tt.want.err != nil {
+				require.ErrorContains(t, err, tt.want.err.Error())
+				require.Empty(t, got)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, "go.acme.io", got.Host)
+		})
+	}
+}
+
+func TestDomain_DeleteDomain(t *testing.T) {
+	workspaceID := uuid.New()
+	domainID := uuid.New()
+
+	tests := []struct {
+		name    string
+		id      string
+		repoErr error
+		err     error
+	}{
+		{name: "success", id: domainID.String()},
+		{name: "invalid id", id: "not-a-uuid", err: service.ErrDomainNotFound},
+		{name: "not found", id: domainID.String(), repoErr: domainrepo.ErrDomainNotFound, err: service.ErrDomainNotFound},
+		{name: "has links", id: domainID.String(), repoErr: domainrepo.ErrDomainInUse, err: service.ErrDomainInUse},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockRepo := mocks.NewMockDomainRepo(ctrl)
+			if tt.id == domainID.String() {
+				mockRepo.EXPECT().
+					DeleteDomain(gomock.Any(), workspaceID, domainID).
+					Return(tt.repoErr)
+			}
+
+			svc := service.New(mockRepo)
+
+			err := svc.DeleteDomain(context.Background(), workspaceID, tt.id)
+
+			if tt.err != nil {
+				require.ErrorIs(t, err, tt.err)
+				return
+			}
+
+			require.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,14 @@
+package domain
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+type Domain struct {
+	ID          uuid.UUID
+	WorkspaceID uuid.UUID
+	Host        string
+	FallbackURL *string
+	CreatedAt   time.Time
+}
@@ -0,0 +1,15 @@
+package dto
+
+import "time"
+
+type CreateDomain struct {
+	Host        string `json:"host" validate:"required,hostname,max=253"`
+	FallbackURL string `json:"fallback_url,omitempty" validate:"omitempty,url"`
+}
+
+type Domain struct {
+	ID          string    `json:"id"`
+	Host        string    `json:"host"`
+	FallbackURL *string   `json:"fallback_url,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
@@ -0,0 +1,88 @@
+// Package eventbus defines the events the data platform consumes from the
+// message bus, and how they are published.
+package eventbus
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+// SchemaVersion is the version of the JSON schema of Event. It is bumped
+// on every change consumers could break on, such as a removed or retyped
+// field; new optional fields do not bump it.
+const SchemaVersion = 1
+
+// Event types.
+const (
+	TypeLinkCreated = "link.created"
+	TypeLinkClicked = "link.clicked"
+)
+
+//go:generate mockgen -source=eventbus.go -destination=mocks/eventbus_mocks.go -package=mocks
+
+// EventPublisher publishes events to the message bus. Publish must not
+// block: it is called on the redirect path.
+type EventPublisher interface {
+	Publish(event Event)
+}
+
+// Event is a message of the bus. Events of a link share its alias as
+// their partition key, so that they are consumed in order.
+type Event struct {
+	SchemaVersion int       `json:"schema_version"`
+	ID            uuid.UUID `json:"id"`
+	Type          string    `json:"type"`
+	OccurredAt    time.Time `json:"occurred_at"`
+	WorkspaceID   uuid.UUID `json:"workspace_id"`
+	Link          Link      `json:"link"`
+	Click         *Click    `json:"click,omitempty"`
+}
+
+type Link struct {
+	ID        uuid.UUID  `json:"id"`
+	Alias     string     `json:"alias"`
+	Domain    string     `json:"domain,omitempty"`
+	URL       string     `json:"url,omitempty"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	MaxClicks *int       `json:"max_clicks,omitempty"`
+}
+
+// Click is the click of a link.clicked event. VisitorID is the salted
+// hash clicks are counted as unique by, empty when it is unknown.
+type Click struct {
+	Device    string `json:"device"`
+	Client    string `json:"client"`
+	OS        string `json:"os,omitempty"`
+	Referrer  string `json:"referrer,omitempty"`
+	Country   string `json:"country,omitempty"`
+	Bot       bool   `json:"bot"`
+	VisitorID string `json:"visitor_id,omitempty"`
+}
+
+// NewLinkCreated returns the event of link being created in workspaceID.
+func NewLinkCreated(workspaceID uuid.UUID, link Link) Event {
+	return newEvent(TypeLinkCreated, workspaceID, link, time.Now())
+}
+
+// NewLinkClicked returns the event of link being clicked at clickedAt.
+func NewLinkClicked(workspaceID uuid.UUID, link Link, click Click, clickedAt time.Time) Event {
+	event := newEvent(TypeLinkClicked, workspaceID, link, clickedAt)
+	event.Click = &click
+	return event
+}
+
+func newEvent(eventType string, workspaceID uuid.UUID, link Link, occurredAt time.Time) Event {
+	return Event{
+		SchemaVersion: SchemaVersion,
+		ID:            uuid.New(),
+		Type:          eventType,
+		OccurredAt:    occurredAt.UTC(),
+		WorkspaceID:   workspaceID,
+		Link:          link,
+	}
+}
+
+// Key is the partition key of the event.
+func (e Event) Key() string {
+	return e.Link.Alias
+}
@@ -0,0 +1,57 @@
+package publisher
+
+import (
+	"context"
+	"github.com/ilam072/shortener/pkg/errutils"
+	kafkago "github.com/segmentio/kafka-go"
+	"github.com/wb-go/wbf/kafka"
+	"time"
+)
+
+// typeHeader carries the type of an event, so that consumers can filter
+// without decoding it.
+const typeHeader = "type"
+
+// Kafka writes events to a Kafka topic. Messages are partitioned by their
+// key, so the events of a link land on the same partition, in order.
+type Kafka struct {
+	producer *kafka.Producer
+}
+
+// kafkaBatchTimeout is how long the writer waits to fill a batch. Writes
+// are synchronous, so it bounds the latency of every batch the publisher
+// writes, which is already as full as the queue allows.
+const kafkaBatchTimeout = 5 * time.Millisecond
+
+// NewKafka returns a bus over topic writing up to batchSize messages at
+// once, each acknowledged by all in-sync replicas.
+func NewKafka(brokers []string, topic string, batchSize int) *Kafka {
+	producer := kafka.NewProducer(brokers, topic)
+	// The default balancer spreads messages regardless of their key.
+	producer.Writer.Balancer = &kafkago.Hash{}
+	producer.Writer.BatchSize = batchSize
+	producer.Writer.BatchTimeout = kafkaBatchTimeout
+	// Without acks, failed writes are never reported and never retried.
+	producer.Writer.RequiredAcks = kafkago.RequireAll
+	return &Kafka{producer: producer}
+}
+
+func (k *Kafka) Write(ctx context.Context, messages []Message) error {
+	kafkaMessages := make([]kafkago.Message, 0, len(messages))
+	for _, message := range messages {
+		kafkaMessages = append(kafkaMessages, kafkago.Message{
+			Key:     []byte(message.Key),
+			Value:   message.Value,
+			Headers: []kafkago.Header{{Key: typeHeader, Value: []byte(message.Type)}},
+		})
+	}
+
+	if err := k.producer.Writer.WriteMessages(ctx, kafkaMessages...); err != nil {
+		return errutils.Wrap("failed to write events to kafka", err)
+	}
+	return nil
+}
+
+func (k *Kafka) Close() error {
+	return k.producer.Close()
+}
@@ -0,0 +1,8 @@
+package publisher
+
+import "github.com/ilam072/shortener/internal/eventbus"
+
+// Noop drops every event. It is the publisher while no bus is configured.
+type Noop struct{}
+
+func (Noop) Publish(eventbus.Event) {}
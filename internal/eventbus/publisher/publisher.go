@@ -0,0 +1,153 @@
+package publisher
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/ilam072/shortener/internal/eventbus"
+	"github.com/ilam072/shortener/pkg/errutils"
+	"github.com/wb-go/wbf/retry"
+	"github.com/wb-go/wbf/zlog"
+	"sync"
+)
+
+// Message is an encoded event, as it is written to a bus.
+type Message struct {
+	Key   string
+	Type  string
+	Value []byte
+}
+
+// Bus writes messages to a message bus. Messages with the same key must
+// be kept in the order they are written in, as Kafka partitions and NATS
+// subjects do.
+type Bus interface {
+	Write(ctx context.Context, messages []Message) error
+	Close() error
+}
+
+type Config struct {
+	// Size is the number of events waiting to be published beyond which
+	// new ones are dropped.
+	Size int
+	// BatchSize is the largest number of events written at once.
+	BatchSize int
+}
+
+// Publisher publishes events to a bus off the path of the request they
+// happened in. A single worker writes them in batches, so events keep the
+// order they were published in. Failed batches are retried with the
+// strategy and dropped once it is out of attempts.
+type Publisher struct {
+	bus      Bus
+	strategy retry.Strategy
+	cfg      Config
+	events   chan eventbus.Event
+
+	mu     sync.RWMutex
+	closed bool
+	done   chan struct{}
+}
+
+func New(bus Bus, strategy retry.Strategy, cfg Config) *Publisher {
+	// A strategy without attempts would never write anything.
+	strategy.Attempts = max(strategy.Attempts, 1)
+	return &Publisher{
+		bus:      bus,
+		strategy: strategy,
+		cfg:      cfg,
+		events:   make(chan eventbus.Event, cfg.Size),
+		done:     make(chan struct{}),
+	}
+}
+
+// Start launches the worker.
+func (p *Publisher) Start() {
+	go p.work()
+}
+
+// Publish queues event for publishing. It never blocks: events are
+// dropped while the queue is full and once the publisher is shut down.
+func (p *Publisher) Publish(event eventbus.Event) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if p.closed {
+		return
+	}
+
+	select {
+	case p.events <- event:
+	default:
+		zlog.Logger.Warn().Str("event", event.Type).Str("alias", event.Link.Alias).Msg("event queue is full, dropping event")
+	}
+}
+
+// Shutdown stops accepting events, waits until the worker has published
+// the ones already queued, or until ctx is done, and closes the bus.
+func (p *Publisher) Shutdown(ctx context.Context) error {
+	const op = "publisher.Shutdown"
+
+	p.mu.Lock()
+	if !p.closed {
+		p.closed = true
+		close(p.events)
+	}
+	p.mu.Unlock()
+
+	select {
+	case <-p.done:
+	case <-ctx.Done():
+		return errutils.Wrap(op, ctx.Err())
+	}
+
+	if err := p.bus.Close(); err != nil {
+		return errutils.Wrap(op, err)
+	}
+	return nil
+}
+
+func (p *Publisher) work() {
+	defer close(p.done)
+
+	batch := make([]Message, 0, p.cfg.BatchSize)
+	for event := range p.events {
+		batch = p.encode(batch[:0], event)
+
+		// Whatever else is queued goes in the same batch, up to its size.
+	fill:
+		for len(batch) < p.cfg.BatchSize {
+			select {
+			case event, ok := <-p.events:
+				if !ok {
+					break fill
+				}
+				batch = p.encode(batch, event)
+			default:
+				break fill
+			}
+		}
+
+		if len(batch) > 0 {
+			p.write(batch)
+		}
+	}
+}
+
+// encode appends event to batch, or drops it when it cannot be encoded.
+func (p *Publisher) encode(batch []Message, event eventbus.Event) []Message {
+	value, err := json.Marshal(event)
+	if err != nil {
+		zlog.Logger.Error().Err(err).Str("event", event.Type).Msg("failed to encode event")
+		return batch
+	}
+	return append(batch, Message{Key: event.Key(), Type: event.Type, Value: value})
+}
+
+func (p *Publisher) write(batch []Message) {
+	err := retry.Do(func() error {
+		return p.bus.Write(context.Background(), batch)
+	}, p.strategy)
+	if err != nil {
+		zlog.Logger.Error().Err(err).Int("events", len(batch)).Msg("failed to publish events, dropping them")
+	}
+}
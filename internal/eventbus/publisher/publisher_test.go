@@ -0,0 +1,88 @@
+package publisher_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"github.com/wb-go/wbf/retry"
+
+	"github.com/ilam072/shortener/internal/eventbus"
+	"github.com/ilam072/shortener/internal/eventbus/publisher"
+)
+
+// fakeBus records the batches it is written, failing the first failures
+// writes.
+type fakeBus struct {
+	mu       sync.Mutex
+	failures int
+	writes   int
+	batches  [][]publisher.Message
+	closed   bool
+}
+
+func (b *fakeBus) Write(_ context.Context, messages []publisher.Message) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.writes++
+	if b.writes <= b.failures {
+		return errors.New("bus is down")
+	}
+	b.batches = append(b.batches, append([]publisher.Message(nil), messages...))
+	return nil
+}
+
+func (b *fakeBus) Close() error {
+	b.closed = true
+	return nil
+}
+
+func TestPublisher_PublishesInOrder(t *testing.T) {
+	bus := &fakeBus{failures: 1}
+	p := publisher.New(bus, retry.Strategy{Attempts: 2, Delay: time.Millisecond, Backoff: 1}, publisher.Config{
+		Size:      100,
+		BatchSize: 4,
+	})
+
+	workspaceID := uuid.New()
+	for _, alias := range []string{"a", "b", "a", "c", "a", "b"} {
+		p.Publish(eventbus.NewLinkCreated(workspaceID, eventbus.Link{ID: uuid.New(), Alias: alias}))
+	}
+	p.Start()
+	require.NoError(t, p.Shutdown(context.Background()))
+
+	require.True(t, bus.closed)
+	require.Len(t, bus.batches, 2)
+	require.Len(t, bus.batches[0], 4)
+
+	var keys []string
+	for _, batch := range bus.batches {
+		for _, message := range batch {
+			keys = append(keys, message.Key)
+			require.Equal(t, eventbus.TypeLinkCreated, message.Type)
+
+			var event eventbus.Event
+			require.NoError(t, json.Unmarshal(message.Value, &event))
+			require.Equal(t, eventbus.SchemaVersion, event.SchemaVersion)
+			require.Equal(t, message.Key, event.Link.Alias)
+		}
+	}
+	require.Equal(t, []string{"a", "b", "a", "c", "a", "b"}, keys)
+}
+
+func TestPublisher_DropsAfterShutdown(t *testing.T) {
+	bus := &fakeBus{}
+	p := publisher.New(bus, retry.Strategy{Attempts: 1}, publisher.Config{Size: 10, BatchSize: 10})
+	p.Start()
+	require.NoError(t, p.Shutdown(context.Background()))
+
+	p.Publish(eventbus.NewLinkCreated(uuid.New(), eventbus.Link{Alias: "a"}))
+
+	require.Empty(t, bus.batches)
+}
@@ -0,0 +1,55 @@
+package publisher
+
+import (
+	"context"
+	"github.com/go-redis/redis/v8"
+	"github.com/ilam072/shortener/pkg/errutils"
+	wbfredis "github.com/wb-go/wbf/redis"
+)
+
+// Stream entry fields.
+const (
+	keyField   = "key"
+	typeField  = "type"
+	eventField = "event"
+)
+
+// RedisStream writes events to a Redis stream, one entry per event. A
+// stream is a single ordered log, so the events of a link are read in
+// order whatever their key; the key is kept for consumers that shard on it.
+type RedisStream struct {
+	client *wbfredis.Client
+	stream string
+	maxLen int64
+}
+
+// NewRedisStream returns a bus over stream, trimmed to about maxLen
+// entries, or never trimmed when maxLen is 0.
+func NewRedisStream(client *wbfredis.Client, stream string, maxLen int64) *RedisStream {
+	return &RedisStream{client: client, stream: stream, maxLen: maxLen}
+}
+
+func (r *RedisStream) Write(ctx context.Context, messages []Message) error {
+	pipe := r.client.Pipeline()
+	for _, message := range messages {
+		pipe.XAdd(ctx, &redis.XAddArgs{
+			Stream:       r.stream,
+			MaxLenApprox: r.maxLen,
+			Values: map[string]interface{}{
+				keyField:   message.Key,
+				typeField:  message.Type,
+				eventField: message.Value,
+			},
+		})
+	}
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		return errutils.Wrap("failed to write events to stream", err)
+	}
+	return nil
+}
+
+// Close leaves the client open: it is shared with the rest of the service.
+func (r *RedisStream) Close() error {
+	return nil
+}
@@ -2,6 +2,8 @@ package cache
 
 import (
 	"context"
+	"encoding/json"
+	"github.com/ilam072/shortener/internal/link/types/domain"
 	"github.com/ilam072/shortener/pkg/errutils"
 	"github.com/wb-go/wbf/redis"
 	"time"
@@ -15,17 +17,47 @@ func New(client *redis.Client) *LinkCache {
 	return &LinkCache{client: client}
 }
 
-func (c *LinkCache) SetURL(ctx context.Context, alias string, url string) error {
-	if err := c.client.SetWithExpiration(ctx, alias, url, 24*time.Hour); err != nil {
-		return errutils.Wrap("failed to cache status", err)
+const defaultTTL = 24 * time.Hour
+
+// SetTarget caches target under key. The entry never outlives the link
+// itself: when expiresAt is set, the TTL is capped at the link's remaining
+// lifetime.
+func (c *LinkCache) SetTarget(ctx context.Context, key string, target domain.Target, expiresAt *time.Time) error {
+	ttl := defaultTTL
+	if expiresAt != nil {
+		ttl = min(ttl, time.Until(*expiresAt))
+	}
+	if ttl <= 0 {
+		return nil
+	}
+
+	value, err := json.Marshal(target)
+	if err != nil {
+		return errutils.Wrap("failed to encode target", err)
+	}
+
+	if err = c.client.SetWithExpiration(ctx, key, string(value), ttl); err != nil {
+		return errutils.Wrap("failed to cache target", err)
 	}
 	return nil
 }
 
-func (c *LinkCache) GetURL(ctx context.Context, alias string) (string, error) {
-	url, err := c.client.Get(ctx, alias)
+func (c *LinkCache) GetTarget(ctx context.Context, key string) (domain.Target, error) {
+	value, err := c.client.Get(ctx, key)
 	if err != nil {
-		return "", errutils.Wrap("failed to get status from redis", err)
+		return domain.Target{}, errutils.Wrap("failed to get target from redis", err)
 	}
-	return url, nil
+
+	var target domain.Target
+	if err = json.Unmarshal([]byte(value), &target); err != nil {
+		return domain.Target{}, errutils.Wrap("failed to decode target", err)
+	}
+	return target, nil
+}
+
+func (c *LinkCache) DeleteTarget(ctx context.Context, key string) error {
+	if err := c.client.Del(ctx, key); err != nil {
+		return errutils.Wrap("failed to delete target from redis", err)
+	}
+	return nil
 }
@@ -13,6 +13,7 @@ import (
 	context "context"
 	reflect "reflect"
 
+	auth "github.com/ilam072/shortener/internal/auth"
 	dto "github.com/ilam072/shortener/internal/click/types/dto"
 	dto0 "github.com/ilam072/shortener/internal/link/types/dto"
 	retry "github.com/wb-go/wbf/retry"
@@ -43,34 +44,139 @@ func (m *MockLink) EXPECT() *MockLinkMockRecorder {
 	return m.recorder
 }
 
+// DeleteLink mocks base method.
+func (m *MockLink) DeleteLink(ctx context.Context, access auth.Access, host, alias string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteLink", ctx, access, host, alias)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteLink indicates an expected call of DeleteLink.
+func (mr *MockLinkMockRecorder) DeleteLink(ctx, access, host, alias any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteLink", reflect.TypeOf((*MockLink)(nil).DeleteLink), ctx, access, host, alias)
+}
+
+// DisableLink mocks base method.
+func (m *MockLink) DisableLink(ctx context.Context, access auth.Access, host, alias string) (dto0.LinkInfo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DisableLink", ctx, access, host, alias)
+	ret0, _ := ret[0].(dto0.LinkInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DisableLink indicates an expected call of DisableLink.
+func (mr *MockLinkMockRecorder) DisableLink(ctx, access, host, alias any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DisableLink", reflect.TypeOf((*MockLink)(nil).DisableLink), ctx, access, host, alias)
+}
+
+// GetLink mocks base method.
+func (m *MockLink) GetLink(ctx context.Context, access auth.Access, host, alias string) (dto0.LinkInfo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLink", ctx, access, host, alias)
+	ret0, _ := ret[0].(dto0.LinkInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLink indicates an expected call of GetLink.
+func (mr *MockLinkMockRecorder) GetLink(ctx, access, host, alias any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLink", reflect.TypeOf((*MockLink)(nil).GetLink), ctx, access, host, alias)
+}
+
 // GetURLByAlias mocks base method.
-func (m *MockLink) GetURLByAlias(ctx context.Context, alias string) (string, error) {
+func (m *MockLink) GetURLByAlias(ctx context.Context, namespace, alias string) (dto0.Target, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetURLByAlias", ctx, alias)
-	ret0, _ := ret[0].(string)
+	ret := m.ctrl.Call(m, "GetURLByAlias", ctx, namespace, alias)
+	ret0, _ := ret[0].(dto0.Target)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetURLByAlias indicates an expected call of GetURLByAlias.
-func (mr *MockLinkMockRecorder) GetURLByAlias(ctx, alias any) *gomock.Call {
+func (mr *MockLinkMockRecorder) GetURLByAlias(ctx, namespace, alias any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetURLByAlias", reflect.TypeOf((*MockLink)(nil).GetURLByAlias), ctx, namespace, alias)
+}
+
+// GetURLByHost mocks base method.
+func (m *MockLink) GetURLByHost(ctx context.Context, host, alias string) (dto0.Target, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetURLByHost", ctx, host, alias)
+	ret0, _ := ret[0].(dto0.Target)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetURLByHost indicates an expected call of GetURLByHost.
+func (mr *MockLinkMockRecorder) GetURLByHost(ctx, host, alias any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetURLByHost", reflect.TypeOf((*MockLink)(nil).GetURLByHost), ctx, host, alias)
+}
+
+// ListLinks mocks base method.
+func (m *MockLink) ListLinks(ctx context.Context, access auth.Access, query dto0.ListLinks) ([]dto0.LinkInfo, string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListLinks", ctx, access, query)
+	ret0, _ := ret[0].([]dto0.LinkInfo)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ListLinks indicates an expected call of ListLinks.
+func (mr *MockLinkMockRecorder) ListLinks(ctx, access, query any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetURLByAlias", reflect.TypeOf((*MockLink)(nil).GetURLByAlias), ctx, alias)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListLinks", reflect.TypeOf((*MockLink)(nil).ListLinks), ctx, access, query)
+}
+
+// RestoreLink mocks base method.
+func (m *MockLink) RestoreLink(ctx context.Context, access auth.Access, host, alias string) (dto0.LinkInfo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreLink", ctx, access, host, alias)
+	ret0, _ := ret[0].(dto0.LinkInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RestoreLink indicates an expected call of RestoreLink.
+func (mr *MockLinkMockRecorder) RestoreLink(ctx, access, host, alias any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreLink", reflect.TypeOf((*MockLink)(nil).RestoreLink), ctx, access, host, alias)
 }
 
 // SaveLink mocks base method.
-func (m *MockLink) SaveLink(ctx context.Context, link dto0.Link, strategy retry.Strategy) (string, error) {
+func (m *MockLink) SaveLink(ctx context.Context, creator auth.Principal, link dto0.Link, strategy retry.Strategy) (dto0.ShortLink, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SaveLink", ctx, link, strategy)
-	ret0, _ := ret[0].(string)
+	ret := m.ctrl.Call(m, "SaveLink", ctx, creator, link, strategy)
+	ret0, _ := ret[0].(dto0.ShortLink)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // SaveLink indicates an expected call of SaveLink.
-func (mr *MockLinkMockRecorder) SaveLink(ctx, link, strategy any) *gomock.Call {
+func (mr *MockLinkMockRecorder) SaveLink(ctx, creator, link, strategy any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveLink", reflect.TypeOf((*MockLink)(nil).SaveLink), ctx, creator, link, strategy)
+}
+
+// UpdateLink mocks base method.
+func (m *MockLink) UpdateLink(ctx context.Context, access auth.Access, host, alias string, link dto0.UpdateLink) (dto0.LinkInfo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateLink", ctx, access, host, alias, link)
+	ret0, _ := ret[0].(dto0.LinkInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateLink indicates an expected call of UpdateLink.
+func (mr *MockLinkMockRecorder) UpdateLink(ctx, access, host, alias, link any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveLink", reflect.TypeOf((*MockLink)(nil).SaveLink), ctx, link, strategy)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateLink", reflect.TypeOf((*MockLink)(nil).UpdateLink), ctx, access, host, alias, link)
 }
 
 // MockClick is a mock of Click interface.
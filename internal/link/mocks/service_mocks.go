@@ -12,8 +12,12 @@ package mocks
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
+	uuid "github.com/google/uuid"
+	auth "github.com/ilam072/shortener/internal/auth"
 	domain "github.com/ilam072/shortener/internal/link/types/domain"
+	dto "github.com/ilam072/shortener/internal/webhook/types/dto"
 	gomock "go.uber.org/mock/gomock"
 )
 
@@ -41,6 +45,35 @@ func (m *MockLinkRepo) EXPECT() *MockLinkRepoMockRecorder {
 	return m.recorder
 }
 
+// ConsumeClick mocks base method.
+func (m *MockLinkRepo) ConsumeClick(ctx context.Context, id uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConsumeClick", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ConsumeClick indicates an expected call of ConsumeClick.
+func (mr *MockLinkRepoMockRecorder) ConsumeClick(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConsumeClick", reflect.TypeOf((*MockLinkRepo)(nil).ConsumeClick), ctx, id)
+}
+
+// CountClicks mocks base method.
+func (m *MockLinkRepo) CountClicks(ctx context.Context, workspaceID, linkID uuid.UUID) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountClicks", ctx, workspaceID, linkID)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountClicks indicates an expected call of CountClicks.
+func (mr *MockLinkRepoMockRecorder) CountClicks(ctx, workspaceID, linkID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountClicks", reflect.TypeOf((*MockLinkRepo)(nil).CountClicks), ctx, workspaceID, linkID)
+}
+
 // CreateLink mocks base method.
 func (m *MockLinkRepo) CreateLink(ctx context.Context, link domain.Link) (string, error) {
 	m.ctrl.T.Helper()
@@ -56,19 +89,121 @@ func (mr *MockLinkRepoMockRecorder) CreateLink(ctx, link any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateLink", reflect.TypeOf((*MockLinkRepo)(nil).CreateLink), ctx, link)
 }
 
-// GetURLByAlias mocks base method.
-func (m *MockLinkRepo) GetURLByAlias(ctx context.Context, alias string) (string, error) {
+// GetDomain mocks base method.
+func (m *MockLinkRepo) GetDomain(ctx context.Context, host string) (domain.Domain, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetURLByAlias", ctx, alias)
-	ret0, _ := ret[0].(string)
+	ret := m.ctrl.Call(m, "GetDomain", ctx, host)
+	ret0, _ := ret[0].(domain.Domain)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetURLByAlias indicates an expected call of GetURLByAlias.
-func (mr *MockLinkRepoMockRecorder) GetURLByAlias(ctx, alias any) *gomock.Call {
+// GetDomain indicates an expected call of GetDomain.
+func (mr *MockLinkRepoMockRecorder) GetDomain(ctx, host any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetURLByAlias", reflect.TypeOf((*MockLinkRepo)(nil).GetURLByAlias), ctx, alias)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDomain", reflect.TypeOf((*MockLinkRepo)(nil).GetDomain), ctx, host)
+}
+
+// GetLinkByAlias mocks base method.
+func (m *MockLinkRepo) GetLinkByAlias(ctx context.Context, access auth.Access, host, alias string) (domain.Link, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLinkByAlias", ctx, access, host, alias)
+	ret0, _ := ret[0].(domain.Link)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLinkByAlias indicates an expected call of GetLinkByAlias.
+func (mr *MockLinkRepoMockRecorder) GetLinkByAlias(ctx, access, host, alias any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLinkByAlias", reflect.TypeOf((*MockLinkRepo)(nil).GetLinkByAlias), ctx, access, host, alias)
+}
+
+// ListLinks mocks base method.
+func (m *MockLinkRepo) ListLinks(ctx context.Context, filter domain.LinkFilter) ([]domain.LinkWithClicks, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListLinks", ctx, filter)
+	ret0, _ := ret[0].([]domain.LinkWithClicks)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListLinks indicates an expected call of ListLinks.
+func (mr *MockLinkRepoMockRecorder) ListLinks(ctx, filter any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListLinks", reflect.TypeOf((*MockLinkRepo)(nil).ListLinks), ctx, filter)
+}
+
+// ReleaseDeletedAlias mocks base method.
+func (m *MockLinkRepo) ReleaseDeletedAlias(ctx context.Context, namespace string, domainID uuid.NullUUID, alias string, deletedBefore time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReleaseDeletedAlias", ctx, namespace, domainID, alias, deletedBefore)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReleaseDeletedAlias indicates an expected call of ReleaseDeletedAlias.
+func (mr *MockLinkRepoMockRecorder) ReleaseDeletedAlias(ctx, namespace, domainID, alias, deletedBefore any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReleaseDeletedAlias", reflect.TypeOf((*MockLinkRepo)(nil).ReleaseDeletedAlias), ctx, namespace, domainID, alias, deletedBefore)
+}
+
+// ResolveDomainLink mocks base method.
+func (m *MockLinkRepo) ResolveDomainLink(ctx context.Context, domainID uuid.UUID, alias string) (domain.Link, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResolveDomainLink", ctx, domainID, alias)
+	ret0, _ := ret[0].(domain.Link)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ResolveDomainLink indicates an expected call of ResolveDomainLink.
+func (mr *MockLinkRepoMockRecorder) ResolveDomainLink(ctx, domainID, alias any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResolveDomainLink", reflect.TypeOf((*MockLinkRepo)(nil).ResolveDomainLink), ctx, domainID, alias)
+}
+
+// ResolveLink mocks base method.
+func (m *MockLinkRepo) ResolveLink(ctx context.Context, namespace, alias string) (domain.Link, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResolveLink", ctx, namespace, alias)
+	ret0, _ := ret[0].(domain.Link)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ResolveLink indicates an expected call of ResolveLink.
+func (mr *MockLinkRepoMockRecorder) ResolveLink(ctx, namespace, alias any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResolveLink", reflect.TypeOf((*MockLinkRepo)(nil).ResolveLink), ctx, namespace, alias)
+}
+
+// SetStatus mocks base method.
+func (m *MockLinkRepo) SetStatus(ctx context.Context, access auth.Access, host, alias, status string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetStatus", ctx, access, host, alias, status)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetStatus indicates an expected call of SetStatus.
+func (mr *MockLinkRepoMockRecorder) SetStatus(ctx, access, host, alias, status any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetStatus", reflect.TypeOf((*MockLinkRepo)(nil).SetStatus), ctx, access, host, alias, status)
+}
+
+// UpdateURL mocks base method.
+func (m *MockLinkRepo) UpdateURL(ctx context.Context, access auth.Access, host, alias, url string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateURL", ctx, access, host, alias, url)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateURL indicates an expected call of UpdateURL.
+func (mr *MockLinkRepoMockRecorder) UpdateURL(ctx, access, host, alias, url any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateURL", reflect.TypeOf((*MockLinkRepo)(nil).UpdateURL), ctx, access, host, alias, url)
 }
 
 // MockLinkCache is a mock of LinkCache interface.
@@ -95,31 +230,81 @@ func (m *MockLinkCache) EXPECT() *MockLinkCacheMockRecorder {
 	return m.recorder
 }
 
-// GetURL mocks base method.
-func (m *MockLinkCache) GetURL(ctx context.Context, alias string) (string, error) {
+// DeleteTarget mocks base method.
+func (m *MockLinkCache) DeleteTarget(ctx context.Context, key string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetURL", ctx, alias)
-	ret0, _ := ret[0].(string)
+	ret := m.ctrl.Call(m, "DeleteTarget", ctx, key)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteTarget indicates an expected call of DeleteTarget.
+func (mr *MockLinkCacheMockRecorder) DeleteTarget(ctx, key any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteTarget", reflect.TypeOf((*MockLinkCache)(nil).DeleteTarget), ctx, key)
+}
+
+// GetTarget mocks base method.
+func (m *MockLinkCache) GetTarget(ctx context.Context, key string) (domain.Target, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTarget", ctx, key)
+	ret0, _ := ret[0].(domain.Target)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetURL indicates an expected call of GetURL.
-func (mr *MockLinkCacheMockRecorder) GetURL(ctx, alias any) *gomock.Call {
+// GetTarget indicates an expected call of GetTarget.
+func (mr *MockLinkCacheMockRecorder) GetTarget(ctx, key any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetURL", reflect.TypeOf((*MockLinkCache)(nil).GetURL), ctx, alias)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTarget", reflect.TypeOf((*MockLinkCache)(nil).GetTarget), ctx, key)
 }
 
-// SetURL mocks base method.
-func (m *MockLinkCache) SetURL(ctx context.Context, alias, url string) error {
+// SetTarget mocks base method.
+func (m *MockLinkCache) SetTarget(ctx context.Context, key string, target domain.Target, expiresAt *time.Time) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SetURL", ctx, alias, url)
+	ret := m.ctrl.Call(m, "SetTarget", ctx, key, target, expiresAt)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// SetURL indicates an expected call of SetURL.
-func (mr *MockLinkCacheMockRecorder) SetURL(ctx, alias, url any) *gomock.Call {
+// SetTarget indicates an expected call of SetTarget.
+func (mr *MockLinkCacheMockRecorder) SetTarget(ctx, key, target, expiresAt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetTarget", reflect.TypeOf((*MockLinkCache)(nil).SetTarget), ctx, key, target, expiresAt)
+}
+
+// MockLinkEvents is a mock of LinkEvents interface.
+type MockLinkEvents struct {
+	ctrl     *gomock.Controller
+	recorder *MockLinkEventsMockRecorder
+	isgomock struct{}
+}
+
+// MockLinkEventsMockRecorder is the mock recorder for MockLinkEvents.
+type MockLinkEventsMockRecorder struct {
+	mock *MockLinkEvents
+}
+
+// NewMockLinkEvents creates a new mock instance.
+func NewMockLinkEvents(ctrl *gomock.Controller) *MockLinkEvents {
+	mock := &MockLinkEvents{ctrl: ctrl}
+	mock.recorder = &MockLinkEventsMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockLinkEvents) EXPECT() *MockLinkEventsMockRecorder {
+	return m.recorder
+}
+
+// Emit mocks base method.
+func (m *MockLinkEvents) Emit(event dto.Event) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Emit", event)
+}
+
+// Emit indicates an expected call of Emit.
+func (mr *MockLinkEventsMockRecorder) Emit(event any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetURL", reflect.TypeOf((*MockLinkCache)(nil).SetURL), ctx, alias, url)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Emit", reflect.TypeOf((*MockLinkEvents)(nil).Emit), event)
 }
@@ -4,11 +4,17 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/ilam072/shortener/internal/auth"
 	"github.com/ilam072/shortener/internal/link/repo"
 	"github.com/ilam072/shortener/internal/link/types/domain"
 	"github.com/ilam072/shortener/pkg/errutils"
 	"github.com/lib/pq"
 	"github.com/wb-go/wbf/dbpg"
+	"strconv"
+	"strings"
+	"time"
 )
 
 type LinkRepo struct {
@@ -23,13 +29,25 @@ func (r *LinkRepo) CreateLink(ctx context.Context, link domain.Link) (string, er
 	const op = "repo.link.Create"
 
 	query := `
-		INSERT INTO links(id, url, alias)
-		VALUES ($1,$2,$3)
+		INSERT INTO links(id, workspace_id, namespace, domain_id, owner_id, url, alias, expires_at, max_clicks)
+		VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9)
 		RETURNING alias;
 	`
 
 	var alias string
-	if err := r.db.QueryRowContext(ctx, query, link.ID, link.URL, link.Alias).Scan(&alias); err != nil {
+	if err := r.db.QueryRowContext(
+		ctx,
+		query,
+		link.ID,
+		link.WorkspaceID,
+		link.Namespace,
+		link.DomainID,
+		link.OwnerID,
+		link.URL,
+		link.Alias,
+		link.ExpiresAt,
+		link.MaxClicks,
+	).Scan(&alias); err != nil {
 		if isUniqueViolation(err) {
 			return "", errutils.Wrap(op, repo.ErrAliasAlreadyExists)
 		}
@@ -39,25 +57,397 @@ func (r *LinkRepo) CreateLink(ctx context.Context, link domain.Link) (string, er
 	return alias, nil
 }
 
-func (r *LinkRepo) GetURLByAlias(ctx context.Context, alias string) (string, error) {
-	const op = "repo.link.GetURLByAlias"
+const linkColumns = `id, workspace_id, namespace, domain_id,
+		COALESCE((SELECT host FROM domains WHERE id = links.domain_id), ''), owner_id, url, alias, created_at,
+		expires_at, max_clicks, click_count, status, deleted_at`
+
+// onHost matches links on the custom domain whose host is the given
+// parameter, or links on the default hosts when it is empty.
+func onHost(param string) string {
+	return `(CASE WHEN ` + param + ` = '' THEN domain_id IS NULL
+		ELSE domain_id = (SELECT id FROM domains WHERE host = ` + param + `) END)`
+}
+
+// GetLinkByAlias looks a link up within the workspace of access. host is
+// the custom domain of the link, empty for the default hosts.
+func (r *LinkRepo) GetLinkByAlias(ctx context.Context, access auth.Access, host string, alias string) (domain.Link, error) {
+	const op = "repo.link.GetLinkByAlias"
 
 	query := `
-		SELECT url
+		SELECT ` + linkColumns + `
 		FROM links
-		WHERE alias = $1
+		WHERE alias = $1 AND workspace_id = $2 AND ($3 OR owner_id IS NOT DISTINCT FROM $4) AND ` + onHost("$5") + `
+		  AND released_at IS NULL
 		LIMIT 1;
 	`
 
-	var url string
-	if err := r.db.QueryRowContext(ctx, query, alias).Scan(&url); err != nil {
+	link, err := scanLink(r.db.QueryRowContext(ctx, query, alias, access.Workspace.ID, access.All, access.OwnerID, host))
+	if err != nil {
+		return domain.Link{}, errutils.Wrap(op, err)
+	}
+
+	return link, nil
+}
+
+// ResolveLink looks a link up by the public (namespace, alias) pair a
+// redirect on the default hosts is addressed by.
+func (r *LinkRepo) ResolveLink(ctx context.Context, namespace string, alias string) (domain.Link, error) {
+	const op = "repo.link.ResolveLink"
+
+	query := `
+		SELECT ` + linkColumns + `
+		FROM links
+		WHERE namespace = $1 AND alias = $2 AND domain_id IS NULL AND released_at IS NULL;
+	`
+
+	link, err := scanLink(r.db.QueryRowContext(ctx, query, namespace, alias))
+	if err != nil {
+		return domain.Link{}, errutils.Wrap(op, err)
+	}
+
+	return link, nil
+}
+
+// ResolveDomainLink looks a link up by the alias a redirect on a custom
+// domain is addressed by.
+func (r *LinkRepo) ResolveDomainLink(ctx context.Context, domainID uuid.UUID, alias string) (domain.Link, error) {
+	const op = "repo.link.ResolveDomainLink"
+
+	query := `
+		SELECT ` + linkColumns + `
+		FROM links
+		WHERE domain_id = $1 AND alias = $2 AND released_at IS NULL;
+	`
+
+	link, err := scanLink(r.db.QueryRowContext(ctx, query, domainID, alias))
+	if err != nil {
+		return domain.Link{}, errutils.Wrap(op, err)
+	}
+
+	return link, nil
+}
+
+func (r *LinkRepo) GetDomain(ctx context.Context, host string) (domain.Domain, error) {
+	const op = "repo.link.GetDomain"
+
+	query := `
+		SELECT id, workspace_id, host, fallback_url
+		FROM domains
+		WHERE host = $1;
+	`
+
+	var d domain.Domain
+	if err := r.db.QueryRowContext(ctx, query, host).Scan(
+		&d.ID,
+		&d.WorkspaceID,
+		&d.Host,
+		&d.FallbackURL,
+	); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return "", errutils.Wrap(op, repo.ErrAliasNotFound)
+			return domain.Domain{}, errutils.Wrap(op, repo.ErrDomainNotFound)
 		}
-		return "", errutils.Wrap(op, err)
+		return domain.Domain{}, errutils.Wrap(op, err)
+	}
+
+	return d, nil
+}
+
+func scanLink(row *sql.Row) (domain.Link, error) {
+	var link domain.Link
+	if err := row.Scan(
+		&link.ID,
+		&link.WorkspaceID,
+		&link.Namespace,
+		&link.DomainID,
+		&link.Host,
+		&link.OwnerID,
+		&link.URL,
+		&link.Alias,
+		&link.CreatedAt,
+		&link.ExpiresAt,
+		&link.MaxClicks,
+		&link.Clicks,
+		&link.Status,
+		&link.DeletedAt,
+	); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.Link{}, repo.ErrAliasNotFound
+		}
+		return domain.Link{}, err
+	}
+	return link, nil
+}
+
+func (r *LinkRepo) UpdateURL(ctx context.Context, access auth.Access, host string, alias string, url string) error {
+	const op = "repo.link.UpdateURL"
+
+	query := `
+		UPDATE links
+		SET url = $2
+		WHERE alias = $1 AND workspace_id = $3 AND status <> 'deleted'
+		  AND ($4 OR owner_id IS NOT DISTINCT FROM $5) AND ` + onHost("$6") + `
+		  AND released_at IS NULL;
+	`
+
+	res, err := r.db.ExecContext(ctx, query, alias, url, access.Workspace.ID, access.All, access.OwnerID, host)
+	if err != nil {
+		return errutils.Wrap(op, err)
+	}
+
+	if err = checkAffected(res); err != nil {
+		return errutils.Wrap(op, err)
+	}
+
+	return nil
+}
+
+// SetStatus moves a link to status. deleted_at is stamped when the link
+// is deleted and cleared when it leaves the deleted status. A deleted link
+// can only be restored: disabling it would end its quarantine, so it fails
+// with ErrLinkDeleted.
+func (r *LinkRepo) SetStatus(ctx context.Context, access auth.Access, host string, alias string, status string) error {
+	const op = "repo.link.SetStatus"
+
+	query := `
+		WITH target AS (
+			SELECT id, status
+			FROM links
+			WHERE alias = $1 AND workspace_id = $3 AND ($4 OR owner_id IS NOT DISTINCT FROM $5)
+			  AND ` + onHost("$6") + ` AND released_at IS NULL
+		), updated AS (
+			UPDATE links
+			SET status = $2,
+			    deleted_at = CASE
+			        WHEN $2 = 'deleted' THEN COALESCE(deleted_at, now())
+			    END
+			WHERE id IN (
+				SELECT id FROM target WHERE NOT ($2 = 'disabled' AND status = 'deleted')
+			)
+			RETURNING id
+		)
+		SELECT (SELECT COUNT(*) FROM target), (SELECT COUNT(*) FROM updated);
+	`
+
+	var found, updated int
+	if err := r.db.QueryRowContext(ctx, query, alias, status, access.Workspace.ID, access.All, access.OwnerID, host).
+		Scan(&found, &updated); err != nil {
+		return errutils.Wrap(op, err)
+	}
+	if found == 0 {
+		return errutils.Wrap(op, repo.ErrAliasNotFound)
+	}
+	if updated == 0 {
+		return errutils.Wrap(op, repo.ErrLinkDeleted)
+	}
+
+	return nil
+}
+
+// ReleaseDeletedAlias releases the alias of a link that was deleted
+// before deletedBefore, for a new link to take it. The deleted link is
+// kept, along with its clicks, but can no longer be looked up by alias.
+func (r *LinkRepo) ReleaseDeletedAlias(
+	ctx context.Context,
+	namespace string,
+	domainID uuid.NullUUID,
+	alias string,
+	deletedBefore time.Time,
+) error {
+	const op = "repo.link.ReleaseDeletedAlias"
+
+	query := `
+		UPDATE links
+		SET released_at = now()
+		WHERE namespace = $1 AND domain_id IS NOT DISTINCT FROM $2 AND alias = $3
+		  AND status = 'deleted' AND deleted_at < $4 AND released_at IS NULL;
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, namespace, domainID, alias, deletedBefore); err != nil {
+		return errutils.Wrap(op, err)
+	}
+
+	return nil
+}
+
+// humanClicks counts the clicks of a link that were not made by bots: the
+// daily totals of rolled up clicks plus the raw clicks not rolled up yet.
+// %[1]s is the workspace id and %[2]s the link id, so that a list joins it
+// per link and only pays for the links it reads.
+const humanClicks = `
+	SELECT COALESCE(SUM(clicks), 0)::bigint AS clicks
+	FROM (
+		SELECT COUNT(*) AS clicks
+		FROM clicks
+		WHERE workspace_id = %[1]s AND link_id = %[2]s AND NOT rolled_up AND NOT is_bot
+		UNION ALL
+		SELECT SUM(clicks) AS clicks
+		FROM click_rollups
+		WHERE workspace_id = %[1]s AND link_id = %[2]s AND dimension = '' AND NOT is_bot
+	) t`
+
+// CountClicks counts the clicks of a link that were not made by bots,
+// including those already rolled up.
+func (r *LinkRepo) CountClicks(ctx context.Context, workspaceID uuid.UUID, linkID uuid.UUID) (int, error) {
+	const op = "repo.link.CountClicks"
+
+	query := fmt.Sprintf(humanClicks, "$1", "$2") + ";"
+
+	var clicks int
+	if err := r.db.QueryRowContext(ctx, query, workspaceID, linkID).Scan(&clicks); err != nil {
+		return 0, errutils.Wrap(op, err)
+	}
+
+	return clicks, nil
+}
+
+// ConsumeClick atomically spends one click of a limited link. It returns
+// ErrClickLimitReached once click_count has reached max_clicks.
+func (r *LinkRepo) ConsumeClick(ctx context.Context, id uuid.UUID) error {
+	const op = "repo.link.ConsumeClick"
+
+	query := `
+		UPDATE links
+		SET click_count = click_count + 1
+		WHERE id = $1 AND max_clicks IS NOT NULL AND click_count < max_clicks;
+	`
+
+	res, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return errutils.Wrap(op, err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return errutils.Wrap(op, err)
+	}
+	if affected == 0 {
+		return errutils.Wrap(op, repo.ErrClickLimitReached)
+	}
+
+	return nil
+}
+
+func (r *LinkRepo) ListLinks(ctx context.Context, filter domain.LinkFilter) ([]domain.LinkWithClicks, error) {
+	const op = "repo.link.List"
+
+	query, args := buildListQuery(filter)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, errutils.Wrap(op, err)
 	}
+	defer rows.Close()
 
-	return url, nil
+	var links []domain.LinkWithClicks
+	for rows.Next() {
+		var link domain.LinkWithClicks
+		if err := rows.Scan(
+			&link.ID,
+			&link.WorkspaceID,
+			&link.Namespace,
+			&link.DomainID,
+			&link.Host,
+			&link.OwnerID,
+			&link.URL,
+			&link.Alias,
+			&link.CreatedAt,
+			&link.ExpiresAt,
+			&link.MaxClicks,
+			&link.Clicks,
+			&link.Status,
+			&link.DeletedAt,
+			&link.TotalClicks,
+		); err != nil {
+			return nil, errutils.Wrap(op, err)
+		}
+		links = append(links, link)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errutils.Wrap(op, err)
+	}
+
+	return links, nil
+}
+
+func buildListQuery(filter domain.LinkFilter) (string, []interface{}) {
+	var (
+		conds []string
+		args  []interface{}
+	)
+	arg := func(v interface{}) string {
+		args = append(args, v)
+		return "$" + strconv.Itoa(len(args))
+	}
+
+	conds = append(conds, "l.workspace_id = "+arg(filter.Access.Workspace.ID), "l.released_at IS NULL")
+	if !filter.Access.All {
+		conds = append(conds, "l.owner_id IS NOT DISTINCT FROM "+arg(filter.Access.OwnerID))
+	}
+	if filter.CreatedFrom != nil {
+		conds = append(conds, "l.created_at >= "+arg(*filter.CreatedFrom))
+	}
+	if filter.CreatedTo != nil {
+		conds = append(conds, "l.created_at < "+arg(*filter.CreatedTo))
+	}
+	if filter.Host != "" {
+		conds = append(conds, "lower(substring(l.url from '^[^:]+://([^/:?#]+)')) = lower("+arg(filter.Host)+")")
+	}
+	if filter.AliasPrefix != "" {
+		conds = append(conds, "l.alias LIKE "+arg(escapeLike(filter.AliasPrefix)+"%"))
+	}
+
+	sortKey := "l.created_at"
+	if filter.Sort == domain.SortByClicks {
+		sortKey = "c.clicks"
+	}
+	cmp, dir := ">", "ASC"
+	if filter.Desc {
+		cmp, dir = "<", "DESC"
+	}
+
+	if after := filter.After; after != nil {
+		// created_at is stored without time zone, so the cursor value is
+		// compared as a plain timestamp to round-trip exactly.
+		value := arg(after.CreatedAt.Format(time.RFC3339Nano)) + "::timestamp"
+		if filter.Sort == domain.SortByClicks {
+			value = arg(after.Clicks)
+		}
+		conds = append(conds, fmt.Sprintf("(%s, l.id) %s (%s, %s)", sortKey, cmp, value, arg(after.ID)))
+	}
+
+	where := ""
+	if len(conds) > 0 {
+		where = "WHERE " + strings.Join(conds, " AND ")
+	}
+
+	query := fmt.Sprintf(`
+		SELECT l.id, l.workspace_id, l.namespace, l.domain_id, COALESCE(d.host, ''), l.owner_id, l.url, l.alias,
+		       l.created_at, l.expires_at, l.max_clicks, l.click_count, l.status, l.deleted_at, c.clicks
+		FROM links l
+		LEFT JOIN domains d ON d.id = l.domain_id
+		CROSS JOIN LATERAL (%s) c
+		%s
+		ORDER BY %s %s, l.id %s
+		LIMIT %s;
+	`, fmt.Sprintf(humanClicks, "l.workspace_id", "l.id"), where, sortKey, dir, dir, arg(filter.Limit))
+
+	return query, args
+}
+
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
+
+// checkAffected reports ErrAliasNotFound when a mutation matched no link.
+func checkAffected(res sql.Result) error {
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return repo.ErrAliasNotFound
+	}
+	return nil
 }
 
 func isUniqueViolation(err error) bool {
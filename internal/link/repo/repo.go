@@ -5,4 +5,7 @@ import "errors"
 var (
 	ErrAliasAlreadyExists = errors.New("alias already exists")
 	ErrAliasNotFound      = errors.New("alias not found")
+	ErrClickLimitReached  = errors.New("click limit reached")
+	ErrDomainNotFound     = errors.New("domain not found")
+	ErrLinkDeleted        = errors.New("link deleted")
 )
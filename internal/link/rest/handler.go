@@ -5,6 +5,8 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"github.com/google/uuid"
+	"github.com/ilam072/shortener/internal/auth"
 	_ "github.com/ilam072/shortener/internal/click/types/dto"
 	clickdto "github.com/ilam072/shortener/internal/click/types/dto"
 	"github.com/ilam072/shortener/internal/link/service"
@@ -17,13 +19,27 @@ import (
 	"github.com/wb-go/wbf/zlog"
 	"net"
 	"net/http"
+	"net/url"
 	"strings"
 )
 
 //go:generate mockgen -source=handler.go -destination=../mocks/rest_mocks.go -package=mocks
 type Link interface {
-	SaveLink(ctx context.Context, link linkdto.Link, strategy retry.Strategy) (string, error)
-	GetURLByAlias(ctx context.Context, alias string) (string, error)
+	SaveLink(ctx context.Context, creator auth.Principal, link linkdto.Link, strategy retry.Strategy) (linkdto.ShortLink, error)
+	GetURLByAlias(ctx context.Context, namespace string, alias string) (linkdto.Target, error)
+	GetURLByHost(ctx context.Context, host string, alias string) (linkdto.Target, error)
+	GetLink(ctx context.Context, access auth.Access, host string, alias string) (linkdto.LinkInfo, error)
+	ListLinks(ctx context.Context, access auth.Access, query linkdto.ListLinks) ([]linkdto.LinkInfo, string, error)
+	UpdateLink(
+		ctx context.Context,
+		access auth.Access,
+		host string,
+		alias string,
+		link linkdto.UpdateLink,
+	) (linkdto.LinkInfo, error)
+	DeleteLink(ctx context.Context, access auth.Access, host string, alias string) error
+	DisableLink(ctx context.Context, access auth.Access, host string, alias string) (linkdto.LinkInfo, error)
+	RestoreLink(ctx context.Context, access auth.Access, host string, alias string) (linkdto.LinkInfo, error)
 }
 
 type Click interface {
@@ -47,14 +63,19 @@ func NewLinkHandler(link Link, click Click, validator Validator, strategy retry.
 
 // CreateLink godoc
 // @Summary Создать короткую ссылку
-// @Description Создаёт новую короткую ссылку. Alias можно передать вручную или он будет сгенерирован автоматически
+// @Description Создаёт новую короткую ссылку. Alias можно передать вручную или он будет сгенерирован автоматически. Если указан domain, ссылка создаётся на собственном домене рабочего пространства
 // @Tags Links
 // @Accept json
 // @Produce json
+// @Security ApiKeyAuth
+// @Security BearerAuth
 // @Param input body dto.Link true "Данные для создания ссылки"
-// @Success 201 {object} response.Response "alias созданной ссылки"
-// @Failure 400 {object} response.Response "invalid request body или validation error"
-// @Failure 409 {object} response.Response "alias already exists"
+// @Success 201 {object} response.Response{payload=dto.ShortLink} "alias и полный короткий URL созданной ссылки"
+// @Failure 400 {object} response.Response "invalid request body, validation error или expiration in the past"
+// @Failure 401 {object} response.Response "invalid credentials"
+// @Failure 403 {object} response.Response "insufficient scope"
+// @Failure 404 {object} response.Response "domain not found"
+// @Failure 409 {object} response.Response "alias already exists или alias is reserved"
 // @Failure 500 {object} response.Response "internal server error"
 // @Router /shorten [post]
 func (h *LinkHandler) CreateLink(c *ginext.Context) {
@@ -67,29 +88,45 @@ func (h *LinkHandler) CreateLink(c *ginext.Context) {
 		response.Error(fmt.Sprintf("validation error: %s", err.Error())).WriteJSON(c, http.StatusBadRequest)
 		return
 	}
-	alias, err := h.link.SaveLink(c.Request.Context(), link, h.strategy)
+	creator := auth.FromContext(c.Request.Context())
+	created, err := h.link.SaveLink(c.Request.Context(), creator, link, h.strategy)
 	if err != nil {
+		if errors.Is(err, service.ErrInvalidExpiration) {
+			response.Error("expiration must be in the future and ttl at most 10 years").WriteJSON(c, http.StatusBadRequest)
+			return
+		}
 		if errors.Is(err, service.ErrAliasAlreadyExists) {
 			response.Error("url with such alias already exists").WriteJSON(c, http.StatusConflict)
 			return
 		}
+		if errors.Is(err, service.ErrAliasReserved) {
+			response.Error("alias is reserved").WriteJSON(c, http.StatusConflict)
+			return
+		}
+		if errors.Is(err, service.ErrDomainNotFound) {
+			response.Error("domain not found").WriteJSON(c, http.StatusNotFound)
+			return
+		}
 		zlog.Logger.Error().Err(err).Str("alias", link.Alias).Msg("failed to save short link")
 		response.Error("internal server error, try again later").WriteJSON(c, http.StatusInternalServerError)
 		return
 	}
-	response.Success(alias).WriteJSON(c, http.StatusCreated)
+	response.Success(created).WriteJSON(c, http.StatusCreated)
 }
 
 // Redirect godoc
 // @Summary Редирект по короткой ссылке
-// @Description Перенаправляет пользователя на оригинальный URL по alias и сохраняет информацию о клике
+// @Description Перенаправляет пользователя на оригинальный URL по alias и сохраняет информацию о клике. При ALIAS_NAMESPACE=workspace alias указывается вместе со slug рабочего пространства. Путь задаётся REDIRECT_PREFIX: по умолчанию /api/s, при REDIRECT_PREFIX=/ редирект доступен прямо по /{alias}
 // @Tags Links
+// @Param workspace path string true "Slug рабочего пространства"
 // @Param alias path string true "Alias ссылки"
 // @Success 302 "Redirect to original URL"
 // @Failure 400 {object} response.Response "alias must not be empty"
-// @Failure 404 {object} response.Response "alias not found"
+// @Failure 404 {object} response.Response "alias not found или link is disabled"
+// @Failure 410 {object} response.Response "link has expired, click limit reached или link has been deleted"
 // @Failure 500 {object} response.Response "internal server error"
 // @Router /s/{alias} [get]
+// @Router /s/{workspace}/{alias} [get]
 func (h *LinkHandler) Redirect(c *ginext.Context) {
 	alias := c.Param("alias")
 	if alias == "" {
@@ -97,33 +134,327 @@ func (h *LinkHandler) Redirect(c *ginext.Context) {
 		return
 	}
 
-	url, err := h.link.GetURLByAlias(c.Request.Context(), alias)
+	// The workspace segment is only routed when aliases are per workspace.
+	target, err := h.link.GetURLByAlias(c.Request.Context(), c.Param("workspace"), alias)
+	h.redirect(c, alias, target, err)
+}
+
+// RedirectByHost serves short links of custom domains at https://{host}/{alias}.
+// It is routed by middleware.HostRouting rather than by path.
+func (h *LinkHandler) RedirectByHost(c *ginext.Context) {
+	host, _, err := net.SplitHostPort(c.Request.Host)
 	if err != nil {
+		host = c.Request.Host
+	}
+	alias := strings.TrimPrefix(c.Request.URL.Path, "/")
+
+	target, err := h.link.GetURLByHost(c.Request.Context(), host, alias)
+	h.redirect(c, alias, target, err)
+}
+
+// redirect answers a resolved short link: the error of the resolution, or
+// a redirect to target after recording the click.
+func (h *LinkHandler) redirect(c *ginext.Context, alias string, target linkdto.Target, err error) {
+	if err != nil {
+		if errors.Is(err, service.ErrDomainNotFound) {
+			response.Error("domain not found").WriteJSON(c, http.StatusNotFound)
+			return
+		}
 		if errors.Is(err, service.ErrAliasNotFound) {
 			response.Error("alias not found").WriteJSON(c, http.StatusNotFound)
 			return
 		}
+		if errors.Is(err, service.ErrLinkDisabled) {
+			response.Error("link is disabled").WriteJSON(c, http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, service.ErrLinkDeleted) {
+			response.Error("link has been deleted").WriteJSON(c, http.StatusGone)
+			return
+		}
+		if errors.Is(err, service.ErrLinkExpired) {
+			response.Error("link has expired").WriteJSON(c, http.StatusGone)
+			return
+		}
+		if errors.Is(err, service.ErrClickLimitReached) {
+			response.Error("link click limit reached").WriteJSON(c, http.StatusGone)
+			return
+		}
 		zlog.Logger.Error().Err(err).Str("alias", alias).Msg("failed to get url by alias")
 		response.Error("internal server error, try again later").WriteJSON(c, http.StatusInternalServerError)
 		return
 	}
 
+	// Fallback redirects of custom domains belong to no link.
+	if target.LinkID == uuid.Nil {
+		http.Redirect(c.Writer, c.Request, target.URL, http.StatusFound)
+		return
+	}
+
 	userAgent := c.GetHeader("User-Agent")
-	client, device := parseClientInfo(userAgent)
+	client := parseClientInfo(userAgent)
 
 	click := clickdto.Click{
-		Alias:     alias,
-		UserAgent: userAgent,
-		Client:    client,
-		Device:    device,
-		IP:        getClientIP(c),
+		LinkID:        target.LinkID,
+		WorkspaceID:   target.WorkspaceID,
+		Alias:         alias,
+		UserAgent:     userAgent,
+		Client:        client.browser,
+		ClientVersion: client.browserVersion,
+		Device:        client.device,
+		OS:            client.os,
+		OSVersion:     client.osVersion,
+		Referrer:      referrerHost(c.GetHeader("Referer")),
+		IP:            getClientIP(c),
 	}
 
 	if err = h.click.SaveClick(c.Request.Context(), click); err != nil {
 		zlog.Logger.Error().Err(err).Str("alias", alias).Msg("failed to save click")
 	}
 
-	http.Redirect(c.Writer, c.Request, url, http.StatusFound)
+	http.Redirect(c.Writer, c.Request, target.URL, http.StatusFound)
+}
+
+// GetLink godoc
+// @Summary Получить информацию о ссылке
+// @Description Возвращает оригинальный URL, дату создания и количество кликов по alias без учёта ботов
+// @Tags Links
+// @Produce json
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Param alias path string true "Alias ссылки"
+// @Param domain query string false "Собственный домен ссылки"
+// @Success 200 {object} response.Response{payload=dto.LinkInfo} "Информация о ссылке"
+// @Failure 400 {object} response.Response "alias must not be empty"
+// @Failure 401 {object} response.Response "invalid credentials"
+// @Failure 403 {object} response.Response "insufficient scope"
+// @Failure 404 {object} response.Response "alias not found"
+// @Failure 500 {object} response.Response "internal server error"
+// @Router /links/{alias} [get]
+func (h *LinkHandler) GetLink(c *ginext.Context) {
+	alias := c.Param("alias")
+	if alias == "" {
+		response.Error("alias must not be empty.").WriteJSON(c, http.StatusBadRequest)
+		return
+	}
+
+	access := auth.FromContext(c.Request.Context()).Access()
+	info, err := h.link.GetLink(c.Request.Context(), access, c.Query("domain"), alias)
+	if err != nil {
+		if errors.Is(err, service.ErrAliasNotFound) {
+			response.Error("alias not found").WriteJSON(c, http.StatusNotFound)
+			return
+		}
+		zlog.Logger.Error().Err(err).Str("alias", alias).Msg("failed to get link")
+		response.Error("internal server error, try again later").WriteJSON(c, http.StatusInternalServerError)
+		return
+	}
+
+	response.Success(info).WriteJSON(c, http.StatusOK)
+}
+
+// ListLinks godoc
+// @Summary Список ссылок
+// @Description Возвращает ссылки с фильтрами по дате создания, хосту назначения и префиксу alias. Пагинация по курсору
+// @Tags Links
+// @Produce json
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Param created_from query string false "Начало периода создания (RFC3339)"
+// @Param created_to query string false "Конец периода создания (RFC3339, не включительно)"
+// @Param host query string false "Хост оригинального URL"
+// @Param alias_prefix query string false "Префикс alias"
+// @Param sort query string false "Поле сортировки" Enums(created_at, clicks)
+// @Param order query string false "Направление сортировки" Enums(asc, desc)
+// @Param limit query int false "Размер страницы (1-100, по умолчанию 20)"
+// @Param cursor query string false "Курсор следующей страницы"
+// @Success 200 {object} response.Response{payload=response.Page{items=[]dto.LinkInfo}} "Страница ссылок"
+// @Failure 400 {object} response.Response "invalid query или invalid cursor"
+// @Failure 401 {object} response.Response "invalid credentials"
+// @Failure 403 {object} response.Response "insufficient scope"
+// @Failure 500 {object} response.Response "internal server error"
+// @Router /links [get]
+func (h *LinkHandler) ListLinks(c *ginext.Context) {
+	var query linkdto.ListLinks
+	if err := c.ShouldBindQuery(&query); err != nil {
+		response.Error("invalid query parameters").WriteJSON(c, http.StatusBadRequest)
+		return
+	}
+	if err := h.validator.Validate(query); err != nil {
+		response.Error(fmt.Sprintf("validation error: %s", err.Error())).WriteJSON(c, http.StatusBadRequest)
+		return
+	}
+
+	access := auth.FromContext(c.Request.Context()).Access()
+	links, next, err := h.link.ListLinks(c.Request.Context(), access, query)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidCursor) {
+			response.Error("invalid cursor").WriteJSON(c, http.StatusBadRequest)
+			return
+		}
+		zlog.Logger.Error().Err(err).Msg("failed to list links")
+		response.Error("internal server error, try again later").WriteJSON(c, http.StatusInternalServerError)
+		return
+	}
+
+	response.Paginated(links, next).WriteJSON(c, http.StatusOK)
+}
+
+// UpdateLink godoc
+// @Summary Изменить оригинальный URL ссылки
+// @Description Меняет URL, на который ведёт alias, и сбрасывает закэшированный редирект
+// @Tags Links
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Param alias path string true "Alias ссылки"
+// @Param domain query string false "Собственный домен ссылки"
+// @Param input body dto.UpdateLink true "Новый URL"
+// @Success 200 {object} response.Response{payload=dto.LinkInfo} "Обновлённая ссылка"
+// @Failure 400 {object} response.Response "invalid request body или validation error"
+// @Failure 401 {object} response.Response "invalid credentials"
+// @Failure 403 {object} response.Response "insufficient scope"
+// @Failure 404 {object} response.Response "alias not found"
+// @Failure 500 {object} response.Response "internal server error"
+// @Router /links/{alias} [patch]
+func (h *LinkHandler) UpdateLink(c *ginext.Context) {
+	alias := c.Param("alias")
+	if alias == "" {
+		response.Error("alias must not be empty.").WriteJSON(c, http.StatusBadRequest)
+		return
+	}
+
+	var link linkdto.UpdateLink
+	if err := json.NewDecoder(c.Request.Body).Decode(&link); err != nil {
+		response.Error("invalid request body").WriteJSON(c, http.StatusBadRequest)
+		return
+	}
+	if err := h.validator.Validate(link); err != nil {
+		response.Error(fmt.Sprintf("validation error: %s", err.Error())).WriteJSON(c, http.StatusBadRequest)
+		return
+	}
+
+	access := auth.FromContext(c.Request.Context()).Access()
+	info, err := h.link.UpdateLink(c.Request.Context(), access, c.Query("domain"), alias, link)
+	if err != nil {
+		if errors.Is(err, service.ErrAliasNotFound) {
+			response.Error("alias not found").WriteJSON(c, http.StatusNotFound)
+			return
+		}
+		zlog.Logger.Error().Err(err).Str("alias", alias).Msg("failed to update link")
+		response.Error("internal server error, try again later").WriteJSON(c, http.StatusInternalServerError)
+		return
+	}
+
+	response.Success(info).WriteJSON(c, http.StatusOK)
+}
+
+// DeleteLink godoc
+// @Summary Удалить ссылку
+// @Description Помечает ссылку удалённой: редирект перестаёт работать, а аналитика по кликам сохраняется. Пока alias в карантине, ссылку можно восстановить. Когда alias занимает новая ссылка, удалённая ссылка и её клики остаются в базе, но по alias она больше не находится
+// @Tags Links
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Param alias path string true "Alias ссылки"
+// @Param domain query string false "Собственный домен ссылки"
+// @Success 204 "Link deleted"
+// @Failure 400 {object} response.Response "alias must not be empty"
+// @Failure 401 {object} response.Response "invalid credentials"
+// @Failure 403 {object} response.Response "insufficient scope"
+// @Failure 404 {object} response.Response "alias not found"
+// @Failure 500 {object} response.Response "internal server error"
+// @Router /links/{alias} [delete]
+func (h *LinkHandler) DeleteLink(c *ginext.Context) {
+	alias := c.Param("alias")
+	if alias == "" {
+		response.Error("alias must not be empty.").WriteJSON(c, http.StatusBadRequest)
+		return
+	}
+
+	access := auth.FromContext(c.Request.Context()).Access()
+	if err := h.link.DeleteLink(c.Request.Context(), access, c.Query("domain"), alias); err != nil {
+		if errors.Is(err, service.ErrAliasNotFound) {
+			response.Error("alias not found").WriteJSON(c, http.StatusNotFound)
+			return
+		}
+		zlog.Logger.Error().Err(err).Str("alias", alias).Msg("failed to delete link")
+		response.Error("internal server error, try again later").WriteJSON(c, http.StatusInternalServerError)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// DisableLink godoc
+// @Summary Отключить ссылку
+// @Description Временно отключает редирект по alias, не удаляя ссылку. Удалённую ссылку отключить нельзя, её нужно сначала восстановить
+// @Tags Links
+// @Produce json
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Param alias path string true "Alias ссылки"
+// @Param domain query string false "Собственный домен ссылки"
+// @Success 200 {object} response.Response{payload=dto.LinkInfo} "Отключённая ссылка"
+// @Failure 400 {object} response.Response "alias must not be empty"
+// @Failure 401 {object} response.Response "invalid credentials"
+// @Failure 403 {object} response.Response "insufficient scope"
+// @Failure 404 {object} response.Response "alias not found"
+// @Failure 409 {object} response.Response "link is deleted, restore it first"
+// @Failure 500 {object} response.Response "internal server error"
+// @Router /links/{alias}/disable [post]
+func (h *LinkHandler) DisableLink(c *ginext.Context) {
+	h.changeStatus(c, h.link.DisableLink, "failed to disable link")
+}
+
+// RestoreLink godoc
+// @Summary Восстановить ссылку
+// @Description Снова включает отключённую или удалённую ссылку
+// @Tags Links
+// @Produce json
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Param alias path string true "Alias ссылки"
+// @Param domain query string false "Собственный домен ссылки"
+// @Success 200 {object} response.Response{payload=dto.LinkInfo} "Восстановленная ссылка"
+// @Failure 400 {object} response.Response "alias must not be empty"
+// @Failure 401 {object} response.Response "invalid credentials"
+// @Failure 403 {object} response.Response "insufficient scope"
+// @Failure 404 {object} response.Response "alias not found"
+// @Failure 500 {object} response.Response "internal server error"
+// @Router /links/{alias}/restore [post]
+func (h *LinkHandler) RestoreLink(c *ginext.Context) {
+	h.changeStatus(c, h.link.RestoreLink, "failed to restore link")
+}
+
+func (h *LinkHandler) changeStatus(
+	c *ginext.Context,
+	change func(ctx context.Context, access auth.Access, host string, alias string) (linkdto.LinkInfo, error),
+	failMsg string,
+) {
+	alias := c.Param("alias")
+	if alias == "" {
+		response.Error("alias must not be empty.").WriteJSON(c, http.StatusBadRequest)
+		return
+	}
+
+	access := auth.FromContext(c.Request.Context()).Access()
+	info, err := change(c.Request.Context(), access, c.Query("domain"), alias)
+	if err != nil {
+		if errors.Is(err, service.ErrAliasNotFound) {
+			response.Error("alias not found").WriteJSON(c, http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, service.ErrLinkDeleted) {
+			response.Error("link is deleted, restore it first").WriteJSON(c, http.StatusConflict)
+			return
+		}
+		zlog.Logger.Error().Err(err).Str("alias", alias).Msg(failMsg)
+		response.Error("internal server error, try again later").WriteJSON(c, http.StatusInternalServerError)
+		return
+	}
+
+	response.Success(info).WriteJSON(c, http.StatusOK)
 }
 
 func getClientIP(c *ginext.Context) string {
@@ -137,14 +468,39 @@ func getClientIP(c *ginext.Context) string {
 	return ip
 }
 
-func parseClientInfo(uaString string) (string, string) {
+type clientInfo struct {
+	browser        string
+	browserVersion string
+	device         string
+	os             string
+	osVersion      string
+}
+
+func parseClientInfo(uaString string) clientInfo {
 	ua := user_agent.New(uaString)
-	name, _ := ua.Browser()
+	browser, browserVersion := ua.Browser()
 	device := "desktop"
 	if ua.Mobile() {
 		device = "mobile"
 	} else if ua.Bot() {
 		device = "bot"
 	}
-	return name, device
+	os := ua.OSInfo()
+	return clientInfo{
+		browser:        browser,
+		browserVersion: browserVersion,
+		device:         device,
+		os:             os.Name,
+		osVersion:      os.Version,
+	}
+}
+
+// referrerHost reduces a Referer to its host, so neither paths nor query
+// strings of the referring pages are stored.
+func referrerHost(referer string) string {
+	u, err := url.Parse(referer)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(u.Hostname())
 }
@@ -2,6 +2,7 @@ package rest_test
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"github.com/wb-go/wbf/ginext"
@@ -11,6 +12,7 @@ import (
 	"testing"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 
 	"github.com/stretchr/testify/require"
 
@@ -76,12 +78,27 @@ func TestLinkHandler_CreateLink(t *testing.T) {
 						Validate(gomock.Any()).
 						Return(nil)
 					link.EXPECT().
-						SaveLink(gomock.Any(), gomock.Any(), gomock.Any()).
-						Return("", service.ErrAliasAlreadyExists)
+						SaveLink(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+						Return(linkdto.ShortLink{}, service.ErrAliasAlreadyExists)
 				},
 			},
 			want: want{status: http.StatusConflict},
 		},
+		{
+			name: "expiration in the past",
+			body: linkdto.Link{URL: "https://example.com"},
+			fields: fields{
+				setup: func(link *mocks.MockLink, validator *mocks.MockValidator) {
+					validator.EXPECT().
+						Validate(gomock.Any()).
+						Return(nil)
+					link.EXPECT().
+						SaveLink(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+						Return(linkdto.ShortLink{}, service.ErrInvalidExpiration)
+				},
+			},
+			want: want{status: http.StatusBadRequest},
+		},
 		{
 			name: "internal error",
 			body: linkdto.Link{URL: "https://example.com"},
@@ -91,8 +108,8 @@ func TestLinkHandler_CreateLink(t *testing.T) {
 						Validate(gomock.Any()).
 						Return(nil)
 					link.EXPECT().
-						SaveLink(gomock.Any(), gomock.Any(), gomock.Any()).
-						Return("", errors.New("db error"))
+						SaveLink(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+						Return(linkdto.ShortLink{}, errors.New("db error"))
 				},
 			},
 			want: want{status: http.StatusInternalServerError},
@@ -106,8 +123,8 @@ func TestLinkHandler_CreateLink(t *testing.T) {
 						Validate(gomock.Any()).
 						Return(nil)
 					link.EXPECT().
-						SaveLink(gomock.Any(), gomock.Any(), gomock.Any()).
-						Return("abc123", nil)
+						SaveLink(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+						Return(linkdto.ShortLink{Alias: "abc123", URL: "https://sho.rt/abc123"}, nil)
 				},
 			},
 			want: want{status: http.StatusCreated},
@@ -171,20 +188,68 @@ func TestLinkHandler_Redirect(t *testing.T) {
 			fields: fields{
 				setup: func(link *mocks.MockLink, click *mocks.MockClick) {
 					link.EXPECT().
-						GetURLByAlias(gomock.Any(), "abc").
-						Return("", service.ErrAliasNotFound)
+						GetURLByAlias(gomock.Any(), gomock.Any(), "abc").
+						Return(linkdto.Target{}, service.ErrAliasNotFound)
+				},
+			},
+			want: want{status: http.StatusNotFound},
+		},
+		{
+			name:  "link disabled",
+			alias: "abc",
+			fields: fields{
+				setup: func(link *mocks.MockLink, click *mocks.MockClick) {
+					link.EXPECT().
+						GetURLByAlias(gomock.Any(), gomock.Any(), "abc").
+						Return(linkdto.Target{}, service.ErrLinkDisabled)
 				},
 			},
 			want: want{status: http.StatusNotFound},
 		},
+		{
+			name:  "link deleted",
+			alias: "abc",
+			fields: fields{
+				setup: func(link *mocks.MockLink, click *mocks.MockClick) {
+					link.EXPECT().
+						GetURLByAlias(gomock.Any(), gomock.Any(), "abc").
+						Return(linkdto.Target{}, service.ErrLinkDeleted)
+				},
+			},
+			want: want{status: http.StatusGone},
+		},
+		{
+			name:  "link expired",
+			alias: "abc",
+			fields: fields{
+				setup: func(link *mocks.MockLink, click *mocks.MockClick) {
+					link.EXPECT().
+						GetURLByAlias(gomock.Any(), gomock.Any(), "abc").
+						Return(linkdto.Target{}, service.ErrLinkExpired)
+				},
+			},
+			want: want{status: http.StatusGone},
+		},
+		{
+			name:  "click limit reached",
+			alias: "abc",
+			fields: fields{
+				setup: func(link *mocks.MockLink, click *mocks.MockClick) {
+					link.EXPECT().
+						GetURLByAlias(gomock.Any(), gomock.Any(), "abc").
+						Return(linkdto.Target{}, service.ErrClickLimitReached)
+				},
+			},
+			want: want{status: http.StatusGone},
+		},
 		{
 			name:  "internal error",
 			alias: "abc",
 			fields: fields{
 				setup: func(link *mocks.MockLink, click *mocks.MockClick) {
 					link.EXPECT().
-						GetURLByAlias(gomock.Any(), "abc").
-						Return("", errors.New("db error"))
+						GetURLByAlias(gomock.Any(), gomock.Any(), "abc").
+						Return(linkdto.Target{}, errors.New("db error"))
 				},
 			},
 			want: want{status: http.StatusInternalServerError},
@@ -195,8 +260,8 @@ func TestLinkHandler_Redirect(t *testing.T) {
 			fields: fields{
 				setup: func(link *mocks.MockLink, click *mocks.MockClick) {
 					link.EXPECT().
-						GetURLByAlias(gomock.Any(), "abc").
-						Return("https://example.com", nil)
+						GetURLByAlias(gomock.Any(), gomock.Any(), "abc").
+						Return(linkdto.Target{LinkID: uuid.New(), URL: "https://example.com"}, nil)
 
 					click.EXPECT().
 						SaveClick(gomock.Any(), gomock.AssignableToTypeOf(clickdto.Click{})).
@@ -211,8 +276,8 @@ func TestLinkHandler_Redirect(t *testing.T) {
 			fields: fields{
 				setup: func(link *mocks.MockLink, click *mocks.MockClick) {
 					link.EXPECT().
-						GetURLByAlias(gomock.Any(), "abc").
-						Return("https://example.com", nil)
+						GetURLByAlias(gomock.Any(), gomock.Any(), "abc").
+						Return(linkdto.Target{LinkID: uuid.New(), URL: "https://example.com"}, nil)
 
 					click.EXPECT().
 						SaveClick(gomock.Any(), gomock.Any()).
@@ -248,3 +313,525 @@ func TestLinkHandler_Redirect(t *testing.T) {
 		})
 	}
 }
+
+func TestLinkHandler_Redirect_ClickDetails(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockLink := mocks.NewMockLink(ctrl)
+	mockClick := mocks.NewMockClick(ctrl)
+
+	mockLink.EXPECT().
+		GetURLByAlias(gomock.Any(), gomock.Any(), "abc").
+		Return(linkdto.Target{LinkID: uuid.New(), URL: "https://example.com"}, nil)
+	mockClick.EXPECT().
+		SaveClick(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, click clickdto.Click) error {
+			require.Equal(t, "Chrome", click.Client)
+			require.Equal(t, "120.0.0.0", click.ClientVersion)
+			require.Equal(t, "desktop", click.Device)
+			require.Equal(t, "Windows", click.OS)
+			require.Equal(t, "10", click.OSVersion)
+			require.Equal(t, "news.example.org", click.Referrer)
+			return nil
+		})
+
+	handler := rest.NewLinkHandler(mockLink, mockClick, mocks.NewMockValidator(ctrl), retry.Strategy{})
+
+	c, w := newTestContext(http.MethodGet, "/abc", nil)
+	c.Params = gin.Params{{Key: "alias", Value: "abc"}}
+	c.Request.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36")
+	c.Request.Header.Set("Referer", "https://News.example.org:8443/story?id=1")
+
+	handler.Redirect(c)
+
+	require.Equal(t, http.StatusFound, w.Code)
+}
+
+func TestLinkHandler_RedirectByHost(t *testing.T) {
+	type fields struct {
+		setup func(link *mocks.MockLink, click *mocks.MockClick)
+	}
+	type want struct {
+		status   int
+		location string
+	}
+
+	tests := []struct {
+		name   string
+		fields fields
+		want   want
+	}{
+		{
+			name: "unknown domain",
+			fields: fields{
+				setup: func(link *mocks.MockLink, click *mocks.MockClick) {
+					link.EXPECT().
+						GetURLByHost(gomock.Any(), "go.acme.io", "abc").
+						Return(linkdto.Target{}, service.ErrDomainNotFound)
+				},
+			},
+			want: want{status: http.StatusNotFound},
+		},
+		{
+			name: "link on domain",
+			fields: fields{
+				setup: func(link *mocks.MockLink, click *mocks.MockClick) {
+					link.EXPECT().
+						GetURLByHost(gomock.Any(), "go.acme.io", "abc").
+						Return(linkdto.Target{LinkID: uuid.New(), URL: "https://example.com"}, nil)
+
+					click.EXPECT().
+						SaveClick(gomock.Any(), gomock.AssignableToTypeOf(clickdto.Click{})).
+						Return(nil)
+				},
+			},
+			want: want{status: http.StatusFound, location: "https://example.com"},
+		},
+		{
+			name: "fallback is not recorded as a click",
+			fields: fields{
+				setup: func(link *mocks.MockLink, click *mocks.MockClick) {
+					link.EXPECT().
+						GetURLByHost(gomock.Any(), "go.acme.io", "abc").
+						Return(linkdto.Target{URL: "https://acme.io"}, nil)
+				},
+			},
+			want: want{status: http.StatusFound, location: "https://acme.io"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockLink := mocks.NewMockLink(ctrl)
+			mockClick := mocks.NewMockClick(ctrl)
+			mockValidator := mocks.NewMockValidator(ctrl)
+
+			if tt.fields.setup != nil {
+				tt.fields.setup(mockLink, mockClick)
+			}
+
+			handler := rest.NewLinkHandler(mockLink, mockClick, mockValidator, retry.Strategy{})
+
+			c, w := newTestContext(http.MethodGet, "/abc", nil)
+			c.Request.Host = "go.acme.io:443"
+
+			handler.RedirectByHost(c)
+
+			require.Equal(t, tt.want.status, w.Code)
+			require.Equal(t, tt.want.location, w.Header().Get("Location"))
+		})
+	}
+}
+
+func TestLinkHandler_GetLink(t *testing.T) {
+	type fields struct {
+		setup func(link *mocks.MockLink)
+	}
+	type want struct {
+		status int
+	}
+
+	tests := []struct {
+		name   string
+		alias  string
+		fields fields
+		want   want
+	}{
+		{
+			name:  "empty alias",
+			alias: "",
+			want:  want{status: http.StatusBadRequest},
+		},
+		{
+			name:  "alias not found",
+			alias: "abc",
+			fields: fields{
+				setup: func(link *mocks.MockLink) {
+					link.EXPECT().
+						GetLink(gomock.Any(), gomock.Any(), "", "abc").
+						Return(linkdto.LinkInfo{}, service.ErrAliasNotFound)
+				},
+			},
+			want: want{status: http.StatusNotFound},
+		},
+		{
+			name:  "success",
+			alias: "abc",
+			fields: fields{
+				setup: func(link *mocks.MockLink) {
+					link.EXPECT().
+						GetLink(gomock.Any(), gomock.Any(), "", "abc").
+						Return(linkdto.LinkInfo{Alias: "abc", URL: "https://example.com"}, nil)
+				},
+			},
+			want: want{status: http.StatusOK},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockLink := mocks.NewMockLink(ctrl)
+			mockClick := mocks.NewMockClick(ctrl)
+			mockValidator := mocks.NewMockValidator(ctrl)
+
+			if tt.fields.setup != nil {
+				tt.fields.setup(mockLink)
+			}
+
+			handler := rest.NewLinkHandler(mockLink, mockClick, mockValidator, retry.Strategy{})
+
+			c, w := newTestContext(http.MethodGet, "/links/"+tt.alias, nil)
+			c.Params = gin.Params{{Key: "alias", Value: tt.alias}}
+
+			handler.GetLink(c)
+
+			require.Equal(t, tt.want.status, w.Code)
+		})
+	}
+}
+
+func TestLinkHandler_UpdateLink(t *testing.T) {
+	type fields struct {
+		setup func(link *mocks.MockLink, validator *mocks.MockValidator)
+	}
+	type want struct {
+		status int
+	}
+
+	tests := []struct {
+		name   string
+		body   interface{}
+		fields fields
+		want   want
+	}{
+		{
+			name: "invalid json",
+			body: "invalid",
+			want: want{status: http.StatusBadRequest},
+		},
+		{
+			name: "validation error",
+			body: linkdto.UpdateLink{URL: "bad"},
+			fields: fields{
+				setup: func(link *mocks.MockLink, validator *mocks.MockValidator) {
+					validator.EXPECT().
+						Validate(gomock.Any()).
+						Return(errors.New("validation failed"))
+				},
+			},
+			want: want{status: http.StatusBadRequest},
+		},
+		{
+			name: "alias not found",
+			body: linkdto.UpdateLink{URL: "https://example.com"},
+			fields: fields{
+				setup: func(link *mocks.MockLink, validator *mocks.MockValidator) {
+					validator.EXPECT().
+						Validate(gomock.Any()).
+						Return(nil)
+					link.EXPECT().
+						UpdateLink(gomock.Any(), gomock.Any(), "", "abc", gomock.Any()).
+						Return(linkdto.LinkInfo{}, service.ErrAliasNotFound)
+				},
+			},
+			want: want{status: http.StatusNotFound},
+		},
+		{
+			name: "success",
+			body: linkdto.UpdateLink{URL: "https://example.com"},
+			fields: fields{
+				setup: func(link *mocks.MockLink, validator *mocks.MockValidator) {
+					validator.EXPECT().
+						Validate(gomock.Any()).
+						Return(nil)
+					link.EXPECT().
+						UpdateLink(gomock.Any(), gomock.Any(), "", "abc", linkdto.UpdateLink{URL: "https://example.com"}).
+						Return(linkdto.LinkInfo{Alias: "abc", URL: "https://example.com"}, nil)
+				},
+			},
+			want: want{status: http.StatusOK},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockLink := mocks.NewMockLink(ctrl)
+			mockClick := mocks.NewMockClick(ctrl)
+			mockValidator := mocks.NewMockValidator(ctrl)
+
+			if tt.fields.setup != nil {
+				tt.fields.setup(mockLink, mockValidator)
+			}
+
+			handler := rest.NewLinkHandler(mockLink, mockClick, mockValidator, retry.Strategy{})
+
+			var bodyBytes []byte
+			switch v := tt.body.(type) {
+			case string:
+				bodyBytes = []byte(v)
+			default:
+				bodyBytes, _ = json.Marshal(v)
+			}
+
+			c, w := newTestContext(http.MethodPatch, "/links/abc", bodyBytes)
+			c.Params = gin.Params{{Key: "alias", Value: "abc"}}
+
+			handler.UpdateLink(c)
+
+			require.Equal(t, tt.want.status, w.Code)
+		})
+	}
+}
+
+func TestLinkHandler_DeleteLink(t *testing.T) {
+	type fields struct {
+		setup func(link *mocks.MockLink)
+	}
+	type want struct {
+		status int
+	}
+
+	tests := []struct {
+		name   string
+		alias  string
+		fields fields
+		want   want
+	}{
+		{
+			name:  "alias not found",
+			alias: "abc",
+			fields: fields{
+				setup: func(link *mocks.MockLink) {
+					link.EXPECT().
+						DeleteLink(gomock.Any(), gomock.Any(), "", "abc").
+						Return(service.ErrAliasNotFound)
+				},
+			},
+			want: want{status: http.StatusNotFound},
+		},
+		{
+			name:  "internal error",
+			alias: "abc",
+			fields: fields{
+				setup: func(link *mocks.MockLink) {
+					link.EXPECT().
+						DeleteLink(gomock.Any(), gomock.Any(), "", "abc").
+						Return(errors.New("db error"))
+				},
+			},
+			want: want{status: http.StatusInternalServerError},
+		},
+		{
+			name:  "success",
+			alias: "abc",
+			fields: fields{
+				setup: func(link *mocks.MockLink) {
+					link.EXPECT().
+						DeleteLink(gomock.Any(), gomock.Any(), "", "abc").
+						Return(nil)
+				},
+			},
+			want: want{status: http.StatusNoContent},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockLink := mocks.NewMockLink(ctrl)
+			mockClick := mocks.NewMockClick(ctrl)
+			mockValidator := mocks.NewMockValidator(ctrl)
+
+			if tt.fields.setup != nil {
+				tt.fields.setup(mockLink)
+			}
+
+			handler := rest.NewLinkHandler(mockLink, mockClick, mockValidator, retry.Strategy{})
+
+			c, w := newTestContext(http.MethodDelete, "/links/"+tt.alias, nil)
+			c.Params = gin.Params{{Key: "alias", Value: tt.alias}}
+
+			handler.DeleteLink(c)
+			c.Writer.WriteHeaderNow()
+
+			require.Equal(t, tt.want.status, w.Code)
+		})
+	}
+}
+
+func TestLinkHandler_DisableLink(t *testing.T) {
+	type fields struct {
+		setup func(link *mocks.MockLink)
+	}
+	type want struct {
+		status int
+	}
+
+	tests := []struct {
+		name   string
+		alias  string
+		fields fields
+		want   want
+	}{
+		{
+			name:  "alias not found",
+			alias: "abc",
+			fields: fields{
+				setup: func(link *mocks.MockLink) {
+					link.EXPECT().
+						DisableLink(gomock.Any(), gomock.Any(), "", "abc").
+						Return(linkdto.LinkInfo{}, service.ErrAliasNotFound)
+				},
+			},
+			want: want{status: http.StatusNotFound},
+		},
+		{
+			name:  "link deleted",
+			alias: "abc",
+			fields: fields{
+				setup: func(link *mocks.MockLink) {
+					link.EXPECT().
+						DisableLink(gomock.Any(), gomock.Any(), "", "abc").
+						Return(linkdto.LinkInfo{}, service.ErrLinkDeleted)
+				},
+			},
+			want: want{status: http.StatusConflict},
+		},
+		{
+			name:  "internal error",
+			alias: "abc",
+			fields: fields{
+				setup: func(link *mocks.MockLink) {
+					link.EXPECT().
+						DisableLink(gomock.Any(), gomock.Any(), "", "abc").
+						Return(linkdto.LinkInfo{}, errors.New("db error"))
+				},
+			},
+			want: want{status: http.StatusInternalServerError},
+		},
+		{
+			name:  "success",
+			alias: "abc",
+			fields: fields{
+				setup: func(link *mocks.MockLink) {
+					link.EXPECT().
+						DisableLink(gomock.Any(), gomock.Any(), "", "abc").
+						Return(linkdto.LinkInfo{Alias: "abc", Status: "disabled"}, nil)
+				},
+			},
+			want: want{status: http.StatusOK},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockLink := mocks.NewMockLink(ctrl)
+			mockClick := mocks.NewMockClick(ctrl)
+			mockValidator := mocks.NewMockValidator(ctrl)
+
+			if tt.fields.setup != nil {
+				tt.fields.setup(mockLink)
+			}
+
+			handler := rest.NewLinkHandler(mockLink, mockClick, mockValidator, retry.Strategy{})
+
+			c, w := newTestContext(http.MethodPost, "/links/"+tt.alias+"/disable", nil)
+			c.Params = gin.Params{{Key: "alias", Value: tt.alias}}
+
+			handler.DisableLink(c)
+			c.Writer.WriteHeaderNow()
+
+			require.Equal(t, tt.want.status, w.Code)
+		})
+	}
+}
+
+func TestLinkHandler_ListLinks(t *testing.T) {
+	type fields struct {
+		setup func(link *mocks.MockLink, validator *mocks.MockValidator)
+	}
+	type want struct {
+		status int
+	}
+
+	tests := []struct {
+		name   string
+		query  string
+		fields fields
+		want   want
+	}{
+		{
+			name:  "malformed created_from",
+			query: "?created_from=yesterday",
+			want:  want{status: http.StatusBadRequest},
+		},
+		{
+			name:  "invalid cursor",
+			query: "?cursor=bad",
+			fields: fields{
+				setup: func(link *mocks.MockLink, validator *mocks.MockValidator) {
+					validator.EXPECT().
+						Validate(gomock.Any()).
+						Return(nil)
+					link.EXPECT().
+						ListLinks(gomock.Any(), gomock.Any(), linkdto.ListLinks{Cursor: "bad"}).
+						Return(nil, "", service.ErrInvalidCursor)
+				},
+			},
+			want: want{status: http.StatusBadRequest},
+		},
+		{
+			name:  "success",
+			query: "?alias_prefix=pr&sort=clicks&limit=10",
+			fields: fields{
+				setup: func(link *mocks.MockLink, validator *mocks.MockValidator) {
+					validator.EXPECT().
+						Validate(gomock.Any()).
+						Return(nil)
+					link.EXPECT().
+						ListLinks(gomock.Any(), gomock.Any(), linkdto.ListLinks{AliasPrefix: "pr", Sort: "clicks", Limit: 10}).
+						Return([]linkdto.LinkInfo{{Alias: "promo"}}, "next", nil)
+				},
+			},
+			want: want{status: http.StatusOK},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockLink := mocks.NewMockLink(ctrl)
+			mockClick := mocks.NewMockClick(ctrl)
+			mockValidator := mocks.NewMockValidator(ctrl)
+
+			if tt.fields.setup != nil {
+				tt.fields.setup(mockLink, mockValidator)
+			}
+
+			handler := rest.NewLinkHandler(mockLink, mockClick, mockValidator, retry.Strategy{})
+
+			c, w := newTestContext(http.MethodGet, "/links"+tt.query, nil)
+
+			handler.ListLinks(c)
+
+			require.Equal(t, tt.want.status, w.Code)
+		})
+	}
+}
@@ -2,115 +2,706 @@ package service
 
 import (
 	"context"
+	"encoding/base64"
+	"encoding/json"
 	"errors"
 	"github.com/google/uuid"
+	"github.com/ilam072/shortener/internal/auth"
+	"github.com/ilam072/shortener/internal/eventbus"
 	"github.com/ilam072/shortener/internal/link/repo"
 	"github.com/ilam072/shortener/internal/link/types/domain"
 	"github.com/ilam072/shortener/internal/link/types/dto"
+	webhookdomain "github.com/ilam072/shortener/internal/webhook/types/domain"
+	webhookdto "github.com/ilam072/shortener/internal/webhook/types/dto"
 	"github.com/ilam072/shortener/pkg/errutils"
 	"github.com/ilam072/shortener/pkg/random"
 	"github.com/wb-go/wbf/redis"
 	"github.com/wb-go/wbf/retry"
 	"github.com/wb-go/wbf/zlog"
+	"net/url"
+	"strings"
+	"time"
 )
 
 //go:generate mockgen -source=link.go -destination=../mocks/service_mocks.go -package=mocks
 type LinkRepo interface {
 	CreateLink(ctx context.Context, link domain.Link) (string, error)
-	GetURLByAlias(ctx context.Context, alias string) (string, error)
+	GetLinkByAlias(ctx context.Context, access auth.Access, host string, alias string) (domain.Link, error)
+	ResolveLink(ctx context.Context, namespace string, alias string) (domain.Link, error)
+	ResolveDomainLink(ctx context.Context, domainID uuid.UUID, alias string) (domain.Link, error)
+	GetDomain(ctx context.Context, host string) (domain.Domain, error)
+	ConsumeClick(ctx context.Context, id uuid.UUID) error
+	UpdateURL(ctx context.Context, access auth.Access, host string, alias string, url string) error
+	SetStatus(ctx context.Context, access auth.Access, host string, alias string, status string) error
+	ReleaseDeletedAlias(
+		ctx context.Context,
+		namespace string,
+		domainID uuid.NullUUID,
+		alias string,
+		deletedBefore time.Time,
+	) error
+	CountClicks(ctx context.Context, workspaceID uuid.UUID, linkID uuid.UUID) (int, error)
+	ListLinks(ctx context.Context, filter domain.LinkFilter) ([]domain.LinkWithClicks, error)
 }
 
 type LinkCache interface {
-	SetURL(ctx context.Context, alias string, url string) error
-	GetURL(ctx context.Context, alias string) (string, error)
+	SetTarget(ctx context.Context, key string, target domain.Target, expiresAt *time.Time) error
+	GetTarget(ctx context.Context, key string) (domain.Target, error)
+	DeleteTarget(ctx context.Context, key string) error
 }
 
+// LinkEvents announces the lifecycle events of links to their webhooks.
+// Emit must not block.
+type LinkEvents interface {
+	Emit(event webhookdto.Event)
+}
+
+// Alias namespaces. With NamespaceGlobal an alias is unique across the
+// whole deployment, with NamespaceWorkspace only within its workspace and
+// redirects are addressed by workspace slug and alias.
+const (
+	NamespaceGlobal    = "global"
+	NamespaceWorkspace = "workspace"
+)
+
 type Link struct {
-	repo  LinkRepo
-	cache LinkCache
+	repo       LinkRepo
+	cache      LinkCache
+	events     LinkEvents
+	publisher  eventbus.EventPublisher
+	quarantine time.Duration
+	namespace  string
+	baseURL    string
+	scheme     string
+	reserved   []string
 }
 
-func New(repo LinkRepo, cache LinkCache) *Link {
-	return &Link{repo: repo, cache: cache}
+// New creates a link service. events is told about the links created and
+// deleted, and publisher about the links created. quarantine is how long
+// the alias of a deleted link stays reserved before it can be taken by a
+// new link. The deleted link and its clicks are kept, but it can no longer
+// be restored.
+// namespace is NamespaceGlobal or NamespaceWorkspace.
+//
+// baseURL is the URL redirects on the default hosts are served under,
+// such as "https://sho.rt" or "https://example.com/api/s"; links on custom
+// domains are served under the same scheme. reserved lists the aliases
+// that would shadow other routes and cannot be taken.
+func New(
+	repo LinkRepo,
+	cache LinkCache,
+	events LinkEvents,
+	publisher eventbus.EventPublisher,
+	quarantine time.Duration,
+	namespace string,
+	baseURL string,
+	reserved []string,
+) *Link {
+	scheme := "https"
+	if u, err := url.Parse(baseURL); err == nil && u.Scheme != "" {
+		scheme = u.Scheme
+	}
+	return &Link{
+		repo:       repo,
+		cache:      cache,
+		events:     events,
+		publisher:  publisher,
+		quarantine: quarantine,
+		namespace:  namespace,
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		scheme:     scheme,
+		reserved:   reserved,
+	}
 }
 
 var (
 	ErrAliasNotFound      = errors.New("alias not found")
 	ErrAliasAlreadyExists = errors.New("alias already exists")
+	ErrAliasReserved      = errors.New("alias is reserved")
+	ErrLinkExpired        = errors.New("link expired")
+	ErrInvalidExpiration  = errors.New("invalid expiration")
+	ErrClickLimitReached  = errors.New("click limit reached")
+	ErrLinkDisabled       = errors.New("link disabled")
+	ErrLinkDeleted        = errors.New("link deleted")
+	ErrInvalidCursor      = errors.New("invalid cursor")
+	ErrDomainNotFound     = errors.New("domain not found")
 )
 
-func (l *Link) SaveLink(ctx context.Context, link dto.Link, strategy retry.Strategy) (string, error) {
+const defaultPageSize = 20
+
+// maxTTL is the longest ttl, in seconds, a link can be created with: ten
+// years, well below where it would overflow a time.Duration.
+const maxTTL = 10 * 365 * 24 * 60 * 60
+
+// SaveLink creates a link in the workspace of creator. The link is owned
+// by the creator's user, if the creator acts for one. A link with a Domain
+// is served from that custom domain, which must belong to the workspace.
+func (l *Link) SaveLink(
+	ctx context.Context,
+	creator auth.Principal,
+	link dto.Link,
+	strategy retry.Strategy,
+) (dto.ShortLink, error) {
 	const op = "service.link.Save"
 
+	expiresAt, err := expirationOf(link)
+	if err != nil {
+		return dto.ShortLink{}, err
+	}
+
+	var maxClicks *int
+	if link.MaxClicks > 0 {
+		maxClicks = &link.MaxClicks
+	}
+
+	// Aliases on a custom domain are unique per domain, not per namespace.
+	namespace := ""
+	var domainID uuid.NullUUID
+	if link.Domain != "" {
+		d, err := l.repo.GetDomain(ctx, strings.ToLower(link.Domain))
+		if err != nil {
+			if errors.Is(err, repo.ErrDomainNotFound) {
+				return dto.ShortLink{}, errutils.Wrap(op, ErrDomainNotFound)
+			}
+			return dto.ShortLink{}, errutils.Wrap(op, err)
+		}
+		if d.WorkspaceID != creator.Workspace.ID {
+			return dto.ShortLink{}, errutils.Wrap(op, ErrDomainNotFound)
+		}
+		domainID = uuid.NullUUID{UUID: d.ID, Valid: true}
+	} else {
+		namespace = l.namespaceOf(creator.Workspace)
+	}
+
+	// Custom domains serve nothing but short links, so only aliases on the
+	// default hosts can collide with other routes.
+	isReserved := func(alias string) bool {
+		return !domainID.Valid && l.isReserved(alias)
+	}
+	shortLink := func(alias string) dto.ShortLink {
+		return dto.ShortLink{Alias: alias, URL: l.shortURL(strings.ToLower(link.Domain), namespace, alias)}
+	}
+
 	alias := link.Alias
 	if alias != "" {
+		if isReserved(alias) {
+			return dto.ShortLink{}, errutils.Wrap(op, ErrAliasReserved)
+		}
+		if err = l.repo.ReleaseDeletedAlias(ctx, namespace, domainID, alias, time.Now().Add(-l.quarantine)); err != nil {
+			return dto.ShortLink{}, errutils.Wrap(op, err)
+		}
+
 		domainLink := domain.Link{
-			ID:    uuid.New(),
-			URL:   link.URL,
-			Alias: alias,
+			ID:          uuid.New(),
+			WorkspaceID: creator.Workspace.ID,
+			Namespace:   namespace,
+			DomainID:    domainID,
+			OwnerID:     creator.UserID,
+			URL:         link.URL,
+			Alias:       alias,
+			ExpiresAt:   expiresAt,
+			MaxClicks:   maxClicks,
 		}
 		resAlias, err := l.repo.CreateLink(ctx, domainLink)
 		if err != nil {
 			if errors.Is(err, repo.ErrAliasAlreadyExists) {
-				return "", ErrAliasAlreadyExists
+				return dto.ShortLink{}, ErrAliasAlreadyExists
 			}
-			return "", errutils.Wrap(op, err)
+			return dto.ShortLink{}, errutils.Wrap(op, err)
 		}
-		return resAlias, nil
+		domainLink.Alias = resAlias
+		l.created(domainLink, link.Domain)
+		return shortLink(resAlias), nil
 	}
 
-	var resAlias string
-	err := retry.Do(func() error {
+	var created domain.Link
+	err = retry.Do(func() error {
 		tmpAlias := random.NewString(6)
+		if isReserved(tmpAlias) {
+			return repo.ErrAliasAlreadyExists
+		}
 		domainLink := domain.Link{
-			ID:    uuid.New(),
-			URL:   link.URL,
-			Alias: tmpAlias,
+			ID:          uuid.New(),
+			WorkspaceID: creator.Workspace.ID,
+			Namespace:   namespace,
+			DomainID:    domainID,
+			OwnerID:     creator.UserID,
+			URL:         link.URL,
+			Alias:       tmpAlias,
+			ExpiresAt:   expiresAt,
+			MaxClicks:   maxClicks,
 		}
 
-		var err error
-		resAlias, err = l.repo.CreateLink(ctx, domainLink)
+		resAlias, err := l.repo.CreateLink(ctx, domainLink)
 		if err != nil {
 			if errors.Is(err, repo.ErrAliasAlreadyExists) {
 				return err
 			}
 			return errutils.Wrap(op, err)
 		}
+		domainLink.Alias = resAlias
+		created = domainLink
 		return nil
 	}, strategy)
 
 	if err != nil {
 		if errors.Is(err, repo.ErrAliasAlreadyExists) {
-			return "", ErrAliasAlreadyExists
+			return dto.ShortLink{}, ErrAliasAlreadyExists
 		}
-		return "", err
+		return dto.ShortLink{}, err
+	}
+	l.created(created, link.Domain)
+
+	return shortLink(created.Alias), nil
+}
+
+// shortURL returns the URL alias is served at: on the custom domain host,
+// or on the default hosts within namespace.
+func (l *Link) shortURL(host string, namespace string, alias string) string {
+	if host != "" {
+		return l.scheme + "://" + host + "/" + alias
+	}
+	if namespace != "" {
+		return l.baseURL + "/" + namespace + "/" + alias
 	}
+	return l.baseURL + "/" + alias
+}
 
-	return resAlias, nil
+func (l *Link) isReserved(alias string) bool {
+	for _, reserved := range l.reserved {
+		if strings.EqualFold(alias, reserved) {
+			return true
+		}
+	}
+	return false
 }
 
-func (l *Link) GetURLByAlias(ctx context.Context, alias string) (string, error) {
+// GetURLByAlias resolves alias on the default hosts to its redirect
+// target. Links with a click limit are never cached, so every redirect
+// through them goes to Postgres and spends one click atomically there.
+//
+// namespace is the workspace slug the redirect was addressed to, and is
+// ignored when aliases are global.
+func (l *Link) GetURLByAlias(ctx context.Context, namespace string, alias string) (dto.Target, error) {
 	const op = "service.link.GetURLByAlias"
 
-	url, err := l.cache.GetURL(ctx, alias)
+	if l.namespace != NamespaceWorkspace {
+		namespace = ""
+	}
+	key := cacheKey(namespace, alias)
+
+	if target, ok := l.cached(ctx, key); ok {
+		return target, nil
+	}
+
+	// Redirects are public, whoever owns the link.
+	link, err := l.repo.ResolveLink(ctx, namespace, alias)
+	if err != nil {
+		if errors.Is(err, repo.ErrAliasNotFound) {
+			return dto.Target{}, errutils.Wrap(op, ErrAliasNotFound)
+		}
+		return dto.Target{}, errutils.Wrap(op, err)
+	}
+
+	target, err := l.resolve(ctx, key, link)
+	if err != nil {
+		return dto.Target{}, errutils.Wrap(op, err)
+	}
+
+	return target, nil
+}
+
+// GetURLByHost resolves alias on the custom domain host. Unknown aliases
+// redirect to the fallback URL of the domain, if it has one; such targets
+// have no LinkID.
+func (l *Link) GetURLByHost(ctx context.Context, host string, alias string) (dto.Target, error) {
+	const op = "service.link.GetURLByHost"
+
+	host = strings.ToLower(host)
+	key := hostKey(host, alias)
+
+	if target, ok := l.cached(ctx, key); ok {
+		return target, nil
+	}
+
+	d, err := l.repo.GetDomain(ctx, host)
+	if err != nil {
+		if errors.Is(err, repo.ErrDomainNotFound) {
+			return dto.Target{}, errutils.Wrap(op, ErrDomainNotFound)
+		}
+		return dto.Target{}, errutils.Wrap(op, err)
+	}
+
+	link, err := l.repo.ResolveDomainLink(ctx, d.ID, alias)
+	if err != nil {
+		if errors.Is(err, repo.ErrAliasNotFound) {
+			if d.FallbackURL != nil {
+				return dto.Target{WorkspaceID: d.WorkspaceID, URL: *d.FallbackURL}, nil
+			}
+			return dto.Target{}, errutils.Wrap(op, ErrAliasNotFound)
+		}
+		return dto.Target{}, errutils.Wrap(op, err)
+	}
+
+	target, err := l.resolve(ctx, key, link)
+	if err != nil {
+		return dto.Target{}, errutils.Wrap(op, err)
+	}
+
+	return target, nil
+}
+
+func (l *Link) cached(ctx context.Context, key string) (dto.Target, bool) {
+	target, err := l.cache.GetTarget(ctx, key)
 	if err == nil {
-		return url, nil
+		return toTarget(target), true
 	}
 	if !errors.Is(err, redis.NoMatches) {
-		zlog.Logger.Error().Err(err).Str("alias", alias).Msg("failed to get url from cache")
+		zlog.Logger.Error().Err(err).Str("key", key).Msg("failed to get target from cache")
 	}
+	return dto.Target{}, false
+}
 
-	url, err = l.repo.GetURLByAlias(ctx, alias)
+// resolve checks that link can be redirected through, spends a click of
+// limited links and caches the target of the others under key.
+func (l *Link) resolve(ctx context.Context, key string, link domain.Link) (dto.Target, error) {
+	switch link.Status {
+	case domain.StatusDisabled:
+		return dto.Target{}, ErrLinkDisabled
+	case domain.StatusDeleted:
+		return dto.Target{}, ErrLinkDeleted
+	}
+
+	if isExpired(link, time.Now()) {
+		return dto.Target{}, ErrLinkExpired
+	}
+
+	target := domain.Target{LinkID: link.ID, WorkspaceID: link.WorkspaceID, URL: link.URL}
+
+	if link.MaxClicks != nil {
+		if err := l.repo.ConsumeClick(ctx, link.ID); err != nil {
+			if errors.Is(err, repo.ErrClickLimitReached) {
+				return dto.Target{}, ErrClickLimitReached
+			}
+			return dto.Target{}, err
+		}
+		return toTarget(target), nil
+	}
+
+	if err := l.cache.SetTarget(ctx, key, target, link.ExpiresAt); err != nil {
+		zlog.Logger.Error().Err(err).Str("alias", link.Alias).Str("url", link.URL).Msg("failed to cache target")
+	}
+
+	return toTarget(target), nil
+}
+
+// GetLink returns a link visible through access. Links outside of access
+// are reported as not found so their existence does not leak.
+func (l *Link) GetLink(ctx context.Context, access auth.Access, host string, alias string) (dto.LinkInfo, error) {
+	const op = "service.link.GetLink"
+
+	link, err := l.repo.GetLinkByAlias(ctx, access, strings.ToLower(host), alias)
 	if err != nil {
 		if errors.Is(err, repo.ErrAliasNotFound) {
-			return "", errutils.Wrap(op, ErrAliasNotFound)
+			return dto.LinkInfo{}, errutils.Wrap(op, ErrAliasNotFound)
 		}
-		return "", errutils.Wrap(op, err)
+		return dto.LinkInfo{}, errutils.Wrap(op, err)
+	}
+
+	clicks, err := l.repo.CountClicks(ctx, link.WorkspaceID, link.ID)
+	if err != nil {
+		return dto.LinkInfo{}, errutils.Wrap(op, err)
 	}
 
-	if err = l.cache.SetURL(ctx, alias, url); err != nil {
-		zlog.Logger.Error().Err(err).Str("alias", alias).Str("url", url).Msg("failed to cache url")
+	return toLinkInfo(link, clicks), nil
+}
+
+// ListLinks returns one page of links matching query and the cursor of
+// the next page, which is empty on the last page.
+func (l *Link) ListLinks(ctx context.Context, access auth.Access, query dto.ListLinks) ([]dto.LinkInfo, string, error) {
+	const op = "service.link.ListLinks"
+
+	filter := domain.LinkFilter{
+		Access:      access,
+		CreatedFrom: query.CreatedFrom,
+		CreatedTo:   query.CreatedTo,
+		Host:        query.Host,
+		AliasPrefix: query.AliasPrefix,
+		Sort:        query.Sort,
+		Desc:        query.Order != "asc",
+		Limit:       query.Limit,
+	}
+	if filter.Sort == "" {
+		filter.Sort = domain.SortByCreatedAt
+	}
+	if filter.Limit == 0 {
+		filter.Limit = defaultPageSize
 	}
+	if query.Cursor != "" {
+		after, err := decodeCursor(query.Cursor, filter)
+		if err != nil {
+			return nil, "", err
+		}
+		filter.After = &after
+	}
+
+	// One extra row tells whether there is a next page.
+	pageSize := filter.Limit
+	filter.Limit++
+
+	links, err := l.repo.ListLinks(ctx, filter)
+	if err != nil {
+		return nil, "", errutils.Wrap(op, err)
+	}
+
+	var next string
+	if len(links) > pageSize {
+		links = links[:pageSize]
+		last := links[len(links)-1]
+		next = encodeCursor(filter, domain.LinkCursor{
+			CreatedAt: last.CreatedAt,
+			Clicks:    last.TotalClicks,
+			ID:        last.ID,
+		})
+	}
+
+	items := make([]dto.LinkInfo, 0, len(links))
+	for _, link := range links {
+		items = append(items, toLinkInfo(link.Link, link.TotalClicks))
+	}
+
+	return items, next, nil
+}
+
+func (l *Link) UpdateLink(
+	ctx context.Context,
+	access auth.Access,
+	host string,
+	alias string,
+	link dto.UpdateLink,
+) (dto.LinkInfo, error) {
+	const op = "service.link.UpdateLink"
+
+	host = strings.ToLower(host)
+	if err := l.repo.UpdateURL(ctx, access, host, alias, link.URL); err != nil {
+		if errors.Is(err, repo.ErrAliasNotFound) {
+			return dto.LinkInfo{}, errutils.Wrap(op, ErrAliasNotFound)
+		}
+		return dto.LinkInfo{}, errutils.Wrap(op, err)
+	}
+
+	l.invalidate(ctx, l.keyOf(access.Workspace, host, alias))
+
+	return l.GetLink(ctx, access, host, alias)
+}
+
+// DeleteLink soft-deletes a link: redirects stop working but its clicks
+// stay available for analytics until the alias is reused. Deleting a
+// deleted link again succeeds without announcing it twice.
+func (l *Link) DeleteLink(ctx context.Context, access auth.Access, host string, alias string) error {
+	const op = "service.link.DeleteLink"
+
+	link, err := l.repo.GetLinkByAlias(ctx, access, strings.ToLower(host), alias)
+	if err != nil {
+		if errors.Is(err, repo.ErrAliasNotFound) {
+			return errutils.Wrap(op, ErrAliasNotFound)
+		}
+		return errutils.Wrap(op, err)
+	}
+
+	if err = l.setStatus(ctx, access, host, alias, domain.StatusDeleted); err != nil {
+		return errutils.Wrap(op, err)
+	}
+	if link.Status != domain.StatusDeleted {
+		l.emit(webhookdomain.EventLinkDeleted, link, link.Host)
+	}
+
+	return nil
+}
+
+// DisableLink stops the redirects of a link until it is restored. Deleted
+// links cannot be disabled, they fail with ErrLinkDeleted.
+func (l *Link) DisableLink(ctx context.Context, access auth.Access, host string, alias string) (dto.LinkInfo, error) {
+	const op = "service.link.DisableLink"
+
+	if err := l.setStatus(ctx, access, host, alias, domain.StatusDisabled); err != nil {
+		return dto.LinkInfo{}, errutils.Wrap(op, err)
+	}
+
+	return l.GetLink(ctx, access, host, alias)
+}
+
+// RestoreLink makes a disabled or deleted link active again.
+func (l *Link) RestoreLink(ctx context.Context, access auth.Access, host string, alias string) (dto.LinkInfo, error) {
+	const op = "service.link.RestoreLink"
+
+	if err := l.setStatus(ctx, access, host, alias, domain.StatusActive); err != nil {
+		return dto.LinkInfo{}, errutils.Wrap(op, err)
+	}
+
+	return l.GetLink(ctx, access, host, alias)
+}
+
+func (l *Link) setStatus(ctx context.Context, access auth.Access, host string, alias string, status string) error {
+	host = strings.ToLower(host)
+	if err := l.repo.SetStatus(ctx, access, host, alias, status); err != nil {
+		if errors.Is(err, repo.ErrAliasNotFound) {
+			return ErrAliasNotFound
+		}
+		if errors.Is(err, repo.ErrLinkDeleted) {
+			return ErrLinkDeleted
+		}
+		return err
+	}
+
+	l.invalidate(ctx, l.keyOf(access.Workspace, host, alias))
+
+	return nil
+}
+
+// invalidate drops the cached target of key after a change to its link.
+// The change is already stored by then, so a failure is only logged: the
+// cached target expires on its own, and failing would have clients retry
+// a change that succeeded.
+func (l *Link) invalidate(ctx context.Context, key string) {
+	if err := l.cache.DeleteTarget(ctx, key); err != nil {
+		zlog.Logger.Error().Err(err).Str("key", key).Msg("failed to invalidate cached target")
+	}
+}
+
+// created announces a new link, served on host, to webhooks and to the
+// event bus.
+func (l *Link) created(link domain.Link, host string) {
+	l.emit(webhookdomain.EventLinkCreated, link, host)
+	l.publisher.Publish(eventbus.NewLinkCreated(link.WorkspaceID, eventbus.Link{
+		ID:        link.ID,
+		Alias:     link.Alias,
+		Domain:    strings.ToLower(host),
+		URL:       link.URL,
+		ExpiresAt: link.ExpiresAt,
+		MaxClicks: link.MaxClicks,
+	}))
+}
+
+// emit announces event about link, served on host or on the default hosts
+// when host is empty.
+func (l *Link) emit(event string, link domain.Link, host string) {
+	l.events.Emit(webhookdto.Event{
+		Type:        event,
+		WorkspaceID: link.WorkspaceID,
+		Link: webhookdto.EventLink{
+			ID:        link.ID,
+			Alias:     link.Alias,
+			Domain:    strings.ToLower(host),
+			URL:       link.URL,
+			ExpiresAt: link.ExpiresAt,
+			MaxClicks: link.MaxClicks,
+		},
+	})
+}
+
+// namespaceOf returns the alias namespace of links created in workspace.
+func (l *Link) namespaceOf(workspace auth.Workspace) string {
+	if l.namespace == NamespaceWorkspace {
+		return workspace.Slug
+	}
+	return ""
+}
+
+// keyOf returns the cache key of alias in workspace, on the custom domain
+// host or on the default hosts when host is empty.
+func (l *Link) keyOf(workspace auth.Workspace, host string, alias string) string {
+	if host != "" {
+		return hostKey(host, alias)
+	}
+	return cacheKey(l.namespaceOf(workspace), alias)
+}
+
+func cacheKey(namespace string, alias string) string {
+	if namespace == "" {
+		return alias
+	}
+	return namespace + "/" + alias
+}
+
+// hostKey cannot collide with cacheKey: neither namespaces nor routed
+// aliases start with a slash.
+func hostKey(host string, alias string) string {
+	return "//" + host + "/" + alias
+}
+
+func toTarget(target domain.Target) dto.Target {
+	return dto.Target{LinkID: target.LinkID, WorkspaceID: target.WorkspaceID, URL: target.URL}
+}
+
+func toLinkInfo(link domain.Link, clicks int) dto.LinkInfo {
+	return dto.LinkInfo{
+		Domain:    link.Host,
+		Alias:     link.Alias,
+		URL:       link.URL,
+		CreatedAt: link.CreatedAt,
+		ExpiresAt: link.ExpiresAt,
+		MaxClicks: link.MaxClicks,
+		Clicks:    clicks,
+		Status:    link.Status,
+		DeletedAt: link.DeletedAt,
+	}
+}
+
+// cursor is the opaque pagination token handed out to clients. It pins
+// the sort and order so a token cannot be replayed against another one.
+type cursor struct {
+	Sort      string    `json:"s"`
+	Desc      bool      `json:"d"`
+	CreatedAt time.Time `json:"t"`
+	Clicks    int       `json:"c"`
+	ID        uuid.UUID `json:"id"`
+}
+
+func encodeCursor(filter domain.LinkFilter, after domain.LinkCursor) string {
+	raw, _ := json.Marshal(cursor{
+		Sort:      filter.Sort,
+		Desc:      filter.Desc,
+		CreatedAt: after.CreatedAt,
+		Clicks:    after.Clicks,
+		ID:        after.ID,
+	})
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func decodeCursor(token string, filter domain.LinkFilter) (domain.LinkCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return domain.LinkCursor{}, ErrInvalidCursor
+	}
+
+	var c cursor
+	if err = json.Unmarshal(raw, &c); err != nil {
+		return domain.LinkCursor{}, ErrInvalidCursor
+	}
+	if c.Sort != filter.Sort || c.Desc != filter.Desc {
+		return domain.LinkCursor{}, ErrInvalidCursor
+	}
+
+	return domain.LinkCursor{CreatedAt: c.CreatedAt, Clicks: c.Clicks, ID: c.ID}, nil
+}
+
+// expirationOf resolves the absolute expiration time of a new link from
+// either expires_at or ttl (in seconds). Nil means the link never expires.
+func expirationOf(link dto.Link) (*time.Time, error) {
+	if link.TTL > maxTTL {
+		return nil, ErrInvalidExpiration
+	}
+	if link.TTL > 0 {
+		expiresAt := time.Now().Add(time.Duration(link.TTL) * time.Second)
+		return &expiresAt, nil
+	}
+	if link.ExpiresAt != nil && !link.ExpiresAt.After(time.Now()) {
+		return nil, ErrInvalidExpiration
+	}
+	return link.ExpiresAt, nil
+}
 
-	return url, nil
+func isExpired(link domain.Link, now time.Time) bool {
+	return link.ExpiresAt != nil && !link.ExpiresAt.After(now)
 }
@@ -4,21 +4,43 @@ import (
 	"context"
 	"errors"
 	"go.uber.org/mock/gomock"
+	"math"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/require"
 
+	"github.com/ilam072/shortener/internal/auth"
+	"github.com/ilam072/shortener/internal/eventbus"
+	eventbusmocks "github.com/ilam072/shortener/internal/eventbus/mocks"
+	"github.com/ilam072/shortener/internal/eventbus/publisher"
 	"github.com/ilam072/shortener/internal/link/mocks"
 	linkrepo "github.com/ilam072/shortener/internal/link/repo"
 	"github.com/ilam072/shortener/internal/link/service"
 	"github.com/ilam072/shortener/internal/link/types/domain"
 	"github.com/ilam072/shortener/internal/link/types/dto"
+	webhookdomain "github.com/ilam072/shortener/internal/webhook/types/domain"
+	webhookdto "github.com/ilam072/shortener/internal/webhook/types/dto"
 	"github.com/wb-go/wbf/redis"
 	"github.com/wb-go/wbf/retry"
 )
 
+// access is the view of a regular user who owns the links under test.
+var access = auth.Access{
+	Workspace: auth.Workspace{ID: workspaceID, Slug: "acme"},
+	OwnerID:   uuid.NullUUID{UUID: uuid.MustParse("0b5e6a57-4c1d-4d8a-9f3e-2a7c1b9d8e60"), Valid: true},
+}
+
+var (
+	workspaceID = uuid.MustParse("5f0c2d1e-8a3b-4c6d-9e7f-1a2b3c4d5e6f")
+	linkID      = uuid.MustParse("9d8c7b6a-5f4e-4d3c-8b2a-1f0e9d8c7b6a")
+)
+
+// target is what the redirect tests resolve "alias" to.
+var target = domain.Target{LinkID: linkID, WorkspaceID: workspaceID, URL: "https://example.com"}
+
 func TestLink_SaveLink(t *testing.T) {
 	type fields struct {
 		setup func(repo *mocks.MockLinkRepo)
@@ -120,6 +142,59 @@ func TestLink_SaveLink(t *testing.T) {
 				err:   nil,
 			},
 		},
+		{
+			name: "ttl sets expiration",
+			fields: fields{
+				setup: func(repo *mocks.MockLinkRepo) {
+					repo.EXPECT().
+						CreateLink(gomock.Any(), gomock.Cond(func(l domain.Link) bool {
+							return l.ExpiresAt != nil &&
+								l.ExpiresAt.After(time.Now().Add(59*time.Minute)) &&
+								l.ExpiresAt.Before(time.Now().Add(61*time.Minute))
+						})).
+						Return("custom", nil)
+				},
+			},
+			args: args{
+				link: dto.Link{
+					URL:   "https://example.com",
+					Alias: "custom",
+					TTL:   3600,
+				},
+			},
+			want: want{
+				alias: "custom",
+				err:   nil,
+			},
+		},
+		{
+			name: "expiration in the past",
+			args: args{
+				link: dto.Link{
+					URL:       "https://example.com",
+					Alias:     "custom",
+					ExpiresAt: func() *time.Time { t := time.Now().Add(-time.Hour); return &t }(),
+				},
+			},
+			want: want{
+				alias: "",
+				err:   service.ErrInvalidExpiration,
+			},
+		},
+		{
+			name: "ttl too long",
+			args: args{
+				link: dto.Link{
+					URL:   "https://example.com",
+					Alias: "custom",
+					TTL:   math.MaxInt64,
+				},
+			},
+			want: want{
+				alias: "",
+				err:   service.ErrInvalidExpiration,
+			},
+		},
 		{
 			name: "auto alias exhausted retry",
 			fields: fields{
@@ -150,11 +225,17 @@ func TestLink_SaveLink(t *testing.T) {
 			mockRepo := mocks.NewMockLinkRepo(ctrl)
 			mockCache := mocks.NewMockLinkCache(ctrl)
 
+			if tt.args.link.Alias != "" {
+				mockRepo.EXPECT().
+					ReleaseDeletedAlias(gomock.Any(), "", uuid.NullUUID{}, tt.args.link.Alias, gomock.Any()).
+					Return(nil).
+					MaxTimes(1)
+			}
 			if tt.fields.setup != nil {
 				tt.fields.setup(mockRepo)
 			}
 
-			svc := service.New(mockRepo, mockCache)
+			svc := service.New(mockRepo, mockCache, ignoreEvents(ctrl), publisher.Noop{}, time.Hour, service.NamespaceGlobal, "https://sho.rt", nil)
 
 			strategy := retry.Strategy{
 				Attempts: 5,
@@ -162,7 +243,7 @@ func TestLink_SaveLink(t *testing.T) {
 				Backoff:  1.5,
 			}
 
-			gotAlias, err := svc.SaveLink(context.Background(), tt.args.link, strategy)
+			gotAlias, err := svc.SaveLink(context.Background(), auth.Principal{Workspace: access.Workspace}, tt.args.link, strategy)
 
 			if tt.want.err != nil {
 				require.Error(t, err)
@@ -172,7 +253,7 @@ func TestLink_SaveLink(t *testing.T) {
 			}
 
 			require.NoError(t, err)
-			require.Equal(t, tt.want.alias, gotAlias)
+			require.Equal(t, tt.want.alias, gotAlias.Alias)
 		})
 	}
 }
@@ -198,8 +279,8 @@ func TestLink_GetURLByAlias(t *testing.T) {
 			fields: fields{
 				setup: func(repo *mocks.MockLinkRepo, cache *mocks.MockLinkCache) {
 					cache.EXPECT().
-						GetURL(gomock.Any(), "alias").
-						Return("https://example.com", nil)
+						GetTarget(gomock.Any(), "alias").
+						Return(target, nil)
 				},
 			},
 			want: want{
@@ -214,13 +295,13 @@ func TestLink_GetURLByAlias(t *testing.T) {
 				setup: func(repo *mocks.MockLinkRepo, cache *mocks.MockLinkCache) {
 					gomock.InOrder(
 						cache.EXPECT().
-							GetURL(gomock.Any(), "alias").
-							Return("", redis.NoMatches),
+							GetTarget(gomock.Any(), "alias").
+							Return(domain.Target{}, redis.NoMatches),
 						repo.EXPECT().
-							GetURLByAlias(gomock.Any(), "alias").
-							Return("https://example.com", nil),
+							ResolveLink(gomock.Any(), "", "alias").
+							Return(domain.Link{ID: linkID, WorkspaceID: workspaceID, URL: "https://example.com", Alias: "alias"}, nil),
 						cache.EXPECT().
-							SetURL(gomock.Any(), "alias", "https://example.com").
+							SetTarget(gomock.Any(), "alias", target, gomock.Nil()).
 							Return(nil),
 					)
 				},
@@ -236,11 +317,11 @@ func TestLink_GetURLByAlias(t *testing.T) {
 			fields: fields{
 				setup: func(repo *mocks.MockLinkRepo, cache *mocks.MockLinkCache) {
 					cache.EXPECT().
-						GetURL(gomock.Any(), "alias").
-						Return("", redis.NoMatches)
+						GetTarget(gomock.Any(), "alias").
+						Return(domain.Target{}, redis.NoMatches)
 					repo.EXPECT().
-						GetURLByAlias(gomock.Any(), "alias").
-						Return("", linkrepo.ErrAliasNotFound)
+						ResolveLink(gomock.Any(), "", "alias").
+						Return(domain.Link{}, linkrepo.ErrAliasNotFound)
 				},
 			},
 			want: want{
@@ -254,11 +335,11 @@ func TestLink_GetURLByAlias(t *testing.T) {
 			fields: fields{
 				setup: func(repo *mocks.MockLinkRepo, cache *mocks.MockLinkCache) {
 					cache.EXPECT().
-						GetURL(gomock.Any(), "alias").
-						Return("", redis.NoMatches)
+						GetTarget(gomock.Any(), "alias").
+						Return(domain.Target{}, redis.NoMatches)
 					repo.EXPECT().
-						GetURLByAlias(gomock.Any(), "alias").
-						Return("", errors.New("db error"))
+						ResolveLink(gomock.Any(), "", "alias").
+						Return(domain.Link{}, errors.New("db error"))
 				},
 			},
 			want: want{
@@ -266,6 +347,240 @@ func TestLink_GetURLByAlias(t *testing.T) {
 				err: errors.New("db error"),
 			},
 		},
+		{
+			name:  "link disabled",
+			alias: "alias",
+			fields: fields{
+				setup: func(repo *mocks.MockLinkRepo, cache *mocks.MockLinkCache) {
+					cache.EXPECT().
+						GetTarget(gomock.Any(), "alias").
+						Return(domain.Target{}, redis.NoMatches)
+					repo.EXPECT().
+						ResolveLink(gomock.Any(), "", "alias").
+						Return(domain.Link{ID: linkID, WorkspaceID: workspaceID, URL: "https://example.com", Alias: "alias", Status: domain.StatusDisabled}, nil)
+				},
+			},
+			want: want{
+				url: "",
+				err: service.ErrLinkDisabled,
+			},
+		},
+		{
+			name:  "link deleted",
+			alias: "alias",
+			fields: fields{
+				setup: func(repo *mocks.MockLinkRepo, cache *mocks.MockLinkCache) {
+					cache.EXPECT().
+						GetTarget(gomock.Any(), "alias").
+						Return(domain.Target{}, redis.NoMatches)
+					repo.EXPECT().
+						ResolveLink(gomock.Any(), "", "alias").
+						Return(domain.Link{ID: linkID, WorkspaceID: workspaceID, URL: "https://example.com", Alias: "alias", Status: domain.StatusDeleted}, nil)
+				},
+			},
+			want: want{
+				url: "",
+				err: service.ErrLinkDeleted,
+			},
+		},
+		{
+			name:  "link expired",
+			alias: "alias",
+			fields: fields{
+				setup: func(repo *mocks.MockLinkRepo, cache *mocks.MockLinkCache) {
+					expiresAt := time.Now().Add(-time.Minute)
+					cache.EXPECT().
+						GetTarget(gomock.Any(), "alias").
+						Return(domain.Target{}, redis.NoMatches)
+					repo.EXPECT().
+						ResolveLink(gomock.Any(), "", "alias").
+						Return(domain.Link{ID: linkID, WorkspaceID: workspaceID, URL: "https://example.com", Alias: "alias", ExpiresAt: &expiresAt}, nil)
+				},
+			},
+			want: want{
+				url: "",
+				err: service.ErrLinkExpired,
+			},
+		},
+		{
+			name:  "limited link spends a click and is not cached",
+			alias: "alias",
+			fields: fields{
+				setup: func(repo *mocks.MockLinkRepo, cache *mocks.MockLinkCache) {
+					maxClicks := 1
+					cache.EXPECT().
+						GetTarget(gomock.Any(), "alias").
+						Return(domain.Target{}, redis.NoMatches)
+					repo.EXPECT().
+						ResolveLink(gomock.Any(), "", "alias").
+						Return(domain.Link{ID: linkID, WorkspaceID: workspaceID, URL: "https://example.com", Alias: "alias", MaxClicks: &maxClicks}, nil)
+					repo.EXPECT().
+						ConsumeClick(gomock.Any(), linkID).
+						Return(nil)
+				},
+			},
+			want: want{
+				url: "https://example.com",
+				err: nil,
+			},
+		},
+		{
+			name:  "click limit reached",
+			alias: "alias",
+			fields: fields{
+				setup: func(repo *mocks.MockLinkRepo, cache *mocks.MockLinkCache) {
+					maxClicks := 1
+					cache.EXPECT().
+						GetTarget(gomock.Any(), "alias").
+						Return(domain.Target{}, redis.NoMatches)
+					repo.EXPECT().
+						ResolveLink(gomock.Any(), "", "alias").
+						Return(domain.Link{ID: linkID, WorkspaceID: workspaceID, URL: "https://example.com", Alias: "alias", MaxClicks: &maxClicks, Clicks: 1}, nil)
+					repo.EXPECT().
+						ConsumeClick(gomock.Any(), linkID).
+						Return(linkrepo.ErrClickLimitReached)
+				},
+			},
+			want: want{
+				url: "",
+				err: service.ErrClickLimitReached,
+			},
+		},
+		{
+			name:  "link not yet expired is cached with its expiration",
+			alias: "alias",
+			fields: fields{
+				setup: func(repo *mocks.MockLinkRepo, cache *mocks.MockLinkCache) {
+					expiresAt := time.Now().Add(time.Hour)
+					cache.EXPECT().
+						GetTarget(gomock.Any(), "alias").
+						Return(domain.Target{}, redis.NoMatches)
+					repo.EXPECT().
+						ResolveLink(gomock.Any(), "", "alias").
+						Return(domain.Link{ID: linkID, WorkspaceID: workspaceID, URL: "https://example.com", Alias: "alias", ExpiresAt: &expiresAt}, nil)
+					cache.EXPECT().
+						SetTarget(gomock.Any(), "alias", target, &expiresAt).
+						Return(nil)
+				},
+			},
+			want: want{
+				url: "https://example.com",
+				err: nil,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockRepo := mocks.NewMockLinkRepo(ctrl)
+			mockCache := mocks.NewMockLinkCache(ctrl)
+
+			if tt.fields.setup != nil {
+				tt.fields.setup(mockRepo, mockCache)
+			}
+
+			svc := service.New(mockRepo, mockCache, ignoreEvents(ctrl), publisher.Noop{}, time.Hour, service.NamespaceGlobal, "https://sho.rt", nil)
+
+			got, err := svc.GetURLByAlias(context.Background(), "ignored", tt.alias)
+
+			if tt.want.err != nil {
+				require.Error(t, err)
+				require.True(t, strings.Contains(err.Error(), tt.want.err.Error()))
+				require.Empty(t, got)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.want.url, got.URL)
+			require.Equal(t, linkID, got.LinkID)
+			require.Equal(t, workspaceID, got.WorkspaceID)
+		})
+	}
+}
+
+func TestLink_UpdateLink(t *testing.T) {
+	type fields struct {
+		setup func(repo *mocks.MockLinkRepo, cache *mocks.MockLinkCache)
+	}
+	type want struct {
+		url string
+		err error
+	}
+
+	tests := []struct {
+		name   string
+		alias  string
+		fields fields
+		want   want
+	}{
+		{
+			name:  "success invalidates cache",
+			alias: "alias",
+			fields: fields{
+				setup: func(repo *mocks.MockLinkRepo, cache *mocks.MockLinkCache) {
+					gomock.InOrder(
+						repo.EXPECT().
+							UpdateURL(gomock.Any(), access, "", "alias", "https://new.example.com").
+							Return(nil),
+						cache.EXPECT().
+							DeleteTarget(gomock.Any(), "alias").
+							Return(nil),
+						repo.EXPECT().
+							GetLinkByAlias(gomock.Any(), access, "", "alias").
+							Return(domain.Link{ID: linkID, WorkspaceID: workspaceID, URL: "https://new.example.com", Alias: "alias"}, nil),
+						repo.EXPECT().
+							CountClicks(gomock.Any(), workspaceID, linkID).
+							Return(3, nil),
+					)
+				},
+			},
+			want: want{
+				url: "https://new.example.com",
+				err: nil,
+			},
+		},
+		{
+			name:  "alias not found",
+			alias: "alias",
+			fields: fields{
+				setup: func(repo *mocks.MockLinkRepo, cache *mocks.MockLinkCache) {
+					repo.EXPECT().
+						UpdateURL(gomock.Any(), access, "", "alias", "https://new.example.com").
+						Return(linkrepo.ErrAliasNotFound)
+				},
+			},
+			want: want{
+				url: "",
+				err: service.ErrAliasNotFound,
+			},
+		},
+		{
+			name:  "cache invalidation error still succeeds",
+			alias: "alias",
+			fields: fields{
+				setup: func(repo *mocks.MockLinkRepo, cache *mocks.MockLinkCache) {
+					repo.EXPECT().
+						UpdateURL(gomock.Any(), access, "", "alias", "https://new.example.com").
+						Return(nil)
+					cache.EXPECT().
+						DeleteTarget(gomock.Any(), "alias").
+						Return(errors.New("redis error"))
+					repo.EXPECT().
+						GetLinkByAlias(gomock.Any(), access, "", "alias").
+						Return(domain.Link{ID: linkID, WorkspaceID: workspaceID, URL: "https://new.example.com", Alias: "alias"}, nil)
+					repo.EXPECT().
+						CountClicks(gomock.Any(), workspaceID, linkID).
+						Return(3, nil)
+				},
+			},
+			want: want{
+				url: "https://new.example.com",
+				err: nil,
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -280,19 +595,523 @@ func TestLink_GetURLByAlias(t *testing.T) {
 				tt.fields.setup(mockRepo, mockCache)
 			}
 
-			svc := service.New(mockRepo, mockCache)
+			svc := service.New(mockRepo, mockCache, ignoreEvents(ctrl), publisher.Noop{}, time.Hour, service.NamespaceGlobal, "https://sho.rt", nil)
 
-			gotURL, err := svc.GetURLByAlias(context.Background(), tt.alias)
+			info, err := svc.UpdateLink(context.Background(), access, "", tt.alias, dto.UpdateLink{URL: "https://new.example.com"})
 
 			if tt.want.err != nil {
 				require.Error(t, err)
 				require.True(t, strings.Contains(err.Error(), tt.want.err.Error()))
-				require.Empty(t, gotURL)
 				return
 			}
 
 			require.NoError(t, err)
-			require.Equal(t, tt.want.url, gotURL)
+			require.Equal(t, tt.want.url, info.URL)
+		})
+	}
+}
+
+func TestLink_DeleteLink(t *testing.T) {
+	type fields struct {
+		setup func(repo *mocks.MockLinkRepo, cache *mocks.MockLinkCache, events *mocks.MockLinkEvents)
+	}
+	type want struct {
+		err error
+	}
+
+	active := domain.Link{ID: linkID, WorkspaceID: workspaceID, URL: "https://example.com", Alias: "alias", Status: domain.StatusActive}
+	deleted := active
+	deleted.Status = domain.StatusDeleted
+
+	tests := []struct {
+		name   string
+		alias  string
+		fields fields
+		want   want
+	}{
+		{
+			name:  "success invalidates cache and emits event",
+			alias: "alias",
+			fields: fields{
+				setup: func(repo *mocks.MockLinkRepo, cache *mocks.MockLinkCache, events *mocks.MockLinkEvents) {
+					gomock.InOrder(
+						repo.EXPECT().
+							GetLinkByAlias(gomock.Any(), access, "", "alias").
+							Return(active, nil),
+						repo.EXPECT().
+							SetStatus(gomock.Any(), access, "", "alias", domain.StatusDeleted).
+							Return(nil),
+						cache.EXPECT().
+							DeleteTarget(gomock.Any(), "alias").
+							Return(nil),
+						events.EXPECT().
+							Emit(webhookdto.Event{
+								Type:        webhookdomain.EventLinkDeleted,
+								WorkspaceID: workspaceID,
+								Link:        webhookdto.EventLink{ID: linkID, Alias: "alias", URL: "https://example.com"},
+							}),
+					)
+				},
+			},
+			want: want{err: nil},
+		},
+		{
+			name:  "already deleted does not emit again",
+			alias: "alias",
+			fields: fields{
+				setup: func(repo *mocks.MockLinkRepo, cache *mocks.MockLinkCache, events *mocks.MockLinkEvents) {
+					repo.EXPECT().
+						GetLinkByAlias(gomock.Any(), access, "", "alias").
+						Return(deleted, nil)
+					repo.EXPECT().
+						SetStatus(gomock.Any(), access, "", "alias", domain.StatusDeleted).
+						Return(nil)
+					cache.EXPECT().
+						DeleteTarget(gomock.Any(), "alias").
+						Return(nil)
+				},
+			},
+			want: want{err: nil},
+		},
+		{
+			name:  "alias not found",
+			alias: "alias",
+			fields: fields{
+				setup: func(repo *mocks.MockLinkRepo, cache *mocks.MockLinkCache, events *mocks.MockLinkEvents) {
+					repo.EXPECT().
+						GetLinkByAlias(gomock.Any(), access, "", "alias").
+						Return(domain.Link{}, linkrepo.ErrAliasNotFound)
+				},
+			},
+			want: want{err: service.ErrAliasNotFound},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockRepo := mocks.NewMockLinkRepo(ctrl)
+			mockCache := mocks.NewMockLinkCache(ctrl)
+			mockEvents := mocks.NewMockLinkEvents(ctrl)
+
+			if tt.fields.setup != nil {
+				tt.fields.setup(mockRepo, mockCache, mockEvents)
+			}
+
+			svc := service.New(mockRepo, mockCache, mockEvents, publisher.Noop{}, time.Hour, service.NamespaceGlobal, "https://sho.rt", nil)
+
+			err := svc.DeleteLink(context.Background(), access, "", tt.alias)
+
+			if tt.want.err != nil {
+				require.Error(t, err)
+				require.True(t, errors.Is(err, tt.want.err))
+				return
+			}
+
+			require.NoError(t, err)
 		})
 	}
 }
+
+func TestLink_SaveLink_AnnouncesCreated(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockLinkRepo(ctrl)
+	mockCache := mocks.NewMockLinkCache(ctrl)
+	mockEvents := mocks.NewMockLinkEvents(ctrl)
+	mockPublisher := eventbusmocks.NewMockEventPublisher(ctrl)
+
+	var created domain.Link
+	mockRepo.EXPECT().
+		CreateLink(gomock.Any(), gomock.AssignableToTypeOf(domain.Link{})).
+		DoAndReturn(func(_ context.Context, link domain.Link) (string, error) {
+			created = link
+			return link.Alias, nil
+		})
+	var event webhookdto.Event
+	mockEvents.EXPECT().
+		Emit(gomock.Any()).
+		Do(func(e webhookdto.Event) { event = e })
+	var published eventbus.Event
+	mockPublisher.EXPECT().
+		Publish(gomock.Any()).
+		Do(func(e eventbus.Event) { published = e })
+
+	svc := service.New(mockRepo, mockCache, mockEvents, mockPublisher, time.Hour, service.NamespaceGlobal, "https://sho.rt", nil)
+
+	short, err := svc.SaveLink(
+		context.Background(),
+		auth.Principal{Workspace: access.Workspace},
+		dto.Link{URL: "https://example.com", MaxClicks: 3},
+		retry.Strategy{Attempts: 1},
+	)
+
+	require.NoError(t, err)
+	require.Equal(t, webhookdomain.EventLinkCreated, event.Type)
+	require.Equal(t, workspaceID, event.WorkspaceID)
+	require.Equal(t, created.ID, event.Link.ID)
+	require.Equal(t, short.Alias, event.Link.Alias)
+	require.Equal(t, "https://example.com", event.Link.URL)
+	require.Equal(t, 3, *event.Link.MaxClicks)
+
+	require.Equal(t, eventbus.SchemaVersion, published.SchemaVersion)
+	require.Equal(t, eventbus.TypeLinkCreated, published.Type)
+	require.Equal(t, workspaceID, published.WorkspaceID)
+	require.Equal(t, created.ID, published.Link.ID)
+	require.Equal(t, short.Alias, published.Key())
+}
+
+func TestLink_RestoreLink(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockLinkRepo(ctrl)
+	mockCache := mocks.NewMockLinkCache(ctrl)
+
+	gomock.InOrder(
+		mockRepo.EXPECT().
+			SetStatus(gomock.Any(), access, "", "alias", domain.StatusActive).
+			Return(nil),
+		mockCache.EXPECT().
+			DeleteTarget(gomock.Any(), "alias").
+			Return(nil),
+		mockRepo.EXPECT().
+			GetLinkByAlias(gomock.Any(), access, "", "alias").
+			Return(domain.Link{ID: linkID, WorkspaceID: workspaceID, URL: "https://example.com", Alias: "alias", Status: domain.StatusActive}, nil),
+		mockRepo.EXPECT().
+			CountClicks(gomock.Any(), workspaceID, linkID).
+			Return(7, nil),
+	)
+
+	svc := service.New(mockRepo, mockCache, ignoreEvents(ctrl), publisher.Noop{}, time.Hour, service.NamespaceGlobal, "https://sho.rt", nil)
+
+	info, err := svc.RestoreLink(context.Background(), access, "", "alias")
+
+	require.NoError(t, err)
+	require.Equal(t, domain.StatusActive, info.Status)
+	require.Equal(t, 7, info.Clicks)
+}
+
+func TestLink_DisableLink_Deleted(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockLinkRepo(ctrl)
+	mockCache := mocks.NewMockLinkCache(ctrl)
+
+	mockRepo.EXPECT().
+		SetStatus(gomock.Any(), access, "", "alias", domain.StatusDisabled).
+		Return(linkrepo.ErrLinkDeleted)
+
+	svc := service.New(mockRepo, mockCache, ignoreEvents(ctrl), publisher.Noop{}, time.Hour, service.NamespaceGlobal, "https://sho.rt", nil)
+
+	_, err := svc.DisableLink(context.Background(), access, "", "alias")
+
+	require.ErrorIs(t, err, service.ErrLinkDeleted)
+}
+
+func TestLink_ListLinks(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockLinkRepo(ctrl)
+	mockCache := mocks.NewMockLinkCache(ctrl)
+
+	created := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	page := []domain.LinkWithClicks{
+		{Link: domain.Link{ID: uuid.New(), Alias: "a", CreatedAt: created}, TotalClicks: 5},
+		{Link: domain.Link{ID: uuid.New(), Alias: "b", CreatedAt: created.Add(-time.Hour)}, TotalClicks: 3},
+		{Link: domain.Link{ID: uuid.New(), Alias: "c", CreatedAt: created.Add(-2 * time.Hour)}, TotalClicks: 1},
+	}
+
+	gomock.InOrder(
+		mockRepo.EXPECT().
+			ListLinks(gomock.Any(), gomock.Cond(func(f domain.LinkFilter) bool {
+				return f.Access == access && f.Limit == 3 && f.Desc && f.Sort == domain.SortByCreatedAt && f.After == nil
+			})).
+			Return(page, nil),
+		mockRepo.EXPECT().
+			ListLinks(gomock.Any(), gomock.Cond(func(f domain.LinkFilter) bool {
+				return f.After != nil && f.After.ID == page[1].ID && f.After.CreatedAt.Equal(page[1].CreatedAt)
+			})).
+			Return(page[2:], nil),
+	)
+
+	svc := service.New(mockRepo, mockCache, ignoreEvents(ctrl), publisher.Noop{}, time.Hour, service.NamespaceGlobal, "https://sho.rt", nil)
+
+	items, next, err := svc.ListLinks(context.Background(), access, dto.ListLinks{Limit: 2})
+	require.NoError(t, err)
+	require.Len(t, items, 2)
+	require.Equal(t, 5, items[0].Clicks)
+	require.NotEmpty(t, next)
+
+	items, next, err = svc.ListLinks(context.Background(), access, dto.ListLinks{Limit: 2, Cursor: next})
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	require.Empty(t, next)
+
+	_, _, err = svc.ListLinks(context.Background(), access, dto.ListLinks{Cursor: "not-a-cursor"})
+	require.ErrorIs(t, err, service.ErrInvalidCursor)
+}
+
+func TestLink_GetURLByAlias_WorkspaceNamespace(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockLinkRepo(ctrl)
+	mockCache := mocks.NewMockLinkCache(ctrl)
+
+	gomock.InOrder(
+		mockCache.EXPECT().
+			GetTarget(gomock.Any(), "acme/alias").
+			Return(domain.Target{}, redis.NoMatches),
+		mockRepo.EXPECT().
+			ResolveLink(gomock.Any(), "acme", "alias").
+			Return(domain.Link{ID: linkID, WorkspaceID: workspaceID, Namespace: "acme", URL: "https://example.com", Alias: "alias"}, nil),
+		mockCache.EXPECT().
+			SetTarget(gomock.Any(), "acme/alias", target, gomock.Nil()).
+			Return(nil),
+	)
+
+	svc := service.New(mockRepo, mockCache, ignoreEvents(ctrl), publisher.Noop{}, time.Hour, service.NamespaceWorkspace, "https://sho.rt", nil)
+
+	got, err := svc.GetURLByAlias(context.Background(), "acme", "alias")
+
+	require.NoError(t, err)
+	require.Equal(t, "https://example.com", got.URL)
+	require.Equal(t, workspaceID, got.WorkspaceID)
+}
+
+func TestLink_GetURLByHost(t *testing.T) {
+	domainID := uuid.New()
+	fallbackURL := "https://acme.io"
+
+	type fields struct {
+		setup func(repo *mocks.MockLinkRepo, cache *mocks.MockLinkCache)
+	}
+	type want struct {
+		target dto.Target
+		err    error
+	}
+
+	tests := []struct {
+		name   string
+		fields fields
+		want   want
+	}{
+		{
+			name: "cache hit",
+			fields: fields{
+				setup: func(repo *mocks.MockLinkRepo, cache *mocks.MockLinkCache) {
+					cache.EXPECT().
+						GetTarget(gomock.Any(), "//go.acme.io/alias").
+						Return(target, nil)
+				},
+			},
+			want: want{target: dto.Target{LinkID: linkID, WorkspaceID: workspaceID, URL: "https://example.com"}},
+		},
+		{
+			name: "link on domain is cached",
+			fields: fields{
+				setup: func(repo *mocks.MockLinkRepo, cache *mocks.MockLinkCache) {
+					gomock.InOrder(
+						cache.EXPECT().
+							GetTarget(gomock.Any(), "//go.acme.io/alias").
+							Return(domain.Target{}, redis.NoMatches),
+						repo.EXPECT().
+							GetDomain(gomock.Any(), "go.acme.io").
+							Return(domain.Domain{ID: domainID, WorkspaceID: workspaceID, Host: "go.acme.io"}, nil),
+						repo.EXPECT().
+							ResolveDomainLink(gomock.Any(), domainID, "alias").
+							Return(domain.Link{ID: linkID, WorkspaceID: workspaceID, URL: "https://example.com", Alias: "alias"}, nil),
+						cache.EXPECT().
+							SetTarget(gomock.Any(), "//go.acme.io/alias", target, gomock.Nil()).
+							Return(nil),
+					)
+				},
+			},
+			want: want{target: dto.Target{LinkID: linkID, WorkspaceID: workspaceID, URL: "https://example.com"}},
+		},
+		{
+			name: "unknown alias redirects to fallback",
+			fields: fields{
+				setup: func(repo *mocks.MockLinkRepo, cache *mocks.MockLinkCache) {
+					cache.EXPECT().
+						GetTarget(gomock.Any(), "//go.acme.io/alias").
+						Return(domain.Target{}, redis.NoMatches)
+					repo.EXPECT().
+						GetDomain(gomock.Any(), "go.acme.io").
+						Return(domain.Domain{ID: domainID, WorkspaceID: workspaceID, FallbackURL: &fallbackURL}, nil)
+					repo.EXPECT().
+						ResolveDomainLink(gomock.Any(), domainID, "alias").
+						Return(domain.Link{}, linkrepo.ErrAliasNotFound)
+				},
+			},
+			want: want{target: dto.Target{WorkspaceID: workspaceID, URL: fallbackURL}},
+		},
+		{
+			name: "unknown alias without fallback",
+			fields: fields{
+				setup: func(repo *mocks.MockLinkRepo, cache *mocks.MockLinkCache) {
+					cache.EXPECT().
+						GetTarget(gomock.Any(), "//go.acme.io/alias").
+						Return(domain.Target{}, redis.NoMatches)
+					repo.EXPECT().
+						GetDomain(gomock.Any(), "go.acme.io").
+						Return(domain.Domain{ID: domainID, WorkspaceID: workspaceID}, nil)
+					repo.EXPECT().
+						ResolveDomainLink(gomock.Any(), domainID, "alias").
+						Return(domain.Link{}, linkrepo.ErrAliasNotFound)
+				},
+			},
+			want: want{err: service.ErrAliasNotFound},
+		},
+		{
+			name: "unknown domain",
+			fields: fields{
+				setup: func(repo *mocks.MockLinkRepo, cache *mocks.MockLinkCache) {
+					cache.EXPECT().
+						GetTarget(gomock.Any(), "//go.acme.io/alias").
+						Return(domain.Target{}, redis.NoMatches)
+					repo.EXPECT().
+						GetDomain(gomock.Any(), "go.acme.io").
+						Return(domain.Domain{}, linkrepo.ErrDomainNotFound)
+				},
+			},
+			want: want{err: service.ErrDomainNotFound},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockRepo := mocks.NewMockLinkRepo(ctrl)
+			mockCache := mocks.NewMockLinkCache(ctrl)
+
+			if tt.fields.setup != nil {
+				tt.fields.setup(mockRepo, mockCache)
+			}
+
+			svc := service.New(mockRepo, mockCache, ignoreEvents(ctrl), publisher.Noop{}, time.Hour, service.NamespaceGlobal, "https://sho.rt", nil)
+
+			got, err := svc.GetURLByHost(context.Background(), "Go.Acme.io", "alias")
+
+			if tt.want.err != nil {
+				require.ErrorIs(t, err, tt.want.err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.want.target, got)
+		})
+	}
+}
+
+func TestLink_SaveLink_Domain(t *testing.T) {
+	domainID := uuid.New()
+
+	tests := []struct {
+		name      string
+		workspace uuid.UUID
+		err       error
+	}{
+		{name: "domain of the workspace", workspace: workspaceID},
+		{name: "domain of another workspace", workspace: uuid.New(), err: service.ErrDomainNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockRepo := mocks.NewMockLinkRepo(ctrl)
+			mockCache := mocks.NewMockLinkCache(ctrl)
+
+			mockRepo.EXPECT().
+				GetDomain(gomock.Any(), "go.acme.io").
+				Return(domain.Domain{ID: domainID, WorkspaceID: tt.workspace, Host: "go.acme.io"}, nil)
+			if tt.err == nil {
+				domainRef := uuid.NullUUID{UUID: domainID, Valid: true}
+				mockRepo.EXPECT().
+					ReleaseDeletedAlias(gomock.Any(), "", domainRef, "x", gomock.Any()).
+					Return(nil)
+				mockRepo.EXPECT().
+					CreateLink(gomock.Any(), gomock.Cond(func(l domain.Link) bool {
+						return l.DomainID == domainRef && l.Namespace == ""
+					})).
+					Return("x", nil)
+			}
+
+			svc := service.New(mockRepo, mockCache, ignoreEvents(ctrl), publisher.Noop{}, time.Hour, service.NamespaceWorkspace, "https://sho.rt", nil)
+
+			creator := auth.Principal{Workspace: access.Workspace}
+			link := dto.Link{URL: "https://example.com", Alias: "x", Domain: "go.acme.io"}
+			created, err := svc.SaveLink(context.Background(), creator, link, retry.Strategy{Attempts: 1})
+
+			if tt.err != nil {
+				require.ErrorIs(t, err, tt.err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, dto.ShortLink{Alias: "x", URL: "https://go.acme.io/x"}, created)
+		})
+	}
+}
+
+func TestLink_SaveLink_ShortURL(t *testing.T) {
+	tests := []struct {
+		name      string
+		namespace string
+		baseURL   string
+		alias     string
+		want      string
+		err       error
+	}{
+		{name: "global", namespace: service.NamespaceGlobal, baseURL: "https://sho.rt/", alias: "x", want: "https://sho.rt/x"},
+		{name: "prefixed", namespace: service.NamespaceGlobal, baseURL: "https://example.com/go", alias: "x", want: "https://example.com/go/x"},
+		{name: "workspace", namespace: service.NamespaceWorkspace, baseURL: "https://sho.rt", alias: "x", want: "https://sho.rt/acme/x"},
+		{name: "reserved", namespace: service.NamespaceGlobal, baseURL: "https://sho.rt", alias: "Swagger", err: service.ErrAliasReserved},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockRepo := mocks.NewMockLinkRepo(ctrl)
+			mockCache := mocks.NewMockLinkCache(ctrl)
+
+			if tt.err == nil {
+				mockRepo.EXPECT().ReleaseDeletedAlias(gomock.Any(), gomock.Any(), gomock.Any(), tt.alias, gomock.Any()).Return(nil)
+				mockRepo.EXPECT().CreateLink(gomock.Any(), gomock.Any()).Return(tt.alias, nil)
+			}
+
+			svc := service.New(mockRepo, mockCache, ignoreEvents(ctrl), publisher.Noop{}, time.Hour, tt.namespace, tt.baseURL, []string{"api", "swagger"})
+
+			creator := auth.Principal{Workspace: access.Workspace}
+			link := dto.Link{URL: "https://example.com", Alias: tt.alias}
+			created, err := svc.SaveLink(context.Background(), creator, link, retry.Strategy{Attempts: 1})
+
+			if tt.err != nil {
+				require.ErrorIs(t, err, tt.err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.want, created.URL)
+		})
+	}
+}
+
+// ignoreEvents returns link events for tests that do not check them.
+func ignoreEvents(ctrl *gomock.Controller) *mocks.MockLinkEvents {
+	events := mocks.NewMockLinkEvents(ctrl)
+	events.EXPECT().Emit(gomock.Any()).AnyTimes()
+	return events
+}
@@ -2,12 +2,79 @@ package domain
 
 import (
 	"github.com/google/uuid"
+	"github.com/ilam072/shortener/internal/auth"
 	"time"
 )
 
+const (
+	StatusActive   = "active"
+	StatusDisabled = "disabled"
+	StatusDeleted  = "deleted"
+)
+
 type Link struct {
-	ID        uuid.UUID
+	ID          uuid.UUID
+	WorkspaceID uuid.UUID
+	// Namespace scopes Alias: aliases are unique per namespace, or per
+	// domain for links on a custom domain.
+	Namespace string
+	DomainID  uuid.NullUUID
+	// Host is the host of DomainID, empty for links on the default hosts.
+	Host      string
+	OwnerID   uuid.NullUUID
 	URL       string
 	Alias     string
 	CreatedAt time.Time
+	ExpiresAt *time.Time
+	MaxClicks *int
+	Clicks    int
+	Status    string
+	DeletedAt *time.Time
+}
+
+// Domain is a custom host short links can be served from.
+type Domain struct {
+	ID          uuid.UUID
+	WorkspaceID uuid.UUID
+	Host        string
+	FallbackURL *string
+}
+
+const (
+	SortByCreatedAt = "created_at"
+	SortByClicks    = "clicks"
+)
+
+type LinkFilter struct {
+	Access      auth.Access
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time
+	Host        string
+	AliasPrefix string
+	Sort        string
+	Desc        bool
+	Limit       int
+	After       *LinkCursor
+}
+
+// LinkCursor is the position of the last link of a page in the chosen
+// sort order. Only the field matching the sort is used besides ID.
+type LinkCursor struct {
+	CreatedAt time.Time
+	Clicks    int
+	ID        uuid.UUID
+}
+
+// Target is what a redirect resolves to. It is cached as is, so that cache
+// hits can still attribute the click to its link and workspace.
+type Target struct {
+	LinkID      uuid.UUID `json:"link_id"`
+	WorkspaceID uuid.UUID `json:"workspace_id"`
+	URL         string    `json:"url"`
+}
+
+type LinkWithClicks struct {
+	Link
+	// TotalClicks counts the clicks not made by bots.
+	TotalClicks int
 }
@@ -1,6 +1,56 @@
 package dto
 
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
 type Link struct {
-	URL   string `json:"url" validate:"required,url"`
-	Alias string `json:"alias,omitempty"`
+	URL       string     `json:"url" validate:"required,url"`
+	Alias     string     `json:"alias,omitempty"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	TTL       int64      `json:"ttl,omitempty" validate:"omitempty,gt=0,max=315360000,excluded_with=ExpiresAt"`
+	MaxClicks int        `json:"max_clicks,omitempty" validate:"omitempty,gt=0"`
+	Domain    string     `json:"domain,omitempty" validate:"omitempty,hostname"`
+}
+
+// ShortLink is a created link: its alias and the full short URL it is
+// served at.
+type ShortLink struct {
+	Alias string `json:"alias"`
+	URL   string `json:"short_url"`
+}
+
+type UpdateLink struct {
+	URL string `json:"url" validate:"required,url"`
+}
+
+type LinkInfo struct {
+	Domain    string     `json:"domain,omitempty"`
+	Alias     string     `json:"alias"`
+	URL       string     `json:"url"`
+	CreatedAt time.Time  `json:"created_at"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	MaxClicks *int       `json:"max_clicks,omitempty"`
+	Clicks    int        `json:"clicks"`
+	Status    string     `json:"status"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+}
+
+type ListLinks struct {
+	CreatedFrom *time.Time `form:"created_from"`
+	CreatedTo   *time.Time `form:"created_to"`
+	Host        string     `form:"host"`
+	AliasPrefix string     `form:"alias_prefix"`
+	Sort        string     `form:"sort" validate:"omitempty,oneof=created_at clicks"`
+	Order       string     `form:"order" validate:"omitempty,oneof=asc desc"`
+	Limit       int        `form:"limit" validate:"omitempty,min=1,max=100"`
+	Cursor      string     `form:"cursor"`
+}
+
+// Target is the destination of a redirect and the link it belongs to.
+type Target struct {
+	LinkID      uuid.UUID
+	WorkspaceID uuid.UUID
+	URL         string
 }
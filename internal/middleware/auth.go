@@ -0,0 +1,68 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	apikeyservice "github.com/ilam072/shortener/internal/apikey/service"
+	"github.com/ilam072/shortener/internal/auth"
+	"github.com/ilam072/shortener/internal/response"
+	userservice "github.com/ilam072/shortener/internal/user/service"
+	"github.com/wb-go/wbf/ginext"
+	"github.com/wb-go/wbf/zlog"
+	"net/http"
+	"strings"
+)
+
+//go:generate mockgen -source=auth.go -destination=mocks/middleware_mocks.go -package=mocks
+type KeyAuthenticator interface {
+	Authenticate(ctx context.Context, raw string) (auth.Principal, error)
+}
+
+type TokenParser interface {
+	ParseToken(token string) (auth.Principal, error)
+}
+
+// AuthMiddleware admits requests whose caller is granted scope. The caller
+// presents either an API key (X-API-Key or "Authorization: Bearer <key>")
+// or a user JWT ("Authorization: Bearer <jwt>"). The resolved principal is
+// stored in the request context, see auth.FromContext.
+func AuthMiddleware(keys KeyAuthenticator, tokens TokenParser, scope string) ginext.HandlerFunc {
+	return func(c *ginext.Context) {
+		principal, err := authenticate(c, keys, tokens)
+		if err != nil {
+			if errors.Is(err, apikeyservice.ErrInvalidKey) || errors.Is(err, userservice.ErrInvalidToken) {
+				response.Error("invalid credentials").WriteJSON(c, http.StatusUnauthorized)
+				c.Abort()
+				return
+			}
+			zlog.Logger.Error().Err(err).Msg("failed to authenticate request")
+			response.Error("internal server error, try again later").WriteJSON(c, http.StatusInternalServerError)
+			c.Abort()
+			return
+		}
+
+		if !principal.HasScope(scope) {
+			response.Error("insufficient scope").WriteJSON(c, http.StatusForbidden)
+			c.Abort()
+			return
+		}
+
+		c.Request = c.Request.WithContext(auth.WithPrincipal(c.Request.Context(), principal))
+		c.Next()
+	}
+}
+
+func authenticate(c *ginext.Context, keys KeyAuthenticator, tokens TokenParser) (auth.Principal, error) {
+	if key := c.GetHeader("X-API-Key"); key != "" {
+		return keys.Authenticate(c.Request.Context(), key)
+	}
+
+	bearer, _ := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+	bearer = strings.TrimSpace(bearer)
+
+	// API keys are base64url and never contain dots, a JWT always has two.
+	if strings.Count(bearer, ".") == 2 {
+		return tokens.ParseToken(bearer)
+	}
+	return keys.Authenticate(c.Request.Context(), bearer)
+}
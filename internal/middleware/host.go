@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"github.com/ilam072/shortener/internal/response"
+	"github.com/wb-go/wbf/ginext"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// HostRouting hands requests addressed to any host but defaultHosts over
+// to redirect, so custom domains serve nothing but their short links. With
+// no defaultHosts every host is a default one and routing is by path only.
+func HostRouting(defaultHosts []string, redirect ginext.HandlerFunc) ginext.HandlerFunc {
+	hosts := make(map[string]struct{}, len(defaultHosts))
+	for _, host := range defaultHosts {
+		hosts[strings.ToLower(host)] = struct{}{}
+	}
+
+	return func(c *ginext.Context) {
+		if len(hosts) == 0 {
+			c.Next()
+			return
+		}
+
+		host, _, err := net.SplitHostPort(c.Request.Host)
+		if err != nil {
+			host = c.Request.Host
+		}
+		if _, ok := hosts[strings.ToLower(host)]; ok {
+			c.Next()
+			return
+		}
+
+		if c.Request.Method != http.MethodGet && c.Request.Method != http.MethodHead {
+			response.Error("not found").WriteJSON(c, http.StatusNotFound)
+			c.Abort()
+			return
+		}
+
+		redirect(c)
+		c.Abort()
+	}
+}
@@ -0,0 +1,413 @@
+package middleware_test
+
+import (
+	"context"
+	"errors"
+	"go.uber.org/mock/gomock"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"github.com/wb-go/wbf/ginext"
+
+	apikeyservice "github.com/ilam072/shortener/internal/apikey/service"
+	"github.com/ilam072/shortener/internal/auth"
+	"github.com/ilam072/shortener/internal/middleware"
+	"github.com/ilam072/shortener/internal/middleware/mocks"
+	userservice "github.com/ilam072/shortener/internal/user/service"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+const (
+	testKey = "sk_live_abcDEF123-_xyz"
+	testJWT = "header.payload.signature"
+)
+
+func TestAuthMiddleware(t *testing.T) {
+	workspaceID := uuid.New()
+
+	type fields struct {
+		setup func(keys *mocks.MockKeyAuthenticator, tokens *mocks.MockTokenParser)
+	}
+	type want struct {
+		status    int
+		workspace uuid.UUID
+	}
+
+	tests := []struct {
+		name    string
+		headers map[string]string
+		scope   string
+		fields  fields
+		want    want
+	}{
+		{
+			name:    "api key header",
+			headers: map[string]string{"X-API-Key": testKey},
+			scope:   auth.ScopeCreate,
+			fields: fields{
+				setup: func(keys *mocks.MockKeyAuthenticator, tokens *mocks.MockTokenParser) {
+					keys.EXPECT().
+						Authenticate(gomock.Any(), testKey).
+						Return(auth.Principal{Workspace: auth.Workspace{ID: workspaceID}, Scopes: []string{auth.ScopeCreate}}, nil)
+				},
+			},
+			want: want{status: http.StatusOK, workspace: workspaceID},
+		},
+		{
+			name:    "api key header wins over bearer",
+			headers: map[string]string{"X-API-Key": testKey, "Authorization": "Bearer " + testJWT},
+			scope:   auth.ScopeCreate,
+			fields: fields{
+				setup: func(keys *mocks.MockKeyAuthenticator, tokens *mocks.MockTokenParser) {
+					keys.EXPECT().
+						Authenticate(gomock.Any(), testKey).
+						Return(auth.Principal{Workspace: auth.Workspace{ID: workspaceID}, Scopes: []string{auth.ScopeCreate}}, nil)
+				},
+			},
+			want: want{status: http.StatusOK, workspace: workspaceID},
+		},
+		{
+			name:    "bearer without dots is an api key",
+			headers: map[string]string{"Authorization": "Bearer " + testKey},
+			scope:   auth.ScopeCreate,
+			fields: fields{
+				setup: func(keys *mocks.MockKeyAuthenticator, tokens *mocks.MockTokenParser) {
+					keys.EXPECT().
+						Authenticate(gomock.Any(), testKey).
+						Return(auth.Principal{Workspace: auth.Workspace{ID: workspaceID}, Scopes: []string{auth.ScopeCreate}}, nil)
+				},
+			},
+			want: want{status: http.StatusOK, workspace: workspaceID},
+		},
+		{
+			name:    "bearer with two dots is a jwt",
+			headers: map[string]string{"Authorization": "Bearer  " + testJWT + " "},
+			scope:   auth.ScopeReadAnalytics,
+			fields: fields{
+				setup: func(keys *mocks.MockKeyAuthenticator, tokens *mocks.MockTokenParser) {
+					tokens.EXPECT().
+						ParseToken(testJWT).
+						Return(auth.Principal{Workspace: auth.Workspace{ID: workspaceID}, Scopes: []string{auth.ScopeReadAnalytics}}, nil)
+				},
+			},
+			want: want{status: http.StatusOK, workspace: workspaceID},
+		},
+		{
+			name:    "bearer with one dot is an api key",
+			headers: map[string]string{"Authorization": "Bearer a.b"},
+			scope:   auth.ScopeCreate,
+			fields: fields{
+				setup: func(keys *mocks.MockKeyAuthenticator, tokens *mocks.MockTokenParser) {
+					keys.EXPECT().
+						Authenticate(gomock.Any(), "a.b").
+						Return(auth.Principal{}, apikeyservice.ErrInvalidKey)
+				},
+			},
+			want: want{status: http.StatusUnauthorized},
+		},
+		{
+			name:  "no credentials",
+			scope: auth.ScopeCreate,
+			fields: fields{
+				setup: func(keys *mocks.MockKeyAuthenticator, tokens *mocks.MockTokenParser) {
+					keys.EXPECT().
+						Authenticate(gomock.Any(), "").
+						Return(auth.Principal{}, apikeyservice.ErrInvalidKey)
+				},
+			},
+			want: want{status: http.StatusUnauthorized},
+		},
+		{
+			name:    "invalid jwt",
+			headers: map[string]string{"Authorization": "Bearer " + testJWT},
+			scope:   auth.ScopeCreate,
+			fields: fields{
+				setup: func(keys *mocks.MockKeyAuthenticator, tokens *mocks.MockTokenParser) {
+					tokens.EXPECT().
+						ParseToken(testJWT).
+						Return(auth.Principal{}, userservice.ErrInvalidToken)
+				},
+			},
+			want: want{status: http.StatusUnauthorized},
+		},
+		{
+			name:    "authentication error",
+			headers: map[string]string{"X-API-Key": testKey},
+			scope:   auth.ScopeCreate,
+			fields: fields{
+				setup: func(keys *mocks.MockKeyAuthenticator, tokens *mocks.MockTokenParser) {
+					keys.EXPECT().
+						Authenticate(gomock.Any(), testKey).
+						Return(auth.Principal{}, errors.New("db error"))
+				},
+			},
+			want: want{status: http.StatusInternalServerError},
+		},
+		{
+			name:    "insufficient scope",
+			headers: map[string]string{"X-API-Key": testKey},
+			scope:   auth.ScopeAdmin,
+			fields: fields{
+				setup: func(keys *mocks.MockKeyAuthenticator, tokens *mocks.MockTokenParser) {
+					keys.EXPECT().
+						Authenticate(gomock.Any(), testKey).
+						Return(auth.Principal{Scopes: []string{auth.ScopeCreate, auth.ScopeReadAnalytics}}, nil)
+				},
+			},
+			want: want{status: http.StatusForbidden},
+		},
+		{
+			name:    "admin is granted other scopes",
+			headers: map[string]string{"X-API-Key": testKey},
+			scope:   auth.ScopeReadAnalytics,
+			fields: fields{
+				setup: func(keys *mocks.MockKeyAuthenticator, tokens *mocks.MockTokenParser) {
+					keys.EXPECT().
+						Authenticate(gomock.Any(), testKey).
+						Return(auth.Principal{Workspace: auth.Workspace{ID: workspaceID}, Scopes: []string{auth.ScopeAdmin}}, nil)
+				},
+			},
+			want: want{status: http.StatusOK, workspace: workspaceID},
+		},
+		{
+			name:    "admin is not an operator",
+			headers: map[string]string{"X-API-Key": testKey},
+			scope:   auth.ScopeOperator,
+			fields: fields{
+				setup: func(keys *mocks.MockKeyAuthenticator, tokens *mocks.MockTokenParser) {
+					keys.EXPECT().
+						Authenticate(gomock.Any(), testKey).
+						Return(auth.Principal{Scopes: []string{auth.ScopeAdmin}}, nil)
+				},
+			},
+			want: want{status: http.StatusForbidden},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockKeys := mocks.NewMockKeyAuthenticator(ctrl)
+			mockTokens := mocks.NewMockTokenParser(ctrl)
+
+			if tt.fields.setup != nil {
+				tt.fields.setup(mockKeys, mockTokens)
+			}
+
+			var reached uuid.UUID
+			engine := gin.New()
+			engine.GET("/api/links", middleware.AuthMiddleware(mockKeys, mockTokens, tt.scope), func(c *ginext.Context) {
+				reached = auth.FromContext(c.Request.Context()).Workspace.ID
+				c.Status(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/api/links", nil)
+			for name, value := range tt.headers {
+				req.Header.Set(name, value)
+			}
+			w := httptest.NewRecorder()
+			engine.ServeHTTP(w, req)
+
+			require.Equal(t, tt.want.status, w.Code)
+			require.Equal(t, tt.want.workspace, reached)
+		})
+	}
+}
+
+func TestHostRouting(t *testing.T) {
+	type want struct {
+		status int
+		body   string
+	}
+
+	tests := []struct {
+		name         string
+		defaultHosts []string
+		method       string
+		host         string
+		path         string
+		want         want
+	}{
+		{
+			name:   "every host is a default one without default hosts",
+			method: http.MethodGet,
+			host:   "go.acme.io",
+			path:   "/api/links",
+			want:   want{status: http.StatusOK, body: "api"},
+		},
+		{
+			name:         "default host is routed by path",
+			defaultHosts: []string{"sho.rt"},
+			method:       http.MethodGet,
+			host:         "sho.rt",
+			path:         "/api/links",
+			want:         want{status: http.StatusOK, body: "api"},
+		},
+		{
+			name:         "default host with port and in another case",
+			defaultHosts: []string{"sho.rt"},
+			method:       http.MethodPost,
+			host:         "SHO.RT:8080",
+			path:         "/api/links",
+			want:         want{status: http.StatusOK, body: "api"},
+		},
+		{
+			name:         "custom host redirects",
+			defaultHosts: []string{"sho.rt"},
+			method:       http.MethodGet,
+			host:         "go.acme.io",
+			path:         "/api/links",
+			want:         want{status: http.StatusFound, body: "redirect"},
+		},
+		{
+			name:         "custom host redirects head requests",
+			defaultHosts: []string{"sho.rt"},
+			method:       http.MethodHead,
+			host:         "go.acme.io:443",
+			path:         "/abc",
+			want:         want{status: http.StatusFound, body: "redirect"},
+		},
+		{
+			name:         "custom host serves nothing else",
+			defaultHosts: []string{"sho.rt"},
+			method:       http.MethodPost,
+			host:         "go.acme.io",
+			path:         "/api/links",
+			want:         want{status: http.StatusNotFound},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			redirect := func(c *ginext.Context) {
+				c.String(http.StatusFound, "redirect")
+			}
+
+			engine := gin.New()
+			engine.Use(middleware.HostRouting(tt.defaultHosts, redirect))
+			engine.Any("/api/links", func(c *ginext.Context) {
+				c.String(http.StatusOK, "api")
+			})
+
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			req.Host = tt.host
+			w := httptest.NewRecorder()
+			engine.ServeHTTP(w, req)
+
+			require.Equal(t, tt.want.status, w.Code)
+			if tt.want.body != "" && tt.method != http.MethodHead {
+				require.Equal(t, tt.want.body, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestTimeoutMiddleware(t *testing.T) {
+	exempt := []string{
+		"/api/analytics/:alias/stream",
+		"/api/analytics/:alias/export",
+		"/api/webhooks/:id/dead-letters/:letter_id/redeliver",
+	}
+
+	// bounded reports whether the request has a deadline.
+	bounded := func(c *ginext.Context) {
+		_, ok := c.Request.Context().Deadline()
+		c.JSON(http.StatusOK, ginext.H{"bounded": ok})
+	}
+	// slow outlives the timeout, unless it is cancelled.
+	slow := func(c *ginext.Context) {
+		select {
+		case <-c.Request.Context().Done():
+		case <-time.After(time.Second):
+			c.Status(http.StatusOK)
+		}
+	}
+
+	type want struct {
+		status int
+		body   string
+	}
+
+	tests := []struct {
+		name    string
+		method  string
+		route   string
+		path    string
+		handler ginext.HandlerFunc
+		want    want
+	}{
+		{
+			name:    "bounds requests",
+			method:  http.MethodGet,
+			route:   "/api/links/:alias",
+			path:    "/api/links/abc",
+			handler: bounded,
+			want:    want{status: http.StatusOK, body: `{"bounded":true}`},
+		},
+		{
+			name:    "times out slow requests",
+			method:  http.MethodGet,
+			route:   "/api/links/:alias",
+			path:    "/api/links/abc",
+			handler: slow,
+			want:    want{status: http.StatusGatewayTimeout, body: `{"error":"request timed out"}`},
+		},
+		{
+			name:    "stream is exempt",
+			method:  http.MethodGet,
+			route:   "/api/analytics/:alias/stream",
+			path:    "/api/analytics/abc/stream",
+			handler: bounded,
+			want:    want{status: http.StatusOK, body: `{"bounded":false}`},
+		},
+		{
+			name:    "export is exempt",
+			method:  http.MethodGet,
+			route:   "/api/analytics/:alias/export",
+			path:    "/api/analytics/abc/export",
+			handler: bounded,
+			want:    want{status: http.StatusOK, body: `{"bounded":false}`},
+		},
+		{
+			name:    "redeliver is exempt",
+			method:  http.MethodPost,
+			route:   "/api/webhooks/:id/dead-letters/:letter_id/redeliver",
+			path:    "/api/webhooks/1/dead-letters/2/redeliver",
+			handler: bounded,
+			want:    want{status: http.StatusOK, body: `{"bounded":false}`},
+		},
+		{
+			name:    "exempt by route, not by path",
+			method:  http.MethodGet,
+			route:   "/api/analytics/:alias",
+			path:    "/api/analytics/stream",
+			handler: bounded,
+			want:    want{status: http.StatusOK, body: `{"bounded":true}`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine := gin.New()
+			engine.Use(middleware.TimeoutMiddleware(20*time.Millisecond, exempt...))
+			engine.Handle(tt.method, tt.route, tt.handler)
+
+			req := httptest.NewRequestWithContext(context.Background(), tt.method, tt.path, nil)
+			w := httptest.NewRecorder()
+			engine.ServeHTTP(w, req)
+
+			require.Equal(t, tt.want.status, w.Code)
+			require.JSONEq(t, tt.want.body, w.Body.String())
+		})
+	}
+}
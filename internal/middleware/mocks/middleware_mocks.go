@@ -0,0 +1,96 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: auth.go
+//
+// Generated by this command:
+//
+//	mockgen -source=auth.go -destination=mocks/middleware_mocks.go -package=mocks
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	auth "github.com/ilam072/shortener/internal/auth"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockKeyAuthenticator is a mock of KeyAuthenticator interface.
+type MockKeyAuthenticator struct {
+	ctrl     *gomock.Controller
+	recorder *MockKeyAuthenticatorMockRecorder
+	isgomock struct{}
+}
+
+// MockKeyAuthenticatorMockRecorder is the mock recorder for MockKeyAuthenticator.
+type MockKeyAuthenticatorMockRecorder struct {
+	mock *MockKeyAuthenticator
+}
+
+// NewMockKeyAuthenticator creates a new mock instance.
+func NewMockKeyAuthenticator(ctrl *gomock.Controller) *MockKeyAuthenticator {
+	mock := &MockKeyAuthenticator{ctrl: ctrl}
+	mock.recorder = &MockKeyAuthenticatorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockKeyAuthenticator) EXPECT() *MockKeyAuthenticatorMockRecorder {
+	return m.recorder
+}
+
+// Authenticate mocks base method.
+func (m *MockKeyAuthenticator) Authenticate(ctx context.Context, raw string) (auth.Principal, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Authenticate", ctx, raw)
+	ret0, _ := ret[0].(auth.Principal)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Authenticate indicates an expected call of Authenticate.
+func (mr *MockKeyAuthenticatorMockRecorder) Authenticate(ctx, raw any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Authenticate", reflect.TypeOf((*MockKeyAuthenticator)(nil).Authenticate), ctx, raw)
+}
+
+// MockTokenParser is a mock of TokenParser interface.
+type MockTokenParser struct {
+	ctrl     *gomock.Controller
+	recorder *MockTokenParserMockRecorder
+	isgomock struct{}
+}
+
+// MockTokenParserMockRecorder is the mock recorder for MockTokenParser.
+type MockTokenParserMockRecorder struct {
+	mock *MockTokenParser
+}
+
+// NewMockTokenParser creates a new mock instance.
+func NewMockTokenParser(ctrl *gomock.Controller) *MockTokenParser {
+	mock := &MockTokenParser{ctrl: ctrl}
+	mock.recorder = &MockTokenParserMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTokenParser) EXPECT() *MockTokenParserMockRecorder {
+	return m.recorder
+}
+
+// ParseToken mocks base method.
+func (m *MockTokenParser) ParseToken(token string) (auth.Principal, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ParseToken", token)
+	ret0, _ := ret[0].(auth.Principal)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ParseToken indicates an expected call of ParseToken.
+func (mr *MockTokenParserMockRecorder) ParseToken(token any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ParseToken", reflect.TypeOf((*MockTokenParser)(nil).ParseToken), token)
+}
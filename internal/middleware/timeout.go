@@ -5,11 +5,19 @@ import (
 	"errors"
 	"github.com/wb-go/wbf/ginext"
 	"net/http"
+	"slices"
 	"time"
 )
 
-func TimeoutMiddleware(timeout time.Duration) ginext.HandlerFunc {
+// TimeoutMiddleware bounds requests to timeout, but for the routes in
+// exempt, such as streams, which bound themselves.
+func TimeoutMiddleware(timeout time.Duration, exempt ...string) ginext.HandlerFunc {
 	return func(c *ginext.Context) {
+		if slices.Contains(exempt, c.FullPath()) {
+			c.Next()
+			return
+		}
+
 		ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
 		defer cancel()
 
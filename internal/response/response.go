@@ -24,6 +24,21 @@ func Error(payload interface{}) Response {
 	}
 }
 
+// Page — страница списка с курсором на следующую страницу.
+type Page struct {
+	Items      interface{} `json:"items"`
+	NextCursor string      `json:"next_cursor,omitempty"`
+}
+
+// Paginated создаёт успешный Response со страницей списка.
+// Пустой nextCursor означает, что страница последняя.
+func Paginated(items interface{}, nextCursor string) Response {
+	return Success(Page{
+		Items:      items,
+		NextCursor: nextCursor,
+	})
+}
+
 // WriteJSON отправляет Response через Gin с указанным HTTP кодом.
 func (r Response) WriteJSON(c *gin.Context, code int) {
 	c.JSON(code, r)
@@ -0,0 +1,89 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/ilam072/shortener/internal/user/repo"
+	"github.com/ilam072/shortener/internal/user/types/domain"
+	"github.com/ilam072/shortener/pkg/errutils"
+	"github.com/lib/pq"
+	"github.com/wb-go/wbf/dbpg"
+)
+
+type UserRepo struct {
+	db *dbpg.DB
+}
+
+func New(db *dbpg.DB) *UserRepo {
+	return &UserRepo{db: db}
+}
+
+func (r *UserRepo) CreateUser(ctx context.Context, user domain.User) (domain.User, error) {
+	const op = "repo.user.Create"
+
+	query := `
+		INSERT INTO users(id, email, password_hash, role, workspace_id)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING created_at;
+	`
+
+	if err := r.db.QueryRowContext(
+		ctx,
+		query,
+		user.ID,
+		user.Email,
+		user.PasswordHash,
+		user.Role,
+		user.WorkspaceID,
+	).Scan(&user.CreatedAt); err != nil {
+		if isUniqueViolation(err) {
+			return domain.User{}, errutils.Wrap(op, repo.ErrEmailAlreadyExists)
+		}
+		if isForeignKeyViolation(err) {
+			return domain.User{}, errutils.Wrap(op, repo.ErrWorkspaceNotFound)
+		}
+		return domain.User{}, errutils.Wrap(op, err)
+	}
+
+	return user, nil
+}
+
+func (r *UserRepo) GetUserByEmail(ctx context.Context, email string) (domain.User, error) {
+	const op = "repo.user.GetByEmail"
+
+	query := `
+		SELECT u.id, u.email, u.password_hash, u.role, u.workspace_id, w.slug, u.created_at
+		FROM users u
+		JOIN workspaces w ON w.id = u.workspace_id
+		WHERE u.email = $1;
+	`
+
+	var user domain.User
+	if err := r.db.QueryRowContext(ctx, query, email).Scan(
+		&user.ID,
+		&user.Email,
+		&user.PasswordHash,
+		&user.Role,
+		&user.WorkspaceID,
+		&user.WorkspaceSlug,
+		&user.CreatedAt,
+	); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.User{}, errutils.Wrap(op, repo.ErrUserNotFound)
+		}
+		return domain.User{}, errutils.Wrap(op, err)
+	}
+
+	return user, nil
+}
+
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505"
+}
+
+func isForeignKeyViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23503"
+}
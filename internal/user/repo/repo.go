@@ -0,0 +1,9 @@
+package repo
+
+import "errors"
+
+var (
+	ErrEmailAlreadyExists = errors.New("email already exists")
+	ErrUserNotFound       = errors.New("user not found")
+	ErrWorkspaceNotFound  = errors.New("workspace not found")
+)
@@ -0,0 +1,133 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/ilam072/shortener/internal/auth"
+	"github.com/ilam072/shortener/internal/response"
+	"github.com/ilam072/shortener/internal/user/service"
+	"github.com/ilam072/shortener/internal/user/types/dto"
+	"github.com/wb-go/wbf/ginext"
+	"github.com/wb-go/wbf/zlog"
+	"net/http"
+)
+
+//go:generate mockgen -source=handler.go -destination=../mocks/rest_mocks.go -package=mocks
+type User interface {
+	CreateUser(ctx context.Context, workspaceID uuid.UUID, user dto.CreateUser) (dto.User, error)
+	Login(ctx context.Context, login dto.Login) (dto.Token, error)
+}
+
+type Validator interface {
+	Validate(i interface{}) error
+}
+
+type UserHandler struct {
+	user      User
+	validator Validator
+}
+
+func NewUserHandler(user User, validator Validator) *UserHandler {
+	return &UserHandler{user: user, validator: validator}
+}
+
+// CreateUser godoc
+// @Summary Создать пользователя
+// @Description Создаёт локальную учётную запись в рабочем пространстве вызывающего. Оператор может указать другое пространство. Пароль хранится в виде bcrypt-хэша
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Param input body dto.CreateUser true "Email, пароль и роль"
+// @Success 201 {object} response.Response{payload=dto.User} "Созданный пользователь"
+// @Failure 400 {object} response.Response "invalid request body или validation error"
+// @Failure 401 {object} response.Response "invalid credentials"
+// @Failure 403 {object} response.Response "insufficient scope"
+// @Failure 404 {object} response.Response "workspace not found"
+// @Failure 409 {object} response.Response "email already exists"
+// @Failure 500 {object} response.Response "internal server error"
+// @Router /users [post]
+func (h *UserHandler) CreateUser(c *ginext.Context) {
+	var user dto.CreateUser
+	if err := json.NewDecoder(c.Request.Body).Decode(&user); err != nil {
+		response.Error("invalid request body").WriteJSON(c, http.StatusBadRequest)
+		return
+	}
+	if err := h.validator.Validate(user); err != nil {
+		response.Error(fmt.Sprintf("validation error: %s", err.Error())).WriteJSON(c, http.StatusBadRequest)
+		return
+	}
+
+	caller := auth.FromContext(c.Request.Context())
+	workspaceID := caller.Workspace.ID
+	if user.WorkspaceID != "" {
+		if !caller.HasScope(auth.ScopeOperator) {
+			response.Error("insufficient scope").WriteJSON(c, http.StatusForbidden)
+			return
+		}
+		id, err := uuid.Parse(user.WorkspaceID)
+		if err != nil {
+			response.Error("validation error: invalid workspace_id").WriteJSON(c, http.StatusBadRequest)
+			return
+		}
+		workspaceID = id
+	}
+
+	created, err := h.user.CreateUser(c.Request.Context(), workspaceID, user)
+	if err != nil {
+		if errors.Is(err, service.ErrEmailAlreadyExists) {
+			response.Error("user with such email already exists").WriteJSON(c, http.StatusConflict)
+			return
+		}
+		if errors.Is(err, service.ErrWorkspaceNotFound) {
+			response.Error("workspace not found").WriteJSON(c, http.StatusNotFound)
+			return
+		}
+		zlog.Logger.Error().Err(err).Msg("failed to create user")
+		response.Error("internal server error, try again later").WriteJSON(c, http.StatusInternalServerError)
+		return
+	}
+
+	response.Success(created).WriteJSON(c, http.StatusCreated)
+}
+
+// Login godoc
+// @Summary Войти
+// @Description Проверяет email и пароль и выдаёт JWT для заголовка Authorization: Bearer
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Param input body dto.Login true "Email и пароль"
+// @Success 200 {object} response.Response{payload=dto.Token} "JWT"
+// @Failure 400 {object} response.Response "invalid request body или validation error"
+// @Failure 401 {object} response.Response "invalid email or password"
+// @Failure 500 {object} response.Response "internal server error"
+// @Router /auth/login [post]
+func (h *UserHandler) Login(c *ginext.Context) {
+	var login dto.Login
+	if err := json.NewDecoder(c.Request.Body).Decode(&login); err != nil {
+		response.Error("invalid request body").WriteJSON(c, http.StatusBadRequest)
+		return
+	}
+	if err := h.validator.Validate(login); err != nil {
+		response.Error(fmt.Sprintf("validation error: %s", err.Error())).WriteJSON(c, http.StatusBadRequest)
+		return
+	}
+
+	token, err := h.user.Login(c.Request.Context(), login)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidCredentials) {
+			response.Error("invalid email or password").WriteJSON(c, http.StatusUnauthorized)
+			return
+		}
+		zlog.Logger.Error().Err(err).Msg("failed to log in")
+		response.Error("internal server error, try again later").WriteJSON(c, http.StatusInternalServerError)
+		return
+	}
+
+	response.Success(token).WriteJSON(c, http.StatusOK)
+}
@@ -0,0 +1,221 @@
+package rest_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"github.com/wb-go/wbf/ginext"
+	"go.uber.org/mock/gomock"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ilam072/shortener/internal/user/mocks"
+	"github.com/ilam072/shortener/internal/user/rest"
+	"github.com/ilam072/shortener/internal/user/service"
+	"github.com/ilam072/shortener/internal/user/types/dto"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+func newTestContext(method, path string, body []byte) (*ginext.Context, *httptest.ResponseRecorder) {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+
+	req := httptest.NewRequest(method, path, bytes.NewBuffer(body))
+	c.Request = req
+
+	return c, w
+}
+
+func TestUserHandler_CreateUser(t *testing.T) {
+	type fields struct {
+		setup func(user *mocks.MockUser, validator *mocks.MockValidator)
+	}
+	type want struct {
+		status int
+	}
+
+	tests := []struct {
+		name   string
+		body   interface{}
+		fields fields
+		want   want
+	}{
+		{
+			name: "invalid json",
+			body: "invalid",
+			want: want{status: http.StatusBadRequest},
+		},
+		{
+			name: "validation error",
+			body: dto.CreateUser{Email: "a@example.com", Password: "short"},
+			fields: fields{
+				setup: func(user *mocks.MockUser, validator *mocks.MockValidator) {
+					validator.EXPECT().
+						Validate(gomock.Any()).
+						Return(errors.New("validation failed"))
+				},
+			},
+			want: want{status: http.StatusBadRequest},
+		},
+		{
+			name: "foreign workspace without operator scope",
+			body: dto.CreateUser{Email: "a@example.com", Password: "password", WorkspaceID: "7f1c2a52-9c1e-4c57-9b43-4a3e8f1c9b01"},
+			fields: fields{
+				setup: func(user *mocks.MockUser, validator *mocks.MockValidator) {
+					validator.EXPECT().
+						Validate(gomock.Any()).
+						Return(nil)
+				},
+			},
+			want: want{status: http.StatusForbidden},
+		},
+		{
+			name: "email taken",
+			body: dto.CreateUser{Email: "a@example.com", Password: "password"},
+			fields: fields{
+				setup: func(user *mocks.MockUser, validator *mocks.MockValidator) {
+					validator.EXPECT().
+						Validate(gomock.Any()).
+						Return(nil)
+					user.EXPECT().
+						CreateUser(gomock.Any(), gomock.Any(), gomock.Any()).
+						Return(dto.User{}, service.ErrEmailAlreadyExists)
+				},
+			},
+			want: want{status: http.StatusConflict},
+		},
+		{
+			name: "success",
+			body: dto.CreateUser{Email: "a@example.com", Password: "password"},
+			fields: fields{
+				setup: func(user *mocks.MockUser, validator *mocks.MockValidator) {
+					validator.EXPECT().
+						Validate(gomock.Any()).
+						Return(nil)
+					user.EXPECT().
+						CreateUser(gomock.Any(), gomock.Any(), gomock.Any()).
+						Return(dto.User{Email: "a@example.com"}, nil)
+				},
+			},
+			want: want{status: http.StatusCreated},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockUser := mocks.NewMockUser(ctrl)
+			mockValidator := mocks.NewMockValidator(ctrl)
+
+			if tt.fields.setup != nil {
+				tt.fields.setup(mockUser, mockValidator)
+			}
+
+			handler := rest.NewUserHandler(mockUser, mockValidator)
+
+			var bodyBytes []byte
+			switch v := tt.body.(type) {
+			case string:
+				bodyBytes = []byte(v)
+			default:
+				bodyBytes, _ = json.Marshal(v)
+			}
+
+			c, w := newTestContext(http.MethodPost, "/users", bodyBytes)
+
+			handler.CreateUser(c)
+
+			require.Equal(t, tt.want.status, w.Code)
+		})
+	}
+}
+
+func TestUserHandler_Login(t *testing.T) {
+	type fields struct {
+		setup func(user *mocks.MockUser, validator *mocks.MockValidator)
+	}
+	type want struct {
+		status int
+	}
+
+	tests := []struct {
+		name   string
+		fields fields
+		want   want
+	}{
+		{
+			name: "invalid credentials",
+			fields: fields{
+				setup: func(user *mocks.MockUser, validator *mocks.MockValidator) {
+					validator.EXPECT().
+						Validate(gomock.Any()).
+						Return(nil)
+					user.EXPECT().
+						Login(gomock.Any(), gomock.Any()).
+						Return(dto.Token{}, service.ErrInvalidCredentials)
+				},
+			},
+			want: want{status: http.StatusUnauthorized},
+		},
+		{
+			name: "internal error",
+			fields: fields{
+				setup: func(user *mocks.MockUser, validator *mocks.MockValidator) {
+					validator.EXPECT().
+						Validate(gomock.Any()).
+						Return(nil)
+					user.EXPECT().
+						Login(gomock.Any(), gomock.Any()).
+						Return(dto.Token{}, errors.New("db error"))
+				},
+			},
+			want: want{status: http.StatusInternalServerError},
+		},
+		{
+			name: "success",
+			fields: fields{
+				setup: func(user *mocks.MockUser, validator *mocks.MockValidator) {
+					validator.EXPECT().
+						Validate(gomock.Any()).
+						Return(nil)
+					user.EXPECT().
+						Login(gomock.Any(), gomock.Any()).
+						Return(dto.Token{Token: "jwt"}, nil)
+				},
+			},
+			want: want{status: http.StatusOK},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockUser := mocks.NewMockUser(ctrl)
+			mockValidator := mocks.NewMockValidator(ctrl)
+
+			if tt.fields.setup != nil {
+				tt.fields.setup(mockUser, mockValidator)
+			}
+
+			handler := rest.NewUserHandler(mockUser, mockValidator)
+
+			body, _ := json.Marshal(dto.Login{Email: "a@example.com", Password: "password"})
+			c, w := newTestContext(http.MethodPost, "/auth/login", body)
+
+			handler.Login(c)
+
+			require.Equal(t, tt.want.status, w.Code)
+		})
+	}
+}
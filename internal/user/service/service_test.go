@@ -0,0 +1,265 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"go.uber.org/mock/gomock"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/ilam072/shortener/internal/auth"
+	"github.com/ilam072/shortener/internal/user/mocks"
+	userrepo "github.com/ilam072/shortener/internal/user/repo"
+	"github.com/ilam072/shortener/internal/user/service"
+	"github.com/ilam072/shortener/internal/user/types/domain"
+	"github.com/ilam072/shortener/internal/user/types/dto"
+)
+
+const secret = "test-secret"
+
+func TestUser_CreateUser(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockUserRepo(ctrl)
+
+	var stored domain.User
+	mockRepo.EXPECT().
+		CreateUser(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, user domain.User) (domain.User, error) {
+			stored = user
+			return user, nil
+		})
+
+	svc := service.New(mockRepo, secret, time.Hour)
+
+	workspaceID := uuid.New()
+	created, err := svc.CreateUser(context.Background(), workspaceID, dto.CreateUser{
+		Email:    "Alice@Example.com",
+		Password: "correct horse",
+	})
+
+	require.NoError(t, err)
+	require.Equal(t, "alice@example.com", created.Email)
+	require.Equal(t, domain.RoleUser, created.Role)
+	require.Equal(t, workspaceID.String(), created.WorkspaceID)
+	require.NoError(t, bcrypt.CompareHashAndPassword([]byte(stored.PasswordHash), []byte("correct horse")))
+}
+
+func TestUser_CreateUser_EmailTaken(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockUserRepo(ctrl)
+	mockRepo.EXPECT().
+		CreateUser(gomock.Any(), gomock.Any()).
+		Return(domain.User{}, userrepo.ErrEmailAlreadyExists)
+
+	svc := service.New(mockRepo, secret, time.Hour)
+
+	_, err := svc.CreateUser(context.Background(), uuid.New(), dto.CreateUser{Email: "a@example.com", Password: "password"})
+	require.ErrorIs(t, err, service.ErrEmailAlreadyExists)
+}
+
+func TestUser_CreateUser_UnknownWorkspace(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockUserRepo(ctrl)
+	mockRepo.EXPECT().
+		CreateUser(gomock.Any(), gomock.Any()).
+		Return(domain.User{}, userrepo.ErrWorkspaceNotFound)
+
+	svc := service.New(mockRepo, secret, time.Hour)
+
+	_, err := svc.CreateUser(context.Background(), uuid.New(), dto.CreateUser{Email: "a@example.com", Password: "password"})
+	require.ErrorIs(t, err, service.ErrWorkspaceNotFound)
+}
+
+func TestUser_Login(t *testing.T) {
+	hash, err := bcrypt.GenerateFromPassword([]byte("password"), bcrypt.MinCost)
+	require.NoError(t, err)
+
+	userID := uuid.New()
+	workspace := auth.Workspace{ID: uuid.New(), Slug: "acme"}
+	stored := func(role string) domain.User {
+		return domain.User{
+			ID:            userID,
+			PasswordHash:  string(hash),
+			Role:          role,
+			WorkspaceID:   workspace.ID,
+			WorkspaceSlug: workspace.Slug,
+		}
+	}
+
+	type fields struct {
+		setup func(repo *mocks.MockUserRepo)
+	}
+	type want struct {
+		principal auth.Principal
+		err       error
+	}
+
+	tests := []struct {
+		name     string
+		password string
+		fields   fields
+		want     want
+	}{
+		{
+			name:     "user",
+			password: "password",
+			fields: fields{
+				setup: func(repo *mocks.MockUserRepo) {
+					repo.EXPECT().
+						GetUserByEmail(gomock.Any(), "a@example.com").
+						Return(stored(domain.RoleUser), nil)
+				},
+			},
+			want: want{principal: auth.Principal{
+				UserID:    uuid.NullUUID{UUID: userID, Valid: true},
+				Workspace: workspace,
+				Scopes:    []string{auth.ScopeCreate, auth.ScopeReadAnalytics},
+			}},
+		},
+		{
+			name:     "admin",
+			password: "password",
+			fields: fields{
+				setup: func(repo *mocks.MockUserRepo) {
+					repo.EXPECT().
+						GetUserByEmail(gomock.Any(), "a@example.com").
+						Return(stored(domain.RoleAdmin), nil)
+				},
+			},
+			want: want{principal: auth.Principal{
+				UserID:    uuid.NullUUID{UUID: userID, Valid: true},
+				Workspace: workspace,
+				Scopes:    []string{auth.ScopeAdmin},
+			}},
+		},
+		{
+			name:     "wrong password",
+			password: "wrong",
+			fields: fields{
+				setup: func(repo *mocks.MockUserRepo) {
+					repo.EXPECT().
+						GetUserByEmail(gomock.Any(), "a@example.com").
+						Return(stored(domain.RoleUser), nil)
+				},
+			},
+			want: want{err: service.ErrInvalidCredentials},
+		},
+		{
+			name:     "unknown email",
+			password: "password",
+			fields: fields{
+				setup: func(repo *mocks.MockUserRepo) {
+					repo.EXPECT().
+						GetUserByEmail(gomock.Any(), "a@example.com").
+						Return(domain.User{}, userrepo.ErrUserNotFound)
+				},
+			},
+			want: want{err: service.ErrInvalidCredentials},
+		},
+		{
+			name:     "repo error",
+			password: "password",
+			fields: fields{
+				setup: func(repo *mocks.MockUserRepo) {
+					repo.EXPECT().
+						GetUserByEmail(gomock.Any(), "a@example.com").
+						Return(domain.User{}, errors.New("db error"))
+				},
+			},
+			want: want{err: errors.New("db error")},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockRepo := mocks.NewMockUserRepo(ctrl)
+
+			if tt.fields.setup != nil {
+				tt.fields.setup(mockRepo)
+			}
+
+			svc := service.New(mockRepo, secret, time.Hour)
+
+			token, err := svc.Login(context.Background(), dto.Login{Email: "A@example.com", Password: tt.password})
+
+			if tt.want.err != nil {
+				require.ErrorContains(t, err, tt.want.err.Error())
+				return
+			}
+
+			require.NoError(t, err)
+
+			principal, err := svc.ParseToken(token.Token)
+			require.NoError(t, err)
+			require.Equal(t, tt.want.principal, principal)
+		})
+	}
+}
+
+func TestUser_ParseToken(t *testing.T) {
+	sign := func(method jwt.SigningMethod, key interface{}, claims jwt.MapClaims) string {
+		token, err := jwt.NewWithClaims(method, claims).SignedString(key)
+		require.NoError(t, err)
+		return token
+	}
+	sub := uuid.New().String()
+	wid := uuid.New().String()
+	exp := time.Now().Add(time.Hour).Unix()
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{
+			name:  "garbage",
+			token: "a.b.c",
+		},
+		{
+			name:  "wrong key",
+			token: sign(jwt.SigningMethodHS256, []byte("other"), jwt.MapClaims{"sub": sub, "wid": wid, "ws": "acme", "exp": exp}),
+		},
+		{
+			name:  "unexpected method",
+			token: sign(jwt.SigningMethodHS512, []byte(secret), jwt.MapClaims{"sub": sub, "wid": wid, "ws": "acme", "exp": exp}),
+		},
+		{
+			name:  "expired",
+			token: sign(jwt.SigningMethodHS256, []byte(secret), jwt.MapClaims{"sub": sub, "wid": wid, "ws": "acme", "exp": time.Now().Add(-time.Minute).Unix()}),
+		},
+		{
+			name:  "no expiration",
+			token: sign(jwt.SigningMethodHS256, []byte(secret), jwt.MapClaims{"sub": sub, "wid": wid, "ws": "acme"}),
+		},
+		{
+			name:  "no workspace",
+			token: sign(jwt.SigningMethodHS256, []byte(secret), jwt.MapClaims{"sub": sub, "exp": exp}),
+		},
+		{
+			name:  "subject is not a user id",
+			token: sign(jwt.SigningMethodHS256, []byte(secret), jwt.MapClaims{"sub": "alice", "wid": wid, "ws": "acme", "exp": exp}),
+		},
+	}
+
+	svc := service.New(nil, secret, time.Hour)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := svc.ParseToken(tt.token)
+			require.ErrorIs(t, err, service.ErrInvalidToken)
+		})
+	}
+}
@@ -0,0 +1,159 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/ilam072/shortener/internal/auth"
+	"github.com/ilam072/shortener/internal/user/repo"
+	"github.com/ilam072/shortener/internal/user/types/domain"
+	"github.com/ilam072/shortener/internal/user/types/dto"
+	"github.com/ilam072/shortener/pkg/errutils"
+	"golang.org/x/crypto/bcrypt"
+	"strings"
+	"time"
+)
+
+//go:generate mockgen -source=user.go -destination=../mocks/service_mocks.go -package=mocks
+type UserRepo interface {
+	CreateUser(ctx context.Context, user domain.User) (domain.User, error)
+	GetUserByEmail(ctx context.Context, email string) (domain.User, error)
+}
+
+type User struct {
+	repo      UserRepo
+	jwtSecret []byte
+	tokenTTL  time.Duration
+}
+
+func New(repo UserRepo, jwtSecret string, tokenTTL time.Duration) *User {
+	return &User{repo: repo, jwtSecret: []byte(jwtSecret), tokenTTL: tokenTTL}
+}
+
+var (
+	ErrEmailAlreadyExists = errors.New("email already exists")
+	ErrInvalidCredentials = errors.New("invalid email or password")
+	ErrInvalidToken       = errors.New("invalid token")
+	ErrWorkspaceNotFound  = errors.New("workspace not found")
+)
+
+// claims are the JWT claims issued on login. Tokens signed with the same
+// key by another issuer are accepted as long as they carry these claims.
+type claims struct {
+	Role          string `json:"role"`
+	WorkspaceID   string `json:"wid"`
+	WorkspaceSlug string `json:"ws"`
+	jwt.RegisteredClaims
+}
+
+// CreateUser creates a local account in the workspace workspaceID.
+func (u *User) CreateUser(ctx context.Context, workspaceID uuid.UUID, user dto.CreateUser) (dto.User, error) {
+	const op = "service.user.Create"
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(user.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return dto.User{}, errutils.Wrap(op, err)
+	}
+
+	role := user.Role
+	if role == "" {
+		role = domain.RoleUser
+	}
+
+	created, err := u.repo.CreateUser(ctx, domain.User{
+		ID:           uuid.New(),
+		Email:        strings.ToLower(user.Email),
+		PasswordHash: string(hash),
+		Role:         role,
+		WorkspaceID:  workspaceID,
+	})
+	if err != nil {
+		if errors.Is(err, repo.ErrEmailAlreadyExists) {
+			return dto.User{}, errutils.Wrap(op, ErrEmailAlreadyExists)
+		}
+		if errors.Is(err, repo.ErrWorkspaceNotFound) {
+			return dto.User{}, errutils.Wrap(op, ErrWorkspaceNotFound)
+		}
+		return dto.User{}, errutils.Wrap(op, err)
+	}
+
+	return dto.User{
+		ID:          created.ID.String(),
+		Email:       created.Email,
+		Role:        created.Role,
+		WorkspaceID: created.WorkspaceID.String(),
+		CreatedAt:   created.CreatedAt,
+	}, nil
+}
+
+// Login checks the password of a local account and issues a signed JWT.
+func (u *User) Login(ctx context.Context, login dto.Login) (dto.Token, error) {
+	const op = "service.user.Login"
+
+	user, err := u.repo.GetUserByEmail(ctx, strings.ToLower(login.Email))
+	if err != nil {
+		if errors.Is(err, repo.ErrUserNotFound) {
+			return dto.Token{}, errutils.Wrap(op, ErrInvalidCredentials)
+		}
+		return dto.Token{}, errutils.Wrap(op, err)
+	}
+
+	if err = bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(login.Password)); err != nil {
+		return dto.Token{}, errutils.Wrap(op, ErrInvalidCredentials)
+	}
+
+	expiresAt := time.Now().Add(u.tokenTTL)
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims{
+		Role:          user.Role,
+		WorkspaceID:   user.WorkspaceID.String(),
+		WorkspaceSlug: user.WorkspaceSlug,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   user.ID.String(),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+	}).SignedString(u.jwtSecret)
+	if err != nil {
+		return dto.Token{}, errutils.Wrap(op, err)
+	}
+
+	return dto.Token{Token: token, ExpiresAt: expiresAt}, nil
+}
+
+// ParseToken verifies a bearer JWT and returns the user it was issued for,
+// acting in the workspace named by the token.
+// Admins get the admin scope, other users may create links and read
+// analytics of their own links.
+func (u *User) ParseToken(token string) (auth.Principal, error) {
+	if len(u.jwtSecret) == 0 {
+		return auth.Principal{}, ErrInvalidToken
+	}
+
+	var c claims
+	if _, err := jwt.ParseWithClaims(token, &c, func(*jwt.Token) (interface{}, error) {
+		return u.jwtSecret, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Name}), jwt.WithExpirationRequired()); err != nil {
+		return auth.Principal{}, ErrInvalidToken
+	}
+
+	userID, err := uuid.Parse(c.Subject)
+	if err != nil {
+		return auth.Principal{}, ErrInvalidToken
+	}
+	workspaceID, err := uuid.Parse(c.WorkspaceID)
+	if err != nil || c.WorkspaceSlug == "" {
+		return auth.Principal{}, ErrInvalidToken
+	}
+
+	scopes := []string{auth.ScopeCreate, auth.ScopeReadAnalytics}
+	if c.Role == domain.RoleAdmin {
+		scopes = []string{auth.ScopeAdmin}
+	}
+
+	return auth.Principal{
+		UserID:    uuid.NullUUID{UUID: userID, Valid: true},
+		Workspace: auth.Workspace{ID: workspaceID, Slug: c.WorkspaceSlug},
+		Scopes:    scopes,
+	}, nil
+}
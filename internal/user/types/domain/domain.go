@@ -0,0 +1,22 @@
+package domain
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
+
+type User struct {
+	ID           uuid.UUID
+	Email        string
+	PasswordHash string
+	Role         string
+	WorkspaceID  uuid.UUID
+	// WorkspaceSlug is only read back by GetUserByEmail, to issue tokens.
+	WorkspaceSlug string
+	CreatedAt     time.Time
+}
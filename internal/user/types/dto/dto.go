@@ -0,0 +1,30 @@
+package dto
+
+import "time"
+
+type CreateUser struct {
+	Email    string `json:"email" validate:"required,email"`
+	Password string `json:"password" validate:"required,min=8,max=72"`
+	Role     string `json:"role,omitempty" validate:"omitempty,oneof=user admin"`
+	// WorkspaceID places the user in another workspace than the caller's.
+	// Only operators may set it.
+	WorkspaceID string `json:"workspace_id,omitempty" validate:"omitempty,uuid"`
+}
+
+type Login struct {
+	Email    string `json:"email" validate:"required,email"`
+	Password string `json:"password" validate:"required"`
+}
+
+type User struct {
+	ID          string    `json:"id"`
+	Email       string    `json:"email"`
+	Role        string    `json:"role"`
+	WorkspaceID string    `json:"workspace_id"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type Token struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
@@ -0,0 +1,205 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: webhook.go
+//
+// Generated by this command:
+//
+//	mockgen -source=webhook.go -destination=../mocks/service_mocks.go -package=mocks
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	uuid "github.com/google/uuid"
+	domain "github.com/ilam072/shortener/internal/webhook/types/domain"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockWebhookRepo is a mock of WebhookRepo interface.
+type MockWebhookRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockWebhookRepoMockRecorder
+	isgomock struct{}
+}
+
+// MockWebhookRepoMockRecorder is the mock recorder for MockWebhookRepo.
+type MockWebhookRepoMockRecorder struct {
+	mock *MockWebhookRepo
+}
+
+// NewMockWebhookRepo creates a new mock instance.
+func NewMockWebhookRepo(ctrl *gomock.Controller) *MockWebhookRepo {
+	mock := &MockWebhookRepo{ctrl: ctrl}
+	mock.recorder = &MockWebhookRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockWebhookRepo) EXPECT() *MockWebhookRepoMockRecorder {
+	return m.recorder
+}
+
+// ClaimDeadLetters mocks base method.
+func (m *MockWebhookRepo) ClaimDeadLetters(ctx context.Context, limit int, lease time.Duration) ([]domain.DeadLetter, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimDeadLetters", ctx, limit, lease)
+	ret0, _ := ret[0].([]domain.DeadLetter)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimDeadLetters indicates an expected call of ClaimDeadLetters.
+func (mr *MockWebhookRepoMockRecorder) ClaimDeadLetters(ctx, limit, lease any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimDeadLetters", reflect.TypeOf((*MockWebhookRepo)(nil).ClaimDeadLetters), ctx, limit, lease)
+}
+
+// ClaimExpiredLinks mocks base method.
+func (m *MockWebhookRepo) ClaimExpiredLinks(ctx context.Context, limit int) ([]domain.ExpiredLink, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimExpiredLinks", ctx, limit)
+	ret0, _ := ret[0].([]domain.ExpiredLink)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimExpiredLinks indicates an expected call of ClaimExpiredLinks.
+func (mr *MockWebhookRepoMockRecorder) ClaimExpiredLinks(ctx, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimExpiredLinks", reflect.TypeOf((*MockWebhookRepo)(nil).ClaimExpiredLinks), ctx, limit)
+}
+
+// CreateDeadLetter mocks base method.
+func (m *MockWebhookRepo) CreateDeadLetter(ctx context.Context, letter domain.DeadLetter) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateDeadLetter", ctx, letter)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateDeadLetter indicates an expected call of CreateDeadLetter.
+func (mr *MockWebhookRepoMockRecorder) CreateDeadLetter(ctx, letter any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateDeadLetter", reflect.TypeOf((*MockWebhookRepo)(nil).CreateDeadLetter), ctx, letter)
+}
+
+// CreateWebhook mocks base method.
+func (m *MockWebhookRepo) CreateWebhook(ctx context.Context, webhook domain.Webhook) (domain.Webhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWebhook", ctx, webhook)
+	ret0, _ := ret[0].(domain.Webhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateWebhook indicates an expected call of CreateWebhook.
+func (mr *MockWebhookRepoMockRecorder) CreateWebhook(ctx, webhook any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWebhook", reflect.TypeOf((*MockWebhookRepo)(nil).CreateWebhook), ctx, webhook)
+}
+
+// DeleteDeadLetter mocks base method.
+func (m *MockWebhookRepo) DeleteDeadLetter(ctx context.Context, id uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteDeadLetter", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteDeadLetter indicates an expected call of DeleteDeadLetter.
+func (mr *MockWebhookRepoMockRecorder) DeleteDeadLetter(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteDeadLetter", reflect.TypeOf((*MockWebhookRepo)(nil).DeleteDeadLetter), ctx, id)
+}
+
+// DeleteWebhook mocks base method.
+func (m *MockWebhookRepo) DeleteWebhook(ctx context.Context, workspaceID, id uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteWebhook", ctx, workspaceID, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteWebhook indicates an expected call of DeleteWebhook.
+func (mr *MockWebhookRepoMockRecorder) DeleteWebhook(ctx, workspaceID, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteWebhook", reflect.TypeOf((*MockWebhookRepo)(nil).DeleteWebhook), ctx, workspaceID, id)
+}
+
+// FailDeadLetter mocks base method.
+func (m *MockWebhookRepo) FailDeadLetter(ctx context.Context, id uuid.UUID, lastError string, nextAttemptAt *time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FailDeadLetter", ctx, id, lastError, nextAttemptAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// FailDeadLetter indicates an expected call of FailDeadLetter.
+func (mr *MockWebhookRepoMockRecorder) FailDeadLetter(ctx, id, lastError, nextAttemptAt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FailDeadLetter", reflect.TypeOf((*MockWebhookRepo)(nil).FailDeadLetter), ctx, id, lastError, nextAttemptAt)
+}
+
+// GetDeadLetter mocks base method.
+func (m *MockWebhookRepo) GetDeadLetter(ctx context.Context, workspaceID, webhookID, id uuid.UUID) (domain.DeadLetter, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDeadLetter", ctx, workspaceID, webhookID, id)
+	ret0, _ := ret[0].(domain.DeadLetter)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDeadLetter indicates an expected call of GetDeadLetter.
+func (mr *MockWebhookRepoMockRecorder) GetDeadLetter(ctx, workspaceID, webhookID, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDeadLetter", reflect.TypeOf((*MockWebhookRepo)(nil).GetDeadLetter), ctx, workspaceID, webhookID, id)
+}
+
+// GetWebhook mocks base method.
+func (m *MockWebhookRepo) GetWebhook(ctx context.Context, workspaceID, id uuid.UUID) (domain.Webhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWebhook", ctx, workspaceID, id)
+	ret0, _ := ret[0].(domain.Webhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWebhook indicates an expected call of GetWebhook.
+func (mr *MockWebhookRepoMockRecorder) GetWebhook(ctx, workspaceID, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWebhook", reflect.TypeOf((*MockWebhookRepo)(nil).GetWebhook), ctx, workspaceID, id)
+}
+
+// ListDeadLetters mocks base method.
+func (m *MockWebhookRepo) ListDeadLetters(ctx context.Context, workspaceID, webhookID uuid.UUID) ([]domain.DeadLetter, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListDeadLetters", ctx, workspaceID, webhookID)
+	ret0, _ := ret[0].([]domain.DeadLetter)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListDeadLetters indicates an expected call of ListDeadLetters.
+func (mr *MockWebhookRepoMockRecorder) ListDeadLetters(ctx, workspaceID, webhookID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListDeadLetters", reflect.TypeOf((*MockWebhookRepo)(nil).ListDeadLetters), ctx, workspaceID, webhookID)
+}
+
+// ListWebhooks mocks base method.
+func (m *MockWebhookRepo) ListWebhooks(ctx context.Context, workspaceID uuid.UUID) ([]domain.Webhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListWebhooks", ctx, workspaceID)
+	ret0, _ := ret[0].([]domain.Webhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListWebhooks indicates an expected call of ListWebhooks.
+func (mr *MockWebhookRepoMockRecorder) ListWebhooks(ctx, workspaceID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListWebhooks", reflect.TypeOf((*MockWebhookRepo)(nil).ListWebhooks), ctx, workspaceID)
+}
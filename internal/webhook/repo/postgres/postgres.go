@@ -0,0 +1,385 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/ilam072/shortener/internal/webhook/repo"
+	"github.com/ilam072/shortener/internal/webhook/types/domain"
+	"github.com/ilam072/shortener/pkg/errutils"
+	"github.com/lib/pq"
+	"github.com/wb-go/wbf/dbpg"
+	"time"
+)
+
+type WebhookRepo struct {
+	db *dbpg.DB
+}
+
+func New(db *dbpg.DB) *WebhookRepo {
+	return &WebhookRepo{db: db}
+}
+
+func (r *WebhookRepo) CreateWebhook(ctx context.Context, webhook domain.Webhook) (domain.Webhook, error) {
+	const op = "repo.webhook.Create"
+
+	query := `
+		INSERT INTO webhooks(id, workspace_id, url, secret, events)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING created_at;
+	`
+
+	if err := r.db.QueryRowContext(
+		ctx,
+		query,
+		webhook.ID,
+		webhook.WorkspaceID,
+		webhook.URL,
+		webhook.Secret,
+		pq.Array(webhook.Events),
+	).Scan(&webhook.CreatedAt); err != nil {
+		return domain.Webhook{}, errutils.Wrap(op, err)
+	}
+
+	return webhook, nil
+}
+
+func (r *WebhookRepo) GetWebhook(ctx context.Context, workspaceID uuid.UUID, id uuid.UUID) (domain.Webhook, error) {
+	const op = "repo.webhook.Get"
+
+	query := `
+		SELECT id, workspace_id, url, secret, events, created_at
+		FROM webhooks
+		WHERE id = $1 AND workspace_id = $2;
+	`
+
+	var webhook domain.Webhook
+	if err := r.db.QueryRowContext(ctx, query, id, workspaceID).Scan(
+		&webhook.ID,
+		&webhook.WorkspaceID,
+		&webhook.URL,
+		&webhook.Secret,
+		pq.Array(&webhook.Events),
+		&webhook.CreatedAt,
+	); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.Webhook{}, errutils.Wrap(op, repo.ErrWebhookNotFound)
+		}
+		return domain.Webhook{}, errutils.Wrap(op, err)
+	}
+
+	return webhook, nil
+}
+
+func (r *WebhookRepo) ListWebhooks(ctx context.Context, workspaceID uuid.UUID) ([]domain.Webhook, error) {
+	const op = "repo.webhook.List"
+
+	query := `
+		SELECT id, workspace_id, url, secret, events, created_at
+		FROM webhooks
+		WHERE workspace_id = $1
+		ORDER BY created_at;
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, workspaceID)
+	if err != nil {
+		return nil, errutils.Wrap(op, err)
+	}
+	defer rows.Close()
+
+	var webhooks []domain.Webhook
+	for rows.Next() {
+		var webhook domain.Webhook
+		if err := rows.Scan(
+			&webhook.ID,
+			&webhook.WorkspaceID,
+			&webhook.URL,
+			&webhook.Secret,
+			pq.Array(&webhook.Events),
+			&webhook.CreatedAt,
+		); err != nil {
+			return nil, errutils.Wrap(op, err)
+		}
+		webhooks = append(webhooks, webhook)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errutils.Wrap(op, err)
+	}
+
+	return webhooks, nil
+}
+
+// DeleteWebhook removes a webhook of the workspace along with its dead
+// letters.
+func (r *WebhookRepo) DeleteWebhook(ctx context.Context, workspaceID uuid.UUID, id uuid.UUID) error {
+	const op = "repo.webhook.Delete"
+
+	query := `
+		DELETE FROM webhooks
+		WHERE id = $1 AND workspace_id = $2;
+	`
+
+	res, err := r.db.ExecContext(ctx, query, id, workspaceID)
+	if err != nil {
+		return errutils.Wrap(op, err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return errutils.Wrap(op, err)
+	}
+	if affected == 0 {
+		return errutils.Wrap(op, repo.ErrWebhookNotFound)
+	}
+
+	return nil
+}
+
+func (r *WebhookRepo) CreateDeadLetter(ctx context.Context, letter domain.DeadLetter) error {
+	const op = "repo.webhook.CreateDeadLetter"
+
+	query := `
+		INSERT INTO webhook_dead_letters(id, webhook_id, workspace_id, event_id, event_type, payload, attempts, last_error, next_attempt_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9);
+	`
+
+	if _, err := r.db.ExecContext(
+		ctx,
+		query,
+		letter.ID,
+		letter.WebhookID,
+		letter.WorkspaceID,
+		letter.EventID,
+		letter.EventType,
+		string(letter.Payload),
+		letter.Attempts,
+		letter.LastError,
+		letter.NextAttemptAt,
+	); err != nil {
+		return errutils.Wrap(op, err)
+	}
+
+	return nil
+}
+
+// deadLettersLimit bounds the number of dead letters listed at once.
+const deadLettersLimit = 100
+
+// ListDeadLetters returns the latest dead letters of a webhook of the
+// workspace, most recent first.
+func (r *WebhookRepo) ListDeadLetters(ctx context.Context, workspaceID uuid.UUID, webhookID uuid.UUID) ([]domain.DeadLetter, error) {
+	const op = "repo.webhook.ListDeadLetters"
+
+	query := `
+		SELECT id, webhook_id, workspace_id, event_id, event_type, payload, attempts, last_error, failed_at, next_attempt_at
+		FROM webhook_dead_letters
+		WHERE webhook_id = $1 AND workspace_id = $2
+		ORDER BY failed_at DESC
+		LIMIT $3;
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, webhookID, workspaceID, deadLettersLimit)
+	if err != nil {
+		return nil, errutils.Wrap(op, err)
+	}
+	defer rows.Close()
+
+	var letters []domain.DeadLetter
+	for rows.Next() {
+		var letter domain.DeadLetter
+		if err := rows.Scan(
+			&letter.ID,
+			&letter.WebhookID,
+			&letter.WorkspaceID,
+			&letter.EventID,
+			&letter.EventType,
+			&letter.Payload,
+			&letter.Attempts,
+			&letter.LastError,
+			&letter.FailedAt,
+			&letter.NextAttemptAt,
+		); err != nil {
+			return nil, errutils.Wrap(op, err)
+		}
+		letters = append(letters, letter)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errutils.Wrap(op, err)
+	}
+
+	return letters, nil
+}
+
+func (r *WebhookRepo) GetDeadLetter(ctx context.Context, workspaceID uuid.UUID, webhookID uuid.UUID, id uuid.UUID) (domain.DeadLetter, error) {
+	const op = "repo.webhook.GetDeadLetter"
+
+	query := `
+		SELECT id, webhook_id, workspace_id, event_id, event_type, payload, attempts, last_error, failed_at, next_attempt_at
+		FROM webhook_dead_letters
+		WHERE id = $1 AND webhook_id = $2 AND workspace_id = $3;
+	`
+
+	letter, err := scanDeadLetter(r.db.QueryRowContext(ctx, query, id, webhookID, workspaceID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.DeadLetter{}, errutils.Wrap(op, repo.ErrDeadLetterNotFound)
+		}
+		return domain.DeadLetter{}, errutils.Wrap(op, err)
+	}
+
+	return letter, nil
+}
+
+// FailDeadLetter records that a redelivery of a dead letter failed too,
+// and when it is due again, never when nextAttemptAt is nil.
+func (r *WebhookRepo) FailDeadLetter(ctx context.Context, id uuid.UUID, lastError string, nextAttemptAt *time.Time) error {
+	const op = "repo.webhook.FailDeadLetter"
+
+	query := `
+		UPDATE webhook_dead_letters
+		SET attempts = attempts + 1, last_error = $2, failed_at = now(), next_attempt_at = $3
+		WHERE id = $1;
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, id, lastError, nextAttemptAt); err != nil {
+		return errutils.Wrap(op, err)
+	}
+
+	return nil
+}
+
+func (r *WebhookRepo) DeleteDeadLetter(ctx context.Context, id uuid.UUID) error {
+	const op = "repo.webhook.DeleteDeadLetter"
+
+	query := `
+		DELETE FROM webhook_dead_letters
+		WHERE id = $1;
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, id); err != nil {
+		return errutils.Wrap(op, err)
+	}
+
+	return nil
+}
+
+// ClaimDeadLetters returns up to limit dead letters that are due again,
+// oldest first, and puts their next attempt off by lease, so that other
+// replicas do not retry them meanwhile.
+func (r *WebhookRepo) ClaimDeadLetters(ctx context.Context, limit int, lease time.Duration) ([]domain.DeadLetter, error) {
+	const op = "repo.webhook.ClaimDeadLetters"
+
+	query := `
+		UPDATE webhook_dead_letters
+		SET next_attempt_at = now() + make_interval(secs => $2)
+		WHERE id IN (
+			SELECT id FROM webhook_dead_letters
+			WHERE next_attempt_at <= now()
+			ORDER BY next_attempt_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, webhook_id, workspace_id, event_id, event_type, payload, attempts, last_error, failed_at, next_attempt_at;
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, limit, lease.Seconds())
+	if err != nil {
+		return nil, errutils.Wrap(op, err)
+	}
+	defer rows.Close()
+
+	var letters []domain.DeadLetter
+	for rows.Next() {
+		var letter domain.DeadLetter
+		if err := rows.Scan(
+			&letter.ID,
+			&letter.WebhookID,
+			&letter.WorkspaceID,
+			&letter.EventID,
+			&letter.EventType,
+			&letter.Payload,
+			&letter.Attempts,
+			&letter.LastError,
+			&letter.FailedAt,
+			&letter.NextAttemptAt,
+		); err != nil {
+			return nil, errutils.Wrap(op, err)
+		}
+		letters = append(letters, letter)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errutils.Wrap(op, err)
+	}
+
+	return letters, nil
+}
+
+// ClaimExpiredLinks marks up to limit links that expired, by time or by
+// click limit, and were not announced yet, and returns them. Each link is
+// claimed once, by whichever replica gets to it first. Deleted links are
+// not announced.
+func (r *WebhookRepo) ClaimExpiredLinks(ctx context.Context, limit int) ([]domain.ExpiredLink, error) {
+	const op = "repo.webhook.ClaimExpiredLinks"
+
+	query := `
+		UPDATE links l
+		SET expired_at = now()
+		WHERE l.id IN (
+			SELECT id FROM links
+			WHERE expired_at IS NULL AND (expires_at IS NOT NULL OR max_clicks IS NOT NULL)
+				AND (expires_at <= now() OR click_count >= max_clicks)
+				AND status <> 'deleted'
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING l.id, l.workspace_id, l.alias,
+			COALESCE((SELECT host FROM domains WHERE id = l.domain_id), ''),
+			l.url, l.expires_at, l.max_clicks;
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, limit)
+	if err != nil {
+		return nil, errutils.Wrap(op, err)
+	}
+	defer rows.Close()
+
+	var links []domain.ExpiredLink
+	for rows.Next() {
+		var link domain.ExpiredLink
+		if err := rows.Scan(
+			&link.ID,
+			&link.WorkspaceID,
+			&link.Alias,
+			&link.Domain,
+			&link.URL,
+			&link.ExpiresAt,
+			&link.MaxClicks,
+		); err != nil {
+			return nil, errutils.Wrap(op, err)
+		}
+		links = append(links, link)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errutils.Wrap(op, err)
+	}
+
+	return links, nil
+}
+
+func scanDeadLetter(row *sql.Row) (domain.DeadLetter, error) {
+	var letter domain.DeadLetter
+	err := row.Scan(
+		&letter.ID,
+		&letter.WebhookID,
+		&letter.WorkspaceID,
+		&letter.EventID,
+		&letter.EventType,
+		&letter.Payload,
+		&letter.Attempts,
+		&letter.LastError,
+		&letter.FailedAt,
+		&letter.NextAttemptAt,
+	)
+	return letter, err
+}
@@ -0,0 +1,8 @@
+package repo
+
+import "errors"
+
+var (
+	ErrWebhookNotFound    = errors.New("webhook not found")
+	ErrDeadLetterNotFound = errors.New("dead letter not found")
+)
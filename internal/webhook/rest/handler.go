@@ -0,0 +1,202 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/ilam072/shortener/internal/auth"
+	"github.com/ilam072/shortener/internal/response"
+	"github.com/ilam072/shortener/internal/webhook/service"
+	"github.com/ilam072/shortener/internal/webhook/types/dto"
+	"github.com/wb-go/wbf/ginext"
+	"github.com/wb-go/wbf/zlog"
+	"net/http"
+)
+
+//go:generate mockgen -source=handler.go -destination=../mocks/rest_mocks.go -package=mocks
+type Webhook interface {
+	CreateWebhook(ctx context.Context, workspaceID uuid.UUID, webhook dto.CreateWebhook) (dto.CreatedWebhook, error)
+	ListWebhooks(ctx context.Context, workspaceID uuid.UUID) ([]dto.Webhook, error)
+	DeleteWebhook(ctx context.Context, workspaceID uuid.UUID, id string) error
+	ListDeadLetters(ctx context.Context, workspaceID uuid.UUID, id string) ([]dto.DeadLetter, error)
+	Redeliver(ctx context.Context, workspaceID uuid.UUID, id string, letterID string) error
+}
+
+type Validator interface {
+	Validate(i interface{}) error
+}
+
+type WebhookHandler struct {
+	webhook   Webhook
+	validator Validator
+}
+
+func NewWebhookHandler(webhook Webhook, validator Validator) *WebhookHandler {
+	return &WebhookHandler{webhook: webhook, validator: validator}
+}
+
+// CreateWebhook godoc
+// @Summary Добавить вебхук
+// @Description Подписывает URL на события ссылок рабочего пространства: link.created, link.clicked, link.expired, link.deleted. События отправляются POST-запросом с JSON в теле. Заголовок X-Webhook-Signature содержит sha256= и HMAC-SHA256 от X-Webhook-Timestamp, точки и тела на секрете вебхука. Секрет возвращается только при создании. Неудачные доставки повторяются, после последней попытки они сохраняются для повторной отправки
+// @Tags Webhooks
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Param input body dto.CreateWebhook true "URL и события вебхука"
+// @Success 201 {object} response.Response{payload=dto.CreatedWebhook} "Добавленный вебхук с секретом"
+// @Failure 400 {object} response.Response "invalid request body или validation error"
+// @Failure 401 {object} response.Response "invalid credentials"
+// @Failure 403 {object} response.Response "insufficient scope"
+// @Failure 500 {object} response.Response "internal server error"
+// @Router /webhooks [post]
+func (h *WebhookHandler) CreateWebhook(c *ginext.Context) {
+	var webhook dto.CreateWebhook
+	if err := json.NewDecoder(c.Request.Body).Decode(&webhook); err != nil {
+		response.Error("invalid request body").WriteJSON(c, http.StatusBadRequest)
+		return
+	}
+	if err := h.validator.Validate(webhook); err != nil {
+		response.Error(fmt.Sprintf("validation error: %s", err.Error())).WriteJSON(c, http.StatusBadRequest)
+		return
+	}
+
+	workspace := auth.FromContext(c.Request.Context()).Workspace
+	created, err := h.webhook.CreateWebhook(c.Request.Context(), workspace.ID, webhook)
+	if err != nil {
+		zlog.Logger.Error().Err(err).Str("url", webhook.URL).Msg("failed to create webhook")
+		response.Error("internal server error, try again later").WriteJSON(c, http.StatusInternalServerError)
+		return
+	}
+
+	response.Success(created).WriteJSON(c, http.StatusCreated)
+}
+
+// ListWebhooks godoc
+// @Summary Список вебхуков
+// @Description Возвращает вебхуки рабочего пространства без секретов
+// @Tags Webhooks
+// @Produce json
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Success 200 {object} response.Response{payload=[]dto.Webhook} "Вебхуки"
+// @Failure 401 {object} response.Response "invalid credentials"
+// @Failure 403 {object} response.Response "insufficient scope"
+// @Failure 500 {object} response.Response "internal server error"
+// @Router /webhooks [get]
+func (h *WebhookHandler) ListWebhooks(c *ginext.Context) {
+	workspace := auth.FromContext(c.Request.Context()).Workspace
+	webhooks, err := h.webhook.ListWebhooks(c.Request.Context(), workspace.ID)
+	if err != nil {
+		zlog.Logger.Error().Err(err).Msg("failed to list webhooks")
+		response.Error("internal server error, try again later").WriteJSON(c, http.StatusInternalServerError)
+		return
+	}
+
+	response.Success(webhooks).WriteJSON(c, http.StatusOK)
+}
+
+// DeleteWebhook godoc
+// @Summary Удалить вебхук
+// @Description Удаляет вебхук по id вместе с его неудачными доставками
+// @Tags Webhooks
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Param id path string true "ID вебхука"
+// @Success 204 "Webhook deleted"
+// @Failure 401 {object} response.Response "invalid credentials"
+// @Failure 403 {object} response.Response "insufficient scope"
+// @Failure 404 {object} response.Response "webhook not found"
+// @Failure 500 {object} response.Response "internal server error"
+// @Router /webhooks/{id} [delete]
+func (h *WebhookHandler) DeleteWebhook(c *ginext.Context) {
+	id := c.Param("id")
+
+	workspace := auth.FromContext(c.Request.Context()).Workspace
+	if err := h.webhook.DeleteWebhook(c.Request.Context(), workspace.ID, id); err != nil {
+		if errors.Is(err, service.ErrWebhookNotFound) {
+			response.Error("webhook not found").WriteJSON(c, http.StatusNotFound)
+			return
+		}
+		zlog.Logger.Error().Err(err).Str("id", id).Msg("failed to delete webhook")
+		response.Error("internal server error, try again later").WriteJSON(c, http.StatusInternalServerError)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ListDeadLetters godoc
+// @Summary Неудачные доставки вебхука
+// @Description Возвращает последние 100 неудачных доставок вебхука, начиная с самых новых. Доставки с next_attempt_at будут повторены автоматически, остальные исчерпали попытки и ждут ручной повторной доставки
+// @Tags Webhooks
+// @Produce json
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Param id path string true "ID вебхука"
+// @Success 200 {object} response.Response{payload=[]dto.DeadLetter} "Неудачные доставки"
+// @Failure 401 {object} response.Response "invalid credentials"
+// @Failure 403 {object} response.Response "insufficient scope"
+// @Failure 404 {object} response.Response "webhook not found"
+// @Failure 500 {object} response.Response "internal server error"
+// @Router /webhooks/{id}/dead-letters [get]
+func (h *WebhookHandler) ListDeadLetters(c *ginext.Context) {
+	id := c.Param("id")
+
+	workspace := auth.FromContext(c.Request.Context()).Workspace
+	letters, err := h.webhook.ListDeadLetters(c.Request.Context(), workspace.ID, id)
+	if err != nil {
+		if errors.Is(err, service.ErrWebhookNotFound) {
+			response.Error("webhook not found").WriteJSON(c, http.StatusNotFound)
+			return
+		}
+		zlog.Logger.Error().Err(err).Str("id", id).Msg("failed to list dead letters")
+		response.Error("internal server error, try again later").WriteJSON(c, http.StatusInternalServerError)
+		return
+	}
+
+	response.Success(letters).WriteJSON(c, http.StatusOK)
+}
+
+// Redeliver godoc
+// @Summary Повторить доставку
+// @Description Отправляет неудачную доставку вебхуку ещё раз, один раз и с тем же id события. Доставленная запись удаляется, при новой ошибке она сохраняется с этой ошибкой, которую видно в списке неудачных доставок
+// @Tags Webhooks
+// @Produce json
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Param id path string true "ID вебхука"
+// @Param letter_id path string true "ID неудачной доставки"
+// @Success 204 "Delivered"
+// @Failure 401 {object} response.Response "invalid credentials"
+// @Failure 403 {object} response.Response "insufficient scope"
+// @Failure 404 {object} response.Response "webhook not found или dead letter not found"
+// @Failure 502 {object} response.Response "webhook delivery failed"
+// @Failure 500 {object} response.Response "internal server error"
+// @Router /webhooks/{id}/dead-letters/{letter_id}/redeliver [post]
+func (h *WebhookHandler) Redeliver(c *ginext.Context) {
+	id := c.Param("id")
+	letterID := c.Param("letter_id")
+
+	workspace := auth.FromContext(c.Request.Context()).Workspace
+	if err := h.webhook.Redeliver(c.Request.Context(), workspace.ID, id, letterID); err != nil {
+		switch {
+		case errors.Is(err, service.ErrWebhookNotFound):
+			response.Error("webhook not found").WriteJSON(c, http.StatusNotFound)
+			return
+		case errors.Is(err, service.ErrDeadLetterNotFound):
+			response.Error("dead letter not found").WriteJSON(c, http.StatusNotFound)
+			return
+		case errors.Is(err, service.ErrDeliveryFailed):
+			response.Error(service.ErrDeliveryFailed.Error()).WriteJSON(c, http.StatusBadGateway)
+			return
+		}
+		zlog.Logger.Error().Err(err).Str("id", id).Str("letter_id", letterID).Msg("failed to redeliver webhook")
+		response.Error("internal server error, try again later").WriteJSON(c, http.StatusInternalServerError)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,188 @@
+package rest_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/wb-go/wbf/ginext"
+	"go.uber.org/mock/gomock"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ilam072/shortener/internal/webhook/mocks"
+	"github.com/ilam072/shortener/internal/webhook/rest"
+	"github.com/ilam072/shortener/internal/webhook/service"
+	"github.com/ilam072/shortener/internal/webhook/types/dto"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+func newTestContext(method, path string, body []byte) (*ginext.Context, *httptest.ResponseRecorder) {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+
+	req := httptest.NewRequest(method, path, bytes.NewBuffer(body))
+	c.Request = req
+
+	return c, w
+}
+
+func TestWebhookHandler_CreateWebhook(t *testing.T) {
+	type fields struct {
+		setup func(webhook *mocks.MockWebhook, validator *mocks.MockValidator)
+	}
+	type want struct {
+		status int
+	}
+
+	tests := []struct {
+		name   string
+		body   interface{}
+		fields fields
+		want   want
+	}{
+		{
+			name: "invalid json",
+			body: "invalid",
+			want: want{status: http.StatusBadRequest},
+		},
+		{
+			name: "validation error",
+			body: dto.CreateWebhook{URL: "https://hooks.acme.io", Events: []string{"link.renamed"}},
+			fields: fields{
+				setup: func(webhook *mocks.MockWebhook, validator *mocks.MockValidator) {
+					validator.EXPECT().
+						Validate(gomock.Any()).
+						Return(errors.New("validation failed"))
+				},
+			},
+			want: want{status: http.StatusBadRequest},
+		},
+		{
+			name: "success",
+			body: dto.CreateWebhook{URL: "https://hooks.acme.io", Events: []string{"link.clicked"}},
+			fields: fields{
+				setup: func(webhook *mocks.MockWebhook, validator *mocks.MockValidator) {
+					validator.EXPECT().
+						Validate(gomock.Any()).
+						Return(nil)
+					webhook.EXPECT().
+						CreateWebhook(gomock.Any(), gomock.Any(), dto.CreateWebhook{URL: "https://hooks.acme.io", Events: []string{"link.clicked"}}).
+						Return(dto.CreatedWebhook{Secret: "whsec_test"}, nil)
+				},
+			},
+			want: want{status: http.StatusCreated},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockWebhook := mocks.NewMockWebhook(ctrl)
+			mockValidator := mocks.NewMockValidator(ctrl)
+
+			if tt.fields.setup != nil {
+				tt.fields.setup(mockWebhook, mockValidator)
+			}
+
+			handler := rest.NewWebhookHandler(mockWebhook, mockValidator)
+
+			var bodyBytes []byte
+			switch v := tt.body.(type) {
+			case string:
+				bodyBytes = []byte(v)
+			default:
+				bodyBytes, _ = json.Marshal(v)
+			}
+
+			c, w := newTestContext(http.MethodPost, "/webhooks", bodyBytes)
+
+			handler.CreateWebhook(c)
+
+			require.Equal(t, tt.want.status, w.Code)
+		})
+	}
+}
+
+func TestWebhookHandler_DeleteWebhook(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		status int
+	}{
+		{name: "not found", err: service.ErrWebhookNotFound, status: http.StatusNotFound},
+		{name: "success", status: http.StatusNoContent},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockWebhook := mocks.NewMockWebhook(ctrl)
+			mockValidator := mocks.NewMockValidator(ctrl)
+
+			mockWebhook.EXPECT().
+				DeleteWebhook(gomock.Any(), gomock.Any(), "webhook-id").
+				Return(tt.err)
+
+			handler := rest.NewWebhookHandler(mockWebhook, mockValidator)
+
+			c, w := newTestContext(http.MethodDelete, "/webhooks/webhook-id", nil)
+			c.Params = gin.Params{{Key: "id", Value: "webhook-id"}}
+
+			handler.DeleteWebhook(c)
+			c.Writer.WriteHeaderNow()
+
+			require.Equal(t, tt.status, w.Code)
+		})
+	}
+}
+
+func TestWebhookHandler_Redeliver(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		status int
+	}{
+		{name: "webhook not found", err: service.ErrWebhookNotFound, status: http.StatusNotFound},
+		{name: "dead letter not found", err: service.ErrDeadLetterNotFound, status: http.StatusNotFound},
+		{name: "delivery failed", err: fmt.Errorf("%w: status 503", service.ErrDeliveryFailed), status: http.StatusBadGateway},
+		{name: "internal error", err: errors.New("db is down"), status: http.StatusInternalServerError},
+		{name: "success", status: http.StatusNoContent},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockWebhook := mocks.NewMockWebhook(ctrl)
+			mockValidator := mocks.NewMockValidator(ctrl)
+
+			mockWebhook.EXPECT().
+				Redeliver(gomock.Any(), gomock.Any(), "webhook-id", "letter-id").
+				Return(tt.err)
+
+			handler := rest.NewWebhookHandler(mockWebhook, mockValidator)
+
+			c, w := newTestContext(http.MethodPost, "/webhooks/webhook-id/dead-letters/letter-id/redeliver", nil)
+			c.Params = gin.Params{{Key: "id", Value: "webhook-id"}, {Key: "letter_id", Value: "letter-id"}}
+
+			handler.Redeliver(c)
+			c.Writer.WriteHeaderNow()
+
+			require.Equal(t, tt.status, w.Code)
+		})
+	}
+}
@@ -0,0 +1,400 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/ilam072/shortener/internal/webhook/types/domain"
+	"github.com/ilam072/shortener/internal/webhook/types/dto"
+	"github.com/ilam072/shortener/pkg/errutils"
+	"github.com/wb-go/wbf/retry"
+	"github.com/wb-go/wbf/zlog"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// subscribersTTL is how long the webhooks of a workspace are cached, and
+// so how long a new or deleted webhook takes to be picked up.
+const subscribersTTL = 10 * time.Second
+
+const (
+	// retryBatch is the number of failed deliveries claimed at once.
+	retryBatch = 20
+	// retryLease is how long a claimed delivery is left to its replica
+	// before others may retry it.
+	retryLease = 5 * time.Minute
+	// storeTimeout bounds the queries keeping failed deliveries, which are
+	// not cancelled with the deliveries themselves.
+	storeTimeout = 5 * time.Second
+)
+
+var (
+	errQueueFull = errors.New("webhook queue is full")
+	errShutdown  = errors.New("dispatcher is shutting down")
+)
+
+type DispatcherConfig struct {
+	// Size is the number of events buffered in memory.
+	Size int
+	// Workers is the number of goroutines delivering events.
+	Workers int
+	// RetryInterval is how often failed deliveries that are due again are
+	// looked for.
+	RetryInterval time.Duration
+}
+
+// Dispatcher delivers events to the webhooks subscribed to them, off the
+// path of the request the event happened in. A delivery is attempted once
+// and kept as a dead letter when it fails, so that a dead endpoint does
+// not hold up the workers. Dead letters are retried every RetryInterval
+// on the schedule of the strategy, and kept for a manual redelivery once
+// it is out of attempts. Events that do not fit in the queue go to an
+// overflow queue of the same size, whose events are kept as dead letters
+// without being attempted, and are dropped once it is full too. Events
+// still queued when Shutdown runs out of time are kept the same way.
+type Dispatcher struct {
+	repo     WebhookRepo
+	sender   *Sender
+	strategy retry.Strategy
+	cfg      DispatcherConfig
+	events   chan dto.Event
+	overflow chan dto.Event
+	dropped  atomic.Int64
+
+	// ctx is cancelled once Shutdown runs out of time, cancelling the
+	// deliveries in flight.
+	ctx    context.Context
+	cancel context.CancelFunc
+	stop   chan struct{}
+
+	mu     sync.RWMutex
+	closed bool
+	wg     sync.WaitGroup
+
+	cacheMu sync.Mutex
+	cache   map[uuid.UUID]subscribers
+}
+
+// subscribers are the webhooks of a workspace as of loadedAt.
+type subscribers struct {
+	webhooks []domain.Webhook
+	loadedAt time.Time
+}
+
+func NewDispatcher(repo WebhookRepo, sender *Sender, strategy retry.Strategy, cfg DispatcherConfig) *Dispatcher {
+	// A strategy without attempts would never send anything.
+	strategy.Attempts = max(strategy.Attempts, 1)
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Dispatcher{
+		repo:     repo,
+		sender:   sender,
+		strategy: strategy,
+		cfg:      cfg,
+		events:   make(chan dto.Event, cfg.Size),
+		overflow: make(chan dto.Event, cfg.Size),
+		ctx:      ctx,
+		cancel:   cancel,
+		stop:     make(chan struct{}),
+		cache:    make(map[uuid.UUID]subscribers),
+	}
+}
+
+// Start launches the workers, the one keeping overflowing events, and the
+// retries of failed deliveries when RetryInterval is set.
+func (d *Dispatcher) Start() {
+	for i := 0; i < d.cfg.Workers; i++ {
+		d.wg.Add(1)
+		go d.work()
+	}
+	d.wg.Add(1)
+	go d.spill()
+	if d.cfg.RetryInterval > 0 {
+		d.wg.Add(1)
+		go d.retryDue()
+	}
+}
+
+// Emit queues event for delivery. It never blocks: events that do not fit
+// in the queue are handed to the overflow queue, and dropped when it is
+// full too, as they are once the dispatcher is shut down.
+func (d *Dispatcher) Emit(event dto.Event) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	if d.closed {
+		return
+	}
+	if event.CreatedAt.IsZero() {
+		event.CreatedAt = time.Now()
+	}
+
+	select {
+	case d.events <- event:
+		return
+	default:
+	}
+
+	select {
+	case d.overflow <- event:
+		zlog.Logger.Warn().Str("event", event.Type).Str("alias", event.Link.Alias).Msg("webhook queue is full, keeping event to retry")
+	default:
+		d.dropped.Add(1)
+		zlog.Logger.Warn().Str("event", event.Type).Str("alias", event.Link.Alias).Msg("webhook overflow queue is full, dropping event")
+	}
+}
+
+// Dropped returns the number of events dropped because both queues were
+// full.
+func (d *Dispatcher) Dropped() int64 {
+	return d.dropped.Load()
+}
+
+// Shutdown stops accepting events and waits until the workers have
+// delivered the ones already queued. Once ctx is done, the deliveries in
+// flight are cancelled and the events left are kept as dead letters to be
+// retried, which Shutdown waits for too.
+func (d *Dispatcher) Shutdown(ctx context.Context) error {
+	const op = "service.webhook.Dispatcher.Shutdown"
+
+	d.mu.Lock()
+	if !d.closed {
+		d.closed = true
+		close(d.events)
+		close(d.overflow)
+		close(d.stop)
+	}
+	d.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		d.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		d.cancel()
+		return nil
+	case <-ctx.Done():
+	}
+
+	d.cancel()
+	<-done
+	return errutils.Wrap(op, ctx.Err())
+}
+
+func (d *Dispatcher) work() {
+	defer d.wg.Done()
+
+	for event := range d.events {
+		var skip error
+		if d.ctx.Err() != nil {
+			skip = errShutdown
+		}
+		d.dispatch(d.ctx, event, skip)
+	}
+}
+
+// spill keeps the events of the overflow queue as dead letters, off the
+// path of Emit.
+func (d *Dispatcher) spill() {
+	defer d.wg.Done()
+
+	for event := range d.overflow {
+		d.dispatch(d.ctx, event, errQueueFull)
+	}
+}
+
+// dispatch delivers event to each webhook of its workspace subscribed to
+// it, with the same payload and id. When skip is set, the deliveries are
+// not attempted but kept as dead letters right away, skip being the
+// reason.
+func (d *Dispatcher) dispatch(ctx context.Context, event dto.Event, skip error) {
+	webhooks, err := d.lookup(event.WorkspaceID)
+	if err != nil {
+		zlog.Logger.Error().Err(err).Str("event", event.Type).Msg("failed to get webhooks")
+		return
+	}
+
+	var (
+		eventID = uuid.New()
+		payload []byte
+	)
+	for _, webhook := range webhooks {
+		if !slices.Contains(webhook.Events, event.Type) {
+			continue
+		}
+		if payload == nil {
+			if payload, err = json.Marshal(dto.Payload{
+				ID:        eventID,
+				Type:      event.Type,
+				CreatedAt: event.CreatedAt.UTC(),
+				Data:      dto.Data{Link: event.Link, Click: event.Click},
+			}); err != nil {
+				zlog.Logger.Error().Err(err).Str("event", event.Type).Msg("failed to encode webhook payload")
+				return
+			}
+		}
+
+		letter := domain.DeadLetter{
+			ID:          uuid.New(),
+			WebhookID:   webhook.ID,
+			WorkspaceID: webhook.WorkspaceID,
+			EventID:     eventID,
+			EventType:   event.Type,
+			Payload:     payload,
+		}
+		if skip != nil {
+			now := time.Now()
+			letter.LastError, letter.NextAttemptAt = skip.Error(), &now
+			d.keep(letter)
+			continue
+		}
+		d.deliver(ctx, webhook, letter)
+	}
+}
+
+// deliver attempts a delivery once, keeping it as a dead letter when it
+// fails.
+func (d *Dispatcher) deliver(ctx context.Context, webhook domain.Webhook, letter domain.DeadLetter) {
+	err := d.sender.Send(ctx, webhook, letter.EventID, letter.EventType, letter.Payload)
+	if err == nil {
+		return
+	}
+
+	letter.Attempts = 1
+	letter.LastError = err.Error()
+	letter.NextAttemptAt = d.nextAttempt(letter.Attempts)
+	d.keep(letter)
+}
+
+func (d *Dispatcher) keep(letter domain.DeadLetter) {
+	log := zlog.Logger.Warn().Str("webhook", letter.WebhookID.String()).Str("event", letter.EventType).Str("error", letter.LastError)
+	if letter.NextAttemptAt == nil {
+		log.Msg("webhook delivery failed, keeping it as a dead letter")
+	} else {
+		log.Time("next_attempt_at", *letter.NextAttemptAt).Msg("webhook delivery failed, retrying later")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), storeTimeout)
+	defer cancel()
+
+	if err := d.repo.CreateDeadLetter(ctx, letter); err != nil {
+		zlog.Logger.Error().Err(err).Str("webhook", letter.WebhookID.String()).Str("event", letter.EventType).Msg("failed to keep dead letter")
+	}
+}
+
+// nextAttempt is when a delivery that failed attempts times is due again,
+// nil once it is out of attempts. Delays grow as in retry.Do.
+func (d *Dispatcher) nextAttempt(attempts int) *time.Time {
+	if attempts >= d.strategy.Attempts {
+		return nil
+	}
+	delay := d.strategy.Delay
+	for i := 1; i < attempts; i++ {
+		delay = time.Duration(float64(delay) * d.strategy.Backoff)
+	}
+	next := time.Now().Add(delay)
+	return &next
+}
+
+func (d *Dispatcher) retryDue() {
+	defer d.wg.Done()
+
+	ticker := time.NewTicker(d.cfg.RetryInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-d.stop:
+			return
+		case <-ticker.C:
+			if err := d.Retry(d.ctx); err != nil {
+				zlog.Logger.Warn().Err(err).Msg("failed to retry webhook deliveries")
+			}
+		}
+	}
+}
+
+// Retry attempts the failed deliveries that are due again. One that
+// succeeds is removed, one that fails again is put off until its next
+// attempt, or for good once it is out of attempts.
+func (d *Dispatcher) Retry(ctx context.Context) error {
+	const op = "service.webhook.Dispatcher.Retry"
+
+	for {
+		letters, err := d.repo.ClaimDeadLetters(ctx, retryBatch, retryLease)
+		if err != nil {
+			return errutils.Wrap(op, err)
+		}
+
+		for _, letter := range letters {
+			if err = d.retry(ctx, letter); err != nil {
+				return errutils.Wrap(op, err)
+			}
+		}
+
+		if len(letters) < retryBatch {
+			return nil
+		}
+	}
+}
+
+func (d *Dispatcher) retry(ctx context.Context, letter domain.DeadLetter) error {
+	webhooks, err := d.lookup(letter.WorkspaceID)
+	if err != nil {
+		return err
+	}
+	// The letters of a deleted webhook go with it.
+	i := slices.IndexFunc(webhooks, func(webhook domain.Webhook) bool { return webhook.ID == letter.WebhookID })
+	if i < 0 {
+		return nil
+	}
+
+	sendErr := d.sender.Send(ctx, webhooks[i], letter.EventID, letter.EventType, letter.Payload)
+
+	storeCtx, cancel := context.WithTimeout(context.Background(), storeTimeout)
+	defer cancel()
+
+	if sendErr == nil {
+		return d.repo.DeleteDeadLetter(storeCtx, letter.ID)
+	}
+	next := d.nextAttempt(letter.Attempts + 1)
+	if next == nil {
+		zlog.Logger.Warn().Err(sendErr).Str("webhook", letter.WebhookID.String()).Str("event", letter.EventType).Msg("webhook delivery is out of attempts, keeping it as a dead letter")
+	}
+	return d.repo.FailDeadLetter(storeCtx, letter.ID, sendErr.Error(), next)
+}
+
+// lookup returns the webhooks of a workspace without being cancelled with
+// the deliveries, so that failed ones can still be kept.
+func (d *Dispatcher) lookup(workspaceID uuid.UUID) ([]domain.Webhook, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), storeTimeout)
+	defer cancel()
+	return d.subscribers(ctx, workspaceID)
+}
+
+// subscribers returns the webhooks of a workspace, cached for
+// subscribersTTL so that clicks do not each cost a query.
+func (d *Dispatcher) subscribers(ctx context.Context, workspaceID uuid.UUID) ([]domain.Webhook, error) {
+	d.cacheMu.Lock()
+	cached, ok := d.cache[workspaceID]
+	d.cacheMu.Unlock()
+	if ok && time.Since(cached.loadedAt) < subscribersTTL {
+		return cached.webhooks, nil
+	}
+
+	webhooks, err := d.repo.ListWebhooks(ctx, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+
+	d.cacheMu.Lock()
+	d.cache[workspaceID] = subscribers{webhooks: webhooks, loadedAt: time.Now()}
+	d.cacheMu.Unlock()
+
+	return webhooks, nil
+}
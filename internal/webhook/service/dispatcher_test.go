@@ -0,0 +1,386 @@
+package service_test
+
+import (
+	"context"
+	"encoding/json"
+	"go.uber.org/mock/gomock"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"github.com/wb-go/wbf/retry"
+
+	"github.com/ilam072/shortener/internal/webhook/mocks"
+	"github.com/ilam072/shortener/internal/webhook/service"
+	"github.com/ilam072/shortener/internal/webhook/types/domain"
+	"github.com/ilam072/shortener/internal/webhook/types/dto"
+)
+
+func TestDispatcher_DeliversSignedEvents(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	receiver, deliveries := newReceiver(t, http.StatusNoContent)
+
+	workspaceID := uuid.New()
+	subscribed := domain.Webhook{
+		ID:          uuid.New(),
+		WorkspaceID: workspaceID,
+		URL:         receiver.URL,
+		Secret:      "whsec_test",
+		Events:      []string{domain.EventLinkClicked},
+	}
+	other := subscribed
+	other.ID = uuid.New()
+	other.Events = []string{domain.EventLinkDeleted}
+
+	mockRepo := mocks.NewMockWebhookRepo(ctrl)
+	mockRepo.EXPECT().
+		ListWebhooks(gomock.Any(), workspaceID).
+		Return([]domain.Webhook{subscribed, other}, nil)
+
+	dispatcher := service.NewDispatcher(mockRepo, service.NewSender(time.Second), retry.Strategy{Attempts: 1}, service.DispatcherConfig{
+		Size:    10,
+		Workers: 1,
+	})
+	dispatcher.Start()
+
+	linkID := uuid.New()
+	dispatcher.Emit(dto.Event{
+		Type:        domain.EventLinkClicked,
+		WorkspaceID: workspaceID,
+		Link:        dto.EventLink{ID: linkID, Alias: "abc"},
+		Click:       &dto.EventClick{Device: "mobile", Client: "Chrome"},
+	})
+	require.NoError(t, dispatcher.Shutdown(context.Background()))
+
+	require.Len(t, deliveries, 1)
+	got := <-deliveries
+
+	timestamp := got.header.Get(service.HeaderTimestamp)
+	require.Equal(t, "sha256="+service.Sign("whsec_test", timestamp, got.body), got.header.Get(service.HeaderSignature))
+	require.Equal(t, domain.EventLinkClicked, got.header.Get(service.HeaderEvent))
+
+	var payload dto.Payload
+	require.NoError(t, json.Unmarshal(got.body, &payload))
+	require.Equal(t, got.header.Get(service.HeaderEventID), payload.ID.String())
+	require.Equal(t, domain.EventLinkClicked, payload.Type)
+	require.Equal(t, linkID, payload.Data.Link.ID)
+	require.Equal(t, "mobile", payload.Data.Click.Device)
+}
+
+func TestDispatcher_KeepsDeadLetters(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	receiver, deliveries := newReceiver(t, http.StatusInternalServerError)
+
+	webhook := domain.Webhook{
+		ID:          uuid.New(),
+		WorkspaceID: uuid.New(),
+		URL:         receiver.URL,
+		Secret:      "whsec_test",
+		Events:      []string{domain.EventLinkDeleted},
+	}
+
+	mockRepo := mocks.NewMockWebhookRepo(ctrl)
+	mockRepo.EXPECT().
+		ListWebhooks(gomock.Any(), webhook.WorkspaceID).
+		Return([]domain.Webhook{webhook}, nil)
+	mockRepo.EXPECT().
+		CreateDeadLetter(gomock.Any(), gomock.Cond(func(letter domain.DeadLetter) bool {
+			return letter.WebhookID == webhook.ID && letter.WorkspaceID == webhook.WorkspaceID &&
+				letter.EventType == domain.EventLinkDeleted && letter.Attempts == 1 && letter.LastError != "" &&
+				letter.NextAttemptAt != nil && time.Until(*letter.NextAttemptAt) > 30*time.Second
+		})).
+		Return(nil)
+
+	dispatcher := service.NewDispatcher(mockRepo, service.NewSender(time.Second), retry.Strategy{
+		Attempts: 3,
+		Delay:    time.Minute,
+		Backoff:  2,
+	}, service.DispatcherConfig{Size: 10, Workers: 1})
+	dispatcher.Start()
+
+	dispatcher.Emit(dto.Event{
+		Type:        domain.EventLinkDeleted,
+		WorkspaceID: webhook.WorkspaceID,
+		Link:        dto.EventLink{ID: uuid.New(), Alias: "abc"},
+	})
+	require.NoError(t, dispatcher.Shutdown(context.Background()))
+
+	require.Len(t, deliveries, 1)
+}
+
+func TestDispatcher_KeepsOverflow(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	receiver, deliveries := newReceiver(t, http.StatusNoContent)
+
+	webhook := domain.Webhook{
+		ID:          uuid.New(),
+		WorkspaceID: uuid.New(),
+		URL:         receiver.URL,
+		Secret:      "whsec_test",
+		Events:      []string{domain.EventLinkDeleted},
+	}
+
+	mockRepo := mocks.NewMockWebhookRepo(ctrl)
+	mockRepo.EXPECT().
+		ListWebhooks(gomock.Any(), webhook.WorkspaceID).
+		Return([]domain.Webhook{webhook}, nil)
+	mockRepo.EXPECT().
+		CreateDeadLetter(gomock.Any(), gomock.Cond(func(letter domain.DeadLetter) bool {
+			return letter.WebhookID == webhook.ID && letter.Attempts == 0 &&
+				letter.LastError == "webhook queue is full" && letter.NextAttemptAt != nil
+		})).
+		Return(nil)
+
+	dispatcher := service.NewDispatcher(mockRepo, service.NewSender(time.Second), retry.Strategy{Attempts: 3}, service.DispatcherConfig{
+		Size:    1,
+		Workers: 1,
+	})
+
+	for range 2 {
+		dispatcher.Emit(dto.Event{
+			Type:        domain.EventLinkDeleted,
+			WorkspaceID: webhook.WorkspaceID,
+			Link:        dto.EventLink{ID: uuid.New(), Alias: "abc"},
+		})
+	}
+	dispatcher.Start()
+	require.NoError(t, dispatcher.Shutdown(context.Background()))
+
+	require.Len(t, deliveries, 1)
+}
+
+func TestDispatcher_EmitNeverTouchesRepo(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// Any call to the repo fails the test.
+	mockRepo := mocks.NewMockWebhookRepo(ctrl)
+
+	dispatcher := service.NewDispatcher(mockRepo, service.NewSender(time.Second), retry.Strategy{Attempts: 3}, service.DispatcherConfig{
+		Size:    1,
+		Workers: 1,
+	})
+
+	for range 3 {
+		dispatcher.Emit(dto.Event{
+			Type:        domain.EventLinkClicked,
+			WorkspaceID: uuid.New(),
+			Link:        dto.EventLink{ID: uuid.New(), Alias: "abc"},
+		})
+	}
+
+	require.Equal(t, int64(1), dispatcher.Dropped())
+}
+
+func TestDispatcher_ShutdownCancelsDeliveries(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// The receiver never answers, until the delivery is cancelled.
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.ReadAll(r.Body)
+		<-r.Context().Done()
+	}))
+	t.Cleanup(receiver.Close)
+
+	webhook := domain.Webhook{
+		ID:          uuid.New(),
+		WorkspaceID: uuid.New(),
+		URL:         receiver.URL,
+		Secret:      "whsec_test",
+		Events:      []string{domain.EventLinkDeleted},
+	}
+
+	mockRepo := mocks.NewMockWebhookRepo(ctrl)
+	mockRepo.EXPECT().
+		ListWebhooks(gomock.Any(), webhook.WorkspaceID).
+		Return([]domain.Webhook{webhook}, nil)
+	gomock.InOrder(
+		mockRepo.EXPECT().
+			CreateDeadLetter(gomock.Any(), gomock.Cond(func(letter domain.DeadLetter) bool {
+				return letter.Attempts == 1 && letter.NextAttemptAt != nil
+			})).
+			Return(nil),
+		mockRepo.EXPECT().
+			CreateDeadLetter(gomock.Any(), gomock.Cond(func(letter domain.DeadLetter) bool {
+				return letter.Attempts == 0 && letter.LastError == "dispatcher is shutting down"
+			})).
+			Return(nil),
+	)
+
+	dispatcher := service.NewDispatcher(mockRepo, service.NewSender(time.Minute), retry.Strategy{Attempts: 3}, service.DispatcherConfig{
+		Size:    10,
+		Workers: 1,
+	})
+	dispatcher.Start()
+
+	for range 2 {
+		dispatcher.Emit(dto.Event{
+			Type:        domain.EventLinkDeleted,
+			WorkspaceID: webhook.WorkspaceID,
+			Link:        dto.EventLink{ID: uuid.New(), Alias: "abc"},
+		})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	require.ErrorIs(t, dispatcher.Shutdown(ctx), context.DeadlineExceeded)
+}
+
+func TestDispatcher_Retry(t *testing.T) {
+	webhookID, workspaceID := uuid.New(), uuid.New()
+	letter := domain.DeadLetter{
+		ID:          uuid.New(),
+		WebhookID:   webhookID,
+		WorkspaceID: workspaceID,
+		EventID:     uuid.New(),
+		EventType:   domain.EventLinkDeleted,
+		Payload:     []byte(`{"type":"link.deleted"}`),
+		Attempts:    1,
+	}
+
+	tests := []struct {
+		name   string
+		status int
+		setup  func(repo *mocks.MockWebhookRepo)
+	}{
+		{
+			name:   "delivered letter is removed",
+			status: http.StatusNoContent,
+			setup: func(repo *mocks.MockWebhookRepo) {
+				repo.EXPECT().DeleteDeadLetter(gomock.Any(), letter.ID).Return(nil)
+			},
+		},
+		{
+			name:   "failed letter is put off",
+			status: http.StatusServiceUnavailable,
+			setup: func(repo *mocks.MockWebhookRepo) {
+				repo.EXPECT().
+					FailDeadLetter(gomock.Any(), letter.ID, gomock.Any(), gomock.Cond(func(next *time.Time) bool {
+						return next != nil && time.Until(*next) > time.Minute
+					})).
+					Return(nil)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			receiver, deliveries := newReceiver(t, tt.status)
+
+			mockRepo := mocks.NewMockWebhookRepo(ctrl)
+			gomock.InOrder(
+				mockRepo.EXPECT().ClaimDeadLetters(gomock.Any(), gomock.Any(), gomock.Any()).Return([]domain.DeadLetter{letter}, nil),
+				mockRepo.EXPECT().ListWebhooks(gomock.Any(), workspaceID).Return([]domain.Webhook{{
+					ID:          webhookID,
+					WorkspaceID: workspaceID,
+					URL:         receiver.URL,
+					Secret:      "whsec_test",
+					Events:      []string{domain.EventLinkDeleted},
+				}}, nil),
+			)
+			tt.setup(mockRepo)
+
+			dispatcher := service.NewDispatcher(mockRepo, service.NewSender(time.Second), retry.Strategy{
+				Attempts: 3,
+				Delay:    time.Minute,
+				Backoff:  2,
+			}, service.DispatcherConfig{})
+
+			require.NoError(t, dispatcher.Retry(context.Background()))
+			require.Len(t, deliveries, 1)
+			require.Equal(t, letter.EventID.String(), (<-deliveries).header.Get(service.HeaderEventID))
+		})
+	}
+}
+
+func TestDispatcher_Retry_OutOfAttempts(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	receiver, _ := newReceiver(t, http.StatusInternalServerError)
+
+	letter := domain.DeadLetter{
+		ID:          uuid.New(),
+		WebhookID:   uuid.New(),
+		WorkspaceID: uuid.New(),
+		EventID:     uuid.New(),
+		EventType:   domain.EventLinkDeleted,
+		Payload:     []byte(`{}`),
+		Attempts:    2,
+	}
+
+	mockRepo := mocks.NewMockWebhookRepo(ctrl)
+	mockRepo.EXPECT().ClaimDeadLetters(gomock.Any(), gomock.Any(), gomock.Any()).Return([]domain.DeadLetter{letter}, nil)
+	mockRepo.EXPECT().ListWebhooks(gomock.Any(), letter.WorkspaceID).Return([]domain.Webhook{{
+		ID:          letter.WebhookID,
+		WorkspaceID: letter.WorkspaceID,
+		URL:         receiver.URL,
+		Secret:      "whsec_test",
+	}}, nil)
+	mockRepo.EXPECT().FailDeadLetter(gomock.Any(), letter.ID, gomock.Any(), nil).Return(nil)
+
+	dispatcher := service.NewDispatcher(mockRepo, service.NewSender(time.Second), retry.Strategy{Attempts: 3}, service.DispatcherConfig{})
+
+	require.NoError(t, dispatcher.Retry(context.Background()))
+}
+
+func TestExpiryWatcher_Watch(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	receiver, deliveries := newReceiver(t, http.StatusOK)
+
+	workspaceID := uuid.New()
+	maxClicks := 10
+	expired := domain.ExpiredLink{
+		ID:          uuid.New(),
+		WorkspaceID: workspaceID,
+		Alias:       "abc",
+		URL:         "https://example.com",
+		MaxClicks:   &maxClicks,
+	}
+
+	mockRepo := mocks.NewMockWebhookRepo(ctrl)
+	mockRepo.EXPECT().
+		ClaimExpiredLinks(gomock.Any(), gomock.Any()).
+		Return([]domain.ExpiredLink{expired}, nil)
+	mockRepo.EXPECT().
+		ListWebhooks(gomock.Any(), workspaceID).
+		Return([]domain.Webhook{{
+			ID:          uuid.New(),
+			WorkspaceID: workspaceID,
+			URL:         receiver.URL,
+			Secret:      "whsec_test",
+			Events:      []string{domain.EventLinkExpired},
+		}}, nil)
+
+	dispatcher := service.NewDispatcher(mockRepo, service.NewSender(time.Second), retry.Strategy{Attempts: 1}, service.DispatcherConfig{
+		Size:    10,
+		Workers: 1,
+	})
+	dispatcher.Start()
+
+	require.NoError(t, service.NewExpiryWatcher(mockRepo, dispatcher, time.Minute).Watch(context.Background()))
+	require.NoError(t, dispatcher.Shutdown(context.Background()))
+
+	require.Len(t, deliveries, 1)
+	var payload dto.Payload
+	require.NoError(t, json.Unmarshal((<-deliveries).body, &payload))
+	require.Equal(t, domain.EventLinkExpired, payload.Type)
+	require.Equal(t, expired.ID, payload.Data.Link.ID)
+	require.Equal(t, 10, *payload.Data.Link.MaxClicks)
+}
@@ -0,0 +1,78 @@
+package service
+
+import (
+	"context"
+	"github.com/ilam072/shortener/internal/webhook/types/domain"
+	"github.com/ilam072/shortener/internal/webhook/types/dto"
+	"github.com/ilam072/shortener/pkg/errutils"
+	"github.com/wb-go/wbf/zlog"
+	"time"
+)
+
+// expiredBatch is the number of expired links claimed at once.
+const expiredBatch = 100
+
+// ExpiryWatcher emits link.expired for links that expire, by time or by
+// click limit. Nothing happens to a link when it expires, so they are
+// looked for every interval.
+type ExpiryWatcher struct {
+	repo       WebhookRepo
+	dispatcher *Dispatcher
+	interval   time.Duration
+}
+
+func NewExpiryWatcher(repo WebhookRepo, dispatcher *Dispatcher, interval time.Duration) *ExpiryWatcher {
+	return &ExpiryWatcher{repo: repo, dispatcher: dispatcher, interval: interval}
+}
+
+// Run looks for expired links right away and then every interval until
+// ctx is done.
+func (w *ExpiryWatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := w.Watch(ctx); err != nil {
+			zlog.Logger.Warn().Err(err).Msg("failed to look for expired links")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Watch emits link.expired for every link that expired since the last
+// watch. Links are claimed before their event is emitted, so an event is
+// lost rather than sent twice when the process stops in between.
+func (w *ExpiryWatcher) Watch(ctx context.Context) error {
+	const op = "service.webhook.ExpiryWatcher.Watch"
+
+	for {
+		links, err := w.repo.ClaimExpiredLinks(ctx, expiredBatch)
+		if err != nil {
+			return errutils.Wrap(op, err)
+		}
+
+		for _, link := range links {
+			w.dispatcher.Emit(dto.Event{
+				Type:        domain.EventLinkExpired,
+				WorkspaceID: link.WorkspaceID,
+				Link: dto.EventLink{
+					ID:        link.ID,
+					Alias:     link.Alias,
+					Domain:    link.Domain,
+					URL:       link.URL,
+					ExpiresAt: link.ExpiresAt,
+					MaxClicks: link.MaxClicks,
+				},
+			})
+		}
+
+		if len(links) < expiredBatch {
+			return nil
+		}
+	}
+}
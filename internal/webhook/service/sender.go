@@ -0,0 +1,80 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/ilam072/shortener/internal/webhook/types/domain"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Delivery headers. The signature is the hex HMAC-SHA256 of the timestamp,
+// a dot and the body, keyed with the secret of the webhook, so that a
+// receiver can check both where a delivery comes from and how old it is.
+const (
+	HeaderEventID   = "X-Webhook-Id"
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	HeaderSignature = "X-Webhook-Signature"
+)
+
+// Sender posts signed payloads to webhooks.
+type Sender struct {
+	client *http.Client
+}
+
+// NewSender returns a sender giving up on deliveries after timeout.
+// Redirects are not followed: a webhook is expected to answer at its URL.
+func NewSender(timeout time.Duration) *Sender {
+	return &Sender{client: &http.Client{
+		Timeout: timeout,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}}
+}
+
+// Send delivers payload to webhook once. Anything but a 2xx response is a
+// failed delivery, reported with ErrDeliveryFailed.
+func (s *Sender) Send(ctx context.Context, webhook domain.Webhook, eventID uuid.UUID, event string, payload []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrDeliveryFailed, err)
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEventID, eventID.String())
+	req.Header.Set(HeaderEvent, event)
+	req.Header.Set(HeaderTimestamp, timestamp)
+	req.Header.Set(HeaderSignature, "sha256="+Sign(webhook.Secret, timestamp, payload))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrDeliveryFailed, err)
+	}
+	// The body is drained so that the connection can be reused.
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	_ = resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("%w: status %d", ErrDeliveryFailed, resp.StatusCode)
+	}
+	return nil
+}
+
+// Sign returns the hex signature of a delivery of payload at timestamp.
+func Sign(secret string, timestamp string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,248 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"go.uber.org/mock/gomock"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ilam072/shortener/internal/webhook/mocks"
+	webhookrepo "github.com/ilam072/shortener/internal/webhook/repo"
+	"github.com/ilam072/shortener/internal/webhook/service"
+	"github.com/ilam072/shortener/internal/webhook/types/domain"
+	"github.com/ilam072/shortener/internal/webhook/types/dto"
+)
+
+// delivery is a request received by a test receiver.
+type delivery struct {
+	header http.Header
+	body   []byte
+}
+
+// newReceiver starts a webhook receiver answering status and recording
+// the deliveries it gets.
+func newReceiver(t *testing.T, status int) (*httptest.Server, chan delivery) {
+	deliveries := make(chan delivery, 16)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		deliveries <- delivery{header: r.Header.Clone(), body: body}
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(receiver.Close)
+	return receiver, deliveries
+}
+
+func TestWebhook_CreateWebhook(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	workspaceID := uuid.New()
+	mockRepo := mocks.NewMockWebhookRepo(ctrl)
+	mockRepo.EXPECT().
+		CreateWebhook(gomock.Any(), gomock.Cond(func(w domain.Webhook) bool {
+			return w.WorkspaceID == workspaceID && w.URL == "https://hooks.acme.io"
+		})).
+		DoAndReturn(func(_ context.Context, w domain.Webhook) (domain.Webhook, error) {
+			w.CreatedAt = time.Now()
+			return w, nil
+		})
+
+	svc := service.New(mockRepo, service.NewSender(time.Second))
+
+	created, err := svc.CreateWebhook(context.Background(), workspaceID, dto.CreateWebhook{
+		URL:    "https://hooks.acme.io",
+		Events: []string{domain.EventLinkDeleted, domain.EventLinkClicked, domain.EventLinkDeleted},
+	})
+
+	require.NoError(t, err)
+	require.Equal(t, []string{domain.EventLinkClicked, domain.EventLinkDeleted}, created.Events)
+	require.True(t, strings.HasPrefix(created.Secret, "whsec_"))
+}
+
+func TestWebhook_DeleteWebhook(t *testing.T) {
+	workspaceID := uuid.New()
+	webhookID := uuid.New()
+
+	tests := []struct {
+		name  string
+		id    string
+		setup func(repo *mocks.MockWebhookRepo)
+		err   error
+	}{
+		{
+			name: "success",
+			id:   webhookID.String(),
+			setup: func(repo *mocks.MockWebhookRepo) {
+				repo.EXPECT().
+					DeleteWebhook(gomock.Any(), workspaceID, webhookID).
+					Return(nil)
+			},
+		},
+		{
+			name: "not found",
+			id:   webhookID.String(),
+			setup: func(repo *mocks.MockWebhookRepo) {
+				repo.EXPECT().
+					DeleteWebhook(gomock.Any(), workspaceID, webhookID).
+					Return(webhookrepo.ErrWebhookNotFound)
+			},
+			err: service.ErrWebhookNotFound,
+		},
+		{
+			name: "invalid id",
+			id:   "not-a-uuid",
+			err:  service.ErrWebhookNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockRepo := mocks.NewMockWebhookRepo(ctrl)
+			if tt.setup != nil {
+				tt.setup(mockRepo)
+			}
+
+			svc := service.New(mockRepo, service.NewSender(time.Second))
+
+			err := svc.DeleteWebhook(context.Background(), workspaceID, tt.id)
+
+			if tt.err != nil {
+				require.ErrorIs(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestWebhook_Redeliver(t *testing.T) {
+	workspaceID := uuid.New()
+	webhookID := uuid.New()
+	letter := domain.DeadLetter{
+		ID:          uuid.New(),
+		WebhookID:   webhookID,
+		WorkspaceID: workspaceID,
+		EventID:     uuid.New(),
+		EventType:   domain.EventLinkClicked,
+		Payload:     []byte(`{"type":"link.clicked"}`),
+		Attempts:    5,
+	}
+
+	tests := []struct {
+		name     string
+		status   int
+		letterID string
+		setup    func(repo *mocks.MockWebhookRepo)
+		err      error
+	}{
+		{
+			name:     "delivered letter is removed",
+			status:   http.StatusOK,
+			letterID: letter.ID.String(),
+			setup: func(repo *mocks.MockWebhookRepo) {
+				repo.EXPECT().
+					GetDeadLetter(gomock.Any(), workspaceID, webhookID, letter.ID).
+					Return(letter, nil)
+				repo.EXPECT().
+					DeleteDeadLetter(gomock.Any(), letter.ID).
+					Return(nil)
+			},
+		},
+		{
+			name:     "failed letter is kept",
+			status:   http.StatusServiceUnavailable,
+			letterID: letter.ID.String(),
+			setup: func(repo *mocks.MockWebhookRepo) {
+				repo.EXPECT().
+					GetDeadLetter(gomock.Any(), workspaceID, webhookID, letter.ID).
+					Return(letter, nil)
+				repo.EXPECT().
+					FailDeadLetter(gomock.Any(), letter.ID, gomock.Any(), letter.NextAttemptAt).
+					Return(nil)
+			},
+			err: service.ErrDeliveryFailed,
+		},
+		{
+			name:     "letter not found",
+			letterID: letter.ID.String(),
+			setup: func(repo *mocks.MockWebhookRepo) {
+				repo.EXPECT().
+					GetDeadLetter(gomock.Any(), workspaceID, webhookID, letter.ID).
+					Return(domain.DeadLetter{}, webhookrepo.ErrDeadLetterNotFound)
+			},
+			err: service.ErrDeadLetterNotFound,
+		},
+		{
+			name:     "invalid letter id",
+			letterID: "not-a-uuid",
+			err:      service.ErrDeadLetterNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			receiver, deliveries := newReceiver(t, tt.status)
+
+			mockRepo := mocks.NewMockWebhookRepo(ctrl)
+			mockRepo.EXPECT().
+				GetWebhook(gomock.Any(), workspaceID, webhookID).
+				Return(domain.Webhook{
+					ID:          webhookID,
+					WorkspaceID: workspaceID,
+					URL:         receiver.URL,
+					Secret:      "whsec_test",
+					Events:      []string{domain.EventLinkClicked},
+				}, nil)
+			if tt.setup != nil {
+				tt.setup(mockRepo)
+			}
+
+			svc := service.New(mockRepo, service.NewSender(time.Second))
+
+			err := svc.Redeliver(context.Background(), workspaceID, webhookID.String(), tt.letterID)
+
+			if tt.status != 0 {
+				got := <-deliveries
+				require.Equal(t, letter.Payload, got.body)
+				require.Equal(t, letter.EventID.String(), got.header.Get(service.HeaderEventID))
+			}
+			if tt.err != nil {
+				require.ErrorIs(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestWebhook_Redeliver_WebhookNotFound(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	workspaceID := uuid.New()
+	webhookID := uuid.New()
+	mockRepo := mocks.NewMockWebhookRepo(ctrl)
+	mockRepo.EXPECT().
+		GetWebhook(gomock.Any(), workspaceID, webhookID).
+		Return(domain.Webhook{}, webhookrepo.ErrWebhookNotFound)
+
+	svc := service.New(mockRepo, service.NewSender(time.Second))
+
+	err := svc.Redeliver(context.Background(), workspaceID, webhookID.String(), uuid.NewString())
+
+	require.True(t, errors.Is(err, service.ErrWebhookNotFound))
+}
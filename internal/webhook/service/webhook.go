@@ -0,0 +1,221 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/ilam072/shortener/internal/webhook/repo"
+	"github.com/ilam072/shortener/internal/webhook/types/domain"
+	"github.com/ilam072/shortener/internal/webhook/types/dto"
+	"github.com/ilam072/shortener/pkg/errutils"
+	"slices"
+	"time"
+)
+
+//go:generate mockgen -source=webhook.go -destination=../mocks/service_mocks.go -package=mocks
+type WebhookRepo interface {
+	CreateWebhook(ctx context.Context, webhook domain.Webhook) (domain.Webhook, error)
+	GetWebhook(ctx context.Context, workspaceID uuid.UUID, id uuid.UUID) (domain.Webhook, error)
+	ListWebhooks(ctx context.Context, workspaceID uuid.UUID) ([]domain.Webhook, error)
+	DeleteWebhook(ctx context.Context, workspaceID uuid.UUID, id uuid.UUID) error
+	CreateDeadLetter(ctx context.Context, letter domain.DeadLetter) error
+	ListDeadLetters(ctx context.Context, workspaceID uuid.UUID, webhookID uuid.UUID) ([]domain.DeadLetter, error)
+	GetDeadLetter(ctx context.Context, workspaceID uuid.UUID, webhookID uuid.UUID, id uuid.UUID) (domain.DeadLetter, error)
+	FailDeadLetter(ctx context.Context, id uuid.UUID, lastError string, nextAttemptAt *time.Time) error
+	DeleteDeadLetter(ctx context.Context, id uuid.UUID) error
+	ClaimDeadLetters(ctx context.Context, limit int, lease time.Duration) ([]domain.DeadLetter, error)
+	ClaimExpiredLinks(ctx context.Context, limit int) ([]domain.ExpiredLink, error)
+}
+
+var (
+	ErrWebhookNotFound    = errors.New("webhook not found")
+	ErrDeadLetterNotFound = errors.New("dead letter not found")
+	ErrDeliveryFailed     = errors.New("webhook delivery failed")
+)
+
+const (
+	secretPrefix = "whsec_"
+	secretBytes  = 32
+)
+
+type Webhook struct {
+	repo   WebhookRepo
+	sender *Sender
+}
+
+// New creates a webhook service. Dead letters are redelivered through
+// sender.
+func New(repo WebhookRepo, sender *Sender) *Webhook {
+	return &Webhook{repo: repo, sender: sender}
+}
+
+// CreateWebhook subscribes url to events of the workspace. Deliveries are
+// signed with a secret generated for the webhook.
+func (s *Webhook) CreateWebhook(ctx context.Context, workspaceID uuid.UUID, webhook dto.CreateWebhook) (dto.CreatedWebhook, error) {
+	const op = "service.webhook.Create"
+
+	secret, err := generateSecret()
+	if err != nil {
+		return dto.CreatedWebhook{}, errutils.Wrap(op, err)
+	}
+
+	events := slices.Clone(webhook.Events)
+	slices.Sort(events)
+	created, err := s.repo.CreateWebhook(ctx, domain.Webhook{
+		ID:          uuid.New(),
+		WorkspaceID: workspaceID,
+		URL:         webhook.URL,
+		Secret:      secret,
+		Events:      slices.Compact(events),
+	})
+	if err != nil {
+		return dto.CreatedWebhook{}, errutils.Wrap(op, err)
+	}
+
+	return dto.CreatedWebhook{Webhook: toDTO(created), Secret: created.Secret}, nil
+}
+
+func (s *Webhook) ListWebhooks(ctx context.Context, workspaceID uuid.UUID) ([]dto.Webhook, error) {
+	const op = "service.webhook.List"
+
+	webhooks, err := s.repo.ListWebhooks(ctx, workspaceID)
+	if err != nil {
+		return nil, errutils.Wrap(op, err)
+	}
+
+	result := make([]dto.Webhook, 0, len(webhooks))
+	for _, webhook := range webhooks {
+		result = append(result, toDTO(webhook))
+	}
+
+	return result, nil
+}
+
+func (s *Webhook) DeleteWebhook(ctx context.Context, workspaceID uuid.UUID, id string) error {
+	const op = "service.webhook.Delete"
+
+	webhookID, err := uuid.Parse(id)
+	if err != nil {
+		return ErrWebhookNotFound
+	}
+
+	if err = s.repo.DeleteWebhook(ctx, workspaceID, webhookID); err != nil {
+		if errors.Is(err, repo.ErrWebhookNotFound) {
+			return errutils.Wrap(op, ErrWebhookNotFound)
+		}
+		return errutils.Wrap(op, err)
+	}
+
+	return nil
+}
+
+// ListDeadLetters returns the failed deliveries to a webhook of the
+// workspace, most recent first, whether they are still to be retried or
+// out of attempts.
+func (s *Webhook) ListDeadLetters(ctx context.Context, workspaceID uuid.UUID, id string) ([]dto.DeadLetter, error) {
+	const op = "service.webhook.ListDeadLetters"
+
+	webhook, err := s.getWebhook(ctx, workspaceID, id)
+	if err != nil {
+		return nil, errutils.Wrap(op, err)
+	}
+
+	letters, err := s.repo.ListDeadLetters(ctx, workspaceID, webhook.ID)
+	if err != nil {
+		return nil, errutils.Wrap(op, err)
+	}
+
+	result := make([]dto.DeadLetter, 0, len(letters))
+	for _, letter := range letters {
+		result = append(result, toDeadLetterDTO(letter))
+	}
+
+	return result, nil
+}
+
+// Redeliver sends a dead letter to its webhook again, once. A delivered
+// letter is removed, one that fails again is kept with the new error and
+// reported with ErrDeliveryFailed.
+func (s *Webhook) Redeliver(ctx context.Context, workspaceID uuid.UUID, id string, letterID string) error {
+	const op = "service.webhook.Redeliver"
+
+	webhook, err := s.getWebhook(ctx, workspaceID, id)
+	if err != nil {
+		return errutils.Wrap(op, err)
+	}
+
+	deadLetterID, err := uuid.Parse(letterID)
+	if err != nil {
+		return ErrDeadLetterNotFound
+	}
+	letter, err := s.repo.GetDeadLetter(ctx, workspaceID, webhook.ID, deadLetterID)
+	if err != nil {
+		if errors.Is(err, repo.ErrDeadLetterNotFound) {
+			return errutils.Wrap(op, ErrDeadLetterNotFound)
+		}
+		return errutils.Wrap(op, err)
+	}
+
+	if sendErr := s.sender.Send(ctx, webhook, letter.EventID, letter.EventType, letter.Payload); sendErr != nil {
+		if err = s.repo.FailDeadLetter(ctx, letter.ID, sendErr.Error(), letter.NextAttemptAt); err != nil {
+			return errutils.Wrap(op, errors.Join(sendErr, err))
+		}
+		return errutils.Wrap(op, sendErr)
+	}
+
+	if err = s.repo.DeleteDeadLetter(ctx, letter.ID); err != nil {
+		return errutils.Wrap(op, err)
+	}
+
+	return nil
+}
+
+func (s *Webhook) getWebhook(ctx context.Context, workspaceID uuid.UUID, id string) (domain.Webhook, error) {
+	webhookID, err := uuid.Parse(id)
+	if err != nil {
+		return domain.Webhook{}, ErrWebhookNotFound
+	}
+
+	webhook, err := s.repo.GetWebhook(ctx, workspaceID, webhookID)
+	if err != nil {
+		if errors.Is(err, repo.ErrWebhookNotFound) {
+			return domain.Webhook{}, ErrWebhookNotFound
+		}
+		return domain.Webhook{}, err
+	}
+
+	return webhook, nil
+}
+
+func generateSecret() (string, error) {
+	b := make([]byte, secretBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return secretPrefix + base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func toDTO(webhook domain.Webhook) dto.Webhook {
+	return dto.Webhook{
+		ID:        webhook.ID.String(),
+		URL:       webhook.URL,
+		Events:    webhook.Events,
+		CreatedAt: webhook.CreatedAt,
+	}
+}
+
+func toDeadLetterDTO(letter domain.DeadLetter) dto.DeadLetter {
+	return dto.DeadLetter{
+		ID:            letter.ID.String(),
+		WebhookID:     letter.WebhookID.String(),
+		EventID:       letter.EventID.String(),
+		EventType:     letter.EventType,
+		Payload:       letter.Payload,
+		Attempts:      letter.Attempts,
+		LastError:     letter.LastError,
+		FailedAt:      letter.FailedAt,
+		NextAttemptAt: letter.NextAttemptAt,
+	}
+}
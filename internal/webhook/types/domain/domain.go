@@ -0,0 +1,52 @@
+package domain
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+// Webhook events.
+const (
+	EventLinkCreated = "link.created"
+	EventLinkClicked = "link.clicked"
+	EventLinkExpired = "link.expired"
+	EventLinkDeleted = "link.deleted"
+)
+
+// Webhook is a subscription of a workspace to Events, delivered to URL and
+// signed with Secret.
+type Webhook struct {
+	ID          uuid.UUID
+	WorkspaceID uuid.UUID
+	URL         string
+	Secret      string
+	Events      []string
+	CreatedAt   time.Time
+}
+
+// DeadLetter is a delivery of an event to a webhook that failed. Payload
+// is the body that was sent. It is retried at NextAttemptAt, and left
+// for a manual redelivery once that is nil.
+type DeadLetter struct {
+	ID            uuid.UUID
+	WebhookID     uuid.UUID
+	WorkspaceID   uuid.UUID
+	EventID       uuid.UUID
+	EventType     string
+	Payload       []byte
+	Attempts      int
+	LastError     string
+	FailedAt      time.Time
+	NextAttemptAt *time.Time
+}
+
+// ExpiredLink is a link that expired, by time or by its click limit.
+type ExpiredLink struct {
+	ID          uuid.UUID
+	WorkspaceID uuid.UUID
+	Alias       string
+	Domain      string
+	URL         string
+	ExpiresAt   *time.Time
+	MaxClicks   *int
+}
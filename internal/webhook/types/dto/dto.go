@@ -0,0 +1,84 @@
+package dto
+
+import (
+	"encoding/json"
+	"github.com/google/uuid"
+	"time"
+)
+
+type CreateWebhook struct {
+	URL    string   `json:"url" validate:"required,http_url,max=2048"`
+	Events []string `json:"events" validate:"required,min=1,dive,oneof=link.created link.clicked link.expired link.deleted"`
+}
+
+type Webhook struct {
+	ID        string    `json:"id"`
+	URL       string    `json:"url"`
+	Events    []string  `json:"events"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// CreatedWebhook carries the signing secret. It is returned only once, at
+// creation.
+type CreatedWebhook struct {
+	Webhook
+	Secret string `json:"secret"`
+}
+
+type DeadLetter struct {
+	ID            string          `json:"id"`
+	WebhookID     string          `json:"webhook_id"`
+	EventID       string          `json:"event_id"`
+	EventType     string          `json:"event_type"`
+	Payload       json.RawMessage `json:"payload" swaggertype:"object"`
+	Attempts      int             `json:"attempts"`
+	LastError     string          `json:"last_error"`
+	FailedAt      time.Time       `json:"failed_at"`
+	NextAttemptAt *time.Time      `json:"next_attempt_at,omitempty"`
+}
+
+// Event is something that happened to a link of a workspace, emitted to
+// the webhooks subscribed to Type. Click is set for link.clicked only.
+// CreatedAt is set on emit when zero.
+type Event struct {
+	Type        string
+	WorkspaceID uuid.UUID
+	Link        EventLink
+	Click       *EventClick
+	CreatedAt   time.Time
+}
+
+// Payload is the body of a delivery. ID identifies the event, so that
+// receivers can tell a redelivery from a new event.
+type Payload struct {
+	ID        uuid.UUID `json:"id"`
+	Type      string    `json:"type"`
+	CreatedAt time.Time `json:"created_at"`
+	Data      Data      `json:"data"`
+}
+
+type Data struct {
+	Link  EventLink   `json:"link"`
+	Click *EventClick `json:"click,omitempty"`
+}
+
+// EventLink is the link of an event, as far as its emitter knows it.
+type EventLink struct {
+	ID        uuid.UUID  `json:"id"`
+	Alias     string     `json:"alias"`
+	Domain    string     `json:"domain,omitempty"`
+	URL       string     `json:"url,omitempty"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	MaxClicks *int       `json:"max_clicks,omitempty"`
+}
+
+// EventClick is the click of a link.clicked event.
+type EventClick struct {
+	ClickedAt time.Time `json:"clicked_at"`
+	Device    string    `json:"device"`
+	Client    string    `json:"client"`
+	OS        string    `json:"os,omitempty"`
+	Referrer  string    `json:"referrer,omitempty"`
+	Country   string    `json:"country,omitempty"`
+	Bot       bool      `json:"bot"`
+}
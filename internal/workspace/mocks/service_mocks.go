@@ -0,0 +1,72 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: workspace.go
+//
+// Generated by this command:
+//
+//	mockgen -source=workspace.go -destination=../mocks/service_mocks.go -package=mocks
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	domain "github.com/ilam072/shortener/internal/workspace/types/domain"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockWorkspaceRepo is a mock of WorkspaceRepo interface.
+type MockWorkspaceRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockWorkspaceRepoMockRecorder
+	isgomock struct{}
+}
+
+// MockWorkspaceRepoMockRecorder is the mock recorder for MockWorkspaceRepo.
+type MockWorkspaceRepoMockRecorder struct {
+	mock *MockWorkspaceRepo
+}
+
+// NewMockWorkspaceRepo creates a new mock instance.
+func NewMockWorkspaceRepo(ctrl *gomock.Controller) *MockWorkspaceRepo {
+	mock := &MockWorkspaceRepo{ctrl: ctrl}
+	mock.recorder = &MockWorkspaceRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockWorkspaceRepo) EXPECT() *MockWorkspaceRepoMockRecorder {
+	return m.recorder
+}
+
+// CreateWorkspace mocks base method.
+func (m *MockWorkspaceRepo) CreateWorkspace(ctx context.Context, workspace domain.Workspace) (domain.Workspace, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWorkspace", ctx, workspace)
+	ret0, _ := ret[0].(domain.Workspace)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateWorkspace indicates an expected call of CreateWorkspace.
+func (mr *MockWorkspaceRepoMockRecorder) CreateWorkspace(ctx, workspace any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWorkspace", reflect.TypeOf((*MockWorkspaceRepo)(nil).CreateWorkspace), ctx, workspace)
+}
+
+// ListWorkspaces mocks base method.
+func (m *MockWorkspaceRepo) ListWorkspaces(ctx context.Context) ([]domain.Workspace, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListWorkspaces", ctx)
+	ret0, _ := ret[0].([]domain.Workspace)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListWorkspaces indicates an expected call of ListWorkspaces.
+func (mr *MockWorkspaceRepoMockRecorder) ListWorkspaces(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListWorkspaces", reflect.TypeOf((*MockWorkspaceRepo)(nil).ListWorkspaces), ctx)
+}
@@ -0,0 +1,84 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"github.com/ilam072/shortener/internal/workspace/repo"
+	"github.com/ilam072/shortener/internal/workspace/types/domain"
+	"github.com/ilam072/shortener/pkg/errutils"
+	"github.com/lib/pq"
+	"github.com/wb-go/wbf/dbpg"
+)
+
+type WorkspaceRepo struct {
+	db *dbpg.DB
+}
+
+func New(db *dbpg.DB) *WorkspaceRepo {
+	return &WorkspaceRepo{db: db}
+}
+
+func (r *WorkspaceRepo) CreateWorkspace(ctx context.Context, workspace domain.Workspace) (domain.Workspace, error) {
+	const op = "repo.workspace.Create"
+
+	query := `
+		INSERT INTO workspaces(id, name, slug)
+		VALUES ($1, $2, $3)
+		RETURNING created_at;
+	`
+
+	if err := r.db.QueryRowContext(
+		ctx,
+		query,
+		workspace.ID,
+		workspace.Name,
+		workspace.Slug,
+	).Scan(&workspace.CreatedAt); err != nil {
+		if isUniqueViolation(err) {
+			return domain.Workspace{}, errutils.Wrap(op, repo.ErrSlugAlreadyExists)
+		}
+		return domain.Workspace{}, errutils.Wrap(op, err)
+	}
+
+	return workspace, nil
+}
+
+func (r *WorkspaceRepo) ListWorkspaces(ctx context.Context) ([]domain.Workspace, error) {
+	const op = "repo.workspace.List"
+
+	query := `
+		SELECT id, name, slug, created_at
+		FROM workspaces
+		ORDER BY created_at;
+	`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, errutils.Wrap(op, err)
+	}
+	defer rows.Close()
+
+	var workspaces []domain.Workspace
+	for rows.Next() {
+		var workspace domain.Workspace
+		if err := rows.Scan(
+			&workspace.ID,
+			&workspace.Name,
+			&workspace.Slug,
+			&workspace.CreatedAt,
+		); err != nil {
+			return nil, errutils.Wrap(op, err)
+		}
+		workspaces = append(workspaces, workspace)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errutils.Wrap(op, err)
+	}
+
+	return workspaces, nil
+}
+
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505"
+}
@@ -0,0 +1,7 @@
+package repo
+
+import "errors"
+
+var (
+	ErrSlugAlreadyExists = errors.New("slug already exists")
+)
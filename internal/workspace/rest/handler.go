@@ -0,0 +1,101 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/ilam072/shortener/internal/response"
+	"github.com/ilam072/shortener/internal/workspace/service"
+	"github.com/ilam072/shortener/internal/workspace/types/dto"
+	"github.com/wb-go/wbf/ginext"
+	"github.com/wb-go/wbf/zlog"
+	"net/http"
+)
+
+//go:generate mockgen -source=handler.go -destination=../mocks/rest_mocks.go -package=mocks
+type Workspace interface {
+	CreateWorkspace(ctx context.Context, workspace dto.CreateWorkspace) (dto.Workspace, error)
+	ListWorkspaces(ctx context.Context) ([]dto.Workspace, error)
+}
+
+type Validator interface {
+	Validate(i interface{}) error
+}
+
+type WorkspaceHandler struct {
+	workspace Workspace
+	validator Validator
+}
+
+func NewWorkspaceHandler(workspace Workspace, validator Validator) *WorkspaceHandler {
+	return &WorkspaceHandler{workspace: workspace, validator: validator}
+}
+
+// CreateWorkspace godoc
+// @Summary Создать рабочее пространство
+// @Description Создаёт рабочее пространство команды. Ссылки, клики, пользователи и API-ключи изолированы внутри пространства
+// @Tags Workspaces
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Param input body dto.CreateWorkspace true "Название и slug пространства"
+// @Success 201 {object} response.Response{payload=dto.Workspace} "Созданное пространство"
+// @Failure 400 {object} response.Response "invalid request body или validation error"
+// @Failure 401 {object} response.Response "invalid credentials"
+// @Failure 403 {object} response.Response "insufficient scope"
+// @Failure 409 {object} response.Response "slug already exists или slug is reserved"
+// @Failure 500 {object} response.Response "internal server error"
+// @Router /workspaces [post]
+func (h *WorkspaceHandler) CreateWorkspace(c *ginext.Context) {
+	var workspace dto.CreateWorkspace
+	if err := json.NewDecoder(c.Request.Body).Decode(&workspace); err != nil {
+		response.Error("invalid request body").WriteJSON(c, http.StatusBadRequest)
+		return
+	}
+	if err := h.validator.Validate(workspace); err != nil {
+		response.Error(fmt.Sprintf("validation error: %s", err.Error())).WriteJSON(c, http.StatusBadRequest)
+		return
+	}
+
+	created, err := h.workspace.CreateWorkspace(c.Request.Context(), workspace)
+	if err != nil {
+		if errors.Is(err, service.ErrSlugAlreadyExists) {
+			response.Error("workspace with such slug already exists").WriteJSON(c, http.StatusConflict)
+			return
+		}
+		if errors.Is(err, service.ErrSlugReserved) {
+			response.Error("slug is reserved").WriteJSON(c, http.StatusConflict)
+			return
+		}
+		zlog.Logger.Error().Err(err).Str("slug", workspace.Slug).Msg("failed to create workspace")
+		response.Error("internal server error, try again later").WriteJSON(c, http.StatusInternalServerError)
+		return
+	}
+
+	response.Success(created).WriteJSON(c, http.StatusCreated)
+}
+
+// ListWorkspaces godoc
+// @Summary Список рабочих пространств
+// @Description Возвращает все рабочие пространства
+// @Tags Workspaces
+// @Produce json
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Success 200 {object} response.Response{payload=[]dto.Workspace} "Рабочие пространства"
+// @Failure 401 {object} response.Response "invalid credentials"
+// @Failure 403 {object} response.Response "insufficient scope"
+// @Failure 500 {object} response.Response "internal server error"
+// @Router /workspaces [get]
+func (h *WorkspaceHandler) ListWorkspaces(c *ginext.Context) {
+	workspaces, err := h.workspace.ListWorkspaces(c.Request.Context())
+	if err != nil {
+		zlog.Logger.Error().Err(err).Msg("failed to list workspaces")
+		response.Error("internal server error, try again later").WriteJSON(c, http.StatusInternalServerError)
+		return
+	}
+
+	response.Success(workspaces).WriteJSON(c, http.StatusOK)
+}
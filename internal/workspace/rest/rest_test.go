@@ -0,0 +1,144 @@
+package rest_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"github.com/wb-go/wbf/ginext"
+	"go.uber.org/mock/gomock"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ilam072/shortener/internal/workspace/mocks"
+	"github.com/ilam072/shortener/internal/workspace/rest"
+	"github.com/ilam072/shortener/internal/workspace/service"
+	"github.com/ilam072/shortener/internal/workspace/types/dto"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+func newTestContext(method, path string, body []byte) (*ginext.Context, *httptest.ResponseRecorder) {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+
+	req := httptest.NewRequest(method, path, bytes.NewBuffer(body))
+	c.Request = req
+
+	return c, w
+}
+
+func TestWorkspaceHandler_CreateWorkspace(t *testing.T) {
+	type fields struct {
+		setup func(workspace *mocks.MockWorkspace, validator *mocks.MockValidator)
+	}
+	type want struct {
+		status int
+	}
+
+	tests := []struct {
+		name   string
+		body   interface{}
+		fields fields
+		want   want
+	}{
+		{
+			name: "invalid json",
+			body: "invalid",
+			want: want{status: http.StatusBadRequest},
+		},
+		{
+			name: "validation error",
+			body: dto.CreateWorkspace{Name: "Acme", Slug: "Acme Inc"},
+			fields: fields{
+				setup: func(workspace *mocks.MockWorkspace, validator *mocks.MockValidator) {
+					validator.EXPECT().
+						Validate(gomock.Any()).
+						Return(errors.New("validation failed"))
+				},
+			},
+			want: want{status: http.StatusBadRequest},
+		},
+		{
+			name: "slug already exists",
+			body: dto.CreateWorkspace{Name: "Acme", Slug: "acme"},
+			fields: fields{
+				setup: func(workspace *mocks.MockWorkspace, validator *mocks.MockValidator) {
+					validator.EXPECT().
+						Validate(gomock.Any()).
+						Return(nil)
+					workspace.EXPECT().
+						CreateWorkspace(gomock.Any(), gomock.Any()).
+						Return(dto.Workspace{}, service.ErrSlugAlreadyExists)
+				},
+			},
+			want: want{status: http.StatusConflict},
+		},
+		{
+			name: "internal error",
+			body: dto.CreateWorkspace{Name: "Acme", Slug: "acme"},
+			fields: fields{
+				setup: func(workspace *mocks.MockWorkspace, validator *mocks.MockValidator) {
+					validator.EXPECT().
+						Validate(gomock.Any()).
+						Return(nil)
+					workspace.EXPECT().
+						CreateWorkspace(gomock.Any(), gomock.Any()).
+						Return(dto.Workspace{}, errors.New("db error"))
+				},
+			},
+			want: want{status: http.StatusInternalServerError},
+		},
+		{
+			name: "success",
+			body: dto.CreateWorkspace{Name: "Acme", Slug: "acme"},
+			fields: fields{
+				setup: func(workspace *mocks.MockWorkspace, validator *mocks.MockValidator) {
+					validator.EXPECT().
+						Validate(gomock.Any()).
+						Return(nil)
+					workspace.EXPECT().
+						CreateWorkspace(gomock.Any(), dto.CreateWorkspace{Name: "Acme", Slug: "acme"}).
+						Return(dto.Workspace{Name: "Acme", Slug: "acme"}, nil)
+				},
+			},
+			want: want{status: http.StatusCreated},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockWorkspace := mocks.NewMockWorkspace(ctrl)
+			mockValidator := mocks.NewMockValidator(ctrl)
+
+			if tt.fields.setup != nil {
+				tt.fields.setup(mockWorkspace, mockValidator)
+			}
+
+			handler := rest.NewWorkspaceHandler(mockWorkspace, mockValidator)
+
+			var bodyBytes []byte
+			switch v := tt.body.(type) {
+			case string:
+				bodyBytes = []byte(v)
+			default:
+				bodyBytes, _ = json.Marshal(v)
+			}
+
+			c, w := newTestContext(http.MethodPost, "/workspaces", bodyBytes)
+
+			handler.CreateWorkspace(c)
+
+			require.Equal(t, tt.want.status, w.Code)
+		})
+	}
+}
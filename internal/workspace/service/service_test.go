@@ -0,0 +1,107 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"go.uber.org/mock/gomock"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ilam072/shortener/internal/workspace/mocks"
+	workspacerepo "github.com/ilam072/shortener/internal/workspace/repo"
+	"github.com/ilam072/shortener/internal/workspace/service"
+	"github.com/ilam072/shortener/internal/workspace/types/domain"
+	"github.com/ilam072/shortener/internal/workspace/types/dto"
+)
+
+func TestWorkspace_CreateWorkspace(t *testing.T) {
+	type fields struct {
+		setup func(repo *mocks.MockWorkspaceRepo)
+	}
+	type want struct {
+		err error
+	}
+
+	tests := []struct {
+		name   string
+		slug   string
+		fields fields
+		want   want
+	}{
+		{
+			name: "success",
+			fields: fields{
+				setup: func(repo *mocks.MockWorkspaceRepo) {
+					repo.EXPECT().
+						CreateWorkspace(gomock.Any(), gomock.Cond(func(w domain.Workspace) bool {
+							return w.ID != uuid.Nil && w.Name == "Acme" && w.Slug == "acme"
+						})).
+						DoAndReturn(func(_ context.Context, w domain.Workspace) (domain.Workspace, error) {
+							return w, nil
+						})
+				},
+			},
+			want: want{},
+		},
+		{
+			name: "slug already exists",
+			fields: fields{
+				setup: func(repo *mocks.MockWorkspaceRepo) {
+					repo.EXPECT().
+						CreateWorkspace(gomock.Any(), gomock.Any()).
+						Return(domain.Workspace{}, workspacerepo.ErrSlugAlreadyExists)
+				},
+			},
+			want: want{err: service.ErrSlugAlreadyExists},
+		},
+		{
+			name: "reserved slug",
+			slug: "api",
+			want: want{err: service.ErrSlugReserved},
+		},
+		{
+			name: "repo error",
+			fields: fields{
+				setup: func(repo *mocks.MockWorkspaceRepo) {
+					repo.EXPECT().
+						CreateWorkspace(gomock.Any(), gomock.Any()).
+						Return(domain.Workspace{}, errors.New("db error"))
+				},
+			},
+			want: want{err: errors.New("db error")},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockRepo := mocks.NewMockWorkspaceRepo(ctrl)
+
+			if tt.fields.setup != nil {
+				tt.fields.setup(mockRepo)
+			}
+
+			svc := service.New(mockRepo, []string{"api"})
+
+			slug := tt.slug
+			if slug == "" {
+				slug = "acme"
+			}
+			got, err := svc.CreateWorkspace(context.Background(), dto.CreateWorkspace{Name: "Acme", Slug: slug})
+
+			if tt.want.err != nil {
+				require.ErrorContains(t, err, tt.want.err.Error())
+				require.Empty(t, got)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, "acme", got.Slug)
+			require.NotEmpty(t, got.ID)
+		})
+	}
+}
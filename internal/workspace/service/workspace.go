@@ -0,0 +1,83 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/ilam072/shortener/internal/workspace/repo"
+	"github.com/ilam072/shortener/internal/workspace/types/domain"
+	"github.com/ilam072/shortener/internal/workspace/types/dto"
+	"github.com/ilam072/shortener/pkg/errutils"
+	"strings"
+)
+
+//go:generate mockgen -source=workspace.go -destination=../mocks/service_mocks.go -package=mocks
+type WorkspaceRepo interface {
+	CreateWorkspace(ctx context.Context, workspace domain.Workspace) (domain.Workspace, error)
+	ListWorkspaces(ctx context.Context) ([]domain.Workspace, error)
+}
+
+type Workspace struct {
+	repo     WorkspaceRepo
+	reserved []string
+}
+
+// New creates a workspace service. reserved lists the slugs that would
+// shadow other routes when redirects are addressed by slug.
+func New(repo WorkspaceRepo, reserved []string) *Workspace {
+	return &Workspace{repo: repo, reserved: reserved}
+}
+
+var (
+	ErrSlugAlreadyExists = errors.New("slug already exists")
+	ErrSlugReserved      = errors.New("slug is reserved")
+)
+
+func (w *Workspace) CreateWorkspace(ctx context.Context, workspace dto.CreateWorkspace) (dto.Workspace, error) {
+	const op = "service.workspace.Create"
+
+	for _, reserved := range w.reserved {
+		if strings.EqualFold(workspace.Slug, reserved) {
+			return dto.Workspace{}, errutils.Wrap(op, ErrSlugReserved)
+		}
+	}
+
+	created, err := w.repo.CreateWorkspace(ctx, domain.Workspace{
+		ID:   uuid.New(),
+		Name: workspace.Name,
+		Slug: workspace.Slug,
+	})
+	if err != nil {
+		if errors.Is(err, repo.ErrSlugAlreadyExists) {
+			return dto.Workspace{}, errutils.Wrap(op, ErrSlugAlreadyExists)
+		}
+		return dto.Workspace{}, errutils.Wrap(op, err)
+	}
+
+	return toDTO(created), nil
+}
+
+func (w *Workspace) ListWorkspaces(ctx context.Context) ([]dto.Workspace, error) {
+	const op = "service.workspace.List"
+
+	workspaces, err := w.repo.ListWorkspaces(ctx)
+	if err != nil {
+		return nil, errutils.Wrap(op, err)
+	}
+
+	result := make([]dto.Workspace, 0, len(workspaces))
+	for _, workspace := range workspaces {
+		result = append(result, toDTO(workspace))
+	}
+
+	return result, nil
+}
+
+func toDTO(workspace domain.Workspace) dto.Workspace {
+	return dto.Workspace{
+		ID:        workspace.ID.String(),
+		Name:      workspace.Name,
+		Slug:      workspace.Slug,
+		CreatedAt: workspace.CreatedAt,
+	}
+}
@@ -0,0 +1,13 @@
+package domain
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+type Workspace struct {
+	ID        uuid.UUID
+	Name      string
+	Slug      string
+	CreatedAt time.Time
+}
@@ -0,0 +1,15 @@
+package dto
+
+import "time"
+
+type CreateWorkspace struct {
+	Name string `json:"name" validate:"required,max=100"`
+	Slug string `json:"slug" validate:"required,min=2,max=32,alphanum,lowercase"`
+}
+
+type Workspace struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Slug      string    `json:"slug"`
+	CreatedAt time.Time `json:"created_at"`
+}
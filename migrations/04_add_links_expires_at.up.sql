@@ -0,0 +1 @@
+ALTER TABLE links ADD COLUMN IF NOT EXISTS expires_at TIMESTAMPTZ;
\ No newline at end of file
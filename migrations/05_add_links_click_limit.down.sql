@@ -0,0 +1,3 @@
+ALTER TABLE links
+    DROP COLUMN IF EXISTS max_clicks,
+    DROP COLUMN IF EXISTS click_count;
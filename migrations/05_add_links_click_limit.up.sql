@@ -0,0 +1,3 @@
+ALTER TABLE links
+    ADD COLUMN IF NOT EXISTS max_clicks INTEGER CHECK (max_clicks > 0),
+    ADD COLUMN IF NOT EXISTS click_count INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,3 @@
+ALTER TABLE clicks DROP CONSTRAINT IF EXISTS clicks_alias_fkey;
+ALTER TABLE clicks
+    ADD CONSTRAINT clicks_alias_fkey FOREIGN KEY (alias) REFERENCES links(alias) ON DELETE CASCADE;
@@ -0,0 +1,3 @@
+ALTER TABLE links
+    DROP COLUMN IF EXISTS status,
+    DROP COLUMN IF EXISTS deleted_at;
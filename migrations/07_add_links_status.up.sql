@@ -0,0 +1,4 @@
+ALTER TABLE links
+    ADD COLUMN IF NOT EXISTS status TEXT NOT NULL DEFAULT 'active'
+        CHECK (status IN ('active', 'disabled', 'deleted')),
+    ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_links_owner_id;
+
+ALTER TABLE api_keys DROP COLUMN IF EXISTS owner_id;
+ALTER TABLE links DROP COLUMN IF EXISTS owner_id;
+
+DROP TABLE IF EXISTS users;
@@ -0,0 +1,19 @@
+DROP INDEX IF EXISTS idx_clicks_workspace_link_clicked_at;
+DROP INDEX IF EXISTS idx_links_workspace_id;
+
+ALTER TABLE links DROP CONSTRAINT IF EXISTS links_namespace_alias_key;
+ALTER TABLE links ADD CONSTRAINT links_alias_key UNIQUE (alias);
+
+ALTER TABLE clicks DROP CONSTRAINT IF EXISTS clicks_link_id_fkey;
+ALTER TABLE clicks
+    ADD CONSTRAINT clicks_alias_fkey FOREIGN KEY (alias) REFERENCES links(alias) ON DELETE CASCADE;
+
+ALTER TABLE clicks DROP COLUMN IF EXISTS workspace_id;
+ALTER TABLE clicks DROP COLUMN IF EXISTS link_id;
+
+ALTER TABLE links DROP COLUMN IF EXISTS namespace;
+ALTER TABLE links DROP COLUMN IF EXISTS workspace_id;
+ALTER TABLE api_keys DROP COLUMN IF EXISTS workspace_id;
+ALTER TABLE users DROP COLUMN IF EXISTS workspace_id;
+
+DROP TABLE IF EXISTS workspaces;
@@ -0,0 +1,46 @@
+CREATE TABLE IF NOT EXISTS workspaces (
+    id UUID PRIMARY KEY,
+    name TEXT NOT NULL,
+    slug TEXT UNIQUE NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+INSERT INTO workspaces (id, name, slug)
+VALUES ('00000000-0000-0000-0000-000000000001', 'Default', 'default')
+ON CONFLICT DO NOTHING;
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS workspace_id UUID REFERENCES workspaces(id);
+UPDATE users SET workspace_id = '00000000-0000-0000-0000-000000000001' WHERE workspace_id IS NULL;
+ALTER TABLE users ALTER COLUMN workspace_id SET NOT NULL;
+
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS workspace_id UUID REFERENCES workspaces(id);
+UPDATE api_keys SET workspace_id = '00000000-0000-0000-0000-000000000001' WHERE workspace_id IS NULL;
+ALTER TABLE api_keys ALTER COLUMN workspace_id SET NOT NULL;
+
+-- namespace is '' when aliases are global and the workspace slug when
+-- every workspace has its own aliases, see ALIAS_NAMESPACE.
+ALTER TABLE links ADD COLUMN IF NOT EXISTS workspace_id UUID REFERENCES workspaces(id);
+ALTER TABLE links ADD COLUMN IF NOT EXISTS namespace TEXT NOT NULL DEFAULT '';
+UPDATE links SET workspace_id = '00000000-0000-0000-0000-000000000001' WHERE workspace_id IS NULL;
+ALTER TABLE links ALTER COLUMN workspace_id SET NOT NULL;
+
+-- Aliases are no longer unique on their own, so clicks reference links by id.
+ALTER TABLE clicks ADD COLUMN IF NOT EXISTS link_id UUID;
+ALTER TABLE clicks ADD COLUMN IF NOT EXISTS workspace_id UUID;
+UPDATE clicks c
+SET link_id = l.id, workspace_id = l.workspace_id
+FROM links l
+WHERE l.alias = c.alias AND c.link_id IS NULL;
+DELETE FROM clicks WHERE link_id IS NULL;
+ALTER TABLE clicks ALTER COLUMN link_id SET NOT NULL;
+ALTER TABLE clicks ALTER COLUMN workspace_id SET NOT NULL;
+
+ALTER TABLE clicks DROP CONSTRAINT IF EXISTS clicks_alias_fkey;
+ALTER TABLE clicks
+    ADD CONSTRAINT clicks_link_id_fkey FOREIGN KEY (link_id) REFERENCES links(id) ON DELETE CASCADE;
+
+ALTER TABLE links DROP CONSTRAINT IF EXISTS links_alias_key;
+ALTER TABLE links ADD CONSTRAINT links_namespace_alias_key UNIQUE (namespace, alias);
+
+CREATE INDEX IF NOT EXISTS idx_links_workspace_id ON links(workspace_id);
+CREATE INDEX IF NOT EXISTS idx_clicks_workspace_link_clicked_at ON clicks(workspace_id, link_id, clicked_at);
@@ -0,0 +1,9 @@
+DELETE FROM links WHERE domain_id IS NOT NULL;
+
+DROP INDEX IF EXISTS links_domain_alias_key;
+DROP INDEX IF EXISTS links_namespace_alias_key;
+ALTER TABLE links ADD CONSTRAINT links_namespace_alias_key UNIQUE (namespace, alias);
+
+ALTER TABLE links DROP COLUMN IF EXISTS domain_id;
+
+DROP TABLE IF EXISTS domains;
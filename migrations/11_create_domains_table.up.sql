@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS domains (
+    id UUID PRIMARY KEY,
+    workspace_id UUID NOT NULL REFERENCES workspaces(id),
+    host TEXT UNIQUE NOT NULL,
+    fallback_url TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_domains_workspace_id ON domains(workspace_id);
+
+-- Links without a domain are served from the default hosts and keep their
+-- namespace. Links on a custom domain are unique per (domain, alias).
+ALTER TABLE links ADD COLUMN IF NOT EXISTS domain_id UUID REFERENCES domains(id);
+
+ALTER TABLE links DROP CONSTRAINT IF EXISTS links_namespace_alias_key;
+CREATE UNIQUE INDEX IF NOT EXISTS links_namespace_alias_key ON links(namespace, alias) WHERE domain_id IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS links_domain_alias_key ON links(domain_id, alias) WHERE domain_id IS NOT NULL;
@@ -0,0 +1,3 @@
+-- Visitors are identified by a hash of ip and user agent salted per day,
+-- so the same visitor gets a new id every day.
+ALTER TABLE clicks ADD COLUMN IF NOT EXISTS visitor_id TEXT;
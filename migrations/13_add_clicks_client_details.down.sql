@@ -0,0 +1,4 @@
+ALTER TABLE clicks DROP COLUMN IF EXISTS referrer_host;
+ALTER TABLE clicks DROP COLUMN IF EXISTS os_version;
+ALTER TABLE clicks DROP COLUMN IF EXISTS os_name;
+ALTER TABLE clicks DROP COLUMN IF EXISTS client_version;
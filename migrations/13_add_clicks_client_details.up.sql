@@ -0,0 +1,4 @@
+ALTER TABLE clicks ADD COLUMN IF NOT EXISTS client_version TEXT;
+ALTER TABLE clicks ADD COLUMN IF NOT EXISTS os_name TEXT;
+ALTER TABLE clicks ADD COLUMN IF NOT EXISTS os_version TEXT;
+ALTER TABLE clicks ADD COLUMN IF NOT EXISTS referrer_host TEXT;
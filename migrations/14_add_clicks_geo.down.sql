@@ -0,0 +1,3 @@
+ALTER TABLE clicks DROP COLUMN IF EXISTS city;
+ALTER TABLE clicks DROP COLUMN IF EXISTS region;
+ALTER TABLE clicks DROP COLUMN IF EXISTS country_code;
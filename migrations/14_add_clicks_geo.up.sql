@@ -0,0 +1,3 @@
+ALTER TABLE clicks ADD COLUMN IF NOT EXISTS country_code TEXT;
+ALTER TABLE clicks ADD COLUMN IF NOT EXISTS region TEXT;
+ALTER TABLE clicks ADD COLUMN IF NOT EXISTS city TEXT;
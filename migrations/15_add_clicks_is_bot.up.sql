@@ -0,0 +1,2 @@
+ALTER TABLE clicks ADD COLUMN IF NOT EXISTS is_bot BOOLEAN NOT NULL DEFAULT false;
+UPDATE clicks SET is_bot = true WHERE device_type = 'bot';
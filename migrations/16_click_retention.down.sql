@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_clicks_clicked_at;
+DROP TABLE IF EXISTS click_rollups;
+
+-- Hashed and dropped addresses have neither dots nor colons.
+UPDATE clicks SET ip = NULL WHERE ip !~ '[.:]';
+ALTER TABLE clicks ALTER COLUMN ip TYPE inet USING ip::inet;
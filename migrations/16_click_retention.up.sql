@@ -0,0 +1,20 @@
+-- Addresses are stored anonymized, which inet cannot hold for hashes.
+ALTER TABLE clicks ALTER COLUMN ip TYPE TEXT USING host(ip);
+
+-- Raw clicks past retention are rolled up per UTC day. Every day has a
+-- total row, with an empty dimension, and a row per value of each
+-- dimension. Visitors get a new id every day, so unique clicks of days add
+-- up.
+CREATE TABLE IF NOT EXISTS click_rollups (
+    link_id       UUID      NOT NULL REFERENCES links(id) ON DELETE CASCADE,
+    workspace_id  UUID      NOT NULL,
+    bucket        TIMESTAMP NOT NULL,
+    is_bot        BOOLEAN   NOT NULL,
+    dimension     TEXT      NOT NULL,
+    value         TEXT      NOT NULL,
+    clicks        INTEGER   NOT NULL,
+    unique_clicks INTEGER   NOT NULL,
+    PRIMARY KEY (link_id, dimension, bucket, is_bot, value)
+);
+
+CREATE INDEX IF NOT EXISTS idx_clicks_clicked_at ON clicks(clicked_at);
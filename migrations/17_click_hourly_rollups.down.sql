@@ -0,0 +1,13 @@
+-- Rollups of hours still kept raw would be counted twice.
+DELETE FROM click_rollups r
+WHERE EXISTS (
+    SELECT 1 FROM clicks c
+    WHERE c.link_id = r.link_id AND c.rolled_up AND date_trunc('hour', c.clicked_at) = r.bucket
+);
+
+DROP INDEX IF EXISTS idx_click_rollups_workspace_link_bucket;
+ALTER TABLE click_rollups DROP COLUMN IF EXISTS first_visits;
+
+DROP INDEX IF EXISTS idx_clicks_link_visitor;
+DROP INDEX IF EXISTS idx_clicks_not_rolled_up;
+ALTER TABLE clicks DROP COLUMN IF EXISTS rolled_up;
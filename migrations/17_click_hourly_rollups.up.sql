@@ -0,0 +1,15 @@
+-- Clicks are rolled up by hour as soon as the hour is closed. Raw clicks
+-- are only counted while they are not rolled up.
+ALTER TABLE clicks ADD COLUMN IF NOT EXISTS rolled_up BOOLEAN NOT NULL DEFAULT false;
+
+CREATE INDEX IF NOT EXISTS idx_clicks_not_rolled_up ON clicks(link_id, clicked_at) WHERE NOT rolled_up;
+CREATE INDEX IF NOT EXISTS idx_clicks_link_visitor ON clicks(link_id, visitor_id, clicked_at);
+
+-- first_visits counts the visitors first seen on their UTC day in the
+-- hour, so that the unique clicks of longer buckets add up. Rollups of
+-- whole days count all their visitors in their first hour.
+ALTER TABLE click_rollups ADD COLUMN IF NOT EXISTS first_visits INTEGER;
+UPDATE click_rollups SET first_visits = unique_clicks WHERE first_visits IS NULL;
+ALTER TABLE click_rollups ALTER COLUMN first_visits SET NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_click_rollups_workspace_link_bucket ON click_rollups(workspace_id, link_id, dimension, bucket);
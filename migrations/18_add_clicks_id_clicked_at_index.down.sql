@@ -0,0 +1 @@
+DROP INDEX CONCURRENTLY IF EXISTS clicks_id_clicked_at_key;
@@ -0,0 +1,3 @@
+-- The primary key of the partitioned clicks has to include clicked_at. The
+-- index is built alone, without blocking writes, ahead of the partitioning.
+CREATE UNIQUE INDEX CONCURRENTLY IF NOT EXISTS clicks_id_clicked_at_key ON clicks(id, clicked_at);
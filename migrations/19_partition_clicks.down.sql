@@ -0,0 +1,19 @@
+-- Copies the clicks back into a single table, which blocks writes for as
+-- long as the copy takes.
+CREATE TABLE clicks_unpartitioned (LIKE clicks INCLUDING DEFAULTS);
+INSERT INTO clicks_unpartitioned SELECT * FROM clicks;
+
+DROP TABLE clicks;
+ALTER TABLE clicks_unpartitioned RENAME TO clicks;
+
+ALTER TABLE clicks ADD CONSTRAINT clicks_pkey PRIMARY KEY (id);
+ALTER TABLE clicks ALTER COLUMN clicked_at DROP NOT NULL;
+ALTER TABLE clicks
+    ADD CONSTRAINT clicks_link_id_fkey FOREIGN KEY (link_id) REFERENCES links(id) ON DELETE CASCADE;
+CREATE INDEX IF NOT EXISTS idx_clicks_alias_clicked_at ON clicks(alias, clicked_at);
+CREATE INDEX IF NOT EXISTS idx_clicks_alias_client ON clicks(alias, client_name);
+CREATE INDEX IF NOT EXISTS idx_clicks_alias_device ON clicks(alias, device_type);
+CREATE INDEX IF NOT EXISTS idx_clicks_workspace_link_clicked_at ON clicks(workspace_id, link_id, clicked_at);
+CREATE INDEX IF NOT EXISTS idx_clicks_clicked_at ON clicks(clicked_at);
+CREATE INDEX IF NOT EXISTS idx_clicks_not_rolled_up ON clicks(link_id, clicked_at) WHERE NOT rolled_up;
+CREATE INDEX IF NOT EXISTS idx_clicks_link_visitor ON clicks(link_id, visitor_id, clicked_at);
@@ -0,0 +1,61 @@
+-- Clicks are partitioned by month of clicked_at. The existing table becomes
+-- the clicks_legacy partition, holding everything before the bound, so no
+-- row is copied. Its bound is checked while writes go on, and only
+-- catalog changes are made under the exclusive lock. The app creates the
+-- monthly partitions from the bound on and expires the old ones.
+
+-- The bound leaves a month of clicks to the legacy partition, so that no
+-- click stamped while the migration runs falls past it.
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'clicks_legacy_bound') THEN
+        EXECUTE format(
+            'ALTER TABLE clicks ADD CONSTRAINT clicks_legacy_bound CHECK (clicked_at IS NOT NULL AND clicked_at < %L) NOT VALID',
+            date_trunc('month', now() AT TIME ZONE 'UTC') + interval '2 months'
+        );
+    END IF;
+END $$;
+COMMIT;
+
+ALTER TABLE clicks VALIDATE CONSTRAINT clicks_legacy_bound;
+COMMIT;
+
+-- Backed by the validated check, neither of these scans the table.
+ALTER TABLE clicks ALTER COLUMN clicked_at SET NOT NULL;
+ALTER TABLE clicks DROP CONSTRAINT clicks_pkey;
+ALTER TABLE clicks ADD CONSTRAINT clicks_legacy_pkey PRIMARY KEY USING INDEX clicks_id_clicked_at_key;
+
+ALTER TABLE clicks RENAME TO clicks_legacy;
+ALTER TABLE clicks_legacy RENAME CONSTRAINT clicks_link_id_fkey TO clicks_legacy_link_id_fkey;
+ALTER INDEX idx_clicks_workspace_link_clicked_at RENAME TO idx_clicks_legacy_workspace_link_clicked_at;
+ALTER INDEX idx_clicks_clicked_at RENAME TO idx_clicks_legacy_clicked_at;
+ALTER INDEX idx_clicks_not_rolled_up RENAME TO idx_clicks_legacy_not_rolled_up;
+ALTER INDEX idx_clicks_link_visitor RENAME TO idx_clicks_legacy_link_visitor;
+
+CREATE TABLE clicks (LIKE clicks_legacy INCLUDING DEFAULTS) PARTITION BY RANGE (clicked_at);
+ALTER TABLE clicks ADD CONSTRAINT clicks_pkey PRIMARY KEY (id, clicked_at);
+ALTER TABLE clicks
+    ADD CONSTRAINT clicks_link_id_fkey FOREIGN KEY (link_id) REFERENCES links(id) ON DELETE CASCADE;
+CREATE INDEX idx_clicks_workspace_link_clicked_at ON clicks(workspace_id, link_id, clicked_at);
+CREATE INDEX idx_clicks_clicked_at ON clicks(clicked_at);
+CREATE INDEX idx_clicks_not_rolled_up ON clicks(link_id, clicked_at) WHERE NOT rolled_up;
+CREATE INDEX idx_clicks_link_visitor ON clicks(link_id, visitor_id, clicked_at);
+
+-- The indexes and constraints of the legacy table match the ones of
+-- clicks, so they are attached as they are.
+DO $$
+DECLARE
+    bound TIMESTAMP;
+BEGIN
+    SELECT substring(pg_get_constraintdef(oid) FROM 'clicked_at < ''([^'']+)''')::timestamp INTO bound
+    FROM pg_constraint
+    WHERE conname = 'clicks_legacy_bound';
+
+    EXECUTE format('ALTER TABLE clicks ATTACH PARTITION clicks_legacy FOR VALUES FROM (MINVALUE) TO (%L)', bound);
+    EXECUTE format(
+        'CREATE TABLE clicks_%s PARTITION OF clicks FOR VALUES FROM (%L) TO (%L)',
+        to_char(bound, 'YYYY_MM'), bound, bound + interval '1 month'
+    );
+END $$;
+
+ALTER TABLE clicks_legacy DROP CONSTRAINT clicks_legacy_bound;
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_links_not_expired;
+ALTER TABLE links DROP COLUMN IF EXISTS expired_at;
+
+DROP TABLE IF EXISTS webhook_dead_letters;
+DROP TABLE IF EXISTS webhooks;
@@ -0,0 +1,35 @@
+CREATE TABLE IF NOT EXISTS webhooks (
+    id UUID PRIMARY KEY,
+    workspace_id UUID NOT NULL REFERENCES workspaces(id),
+    url TEXT NOT NULL,
+    secret TEXT NOT NULL,
+    events TEXT[] NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhooks_workspace_id ON webhooks(workspace_id);
+
+-- Deliveries that failed every attempt, kept until they are redelivered.
+CREATE TABLE IF NOT EXISTS webhook_dead_letters (
+    id UUID PRIMARY KEY,
+    webhook_id UUID NOT NULL REFERENCES webhooks(id) ON DELETE CASCADE,
+    workspace_id UUID NOT NULL REFERENCES workspaces(id),
+    event_id UUID NOT NULL,
+    event_type TEXT NOT NULL,
+    payload JSONB NOT NULL,
+    attempts INTEGER NOT NULL,
+    last_error TEXT NOT NULL,
+    failed_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_dead_letters_webhook_id ON webhook_dead_letters(webhook_id, failed_at);
+
+-- expired_at is set once link.expired has been emitted for a link, by
+-- time or by click limit. Links expired before webhooks existed are not
+-- announced.
+ALTER TABLE links ADD COLUMN IF NOT EXISTS expired_at TIMESTAMPTZ;
+UPDATE links SET expired_at = now()
+WHERE expires_at <= now() OR click_count >= max_clicks;
+
+CREATE INDEX IF NOT EXISTS idx_links_not_expired ON links(id)
+    WHERE expired_at IS NULL AND (expires_at IS NOT NULL OR max_clicks IS NOT NULL);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_webhook_dead_letters_next_attempt_at;
+ALTER TABLE webhook_dead_letters DROP COLUMN IF EXISTS next_attempt_at;
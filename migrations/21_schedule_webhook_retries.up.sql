@@ -0,0 +1,7 @@
+-- Failed deliveries are retried from the dead letters on a schedule: a
+-- letter is due again at next_attempt_at, and is left for a manual
+-- redelivery once it is out of attempts and next_attempt_at is NULL.
+ALTER TABLE webhook_dead_letters ADD COLUMN IF NOT EXISTS next_attempt_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_webhook_dead_letters_next_attempt_at ON webhook_dead_letters(next_attempt_at)
+    WHERE next_attempt_at IS NOT NULL;
@@ -0,0 +1,9 @@
+-- Released links keep their clicks, under an alias of their own.
+UPDATE links SET alias = alias || '~' || id WHERE released_at IS NOT NULL;
+
+DROP INDEX IF EXISTS links_namespace_alias_key;
+DROP INDEX IF EXISTS links_domain_alias_key;
+CREATE UNIQUE INDEX IF NOT EXISTS links_namespace_alias_key ON links(namespace, alias) WHERE domain_id IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS links_domain_alias_key ON links(domain_id, alias) WHERE domain_id IS NOT NULL;
+
+ALTER TABLE links DROP COLUMN IF EXISTS released_at;
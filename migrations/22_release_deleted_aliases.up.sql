@@ -0,0 +1,11 @@
+-- The alias of a deleted link is released, rather than the link deleted,
+-- once a new link takes it after the quarantine, so that its clicks are
+-- kept. Released links do not hold their alias.
+ALTER TABLE links ADD COLUMN IF NOT EXISTS released_at TIMESTAMPTZ;
+
+DROP INDEX IF EXISTS links_namespace_alias_key;
+DROP INDEX IF EXISTS links_domain_alias_key;
+CREATE UNIQUE INDEX IF NOT EXISTS links_namespace_alias_key ON links(namespace, alias)
+    WHERE domain_id IS NULL AND released_at IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS links_domain_alias_key ON links(domain_id, alias)
+    WHERE domain_id IS NOT NULL AND released_at IS NULL;
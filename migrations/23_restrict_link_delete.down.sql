@@ -0,0 +1,7 @@
+ALTER TABLE click_rollups DROP CONSTRAINT IF EXISTS click_rollups_link_id_fkey;
+ALTER TABLE click_rollups
+    ADD CONSTRAINT click_rollups_link_id_fkey FOREIGN KEY (link_id) REFERENCES links(id) ON DELETE CASCADE;
+
+ALTER TABLE clicks DROP CONSTRAINT IF EXISTS clicks_link_id_fkey;
+ALTER TABLE clicks
+    ADD CONSTRAINT clicks_link_id_fkey FOREIGN KEY (link_id) REFERENCES links(id) ON DELETE CASCADE;
@@ -0,0 +1,10 @@
+-- Links are deleted by status, and their aliases released, so that their
+-- analytics are kept. Deleting a link that has clicks or rollups fails
+-- instead of erasing them.
+ALTER TABLE clicks DROP CONSTRAINT IF EXISTS clicks_link_id_fkey;
+ALTER TABLE clicks
+    ADD CONSTRAINT clicks_link_id_fkey FOREIGN KEY (link_id) REFERENCES links(id) ON DELETE RESTRICT;
+
+ALTER TABLE click_rollups DROP CONSTRAINT IF EXISTS click_rollups_link_id_fkey;
+ALTER TABLE click_rollups
+    ADD CONSTRAINT click_rollups_link_id_fkey FOREIGN KEY (link_id) REFERENCES links(id) ON DELETE RESTRICT;